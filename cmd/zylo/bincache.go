@@ -0,0 +1,109 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Los ejecutables de zylo run --compile se guardan en una caché indexada por
+// el hash del código Go generado, así que volver a ejecutar un script sin
+// cambios no compila nada. Cada entrada se nombra <script>-<código>, con el
+// hash de la ruta del script y el del código: al cambiar el script se genera
+// otro código, y la compilación nueva borra las entradas anteriores del mismo
+// script. El archivo .go se conserva junto al ejecutable porque los errores en
+// tiempo de ejecución lo citan y el source map los traduce desde esa ruta.
+
+// goBuildError es un error de go build con la salida del compilador
+type goBuildError struct {
+	output string
+}
+
+func (e *goBuildError) Error() string {
+	return e.output
+}
+
+// binaryCacheDir devuelve el directorio de la caché de ejecutables
+func binaryCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "zylo", "bin")
+	}
+	return filepath.Join(dir, "zylo", "bin")
+}
+
+// buildGoBinary compila goCode, generado a partir de sourcePath, en dir y
+// devuelve las rutas del ejecutable y de su archivo .go. Si el ejecutable ya
+// estaba en la caché no se compila y cached es true. Un fallo de go build se
+// devuelve como *goBuildError, junto con la ruta del archivo .go.
+func buildGoBinary(dir, sourcePath, goCode string) (binary, goFile string, cached bool, err error) {
+	script, key := binaryKey(sourcePath, goCode)
+	name := script + "-" + key
+	goFile = filepath.Join(dir, name+".go")
+	binary = filepath.Join(dir, name)
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+
+	if _, err := os.Stat(binary); err == nil {
+		if _, err := os.Stat(goFile); err == nil {
+			return binary, goFile, true, nil
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", "", false, err
+	}
+	if err := os.WriteFile(goFile, []byte(goCode), 0644); err != nil {
+		return "", "", false, err
+	}
+
+	// Compilar a un nombre temporal para que otra ejecución nunca vea un
+	// ejecutable a medio escribir
+	tmp, err := os.CreateTemp(dir, name+".tmp*")
+	if err != nil {
+		return "", "", false, err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	output, err := exec.Command("go", "build", "-o", tmp.Name(), goFile).CombinedOutput()
+	if err != nil {
+		return "", goFile, false, &goBuildError{output: string(output)}
+	}
+	if err := os.Rename(tmp.Name(), binary); err != nil {
+		return "", "", false, err
+	}
+
+	removeStaleBinaries(dir, script, name)
+	return binary, goFile, false, nil
+}
+
+// binaryKey devuelve el hash de la ruta absoluta del script y el del código
+func binaryKey(sourcePath, goCode string) (script, code string) {
+	if abs, err := filepath.Abs(sourcePath); err == nil {
+		sourcePath = abs
+	}
+	scriptSum := sha256.Sum256([]byte(sourcePath))
+	codeSum := sha256.Sum256([]byte(goCode))
+	return hex.EncodeToString(scriptSum[:8]), hex.EncodeToString(codeSum[:8])
+}
+
+// removeStaleBinaries borra de dir las entradas de script distintas de keep
+func removeStaleBinaries(dir, script, keep string) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, script+"-") || strings.HasPrefix(name, keep) {
+			continue
+		}
+		os.Remove(filepath.Join(dir, name))
+	}
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBuildGoBinaryCaches(t *testing.T) {
+	if !goToolchainAvailable() {
+		t.Skip("toolchain de Go no disponible")
+	}
+
+	dir := t.TempDir()
+	source := filepath.Join(dir, "script.zylo")
+	program := func(message string) string {
+		return "package main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Println(\"" + message + "\")\n}\n"
+	}
+
+	first, _, cached, err := buildGoBinary(dir, source, program("uno"))
+	if err != nil {
+		t.Fatalf("error compilando: %v", err)
+	}
+	if cached {
+		t.Fatal("la primera compilación no puede venir de la caché")
+	}
+
+	again, _, cached, err := buildGoBinary(dir, source, program("uno"))
+	if err != nil {
+		t.Fatalf("error compilando: %v", err)
+	}
+	if !cached || again != first {
+		t.Fatalf("se esperaba reutilizar %s, obtenido %s (cached=%v)", first, again, cached)
+	}
+
+	// Un cambio en el script genera otro código: se compila de nuevo y la
+	// entrada anterior se borra
+	second, _, cached, err := buildGoBinary(dir, source, program("dos"))
+	if err != nil {
+		t.Fatalf("error compilando: %v", err)
+	}
+	if cached || second == first {
+		t.Fatalf("se esperaba un ejecutable nuevo, obtenido %s (cached=%v)", second, cached)
+	}
+	if _, err := os.Stat(first); !os.IsNotExist(err) {
+		t.Fatalf("el ejecutable anterior %s debería haberse borrado", first)
+	}
+
+	out, err := exec.Command(second).Output()
+	if err != nil {
+		t.Fatalf("error ejecutando %s: %v", second, err)
+	}
+	if string(out) != "dos\n" {
+		t.Fatalf("salida incorrecta: %q", out)
+	}
+
+	// Otro script no comparte entradas con el primero
+	other, _, cached, err := buildGoBinary(dir, filepath.Join(dir, "otro.zylo"), program("dos"))
+	if err != nil {
+		t.Fatalf("error compilando: %v", err)
+	}
+	if cached || other == second {
+		t.Fatalf("scripts distintos no deberían compartir ejecutable: %s", other)
+	}
+	if _, err := os.Stat(second); err != nil {
+		t.Fatalf("compilar otro script no debe borrar %s: %v", second, err)
+	}
+}
+
+func TestBuildGoBinaryError(t *testing.T) {
+	if !goToolchainAvailable() {
+		t.Skip("toolchain de Go no disponible")
+	}
+
+	dir := t.TempDir()
+	_, goFile, _, err := buildGoBinary(dir, "script.zylo", "package main\n\nfunc main() {\n\tx := 1\n}\n")
+	var buildErr *goBuildError
+	if !errors.As(err, &buildErr) {
+		t.Fatalf("se esperaba un goBuildError, obtenido %v", err)
+	}
+	if !strings.Contains(buildErr.output, "declared and not used") {
+		t.Fatalf("la salida de go build no contiene el error: %q", buildErr.output)
+	}
+	if !strings.HasPrefix(goFile, dir) {
+		t.Fatalf("se esperaba la ruta del archivo .go en %s, obtenido %q", dir, goFile)
+	}
+}
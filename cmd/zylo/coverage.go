@@ -0,0 +1,99 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/evaluator"
+)
+
+// coverageReport es el informe de cobertura de zylo test --coverage-out
+type coverageReport struct {
+	Files   []evaluator.FileCoverage `json:"files"`
+	Lines   int                      `json:"lines"`
+	Covered int                      `json:"covered"`
+	Percent float64                  `json:"percent"`
+}
+
+func newCoverageReport(files []evaluator.FileCoverage) coverageReport {
+	report := coverageReport{Files: files, Percent: 100}
+	for _, f := range files {
+		report.Lines += f.Lines
+		report.Covered += f.Covered
+	}
+	if report.Lines > 0 {
+		report.Percent = float64(report.Covered) * 100 / float64(report.Lines)
+	}
+	return report
+}
+
+// printCoverage muestra la cobertura de cada archivo y la total
+func printCoverage(files []evaluator.FileCoverage) {
+	fmt.Println(colorize("📈 Cobertura:", ColorCyan))
+	if len(files) == 0 {
+		fmt.Println(colorize("  Los tests no importan ningún módulo", ColorGray))
+		return
+	}
+	for _, f := range files {
+		fmt.Printf("  %-40s %6.1f%% (%d/%d líneas)\n", f.File, f.Percent(), f.Covered, f.Lines)
+	}
+	report := newCoverageReport(files)
+	fmt.Printf("  %-40s %6.1f%% (%d/%d líneas)\n", "total", report.Percent, report.Covered, report.Lines)
+}
+
+// writeCoverageReport escribe el informe en path: HTML si la extensión es
+// .html y JSON en otro caso
+func writeCoverageReport(path string, files []evaluator.FileCoverage) error {
+	report := newCoverageReport(files)
+	if strings.EqualFold(filepath.Ext(path), ".html") {
+		out, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := coverageHTML.Execute(out, report); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	}
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+var coverageHTML = template.Must(template.New("coverage").Funcs(template.FuncMap{
+	"lines": func(missed []int) string {
+		parts := make([]string, len(missed))
+		for i, line := range missed {
+			parts[i] = fmt.Sprint(line)
+		}
+		return strings.Join(parts, ", ")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Cobertura de Zylo</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 4px 10px; text-align: left; }
+td.pct { text-align: right; }
+</style>
+</head>
+<body>
+<h1>Cobertura: {{printf "%.1f" .Percent}}% ({{.Covered}}/{{.Lines}} líneas)</h1>
+<table>
+<tr><th>Archivo</th><th>Cobertura</th><th>Líneas</th><th>Sin ejecutar</th></tr>
+{{range .Files}}<tr><td>{{.File}}</td><td class="pct">{{printf "%.1f" .Percent}}%</td><td>{{.Covered}}/{{.Lines}}</td><td>{{lines .Missed}}</td></tr>
+{{end}}</table>
+</body>
+</html>
+`))
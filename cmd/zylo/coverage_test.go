@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/evaluator"
+)
+
+func TestRunTestsCoverage(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"util.zylo":      "func clasificar(n) {\n    if n > 0 {\n        return \"positivo\"\n    }\n    return \"no positivo\"\n}\n",
+		"util_test.zylo": "import util\nassert_eq(util.clasificar(1), \"positivo\")\n",
+	}
+	for name, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatalf("error escribiendo %s: %v", name, err)
+		}
+	}
+
+	coverage := evaluator.NewCoverage()
+	out := captureStdout(t, func() {
+		runTests([]string{filepath.Join(dir, "util_test.zylo")}, false, coverage)
+		printCoverage(coverage.Report())
+	})
+	if !strings.Contains(out, "75.0% (3/4 líneas)") {
+		t.Errorf("se esperaba una cobertura del 75%%:\n%s", out)
+	}
+
+	path := filepath.Join(dir, "cobertura.json")
+	if err := writeCoverageReport(path, coverage.Report()); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("error leyendo el informe: %v", err)
+	}
+	var report coverageReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("informe JSON inválido: %v\n%s", err, data)
+	}
+	if report.Lines != 4 || report.Covered != 3 || len(report.Files) != 1 || report.Files[0].Missed[0] != 5 {
+		t.Errorf("informe incorrecto: %+v", report)
+	}
+
+	htmlPath := filepath.Join(dir, "cobertura.html")
+	if err := writeCoverageReport(htmlPath, coverage.Report()); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if html, _ := os.ReadFile(htmlPath); !strings.Contains(string(html), "util.zylo") {
+		t.Errorf("el informe HTML no incluye el módulo:\n%s", html)
+	}
+}
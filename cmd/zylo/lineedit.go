@@ -0,0 +1,216 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// completer devuelve los candidatos para completar el final de una línea y la
+// posición donde empieza la palabra que reemplazan
+type completer func(line string) (start int, candidates []string)
+
+// lineEditor lee líneas del REPL. Si la entrada es una terminal la pone en
+// modo sin buffer mientras se escribe, para atender Tab (autocompletado),
+// las flechas arriba y abajo (historial), borrar y Ctrl-D; si no, lee líneas
+// completas.
+type lineEditor struct {
+	in          *bufio.Reader
+	out         io.Writer
+	complete    completer
+	fd          int      // Descriptor de la terminal de entrada
+	terminal    bool     // Si la entrada es una terminal
+	history     []string // Líneas escritas, la más reciente al final
+	historyFile string   // Archivo donde se guarda el historial; "" si no se guarda
+}
+
+// maxHistory es el número de líneas del historial que se conservan
+const maxHistory = 1000
+
+func newLineEditor(in *os.File, out io.Writer, complete completer) *lineEditor {
+	fd := int(in.Fd())
+	return &lineEditor{
+		in:       bufio.NewReader(in),
+		out:      out,
+		complete: complete,
+		fd:       fd,
+		terminal: isTerminal(fd),
+	}
+}
+
+// readLine muestra prompt y devuelve la línea escrita, sin el salto de línea.
+// Devuelve io.EOF al terminar la entrada.
+func (ed *lineEditor) readLine(prompt string) (string, error) {
+	fmt.Fprint(ed.out, prompt)
+	if !ed.terminal {
+		line, err := ed.in.ReadString('\n')
+		if err == io.EOF && line != "" {
+			err = nil
+		}
+		return strings.TrimRight(line, "\r\n"), err
+	}
+
+	restore, err := makeRaw(ed.fd)
+	if err != nil {
+		ed.terminal = false
+		return ed.readLine("")
+	}
+	defer restore()
+	line, err := ed.edit(prompt)
+	if err == nil {
+		ed.addHistory(line)
+	}
+	return line, err
+}
+
+// loadHistory carga el historial guardado en path y hace que las líneas
+// nuevas se añadan a ese archivo. Si el archivo no existe se creará al
+// escribir la primera línea.
+func (ed *lineEditor) loadHistory(path string) {
+	ed.historyFile = path
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if line != "" {
+			ed.history = append(ed.history, line)
+		}
+	}
+	if len(ed.history) > maxHistory {
+		ed.history = ed.history[len(ed.history)-maxHistory:]
+	}
+}
+
+// addHistory añade line al historial, salvo si está vacía o repite la
+// anterior, y la guarda en el archivo del historial
+func (ed *lineEditor) addHistory(line string) {
+	line = strings.TrimSpace(line)
+	if line == "" || (len(ed.history) > 0 && ed.history[len(ed.history)-1] == line) {
+		return
+	}
+	ed.history = append(ed.history, line)
+	if len(ed.history) > maxHistory {
+		ed.history = ed.history[1:]
+	}
+	if ed.historyFile == "" {
+		return
+	}
+	f, err := os.OpenFile(ed.historyFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	fmt.Fprintln(f, line)
+}
+
+// edit procesa las teclas de una línea hasta Enter
+func (ed *lineEditor) edit(prompt string) (string, error) {
+	var line []rune
+	redraw := func() {
+		fmt.Fprintf(ed.out, "\r\033[K%s%s", prompt, string(line))
+	}
+	// Posición en el historial; len(ed.history) es la línea nueva, que se
+	// guarda en draft mientras se recorre el historial
+	position := len(ed.history)
+	var draft []rune
+	recall := func(to int) {
+		if to < 0 || to > len(ed.history) || to == position {
+			return
+		}
+		if position == len(ed.history) {
+			draft = line
+		}
+		position = to
+		if to == len(ed.history) {
+			line = draft
+		} else {
+			line = []rune(ed.history[to])
+		}
+		redraw()
+	}
+
+	for {
+		r, _, err := ed.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(ed.out, "\r\n")
+			return string(line), nil
+		case 4: // Ctrl-D
+			if len(line) == 0 {
+				fmt.Fprint(ed.out, "\r\n")
+				return "", io.EOF
+			}
+		case 3: // Ctrl-C descarta la línea
+			fmt.Fprint(ed.out, "^C\r\n")
+			line = nil
+			position = len(ed.history)
+			fmt.Fprint(ed.out, prompt)
+		case 127, 8: // Retroceso
+			if len(line) > 0 {
+				line = line[:len(line)-1]
+				redraw()
+			}
+		case '\t':
+			line = ed.completeLine(line, prompt)
+			redraw()
+		case 27: // Secuencias de escape: arriba y abajo recorren el historial
+			if next, _ := ed.in.Peek(1); len(next) == 1 && next[0] == '[' {
+				ed.in.ReadByte()
+				key, _ := ed.in.ReadByte()
+				switch key {
+				case 'A':
+					recall(position - 1)
+				case 'B':
+					recall(position + 1)
+				}
+			}
+		default:
+			if r >= ' ' {
+				line = append(line, r)
+				fmt.Fprint(ed.out, string(r))
+			}
+		}
+	}
+}
+
+// completeLine completa la palabra al final de line: con un solo candidato la
+// reemplaza; con varios la extiende hasta el prefijo común y, si ya no se
+// puede extender, muestra los candidatos
+func (ed *lineEditor) completeLine(line []rune, prompt string) []rune {
+	if ed.complete == nil {
+		return line
+	}
+	text := string(line)
+	start, candidates := ed.complete(text)
+	if len(candidates) == 0 {
+		return line
+	}
+
+	completion := commonPrefix(candidates)
+	if len(candidates) == 1 {
+		completion = candidates[0]
+	}
+	if len(completion) > len(text)-start {
+		return []rune(text[:start] + completion)
+	}
+
+	fmt.Fprintf(ed.out, "\r\n%s\r\n", strings.Join(candidates, "  "))
+	return line
+}
+
+// commonPrefix devuelve el prefijo común más largo de words
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
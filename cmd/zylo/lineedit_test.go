@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// editLine simula que se escriben las teclas keys en una terminal
+func editLine(t *testing.T, keys string, complete completer) (string, string) {
+	t.Helper()
+	var out strings.Builder
+	ed := &lineEditor{in: bufio.NewReader(strings.NewReader(keys)), out: &out, complete: complete, terminal: true}
+	line, err := ed.edit("> ")
+	if err != nil {
+		t.Fatalf("%q: error inesperado: %v", keys, err)
+	}
+	return line, out.String()
+}
+
+func TestLineEditorCompletesDefinedVariables(t *testing.T) {
+	eval := evaluator.NewEvaluator()
+	p := parser.New(lexer.New("contador := 1\ncontenido := \"x\"\nnombre := \"zylo\"\n"))
+	program := p.ParseProgram()
+	if err := eval.EvaluateProgram(program); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	tests := []struct {
+		keys     string
+		expected string
+		shown    string // Candidatos que se muestran al no poder extender
+	}{
+		{"nom\t\r", "nombre", ""},
+		{"show.log(nom\t)\r", "show.log(nombre)", ""},
+		// Dos candidatos: se extiende hasta el prefijo común
+		{"con\t\r", "cont", ""},
+		// Con el prefijo común ya escrito, Tab muestra los candidatos
+		{"cont\t\r", "cont", "contador  contains  contenido"},
+		{"xyz\t\r", "xyz", ""},
+		{"nombrx\x7fe\r", "nombre", ""},
+	}
+
+	for _, tt := range tests {
+		line, out := editLine(t, tt.keys, eval.Complete)
+		if line != tt.expected {
+			t.Errorf("%q: línea %q, se esperaba %q", tt.keys, line, tt.expected)
+		}
+		if tt.shown != "" && !strings.Contains(out, tt.shown) {
+			t.Errorf("%q: se esperaba que se mostrara %q, salida %q", tt.keys, tt.shown, out)
+		}
+	}
+}
+
+func TestLineEditorEOF(t *testing.T) {
+	ed := &lineEditor{in: bufio.NewReader(strings.NewReader("\x04")), out: io.Discard, terminal: true}
+	if _, err := ed.edit("> "); err != io.EOF {
+		t.Errorf("Ctrl-D en una línea vacía debe devolver io.EOF, obtenido %v", err)
+	}
+}
+
+func TestLineEditorHistory(t *testing.T) {
+	const up, down = "\x1b[A", "\x1b[B"
+	tests := []struct {
+		keys     string
+		expected string
+	}{
+		{up + "\r", "y := 2"},
+		{up + up + "\r", "x := 1"},
+		// No se sube más allá de la primera línea
+		{up + up + up + "\r", "x := 1"},
+		{up + up + down + "\r", "y := 2"},
+		// Al bajar del todo se recupera lo que se estaba escribiendo
+		{"abc" + up + down + "\r", "abc"},
+		{up + "0\r", "y := 20"},
+		{down + "\r", ""},
+	}
+
+	for _, tt := range tests {
+		var out strings.Builder
+		ed := &lineEditor{in: bufio.NewReader(strings.NewReader(tt.keys)), out: &out, terminal: true, history: []string{"x := 1", "y := 2"}}
+		line, err := ed.edit("> ")
+		if err != nil {
+			t.Fatalf("%q: error inesperado: %v", tt.keys, err)
+		}
+		if line != tt.expected {
+			t.Errorf("%q: línea %q, se esperaba %q", tt.keys, line, tt.expected)
+		}
+	}
+}
+
+func TestLineEditorHistoryFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".zylo_history")
+	if err := os.WriteFile(path, []byte("x := 1\n"), 0600); err != nil {
+		t.Fatalf("error escribiendo historial: %v", err)
+	}
+
+	ed := &lineEditor{out: io.Discard}
+	ed.loadHistory(path)
+	ed.addHistory("y := 2")
+	ed.addHistory("y := 2") // Las repeticiones seguidas no se guardan
+	ed.addHistory("   ")
+
+	// Otra sesión ve el historial de la anterior
+	other := &lineEditor{out: io.Discard}
+	other.loadHistory(path)
+	if strings.Join(other.history, "|") != "x := 1|y := 2" {
+		t.Fatalf("historial incorrecto: %q", other.history)
+	}
+}
@@ -1,19 +1,28 @@
 package main
 
 import (
-	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"go/format"
+	"io"
 	"io/ioutil"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strconv"
 	"strings"
 
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/build"
 	"github.com/zylo-lang/zylo/internal/codegen"
 	"github.com/zylo-lang/zylo/internal/evaluator"
 	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/packages"
 	"github.com/zylo-lang/zylo/internal/parser"
 	"github.com/zylo-lang/zylo/internal/sema"
+	"github.com/zylo-lang/zylo/internal/stdlib"
 )
 
 const Version = "1.0.0"
@@ -41,6 +50,7 @@ func printUsage() {
 	fmt.Println()
 	fmt.Println(colorize("COMANDOS BÁSICOS:", ColorYellow))
 	fmt.Println("  run <archivo>     Ejecuta un script Zylo")
+	fmt.Println("  compile <archivo> Genera un ejecutable nativo (-o <ruta>, requiere toolchain de Go)")
 	fmt.Println("  repl              Inicia REPL interactivo")
 	fmt.Println("  test              Ejecuta tests automáticos")
 	fmt.Println("  version           Muestra versión")
@@ -52,8 +62,8 @@ func printUsage() {
 	fmt.Println("  lint [archivo]    Detecta errores")
 	fmt.Println("  debug <archivo>   Ejecuta con debug")
 	fmt.Println("  doc [archivo]     Genera documentación")
-	fmt.Println("  deps              Lista dependencias")
-	fmt.Println("  add <paquete>     Instala paquetes")
+	fmt.Println("  deps [--sync]     Lista dependencias (--sync instala las de zylo.lock)")
+	fmt.Println("  add <paquete>     Instala un paquete (nombre o URL git, @versión opcional)")
 	fmt.Println()
 	fmt.Println(colorize("SERVIDOR:", ColorYellow))
 	fmt.Println("  serve [proyecto]  Inicia servidor HTTP")
@@ -65,13 +75,29 @@ func printUsage() {
 	fmt.Println(colorize("FLAGS:", ColorYellow))
 	fmt.Println("  -v, --verbose     Modo verbose")
 	fmt.Println("  -w, --watch       Modo watch")
+	fmt.Println("  --compile         Compila a Go antes de ejecutar (requiere toolchain de Go)")
+	fmt.Println("  --interpret       Ejecuta con el intérprete (por defecto)")
+	fmt.Println("  --json            Diagnósticos en JSON (lint, run)")
+	fmt.Println("  --strict          Sin conversiones implícitas a los tipos declarados (run)")
+	fmt.Println("  --profile         Muestra el tiempo y las llamadas de cada función al terminar (run)")
+	fmt.Println("  --sourcemap <f>   Escribe el source map Go→Zylo en f (run --compile)")
+	fmt.Println("  --no-cache        Compila sin usar la caché de código ni de ejecutables")
+	fmt.Println("  --emit-go <f>     Escribe el código Go generado en f sin ejecutar (run)")
+	fmt.Println("  --trace           Muestra cada llamada a función y su resultado (debug)")
+	fmt.Println("  --coverage        Mide la cobertura de líneas de los módulos (test)")
+	fmt.Println("  --coverage-out <f> Escribe el informe de cobertura en f (.json o .html)")
+	fmt.Println("  --                Pasa lo que sigue al script aunque parezcan flags (run)")
 	fmt.Println("  -h, --help        Muestra ayuda")
 	fmt.Println()
 	fmt.Println(colorize("EJEMPLOS:", ColorYellow))
 	fmt.Println("  zylo run hello.zylo")
+	fmt.Println("  zylo run --compile hello.zylo")
+	fmt.Println("  zylo compile hello.zylo -o hello")
+	fmt.Println("  zylo run script.zylo arg1 arg2    (os.args() devuelve [arg1, arg2])")
 	fmt.Println("  zylo init mi-app")
 	fmt.Println("  zylo test")
 	fmt.Println("  zylo run --watch script.zylo")
+	fmt.Println("  zylo debug --trace script.zylo")
 }
 
 func main() {
@@ -85,6 +111,16 @@ func main() {
 	// Parsear flags globales
 	verbose := false
 	watch := false
+	compile := false
+	jsonOutput := false
+	noCache := false
+	sourceMapPath := ""
+	emitGoPath := ""
+	coverage := false
+	coverageOut := ""
+	trace := false
+	strict := false
+	profile := false
 
 	args := os.Args[2:]
 	var filteredArgs []string
@@ -94,9 +130,45 @@ func main() {
 			verbose = true
 		case "-w", "--watch":
 			watch = true
+		case "--compile":
+			compile = true
+		case "--interpret":
+			compile = false
+		case "--json":
+			jsonOutput = true
+		case "--strict":
+			strict = true
+		case "--profile":
+			profile = true
+		case "--no-cache":
+			noCache = true
+		case "--sourcemap":
+			if i+1 < len(args) {
+				i++
+				sourceMapPath = args[i]
+			}
+		case "--emit-go":
+			if i+1 < len(args) {
+				i++
+				emitGoPath = args[i]
+			}
+		case "--trace":
+			trace = true
+		case "--coverage":
+			coverage = true
+		case "--coverage-out":
+			if i+1 < len(args) {
+				i++
+				coverage = true
+				coverageOut = args[i]
+			}
 		case "-h", "--help":
 			printUsage()
 			return
+		case "--":
+			// Lo que sigue son argumentos del script, aunque parezcan flags
+			filteredArgs = append(filteredArgs, args[i+1:]...)
+			i = len(args)
 		default:
 			filteredArgs = append(filteredArgs, args[i])
 		}
@@ -104,11 +176,13 @@ func main() {
 
 	switch command {
 		case "run":
-			handleRun(filteredArgs, verbose, watch)
+			handleRun(filteredArgs, verbose, watch, compile, jsonOutput, noCache, strict, profile, sourceMapPath, emitGoPath)
+		case "compile":
+			handleCompile(filteredArgs, verbose, noCache)
 		case "repl":
 			handleREPL(verbose)
 		case "test":
-		handleTest(verbose)
+		handleTest(verbose, coverage, coverageOut)
 	case "version":
 		handleVersion()
 	case "init":
@@ -118,13 +192,13 @@ func main() {
 	case "fmt":
 		handleFmt(filteredArgs, verbose)
 	case "lint":
-		handleLint(filteredArgs, verbose)
+		handleLint(filteredArgs, verbose, jsonOutput)
 	case "debug":
-		handleDebug(filteredArgs, verbose)
+		handleDebug(filteredArgs, verbose, trace)
 	case "doc":
 		handleDoc(filteredArgs, verbose)
 	case "deps":
-		handleDeps(verbose)
+		handleDeps(filteredArgs, verbose)
 	case "add":
 		handleAdd(filteredArgs, verbose)
 	case "serve":
@@ -146,22 +220,105 @@ func main() {
 // IMPLEMENTACIONES DE FUNCIONES
 // =============================================================================
 
-func handleRun(args []string, verbose, watch bool) {
+func handleRun(args []string, verbose, watch, compile, jsonOutput, noCache, strict, profile bool, sourceMapPath, emitGoPath string) {
 	if len(args) == 0 {
 		fmt.Println(colorize("Error: Debes especificar un archivo .zylo", ColorRed))
 		os.Exit(1)
 	}
 
-	filename := args[0]
+	// Los argumentos que siguen al archivo son del script (os.args)
+	filename, scriptArgs := args[0], args[1:]
 
 	if watch {
 		fmt.Println(colorize("Modo watch no implementado aún", ColorYellow))
-		runFile(filename, verbose)
+		runFile(filename, scriptArgs, verbose, compile, jsonOutput, noCache, strict, profile, sourceMapPath, emitGoPath)
 	} else {
-		runFile(filename, verbose)
+		runFile(filename, scriptArgs, verbose, compile, jsonOutput, noCache, strict, profile, sourceMapPath, emitGoPath)
 	}
 }
 
+// handleCompile compila un script a un ejecutable nativo. La ruta del
+// ejecutable se indica con -o; por defecto es el nombre del script sin
+// extensión en el directorio actual.
+func handleCompile(args []string, verbose, noCache bool) {
+	filename, output := "", ""
+	for i := 0; i < len(args); i++ {
+		switch args[i] {
+		case "-o", "--output":
+			if i+1 >= len(args) {
+				fmt.Println(colorize("Error: -o necesita la ruta del ejecutable", ColorRed))
+				os.Exit(1)
+			}
+			i++
+			output = args[i]
+		default:
+			if filename != "" {
+				fmt.Printf("%sError: argumento inesperado: %s%s\n", ColorRed, args[i], ColorReset)
+				os.Exit(1)
+			}
+			filename = args[i]
+		}
+	}
+	if filename == "" {
+		fmt.Println(colorize("Error: Debes especificar un archivo .zylo", ColorRed))
+		os.Exit(1)
+	}
+	if output == "" {
+		output = executableName(filename)
+	}
+
+	if verbose {
+		fmt.Printf("🔨 Compilando %s...\n", filename)
+	}
+	checkFile(filename, verbose, false)
+
+	// A diferencia de run --compile, sin toolchain no hay alternativa
+	if !goToolchainAvailable() {
+		fmt.Printf("%s❌ No se encontró 'go' en el PATH; compile necesita el toolchain de Go%s\n", ColorRed, ColorReset)
+		os.Exit(1)
+	}
+
+	result := generateGo(filename, verbose, false, noCache)
+	size, err := buildExecutable(result.GoCode, result.SourceMap, output)
+	var buildErr *goBuildError
+	if errors.As(err, &buildErr) {
+		fmt.Printf("%s❌ Errores de compilación del código Go generado:%s\n", ColorRed, ColorReset)
+		fmt.Fprint(os.Stderr, buildErr.output)
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Printf("%s❌ Error compilando código Go: %v%s\n", ColorRed, err, ColorReset)
+		os.Exit(1)
+	}
+	fmt.Printf("%s✅ Ejecutable generado: %s (%s)%s\n", ColorGreen, output, formatSize(size), ColorReset)
+}
+
+// executableName devuelve el nombre del ejecutable por defecto de filename:
+// el nombre del script sin .zylo, con .exe en Windows
+func executableName(filename string) string {
+	name := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+	if runtime.GOOS == "windows" {
+		name += ".exe"
+	}
+	return name
+}
+
+// formatSize muestra un tamaño en bytes con la unidad más adecuada
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, suffix := float64(size)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
 func handleREPL(verbose bool) {
 	if verbose {
 		fmt.Println(colorize("Iniciando REPL de Zylo...", ColorCyan))
@@ -170,16 +327,19 @@ func handleREPL(verbose bool) {
 	fmt.Println(colorize("🐚 Bienvenido al REPL de Zylo v"+Version, ColorCyan))
 	fmt.Println(colorize("Escribe '.exit' para salir o '.help' para ayuda", ColorGray))
 
-	eval := evaluator.NewEvaluator()
-	scanner := bufio.NewScanner(os.Stdin)
+	session := newREPLSession()
+	editor := newLineEditor(os.Stdin, os.Stdout, session.eval.Complete)
+	if home, err := os.UserHomeDir(); err == nil {
+		editor.loadHistory(filepath.Join(home, ".zylo_history"))
+	}
 
 	for {
-		fmt.Print(colorize("zylo> ", ColorBlue))
-		if !scanner.Scan() {
+		line, err := editor.readLine(colorize("zylo> ", ColorBlue))
+		if err != nil {
 			break
 		}
 
-		line := strings.TrimSpace(scanner.Text())
+		line = strings.TrimSpace(line)
 
 		if line == "" {
 			continue
@@ -192,41 +352,54 @@ func handleREPL(verbose bool) {
 				return
 			case ".help":
 				fmt.Println(colorize("Comandos disponibles:", ColorCyan))
-				fmt.Println("  .exit     - Salir del REPL")
-				fmt.Println("  .clear    - Limpiar pantalla")
-				fmt.Println("  .help     - Mostrar esta ayuda")
+				fmt.Println("  .exit         - Salir del REPL")
+				fmt.Println("  .clear        - Limpiar pantalla")
+				fmt.Println("  .load <ruta>  - Evaluar un archivo en la sesión")
+				fmt.Println("  .save <ruta>  - Guardar las entradas evaluadas sin errores")
+				fmt.Println("  .help         - Mostrar esta ayuda")
+				fmt.Println("  Tab           - Completar nombres y miembros (obj.)")
+				fmt.Println("  ↑ / ↓         - Recorrer el historial (~/.zylo_history)")
 				continue
 			case ".clear":
 				fmt.Print("\033[2J\033[1;1H")
 				continue
-			default:
+			}
+			handled, err := session.runCommand(line)
+			if !handled {
 				fmt.Printf("%sComando desconocido: %s%s\n", ColorYellow, line, ColorReset)
 				continue
 			}
+			printREPLError(err)
+			continue
 		}
 
 		// Parsear y ejecutar
-		l := lexer.New(line)
-		p := parser.New(l)
-		program := p.ParseProgram()
-		_ = program // Para evitar el warning "declared and not used"
-
-		if len(p.Errors()) > 0 {
-			fmt.Printf("%sError de sintaxis:%s\n", ColorRed, ColorReset)
-			for _, err := range p.Errors() {
-				fmt.Printf("  %s\n", err)
-			}
-			continue
-		}
+		printREPLError(session.evaluate(line))
+	}
+}
 
-		err := eval.EvaluateProgram(program)
-		if err != nil {
-			fmt.Printf("%sError: %v%s\n", ColorRed, err, ColorReset)
+// printREPLError muestra un error del REPL. os.exit termina el REPL con su
+// código.
+func printREPLError(err error) {
+	if err == nil {
+		return
+	}
+	var exitErr *evaluator.ExitError
+	if errors.As(err, &exitErr) {
+		os.Exit(exitErr.Code)
+	}
+	var syntaxErr *replSyntaxError
+	if errors.As(err, &syntaxErr) {
+		fmt.Printf("%sError de sintaxis:%s\n", ColorRed, ColorReset)
+		for _, msg := range syntaxErr.errors {
+			fmt.Printf("  %s\n", msg)
 		}
+		return
 	}
+	fmt.Printf("%sError: %v%s\n", ColorRed, err, ColorReset)
 }
 
-func handleTest(verbose bool) {
+func handleTest(verbose, coverage bool, coverageOut string) {
 	if verbose {
 		fmt.Println(colorize("🧪 Ejecutando tests...", ColorCyan))
 	}
@@ -247,43 +420,114 @@ func handleTest(verbose bool) {
 		return
 	}
 
-	passed := 0
-	failed := 0
+	var cov *evaluator.Coverage
+	if coverage {
+		cov = evaluator.NewCoverage()
+	}
+	summary := runTests(testFiles, verbose, cov)
+	fmt.Printf("%s📊 Resultados: %d pasaron, %d fallaron (aserciones: %d pasaron, %d fallaron)%s\n",
+		ColorCyan, summary.passed, summary.failed, summary.assertsPassed, summary.assertsFailed, ColorReset)
+
+	if cov == nil {
+		return
+	}
+	report := cov.Report()
+	printCoverage(report)
+	if coverageOut != "" {
+		if err := writeCoverageReport(coverageOut, report); err != nil {
+			fmt.Printf("%s❌ Error escribiendo el informe de cobertura: %v%s\n", ColorRed, err, ColorReset)
+			os.Exit(1)
+		}
+		fmt.Printf("%sInforme de cobertura escrito en %s%s\n", ColorGray, coverageOut, ColorReset)
+	}
+}
+
+// testSummary cuenta los archivos de test y las aserciones que pasaron y fallaron
+type testSummary struct {
+	passed, failed               int
+	assertsPassed, assertsFailed int
+}
+
+// runTests ejecuta los archivos de test. Los módulos que importan se parsean y
+// evalúan una sola vez en un entorno compartido, y cada test se ejecuta en un
+// entorno hijo para que su estado de nivel superior no afecte a los demás.
+// import helpers carga helpers.zylo del directorio del test con su propio
+// espacio de nombres, igual que import "./helpers".
+// Un archivo falla si termina con error o si alguna aserción falló, aunque se
+// haya capturado con try/catch. Con coverage no nil se registra la cobertura
+// de los módulos que importan los tests.
+func runTests(testFiles []string, verbose bool, coverage *evaluator.Coverage) testSummary {
+	var summary testSummary
+	shared := evaluator.NewSharedEnvironment()
+	if coverage != nil {
+		shared.SetCoverage(coverage)
+	}
 
 	for _, testFile := range testFiles {
 		if verbose {
 			fmt.Printf("Ejecutando %s...\n", testFile)
 		}
 
-		content, err := ioutil.ReadFile(testFile)
+		program, err := parseZyloFile(testFile)
 		if err != nil {
-			fmt.Printf("%sError leyendo test %s: %v%s\n", ColorRed, testFile, err, ColorReset)
-			failed++
+			fmt.Printf("%s%v%s\n", ColorRed, err, ColorReset)
+			summary.failed++
 			continue
 		}
 
-		l := lexer.New(string(content))
-		p := parser.New(l)
-		program := p.ParseProgram()
-
-		if len(p.Errors()) > 0 {
-			fmt.Printf("%sErrores de parsing en %s%s\n", ColorRed, testFile, ColorReset)
-			failed++
-			continue
+		eval, err := shared.Run(program, filepath.Dir(testFile))
+		assertions := eval.Assertions()
+		passed, lastFailure := 0, ""
+		for _, a := range assertions {
+			if a.Passed {
+				passed++
+			} else {
+				lastFailure = a.Message
+			}
 		}
+		failed := len(assertions) - passed
+		summary.assertsPassed += passed
+		summary.assertsFailed += failed
 
-		eval := evaluator.NewEvaluator()
-		err = eval.EvaluateProgram(program)
-		if err != nil {
-			fmt.Printf("%s❌ Test %s falló: %v%s\n", ColorRed, testFile, err, ColorReset)
-			failed++
+		if err != nil || failed > 0 {
+			fmt.Printf("%s❌ Test %s falló (%d/%d aserciones)%s\n", ColorRed, testFile, passed, len(assertions), ColorReset)
+			summary.failed++
 		} else {
-			fmt.Printf("%s✅ Test %s pasó%s\n", ColorGreen, testFile, ColorReset)
-			passed++
+			fmt.Printf("%s✅ Test %s pasó (%d/%d aserciones)%s\n", ColorGreen, testFile, passed, len(assertions), ColorReset)
+			summary.passed++
+		}
+
+		for _, a := range assertions {
+			if !a.Passed {
+				fmt.Printf("%s  ✗ %s%s\n", ColorRed, a.Message, ColorReset)
+			} else if verbose {
+				fmt.Printf("%s  ✓ %s%s\n", ColorGreen, a.Message, ColorReset)
+			}
 		}
+		// Una aserción no capturada ya aparece en la lista anterior
+		if err != nil && err.Error() != lastFailure {
+			fmt.Printf("%s  error: %s%s\n", ColorRed, evaluator.FormatError(testFile, err), ColorReset)
+			if trace := evaluator.FormatStackTrace(testFile, err); trace != "" {
+				fmt.Printf("%s  %s%s\n", ColorRed, strings.ReplaceAll(trace, "\n", "\n  "), ColorReset)
+			}
+		}
+	}
+	return summary
+}
+
+// parseZyloFile lee y parsea un archivo .zylo
+func parseZyloFile(filename string) (*ast.Program, error) {
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Error leyendo %s: %v", filename, err)
 	}
 
-	fmt.Printf("%s📊 Resultados: %d pasaron, %d fallaron%s\n", ColorCyan, passed, failed, ColorReset)
+	p := parser.New(lexer.New(string(content)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, fmt.Errorf("Errores de parsing en %s: %s", filename, strings.Join(p.Errors(), "; "))
+	}
+	return program, nil
 }
 
 func handleVersion() {
@@ -419,15 +663,12 @@ func handleDoctor(verbose bool) {
 		fmt.Printf("%s✅ Permisos de escritura: OK%s\n", ColorGreen, ColorReset)
 	}
 
-	// Verificar módulos estándar
-	stdFiles := []string{"http.zylo", "json.zylo", "math.zylo"}
-	for _, file := range stdFiles {
-		path := filepath.Join("std", file)
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			fmt.Printf("%s⚠️  Módulo faltante: %s%s\n", ColorYellow, file, ColorReset)
-		} else {
-			fmt.Printf("%s✅ Módulo encontrado: %s%s\n", ColorGreen, file, ColorReset)
-		}
+	// Toolchain de Go, necesario para --compile y --emit-go
+	checkGoToolchain()
+
+	// Módulos estándar incluidos en el ejecutable
+	for _, name := range stdlib.Names() {
+		fmt.Printf("%s✅ Módulo estándar: std/%s%s\n", ColorGreen, name, ColorReset)
 	}
 
 	fmt.Printf("%s🎉 Verificación completada!%s\n", ColorCyan, ColorReset)
@@ -444,7 +685,11 @@ func handleFmt(args []string, verbose bool) {
 	}
 }
 
-func handleLint(args []string, verbose bool) {
+func handleLint(args []string, verbose, jsonOutput bool) {
+	if jsonOutput {
+		lintJSON(args)
+		return
+	}
 	if len(args) == 0 {
 		if verbose {
 			fmt.Println(colorize("🔍 Analizando todos los archivos .zylo...", ColorCyan))
@@ -455,7 +700,7 @@ func handleLint(args []string, verbose bool) {
 	}
 }
 
-func handleDebug(args []string, verbose bool) {
+func handleDebug(args []string, verbose, trace bool) {
 	if len(args) == 0 {
 		fmt.Println(colorize("Error: Debes especificar un archivo .zylo", ColorRed))
 		os.Exit(1)
@@ -466,8 +711,11 @@ func handleDebug(args []string, verbose bool) {
 		fmt.Printf("🐛 Ejecutando en modo debug: %s\n", filename)
 	}
 
-	os.Setenv("ZYLO_DEBUG", "true")
-	runFile(filename, verbose)
+	os.Setenv(evaluator.DebugEnv, "true")
+	if trace {
+		os.Setenv(evaluator.TraceEnv, "true")
+	}
+	runFile(filename, nil, verbose, false, false, false, false, false, "", "")
 }
 
 func handleDoc(args []string, verbose bool) {
@@ -481,13 +729,49 @@ func handleDoc(args []string, verbose bool) {
 	}
 }
 
-func handleDeps(verbose bool) {
+func handleDeps(args []string, verbose bool) {
+	// --sync instala exactamente las versiones de zylo.lock
+	if len(args) > 0 && args[0] == "--sync" {
+		synced, err := packages.Sync(".")
+		if err != nil {
+			fmt.Printf("%sError: %v%s\n", ColorRed, err, ColorReset)
+			os.Exit(1)
+		}
+		for _, locked := range synced {
+			fmt.Printf("%s✅ %s @ %s%s\n", ColorGreen, locked.Name, shortCommit(locked.Commit), ColorReset)
+		}
+		fmt.Printf("%s✅ %d dependencias sincronizadas con %s%s\n", ColorGreen, len(synced), packages.LockFile, ColorReset)
+		return
+	}
+
 	if verbose {
 		fmt.Println(colorize("📦 Dependencias instaladas:", ColorCyan))
 	}
 
-	// TODO: Implementar lista de dependencias
-	fmt.Println(colorize("  (Funcionalidad no implementada aún)", ColorGray))
+	lock, err := packages.ReadLock(packages.LockFile)
+	if err != nil {
+		fmt.Printf("%sError: %v%s\n", ColorRed, err, ColorReset)
+		os.Exit(1)
+	}
+	if len(lock) == 0 {
+		fmt.Println(colorize("  (sin dependencias)", ColorGray))
+		return
+	}
+	for _, locked := range lock {
+		version := locked.Ref
+		if version == "" {
+			version = "-"
+		}
+		fmt.Printf("  %s %s %s\n", locked.Name, version, colorize(shortCommit(locked.Commit), ColorGray))
+	}
+}
+
+// shortCommit abrevia un hash de commit como lo hace git
+func shortCommit(commit string) string {
+	if len(commit) > 7 {
+		return commit[:7]
+	}
+	return commit
 }
 
 func handleAdd(args []string, verbose bool) {
@@ -496,13 +780,23 @@ func handleAdd(args []string, verbose bool) {
 		os.Exit(1)
 	}
 
-	packageName := args[0]
+	spec := args[0]
 	if verbose {
-		fmt.Printf("📥 Instalando paquete: %s\n", packageName)
+		fmt.Printf("📥 Instalando paquete: %s\n", spec)
+	}
+
+	locked, err := packages.Add(".", spec)
+	if err != nil {
+		fmt.Printf("%sError: %v%s\n", ColorRed, err, ColorReset)
+		os.Exit(1)
 	}
 
-	// TODO: Implementar instalación de paquetes
-	fmt.Printf("%s✅ Paquete '%s' instalado%s\n", ColorGreen, packageName, ColorReset)
+	version := shortCommit(locked.Commit)
+	if locked.Ref != "" {
+		version = locked.Ref + ", " + version
+	}
+	fmt.Printf("%s✅ Paquete '%s' (%s) instalado en %s%s\n", ColorGreen, locked.Name, version, filepath.Join(packages.ModulesDir, locked.Name), ColorReset)
+	fmt.Printf("  import \"%s\"\n", locked.Name)
 }
 
 func handleServe(args []string, verbose bool) {
@@ -522,7 +816,7 @@ func handleServe(args []string, verbose bool) {
 		os.Exit(1)
 	}
 
-	runFile(mainFile, verbose)
+	runFile(mainFile, nil, verbose, false, false, false, false, false, "", "")
 }
 
 func handleVersionCheck(verbose bool) {
@@ -547,11 +841,68 @@ func handleSelfUpdate(verbose bool) {
 // FUNCIONES AUXILIARES
 // =============================================================================
 
-func runFile(filename string, verbose bool) {
+// runFile ejecuta un archivo. Con jsonOutput, los errores de parsing,
+// semánticos y de ejecución se escriben en stderr como diagnósticos JSON (el
+// mismo formato que lint --json) en lugar del mensaje con colores. Con
+// compile y sourceMapPath no vacío, el source map del código generado se
+// escribe en sourceMapPath. Con emitGoPath no vacío, el código Go generado se
+// escribe en ese archivo y el programa no se ejecuta. Con noCache no se usan
+// ni la caché de compilación ni la de ejecutables. Con strict, el intérprete
+// no convierte implícitamente los valores al tipo declarado de una variable.
+func runFile(filename string, scriptArgs []string, verbose, compile, jsonOutput, noCache, strict, profile bool, sourceMapPath, emitGoPath string) {
 	if verbose {
 		fmt.Printf("🚀 Ejecutando %s...\n", filename)
 	}
 
+	program := checkFile(filename, verbose, jsonOutput)
+
+	// Por defecto se interpreta; codegen solo con --compile o --emit-go.
+	// Sin toolchain de Go no podemos compilar: usar el intérprete
+	if compile && emitGoPath == "" && !goToolchainAvailable() {
+		fmt.Printf("%s⚠️  No se encontró 'go' en el PATH, usando el intérprete%s\n", ColorYellow, ColorReset)
+		compile = false
+	}
+
+	if !compile && emitGoPath == "" {
+		interpretProgram(program, filename, scriptArgs, verbose, jsonOutput, strict, profile)
+		return
+	}
+
+	if profile {
+		fmt.Printf("%s⚠️  --profile solo mide programas interpretados, se ignora con --compile%s\n", ColorYellow, ColorReset)
+	}
+	result := generateGo(filename, verbose, jsonOutput, noCache)
+
+	if sourceMapPath != "" {
+		data, err := json.MarshalIndent(result.SourceMap, "", "  ")
+		if err == nil {
+			err = ioutil.WriteFile(sourceMapPath, data, 0644)
+		}
+		if err != nil {
+			fmt.Printf("%s❌ Error escribiendo el source map: %v%s\n", ColorRed, err, ColorReset)
+			os.Exit(1)
+		}
+		if verbose {
+			fmt.Printf("%s✅ Source map escrito en %s%s\n", ColorGreen, sourceMapPath, ColorReset)
+		}
+	}
+
+	if emitGoPath != "" {
+		if err := emitGo(emitGoPath, result.GoCode); err != nil {
+			fmt.Printf("%s❌ Error escribiendo el código Go: %v%s\n", ColorRed, err, ColorReset)
+			os.Exit(1)
+		}
+		fmt.Printf("%s✅ Código Go escrito en %s%s\n", ColorGreen, emitGoPath, ColorReset)
+		return
+	}
+
+	// Compilar y ejecutar
+	compileAndRunGo(result.GoCode, result.SourceMap, filename, verbose, noCache)
+}
+
+// checkFile lee, parsea y analiza filename. Si hay errores los muestra (como
+// diagnósticos JSON con jsonOutput) y termina el proceso.
+func checkFile(filename string, verbose, jsonOutput bool) *ast.Program {
 	// Verificar que el archivo existe
 	if _, err := os.Stat(filename); os.IsNotExist(err) {
 		fmt.Printf("%s❌ Error: El archivo '%s' no existe%s\n", ColorRed, filename, ColorReset)
@@ -577,9 +928,14 @@ func runFile(filename string, verbose bool) {
 	program := p.ParseProgram()
 
 	if len(p.Errors()) > 0 {
+		errs := syntaxErrors(filename, p)
+		if jsonOutput {
+			writeDiagnostics(lintResult{syntaxErrors: errs}.diagnostics(filename))
+			os.Exit(1)
+		}
 		fmt.Printf("%s❌ Errores de parsing:%s\n", ColorRed, ColorReset)
-		for _, err := range p.Errors() {
-			fmt.Printf("  %s\n", err)
+		for _, err := range errs {
+			fmt.Printf("  %s\n", syntaxErrorText(err))
 		}
 		os.Exit(1)
 	}
@@ -590,9 +946,14 @@ func runFile(filename string, verbose bool) {
 
 	// Análisis semántico
 	sa := sema.NewSemanticAnalyzer()
+	sa.SetBaseDir(filepath.Dir(filename))
 	sa.Analyze(program)
 
 	if len(sa.Errors()) > 0 {
+		if jsonOutput {
+			writeDiagnostics(lintResult{errors: sa.ZyloErrors()}.diagnostics(filename))
+			os.Exit(1)
+		}
 		fmt.Printf("%s❌ Errores de análisis semántico:%s\n", ColorRed, ColorReset)
 		for _, err := range sa.Errors() {
 			fmt.Printf("  %s\n", err)
@@ -604,80 +965,312 @@ func runFile(filename string, verbose bool) {
 		fmt.Printf("%s✅ Análisis semántico completado%s\n", ColorGreen, ColorReset)
 	}
 
-	// Generar código Go
-	cg := codegen.NewCodeGenerator(sa.GetSymbolTable())
-	goCode, err := cg.Generate(program)
+	return program
+}
+
+// generateGo genera el código Go de filename y de los módulos que importa con
+// la caché de compilación incremental, o sin ella con noCache
+func generateGo(filename string, verbose, jsonOutput, noCache bool) *build.Result {
+	// Generar código Go; solo se regeneran los módulos que cambiaron
+	cacheDir := buildCacheDir()
+	if noCache {
+		cacheDir = ""
+	}
+	result, err := build.NewCache(cacheDir).Build(filename)
 	if err != nil {
+		if jsonOutput {
+			writeDiagnostics([]lintDiagnostic{errorDiagnostic(filename, buildErrorCode, err)})
+			os.Exit(1)
+		}
 		fmt.Printf("%s❌ Error generando código Go: %v%s\n", ColorRed, err, ColorReset)
 		os.Exit(1)
 	}
 
 	if verbose {
-		fmt.Printf("%s✅ Código Go generado%s\n", ColorGreen, ColorReset)
+		fmt.Printf("%s✅ Código Go generado (%d de %d módulos regenerados)%s\n", ColorGreen, len(result.Regenerated), len(result.Modules), ColorReset)
 	}
 
-	// Compilar y ejecutar
-	compileAndRunGo(goCode, verbose)
+	return result
 }
 
-// compileAndRunGo compila y ejecuta código Go con información de debug
-func compileAndRunGo(goCode string, verbose bool) {
-	// Mostrar código Go generado si verbose está activado
-	if verbose {
-		fmt.Printf("%s🔧 CÓDIGO GO GENERADO:%s\n", ColorCyan, ColorReset)
-		fmt.Printf("```\n%s```\n", goCode)
-		fmt.Printf("%sFIN DEL CÓDIGO GO%s\n\n", ColorCyan, ColorReset)
+// emitGo escribe en path el código Go generado, formateado con gofmt. Si el
+// código no es Go válido se escribe tal cual para poder inspeccionarlo, y se
+// devuelve el error de formato.
+func emitGo(path, goCode string) error {
+	formatted, formatErr := formatGoCode(goCode)
+	if formatErr != nil {
+		formatted = goCode
+	}
+	if err := ioutil.WriteFile(path, []byte(formatted), 0644); err != nil {
+		return err
 	}
+	return formatErr
+}
 
-	// Crear archivo temporal para el código Go
-	tmpFile, err := ioutil.TempFile("", "zylo_*.go")
+// formatGoCode valida el código generado con codegen.Validate y le aplica
+// gofmt. Un código que no es Go válido indica un error de codegen: el error
+// señala la línea e incluye las líneas de alrededor.
+func formatGoCode(goCode string) (string, error) {
+	if err := codegen.Validate(goCode); err != nil {
+		return "", err
+	}
+	formatted, err := format.Source([]byte(goCode))
 	if err != nil {
-		fmt.Printf("%s❌ Error creando archivo temporal: %v%s\n", ColorRed, err, ColorReset)
-		os.Exit(1)
+		return "", fmt.Errorf("error interno de codegen, el código Go generado no es válido: %v", err)
+	}
+	return string(formatted), nil
+}
+
+// buildCacheDir devuelve el directorio de la caché de compilación incremental,
+// o "" si no hay directorio de caché del usuario (la caché queda en memoria)
+func buildCacheDir() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "zylo", "build")
+}
+
+// goToolchainAvailable indica si el comando 'go' está disponible en el PATH
+func goToolchainAvailable() bool {
+	_, err := exec.LookPath("go")
+	return err == nil
+}
+
+// minGoVersion es la versión mínima de Go para compilar el código generado
+const minGoVersion = "1.18"
+
+// checkGoToolchain verifica para doctor que 'go' está en el PATH y que su
+// versión no es anterior a minGoVersion
+func checkGoToolchain() {
+	goPath, err := exec.LookPath("go")
+	if err != nil {
+		fmt.Printf("%s❌ Go: no se encontró 'go' en el PATH; run --compile no está disponible y run usa el intérprete%s\n", ColorRed, ColorReset)
+		return
 	}
-	defer os.Remove(tmpFile.Name()) // Limpiar el archivo temporal
 
-	// Escribir código Go al archivo temporal
-	_, err = tmpFile.WriteString(goCode)
+	output, err := exec.Command(goPath, "version").Output()
 	if err != nil {
-		fmt.Printf("%s❌ Error escribiendo código Go: %v%s\n", ColorRed, err, ColorReset)
-		tmpFile.Close()
-		os.Exit(1)
+		fmt.Printf("%s❌ Go: error ejecutando '%s version': %v%s\n", ColorRed, goPath, err, ColorReset)
+		return
+	}
+	version, ok := parseGoVersion(string(output))
+	if !ok {
+		fmt.Printf("%s⚠️  Go: no se reconoce la versión %q (%s)%s\n", ColorYellow, strings.TrimSpace(string(output)), goPath, ColorReset)
+		return
+	}
+	if !goVersionAtLeast(version, minGoVersion) {
+		fmt.Printf("%s⚠️  Go: %s (%s) es anterior a la versión mínima %s%s\n", ColorYellow, version, goPath, minGoVersion, ColorReset)
+		return
+	}
+	fmt.Printf("%s✅ Go: %s (%s)%s\n", ColorGreen, version, goPath, ColorReset)
+}
+
+// parseGoVersion extrae la versión de la salida de 'go version', por ejemplo
+// "1.22.3" de "go version go1.22.3 linux/amd64"
+func parseGoVersion(output string) (string, bool) {
+	fields := strings.Fields(output)
+	if len(fields) < 3 || fields[0] != "go" || fields[1] != "version" || !strings.HasPrefix(fields[2], "go") {
+		return "", false
+	}
+	version := strings.TrimPrefix(fields[2], "go")
+	if _, ok := goVersionParts(version); !ok {
+		return "", false
+	}
+	return version, true
+}
+
+// goVersionAtLeast indica si version es igual o posterior a min
+func goVersionAtLeast(version, min string) bool {
+	v, _ := goVersionParts(version)
+	m, _ := goVersionParts(min)
+	for i := range v {
+		if v[i] != m[i] {
+			return v[i] > m[i]
+		}
+	}
+	return true
+}
+
+// goVersionParts devuelve el número mayor, menor y de parche de una versión
+// de Go. Los sufijos de prerelease ("1.23rc1") se ignoran.
+func goVersionParts(version string) ([3]int, bool) {
+	var parts [3]int
+	for i, field := range strings.SplitN(version, ".", 3) {
+		end := 0
+		for end < len(field) && field[end] >= '0' && field[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			return parts, false
+		}
+		parts[i], _ = strconv.Atoi(field[:end])
 	}
-	tmpFile.Close()
+	return parts, true
+}
 
+// interpretProgram ejecuta el programa directamente con el evaluador,
+// sin pasar por codegen ni por el compilador de Go
+func interpretProgram(program *ast.Program, filename string, scriptArgs []string, verbose, jsonOutput, strict, profile bool) {
 	if verbose {
-		fmt.Printf("%s✅ Código escrito a %s%s\n", ColorGreen, tmpFile.Name(), ColorReset)
+		fmt.Printf("%s🏃 Interpretando programa...%s\n", ColorBlue, ColorReset)
+	}
+
+	eval := evaluator.NewEvaluator()
+	eval.SetBaseDir(filepath.Dir(filename))
+	eval.SetArgs(scriptArgs)
+	eval.SetStrict(strict)
+
+	// El perfil se muestra en la salida de errores al terminar, también si el
+	// programa termina con un error
+	var prof *evaluator.Profile
+	if profile {
+		prof = evaluator.NewProfile()
+		eval.SetProfile(prof)
+	}
+
+	err := eval.EvaluateProgram(program)
+	if err == nil {
+		// Si el programa inició un servidor con http.listen, seguir atendiendo
+		// peticiones hasta que se llame a http.stop
+		eval.WaitHTTPServer()
+	}
+	if prof != nil {
+		printProfile(os.Stderr, prof.Report())
+	}
+
+	if err != nil {
+		var exitErr *evaluator.ExitError
+		if errors.As(err, &exitErr) {
+			os.Exit(exitErr.Code)
+		}
+		if jsonOutput {
+			writeDiagnostics([]lintDiagnostic{errorDiagnostic(filename, runtimeErrorCode, err)})
+			os.Exit(1)
+		}
+		fmt.Printf("%s❌ Error ejecutando programa: %s%s\n", ColorRed, evaluator.FormatError(filename, err), ColorReset)
+		if trace := evaluator.FormatStackTrace(filename, err); trace != "" {
+			fmt.Printf("%s%s%s\n", ColorRed, trace, ColorReset)
+		}
+		os.Exit(1)
 	}
 
-	// Compilar primero para ver posibles errores
 	if verbose {
-		fmt.Printf("%s🔨 Compilando código Go...%s\n", ColorBlue, ColorReset)
+		fmt.Printf("%s✅ Ejecución completada%s\n", ColorGreen, ColorReset)
 	}
+}
 
-	buildCmd := exec.Command("go", "build", tmpFile.Name())
-	buildOutput, buildErr := buildCmd.CombinedOutput()
+// sourceMapWriter reenvía a out lo que escribe un programa compilado,
+// traduciendo línea a línea las posiciones de goFile a posiciones Zylo
+type sourceMapWriter struct {
+	out       io.Writer
+	sourceMap *codegen.SourceMap
+	goFile    string
+	pending   []byte // Línea incompleta de la última escritura
+}
 
-	if buildErr != nil {
-		if verbose {
-			fmt.Printf("%s⚠️  Errores de compilación:%s\n", ColorYellow, ColorReset)
-			fmt.Printf("%s\n", string(buildOutput))
+func (w *sourceMapWriter) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		i := strings.IndexByte(string(w.pending), '\n')
+		if i < 0 {
+			return len(p), nil
 		}
+		if err := w.writeLine(string(w.pending[:i+1])); err != nil {
+			return 0, err
+		}
+		w.pending = w.pending[i+1:]
+	}
+}
+
+// Flush escribe la línea incompleta que quede al terminar el programa
+func (w *sourceMapWriter) Flush() error {
+	if len(w.pending) == 0 {
+		return nil
+	}
+	err := w.writeLine(string(w.pending))
+	w.pending = nil
+	return err
+}
+
+func (w *sourceMapWriter) writeLine(line string) error {
+	if w.sourceMap != nil {
+		line = w.sourceMap.Translate(line, w.goFile)
 	}
+	_, err := io.WriteString(w.out, line)
+	return err
+}
 
-	// Ejecutar el código con go run
+// compileAndRunGo compila y ejecuta código Go con información de debug. Las
+// posiciones del código generado que aparezcan en stderr (por ejemplo en la
+// traza de un panic) se traducen a posiciones Zylo con sourceMap. El
+// ejecutable se guarda en la caché de ejecutables salvo con noCache.
+func compileAndRunGo(goCode string, sourceMap *codegen.SourceMap, sourcePath string, verbose, noCache bool) {
+	formatted, err := formatGoCode(goCode)
+	if err != nil {
+		fmt.Printf("%s❌ %v%s\n", ColorRed, err, ColorReset)
+		os.Exit(1)
+	}
+	// gofmt no suele mover líneas, pero si lo hace el source map ya no
+	// corresponde al código que se compila
+	if strings.Count(formatted, "\n") != strings.Count(goCode, "\n") {
+		sourceMap = nil
+	}
+	goCode = formatted
+
+	// Mostrar código Go generado si verbose está activado
 	if verbose {
-		fmt.Printf("%s🏃 Ejecutando código Go...%s\n", ColorBlue, ColorReset)
+		fmt.Printf("%s🔧 CÓDIGO GO GENERADO:%s\n", ColorCyan, ColorReset)
+		fmt.Printf("```\n%s```\n", goCode)
+		fmt.Printf("%sFIN DEL CÓDIGO GO%s\n\n", ColorCyan, ColorReset)
 	}
 
-	cmd := exec.Command("go", "run", tmpFile.Name())
+	// Con --no-cache se compila en un directorio temporal que se borra al
+	// terminar
+	dir := binaryCacheDir()
+	if noCache {
+		dir, err = ioutil.TempDir("", "zylo_build_")
+		if err != nil {
+			fmt.Printf("%s❌ Error creando directorio temporal: %v%s\n", ColorRed, err, ColorReset)
+			os.Exit(1)
+		}
+		defer os.RemoveAll(dir)
+	}
+
+	if verbose {
+		fmt.Printf("%s🔨 Compilando código Go...%s\n", ColorBlue, ColorReset)
+	}
+	binary, goFile, cached, err := buildGoBinary(dir, sourcePath, goCode)
+	stderr := &sourceMapWriter{out: os.Stderr, sourceMap: sourceMap, goFile: goFile}
+	var buildErr *goBuildError
+	if errors.As(err, &buildErr) {
+		fmt.Printf("%s❌ Errores de compilación del código Go generado:%s\n", ColorRed, ColorReset)
+		io.WriteString(stderr, buildErr.output)
+		stderr.Flush()
+		os.Exit(1)
+	}
+	if err != nil {
+		fmt.Printf("%s❌ Error compilando código Go: %v%s\n", ColorRed, err, ColorReset)
+		os.Exit(1)
+	}
+	if verbose {
+		if cached {
+			fmt.Printf("%s✅ Usando ejecutable en caché: %s%s\n", ColorGreen, binary, ColorReset)
+		} else {
+			fmt.Printf("%s✅ Ejecutable compilado: %s%s\n", ColorGreen, binary, ColorReset)
+		}
+		fmt.Printf("%s🏃 Ejecutando programa...%s\n", ColorBlue, ColorReset)
+	}
+
+	cmd := exec.Command(binary)
 
 	// Redirigir output directamente a la terminal del usuario
 	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	cmd.Stderr = stderr
 
 	// Ejecutar y mostrar TODA LA INFORMACIÓN
 	runErr := cmd.Run()
+	stderr.Flush()
 	if runErr != nil {
 		fmt.Printf("%s❌ Error ejecutando programa: %v%s\n", ColorRed, runErr, ColorReset)
 		fmt.Printf("%s🔍 Detalles del error: %T%s\n", ColorYellow, runErr, ColorReset)
@@ -692,6 +1285,52 @@ func compileAndRunGo(goCode string, verbose bool) {
 	}
 }
 
+// buildExecutable compila goCode en un ejecutable en output y devuelve su
+// tamaño. El código generado incluye el runtime que necesita, así que el
+// ejecutable no depende de Zylo. Los errores de go build se devuelven como
+// *goBuildError con las posiciones ya traducidas a Zylo con sourceMap.
+func buildExecutable(goCode string, sourceMap *codegen.SourceMap, output string) (int64, error) {
+	formatted, err := formatGoCode(goCode)
+	if err != nil {
+		return 0, err
+	}
+	if strings.Count(formatted, "\n") != strings.Count(goCode, "\n") {
+		sourceMap = nil
+	}
+
+	dir, err := ioutil.TempDir("", "zylo_compile_")
+	if err != nil {
+		return 0, err
+	}
+	defer os.RemoveAll(dir)
+
+	goFile := filepath.Join(dir, "main.go")
+	if err := ioutil.WriteFile(goFile, []byte(formatted), 0644); err != nil {
+		return 0, err
+	}
+	// go build se ejecuta en dir, así que la salida tiene que ser absoluta
+	output, err = filepath.Abs(output)
+	if err != nil {
+		return 0, err
+	}
+
+	cmd := exec.Command("go", "build", "-o", output, goFile)
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		var translated strings.Builder
+		w := &sourceMapWriter{out: &translated, sourceMap: sourceMap, goFile: goFile}
+		io.WriteString(w, string(out))
+		w.Flush()
+		return 0, &goBuildError{output: translated.String()}
+	}
+
+	info, err := os.Stat(output)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
 func formatFile(filename string, verbose bool) {
 	if verbose {
 		fmt.Printf("📝 Formateando %s...\n", filename)
@@ -721,6 +1360,110 @@ func formatAllFiles(verbose bool) {
 	fmt.Printf("%s✅ Todos los archivos formateados%s\n", ColorGreen, ColorReset)
 }
 
+// lintResult agrupa los problemas encontrados al analizar un archivo
+type lintResult struct {
+	syntaxErrors []*sema.ZyloError
+	errors       []*sema.ZyloError
+	warnings     []*sema.ZyloError
+}
+
+// errorCount cuenta los problemas que hacen fallar a lint; las advertencias no
+func (r lintResult) errorCount() int {
+	return len(r.syntaxErrors) + len(r.errors)
+}
+
+// lintSource analiza el código de un archivo: primero la sintaxis y, si es
+// correcta, la semántica. Los archivos analizados con la misma caché de
+// módulos analizan una sola vez cada módulo que importan.
+func lintSource(filename, source string, modules *sema.ModuleCache) lintResult {
+	var result lintResult
+
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		result.syntaxErrors = syntaxErrors(filename, p)
+		return result
+	}
+
+	sa := sema.NewSemanticAnalyzer()
+	sa.SetBaseDir(filepath.Dir(filename))
+	if modules != nil {
+		sa.SetModuleCache(modules)
+	}
+	sa.Analyze(program)
+	for _, err := range sa.ZyloErrors() {
+		err.Filename = filename
+		result.errors = append(result.errors, err)
+	}
+	for _, warning := range sa.Warnings() {
+		warning.Filename = filename
+		result.warnings = append(result.warnings, warning)
+	}
+	return result
+}
+
+// syntaxErrors convierte los errores del parser en errores de sintaxis. Los
+// que vienen del lexer (cadenas sin cerrar, escapes no válidos) llevan la
+// posición donde empieza el problema; el resto no tiene posición.
+func syntaxErrors(filename string, p *parser.Parser) []*sema.ZyloError {
+	builder := sema.NewErrorBuilder(filename)
+	var errs []*sema.ZyloError
+	for i, msg := range p.Errors() {
+		if tok, ok := p.ErrorToken(i); ok {
+			errs = append(errs, builder.SyntaxError(tok, tok.Lexeme))
+			continue
+		}
+		errs = append(errs, &sema.ZyloError{
+			Code:     sema.ZYLO_ERR_001_PARSER_ERROR,
+			Message:  msg,
+			Filename: filename,
+			Severity: "error",
+		})
+	}
+	return errs
+}
+
+// syntaxErrorText da formato a un error de sintaxis: con posición, como los
+// errores del analizador; sin ella, solo el mensaje
+func syntaxErrorText(err *sema.ZyloError) string {
+	if err.Line == 0 {
+		return err.Message
+	}
+	return err.FullError()
+}
+
+// printLintResult muestra los problemas de un archivo
+func printLintResult(result lintResult) {
+	if len(result.syntaxErrors) > 0 {
+		fmt.Printf("%s❌ Errores de sintaxis encontrados:%s\n", ColorRed, ColorReset)
+		for _, err := range result.syntaxErrors {
+			fmt.Printf("  %s\n", syntaxErrorText(err))
+		}
+	}
+	if len(result.errors) > 0 {
+		fmt.Printf("%s❌ Errores de análisis semántico:%s\n", ColorRed, ColorReset)
+		for _, err := range result.errors {
+			fmt.Printf("  %s\n", err.FullError())
+		}
+	}
+	for _, warning := range result.warnings {
+		fmt.Printf("%s⚠️  %s%s\n", ColorYellow, warning.Error(), ColorReset)
+	}
+}
+
+// printLintSummary muestra el total de errores y advertencias
+func printLintSummary(errors, warnings int) {
+	if errors == 0 && warnings == 0 {
+		fmt.Printf("%s🎉 No se encontraron issues!%s\n", ColorGreen, ColorReset)
+		return
+	}
+	color := ColorYellow
+	if errors > 0 {
+		color = ColorRed
+	}
+	fmt.Printf("%s📊 %d errores, %d advertencias%s\n", color, errors, warnings, ColorReset)
+}
+
 func lintFile(filename string, verbose bool) {
 	if verbose {
 		fmt.Printf("🔍 Analizando %s...\n", filename)
@@ -732,23 +1475,108 @@ func lintFile(filename string, verbose bool) {
 		os.Exit(1)
 	}
 
-	l := lexer.New(string(content))
-	p := parser.New(l)
-	program := p.ParseProgram()
-	_ = program // Para evitar el warning "declared and not used"
+	result := lintSource(filename, string(content), nil)
+	printLintResult(result)
+	printLintSummary(result.errorCount(), len(result.warnings))
 
-	if len(p.Errors()) > 0 {
-		fmt.Printf("%s❌ Errores de sintaxis encontrados:%s\n", ColorRed, ColorReset)
-		for _, err := range p.Errors() {
-			fmt.Printf("  %s\n", err)
-		}
+	if result.errorCount() > 0 {
 		os.Exit(1)
 	}
-
-	// TODO: Implementar análisis más avanzado
 	fmt.Printf("%s✅ Análisis completado: %s%s\n", ColorGreen, filename, ColorReset)
 }
 
+// lintDiagnostic es un problema de lint en el formato de lint --json
+type lintDiagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+// diagnostics convierte el resultado de lint en diagnósticos. Los errores de
+// sintaxis que no vienen del lexer no tienen posición, así que se reportan en
+// la línea 0.
+func (r lintResult) diagnostics(filename string) []lintDiagnostic {
+	var diags []lintDiagnostic
+	for _, err := range append(append(append([]*sema.ZyloError{}, r.syntaxErrors...), r.errors...), r.warnings...) {
+		diags = append(diags, lintDiagnostic{
+			File:     filename,
+			Line:     err.Line,
+			Column:   err.Column,
+			Severity: err.Severity,
+			Code:     diagnosticCode(err.Code),
+			Message:  err.Message,
+		})
+	}
+	return diags
+}
+
+// diagnosticCode extrae el código ("ZYLO_ERR_002") de "ZYLO_ERR_002: Variable no definida"
+// Códigos de los diagnósticos de run --json que no vienen del analizador
+const (
+	runtimeErrorCode = "ZYLO_RUNTIME"
+	buildErrorCode   = "ZYLO_BUILD"
+)
+
+// errorDiagnostic convierte un error de ejecución o de compilación en un
+// diagnóstico, con la posición del error si la tiene
+func errorDiagnostic(filename, code string, err error) lintDiagnostic {
+	diag := lintDiagnostic{File: filename, Severity: "error", Code: code, Message: err.Error()}
+	var runtimeErr *evaluator.RuntimeError
+	if errors.As(err, &runtimeErr) {
+		diag.Line, diag.Column = runtimeErr.Line, runtimeErr.Column
+	}
+	return diag
+}
+
+// writeDiagnostics escribe diagnósticos en stderr como JSON
+func writeDiagnostics(diags []lintDiagnostic) {
+	out, _ := json.MarshalIndent(diags, "", "  ")
+	fmt.Fprintln(os.Stderr, string(out))
+}
+
+func diagnosticCode(code string) string {
+	return strings.TrimSpace(strings.SplitN(code, ":", 2)[0])
+}
+
+// lintJSON analiza el archivo indicado, o todos los .zylo si no se indica
+// ninguno, y escribe los diagnósticos como un array JSON en stdout.
+func lintJSON(args []string) {
+	files := args
+	if len(files) == 0 {
+		var err error
+		files, err = filepath.Glob("**/*.zylo")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error buscando archivos: %v\n", err)
+			os.Exit(1)
+		}
+	} else {
+		files = files[:1]
+	}
+
+	diags := []lintDiagnostic{}
+	errors := 0
+	modules := sema.NewModuleCache()
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error leyendo archivo: %v\n", err)
+			os.Exit(1)
+		}
+		result := lintSource(file, string(content), modules)
+		errors += result.errorCount()
+		diags = append(diags, result.diagnostics(file)...)
+	}
+
+	out, _ := json.MarshalIndent(diags, "", "  ")
+	fmt.Println(string(out))
+	if errors > 0 {
+		os.Exit(1)
+	}
+}
+
 func lintAllFiles(verbose bool) {
 	files, err := filepath.Glob("**/*.zylo")
 	if err != nil {
@@ -756,31 +1584,29 @@ func lintAllFiles(verbose bool) {
 		os.Exit(1)
 	}
 
-	totalIssues := 0
+	totalErrors, totalWarnings := 0, 0
+	modules := sema.NewModuleCache()
 	for _, file := range files {
 		content, err := ioutil.ReadFile(file)
 		if err != nil {
 			continue
 		}
 
-		l := lexer.New(string(content))
-		p := parser.New(l)
-		_ = p.ParseProgram()
+		result := lintSource(file, string(content), modules)
+		totalErrors += result.errorCount()
+		totalWarnings += len(result.warnings)
 
-		issues := len(p.Errors())
-		totalIssues += issues
-
-		if issues > 0 {
-			fmt.Printf("%s⚠️  %s: %d issues%s\n", ColorYellow, file, issues, ColorReset)
+		if result.errorCount() > 0 || len(result.warnings) > 0 {
+			fmt.Printf("%s⚠️  %s: %d errores, %d advertencias%s\n", ColorYellow, file, result.errorCount(), len(result.warnings), ColorReset)
+			printLintResult(result)
 		} else if verbose {
 			fmt.Printf("%s✅ %s: OK%s\n", ColorGreen, file, ColorReset)
 		}
 	}
 
-	if totalIssues == 0 {
-		fmt.Printf("%s🎉 No se encontraron issues!%s\n", ColorGreen, ColorReset)
-	} else {
-		fmt.Printf("%s📊 Total de issues encontrados: %d%s\n", ColorYellow, totalIssues, ColorReset)
+	printLintSummary(totalErrors, totalWarnings)
+	if totalErrors > 0 {
+		os.Exit(1)
 	}
 }
 
@@ -789,22 +1615,24 @@ func generateDoc(filename string, verbose bool) {
 		fmt.Printf("📚 Generando documentación para %s...\n", filename)
 	}
 
-	// TODO: Implementar generador de docs real
-	docContent := fmt.Sprintf(`# Documentación para %s
-
-## Funciones
-
-<!-- TODO: Extraer funciones del código -->
-
-## Dependencias
-
-<!-- TODO: Analizar imports -->
+	source, err := ioutil.ReadFile(filename)
+	if err != nil {
+		fmt.Printf("%s❌ Error leyendo archivo: %v%s\n", ColorRed, err, ColorReset)
+		os.Exit(1)
+	}
 
-Generado automáticamente por zylo doc
-`, filename)
+	p := parser.New(lexer.New(string(source)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		fmt.Printf("%s❌ Errores de sintaxis en %s:%s\n", ColorRed, filename, ColorReset)
+		for _, msg := range p.Errors() {
+			fmt.Printf("  %s\n", msg)
+		}
+		os.Exit(1)
+	}
 
 	docFile := strings.TrimSuffix(filename, ".zylo") + "_doc.md"
-	err := ioutil.WriteFile(docFile, []byte(docContent), 0644)
+	err = ioutil.WriteFile(docFile, []byte(renderDoc(filename, program)), 0644)
 	if err != nil {
 		fmt.Printf("%s❌ Error creando documentación: %v%s\n", ColorRed, err, ColorReset)
 		os.Exit(1)
@@ -813,6 +1641,95 @@ Generado automáticamente por zylo doc
 	fmt.Printf("%s✅ Documentación generada: %s%s\n", ColorGreen, docFile, ColorReset)
 }
 
+// renderDoc genera la documentación Markdown de las funciones y clases
+// declaradas en el nivel superior del programa, en el orden del código.
+func renderDoc(filename string, program *ast.Program) string {
+	var funcs []*ast.FuncStatement
+	var classes []*ast.ClassStatement
+	for _, stmt := range program.Statements {
+		if export, ok := stmt.(*ast.ExportStatement); ok {
+			stmt = export.Declaration
+		}
+		switch node := stmt.(type) {
+		case *ast.FuncStatement:
+			funcs = append(funcs, node)
+		case *ast.ClassStatement:
+			classes = append(classes, node)
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "# Documentación para %s\n", filepath.Base(filename))
+
+	if len(funcs) > 0 {
+		out.WriteString("\n## Funciones\n")
+		for _, fn := range funcs {
+			writeFuncDoc(&out, "###", fn.Name.Value, fn.Parameters, fn.ReturnType, fn.Doc)
+		}
+	}
+
+	if len(classes) > 0 {
+		out.WriteString("\n## Clases\n")
+		for _, class := range classes {
+			fmt.Fprintf(&out, "\n### %s\n", class.Name.Value)
+			if class.SuperClass != nil {
+				fmt.Fprintf(&out, "\nExtiende `%s`.\n", class.SuperClass.Value)
+			}
+			if class.Doc != "" {
+				fmt.Fprintf(&out, "\n%s\n", class.Doc)
+			}
+			if class.InitMethod != nil {
+				writeFuncDoc(&out, "####", "init", class.InitMethod.Parameters, "", class.InitMethod.Doc)
+			}
+			for _, method := range class.Methods {
+				writeFuncDoc(&out, "####", method.Name.Value, method.Parameters, method.ReturnType, method.Doc)
+			}
+		}
+	}
+
+	if len(funcs) == 0 && len(classes) == 0 {
+		out.WriteString("\nEl archivo no declara funciones ni clases.\n")
+	}
+
+	out.WriteString("\nGenerado automáticamente por zylo doc\n")
+	return out.String()
+}
+
+// writeFuncDoc escribe la sección de una función o método: firma,
+// comentario de documentación, parámetros y tipo de retorno.
+func writeFuncDoc(out *strings.Builder, heading, name string, params []*ast.Identifier, returnType, doc string) {
+	signature := make([]string, len(params))
+	for i, param := range params {
+		signature[i] = param.Value
+		if param.TypeAnnotation != "" && param.TypeAnnotation != "ANY" {
+			signature[i] += " " + param.TypeAnnotation
+		}
+	}
+	fmt.Fprintf(out, "\n%s %s\n\n```zylo\nfunc %s(%s)", heading, name, name, strings.Join(signature, ", "))
+	if returnType != "" && returnType != "ANY" {
+		fmt.Fprintf(out, ": %s", returnType)
+	}
+	out.WriteString("\n```\n")
+
+	if doc != "" {
+		fmt.Fprintf(out, "\n%s\n", doc)
+	}
+
+	if len(params) > 0 {
+		out.WriteString("\n**Parámetros:**\n\n")
+		for _, param := range params {
+			typ := param.TypeAnnotation
+			if typ == "" {
+				typ = "ANY"
+			}
+			fmt.Fprintf(out, "- `%s`: %s\n", param.Value, typ)
+		}
+	}
+	if returnType != "" && returnType != "ANY" {
+		fmt.Fprintf(out, "\n**Retorna:** %s\n", returnType)
+	}
+}
+
 func generateAllDocs(verbose bool) {
 	files, err := filepath.Glob("**/*.zylo")
 	if err != nil {
@@ -0,0 +1,592 @@
+package main
+
+import (
+	"encoding/json"
+	"go/format"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// captureStdout ejecuta fn y devuelve todo lo que escribió en stdout
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("error creando pipe: %v", err)
+	}
+
+	original := os.Stdout
+	os.Stdout = w
+
+	done := make(chan string)
+	go func() {
+		out, _ := io.ReadAll(r)
+		done <- string(out)
+	}()
+
+	fn()
+
+	w.Close()
+	os.Stdout = original
+	return <-done
+}
+
+func writeZyloFile(t *testing.T, source string) string {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "main.zylo")
+	if err := os.WriteFile(filename, []byte(source), 0644); err != nil {
+		t.Fatalf("error escribiendo archivo: %v", err)
+	}
+	return filename
+}
+
+const runTestProgram = `x := 10
+y := x * 2 + 5
+show.log("resultado:", y)
+i := 0
+while i < 3 {
+    show.log(i)
+    i = i + 1
+}
+`
+
+func TestRunFileInterpretMatchesCompiled(t *testing.T) {
+	filename := writeZyloFile(t, runTestProgram)
+
+	expected := "resultado: 25\n0\n1\n2\n"
+
+	interpreted := captureStdout(t, func() { runFile(filename, nil, false, false, false, false, false, false, "", "") })
+	if interpreted != expected {
+		t.Fatalf("salida interpretada incorrecta.\nesperado: %q\nobtenido: %q", expected, interpreted)
+	}
+
+	if !goToolchainAvailable() {
+		t.Skip("toolchain de Go no disponible, se omite la comparación con el modo compilado")
+	}
+
+	compiled := captureStdout(t, func() { runFile(filename, nil, false, true, false, false, false, false, "", "") })
+	if compiled != interpreted {
+		t.Fatalf("la salida interpretada difiere de la compilada.\ncompilado:   %q\ninterpretado: %q", compiled, interpreted)
+	}
+}
+
+// El codegen todavía no soporta aritmética sobre parámetros sin tipo, así que
+// este programa solo produce la salida esperada si run interpreta por defecto.
+func TestRunFileInterpretsByDefault(t *testing.T) {
+	filename := writeZyloFile(t, `func sumar(a, b) {
+    return a + b
+}
+show.log(sumar(2, 3))
+`)
+
+	out := captureStdout(t, func() { runFile(filename, nil, false, false, false, false, false, false, "", "") })
+	if out != "5\n" {
+		t.Fatalf("se esperaba que run interpretara por defecto, obtenido: %q", out)
+	}
+}
+
+func TestRunFileScriptArgs(t *testing.T) {
+	filename := writeZyloFile(t, "show.log(os.args())\n")
+
+	out := captureStdout(t, func() { runFile(filename, []string{"uno", "-v"}, false, false, false, false, false, false, "", "") })
+	if out != "[uno, -v]\n" {
+		t.Fatalf("os.args() debería devolver los argumentos del script, obtenido: %q", out)
+	}
+}
+
+func TestRunFileCompile(t *testing.T) {
+	if !goToolchainAvailable() {
+		t.Skip("toolchain de Go no disponible")
+	}
+
+	filename := writeZyloFile(t, `show.log("compilado")
+`)
+
+	out := captureStdout(t, func() { runFile(filename, nil, false, true, false, false, false, false, "", "") })
+	if out != "compilado\n" {
+		t.Fatalf("salida compilada incorrecta: %q", out)
+	}
+}
+
+func TestRunFileEmitGo(t *testing.T) {
+	filename := writeZyloFile(t, `show.log("no se ejecuta")
+`)
+	goFile := filepath.Join(t.TempDir(), "out.go")
+
+	out := captureStdout(t, func() { runFile(filename, nil, false, false, false, false, false, false, "", goFile) })
+	if strings.Contains(out, "no se ejecuta") {
+		t.Fatalf("--emit-go no debe ejecutar el programa, salida: %q", out)
+	}
+
+	data, err := os.ReadFile(goFile)
+	if err != nil {
+		t.Fatalf("no se escribió el código Go: %v", err)
+	}
+	if !strings.Contains(string(data), "func main()") {
+		t.Fatalf("el código emitido no tiene main:\n%s", data)
+	}
+	formatted, err := format.Source(data)
+	if err != nil {
+		t.Fatalf("el código emitido no es Go válido: %v", err)
+	}
+	if string(formatted) != string(data) {
+		t.Fatalf("el código emitido no está formateado con gofmt:\n%s", data)
+	}
+}
+
+func TestBuildExecutable(t *testing.T) {
+	if !goToolchainAvailable() {
+		t.Skip("toolchain de Go no disponible")
+	}
+
+	filename := writeZyloFile(t, `show.log("ejecutable")
+`)
+	result := generateGo(filename, false, false, true)
+	output := filepath.Join(t.TempDir(), executableName(filename))
+
+	size, err := buildExecutable(result.GoCode, result.SourceMap, output)
+	if err != nil {
+		t.Fatalf("error compilando: %v", err)
+	}
+	info, err := os.Stat(output)
+	if err != nil || info.Size() != size {
+		t.Fatalf("tamaño incorrecto: %d, archivo %v (%v)", size, info, err)
+	}
+
+	// El ejecutable funciona fuera del directorio de compilación
+	out, err := exec.Command(output).Output()
+	if err != nil {
+		t.Fatalf("error ejecutando %s: %v", output, err)
+	}
+	if string(out) != "ejecutable\n" {
+		t.Fatalf("salida incorrecta: %q", out)
+	}
+}
+
+func TestExecutableName(t *testing.T) {
+	name := executableName(filepath.Join("dir", "app.zylo"))
+	if name != "app" && name != "app.exe" {
+		t.Fatalf("nombre incorrecto: %q", name)
+	}
+}
+
+func TestFormatSize(t *testing.T) {
+	tests := []struct {
+		size     int64
+		expected string
+	}{
+		{512, "512 B"},
+		{2048, "2.0 KB"},
+		{2306867, "2.2 MB"},
+		{3 << 30, "3.0 GB"},
+	}
+
+	for _, tt := range tests {
+		if got := formatSize(tt.size); got != tt.expected {
+			t.Errorf("formatSize(%d) = %q, se esperaba %q", tt.size, got, tt.expected)
+		}
+	}
+}
+
+func TestFormatGoCode(t *testing.T) {
+	formatted, err := formatGoCode("package main\nfunc main(){\nx:=1\n_ = x}\n")
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if formatted != "package main\n\nfunc main() {\n\tx := 1\n\t_ = x\n}\n" {
+		t.Fatalf("código sin formatear: %q", formatted)
+	}
+
+	_, err = formatGoCode("package main\n\nfunc main() {\n\tx := \n}\n")
+	if err == nil {
+		t.Fatal("se esperaba un error para código Go inválido")
+	}
+	for _, want := range []string{"error interno de codegen", ">    5 | }"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("el error no contiene %q: %v", want, err)
+		}
+	}
+}
+
+func TestGoVersion(t *testing.T) {
+	tests := []struct {
+		output  string
+		version string
+		ok      bool
+		atLeast bool
+	}{
+		{"go version go1.22.3 linux/amd64\n", "1.22.3", true, true},
+		{"go version go1.18 darwin/arm64", "1.18", true, true},
+		{"go version go1.17.13 linux/amd64", "1.17.13", true, false},
+		{"go version go1.23rc1 linux/amd64", "1.23rc1", true, true},
+		{"go version devel +abc123 linux/amd64", "", false, false},
+		{"command not found", "", false, false},
+	}
+
+	for _, tt := range tests {
+		version, ok := parseGoVersion(tt.output)
+		if version != tt.version || ok != tt.ok {
+			t.Errorf("parseGoVersion(%q) = %q, %v; se esperaba %q, %v", tt.output, version, ok, tt.version, tt.ok)
+			continue
+		}
+		if ok && goVersionAtLeast(version, minGoVersion) != tt.atLeast {
+			t.Errorf("goVersionAtLeast(%q, %q) = %v", version, minGoVersion, !tt.atLeast)
+		}
+	}
+}
+
+func TestLintReportsUnusedVariables(t *testing.T) {
+	filename := writeZyloFile(t, `x := 1
+y := 2
+show.log(y)
+`)
+
+	out := captureStdout(t, func() { lintFile(filename, false) })
+	expected := "ZYLO_WARN_001: Variable no utilizada - " + filename + ":1:1 - Variable 'x' declarada pero no utilizada"
+	if !strings.Contains(out, expected) {
+		t.Fatalf("se esperaba la advertencia %q, obtenido: %q", expected, out)
+	}
+	if strings.Contains(out, "'y'") {
+		t.Fatalf("y se usa y no debe reportarse: %q", out)
+	}
+}
+
+func TestLintSourceReportsSemanticErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		errors   int
+		warnings int
+		contains string
+	}{
+		{"sin problemas", "x := 1\nshow.log(x)\n", 0, 0, ""},
+		{"variable no definida", "show.log(y)\n", 1, 0, "variable no definida: y"},
+		{"break fuera de bucle", "break\n", 1, 0, "break solo puede usarse dentro de un bucle"},
+		{"solo advertencias", "x := 1\n", 0, 1, ""},
+		{"error de sintaxis", "x := (1\n", 1, 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := lintSource("main.zylo", tt.source, nil)
+			if result.errorCount() != tt.errors {
+				t.Errorf("se esperaban %d errores, obtenidos %d: %v %v", tt.errors, result.errorCount(), result.syntaxErrors, result.errors)
+			}
+			if len(result.warnings) != tt.warnings {
+				t.Errorf("se esperaban %d advertencias, obtenidas %d", tt.warnings, len(result.warnings))
+			}
+			if tt.contains != "" {
+				found := false
+				for _, err := range result.errors {
+					if strings.Contains(err.FullError(), tt.contains) && strings.Contains(err.FullError(), "main.zylo:") {
+						found = true
+					}
+				}
+				if !found {
+					t.Errorf("ningún error contiene %q", tt.contains)
+				}
+			}
+		})
+	}
+}
+
+func TestLintJSONOutput(t *testing.T) {
+	filename := writeZyloFile(t, `x := 1
+y := 2
+show.log(y)
+`)
+
+	out := captureStdout(t, func() { handleLint([]string{filename}, false, true) })
+
+	var diags []lintDiagnostic
+	if err := json.Unmarshal([]byte(out), &diags); err != nil {
+		t.Fatalf("la salida no es JSON válido: %v\n%s", err, out)
+	}
+	expected := lintDiagnostic{File: filename, Line: 1, Column: 1, Severity: "warning", Code: "ZYLO_WARN_001", Message: "Variable 'x' declarada pero no utilizada"}
+	if len(diags) != 1 || diags[0] != expected {
+		t.Fatalf("diagnósticos incorrectos: %+v", diags)
+	}
+}
+
+func TestLintDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected []lintDiagnostic
+	}{
+		{"sin problemas", "x := 1\nshow.log(x)\n", nil},
+		{
+			"error semántico",
+			"show.log(y)\n",
+			[]lintDiagnostic{{File: "main.zylo", Line: 1, Column: 10, Severity: "error", Code: "ZYLO_ERR_003", Message: "variable no definida: y"}},
+		},
+		{
+			"error de sintaxis",
+			"x := (1\n",
+			[]lintDiagnostic{{File: "main.zylo", Severity: "error", Code: "ZYLO_ERR_001", Message: "expected RIGHT_PAREN, got NEWLINE"}},
+		},
+		{
+			// Los errores del lexer señalan la comilla de apertura
+			"cadena sin cerrar",
+			"x := 1\nnombre := \"hola\n",
+			[]lintDiagnostic{{File: "main.zylo", Line: 2, Column: 11, Severity: "error", Code: "ZYLO_ERR_001", Message: "Unterminated string."}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags := lintSource("main.zylo", tt.source, nil).diagnostics("main.zylo")
+			if len(diags) != len(tt.expected) {
+				t.Fatalf("se esperaban %d diagnósticos, obtenidos %+v", len(tt.expected), diags)
+			}
+			for i := range diags {
+				if diags[i] != tt.expected[i] {
+					t.Errorf("diagnóstico %d: esperado %+v, obtenido %+v", i, tt.expected[i], diags[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunTestsSharesImportedModules(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"helpers.zylo": "import base\nfunc doble(x) {\n    return base.valor * x\n}\n",
+		"base.zylo":    "valor := 2\n",
+		"a_test.zylo":  "import helpers\nif helpers.doble(2) != 4 {\n    throw \"doble falló\"\n}\nsolo_a := 1\n",
+		"b_test.zylo":  "import helpers\nimport math\nshow.log(solo_a)\n",
+		"c_test.zylo":  "import helpers\nif helpers.doble(5) != 10 {\n    throw \"doble falló\"\n}\n",
+		// Como en zylo run, lo importado queda bajo el nombre del módulo
+		"d_test.zylo": "import helpers\ndoble(2)\n",
+	}
+	for name, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatalf("error escribiendo %s: %v", name, err)
+		}
+	}
+
+	var summary testSummary
+	out := captureStdout(t, func() {
+		summary = runTests([]string{
+			filepath.Join(dir, "a_test.zylo"),
+			filepath.Join(dir, "b_test.zylo"),
+			filepath.Join(dir, "c_test.zylo"),
+			filepath.Join(dir, "d_test.zylo"),
+		}, false, nil)
+	})
+
+	// b_test falla porque no ve las declaraciones de a_test y d_test porque
+	// doble solo existe como helpers.doble
+	if summary.passed != 2 || summary.failed != 2 {
+		t.Fatalf("se esperaban 2 tests correctos y 2 fallidos, obtenidos %d y %d:\n%s", summary.passed, summary.failed, out)
+	}
+	for _, name := range []string{"b_test.zylo falló", "d_test.zylo falló"} {
+		if !strings.Contains(out, name) {
+			t.Errorf("la salida no contiene %q:\n%s", name, out)
+		}
+	}
+}
+
+func TestRunTestsImportCycle(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.zylo":          "import b\nfunc fa() {\n    return 1\n}\n",
+		"b.zylo":          "import a\nfunc fb() {\n    return 2\n}\n",
+		"ciclo_test.zylo": "import a\nassert_eq(fa(), 1)\n",
+	}
+	for name, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatalf("error escribiendo %s: %v", name, err)
+		}
+	}
+
+	var summary testSummary
+	out := captureStdout(t, func() {
+		summary = runTests([]string{filepath.Join(dir, "ciclo_test.zylo")}, false, nil)
+	})
+	if summary.failed != 1 || !strings.Contains(out, "import circular") {
+		t.Fatalf("se esperaba un error de import circular:\n%s", out)
+	}
+}
+
+func TestRunTestsReportsAssertions(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ok_test.zylo":    "assert(true, \"verdadero\")\nassert_eq(2 * 2, 4, \"producto\")\n",
+		"fallo_test.zylo": "assert_eq(1, 1)\ntry {\n    assert(false, \"capturado\")\n} catch (e) {\n}\nassert_eq(len([1]), 2, \"longitud\")\nassert(true)\n",
+	}
+	for name, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatalf("error escribiendo %s: %v", name, err)
+		}
+	}
+
+	var summary testSummary
+	out := captureStdout(t, func() {
+		summary = runTests([]string{filepath.Join(dir, "ok_test.zylo"), filepath.Join(dir, "fallo_test.zylo")}, false, nil)
+	})
+
+	expected := testSummary{passed: 1, failed: 1, assertsPassed: 3, assertsFailed: 2}
+	if summary != expected {
+		t.Fatalf("resumen incorrecto: esperado %+v, obtenido %+v\n%s", expected, summary, out)
+	}
+	for _, line := range []string{
+		"ok_test.zylo pasó (2/2 aserciones)",
+		"fallo_test.zylo falló (1/3 aserciones)",
+		"✗ capturado",
+		"✗ longitud: esperado 2, obtenido 1",
+	} {
+		if !strings.Contains(out, line) {
+			t.Errorf("la salida no contiene %q:\n%s", line, out)
+		}
+	}
+	if strings.Contains(out, "error:") {
+		t.Errorf("el fallo de una aserción no debe repetirse como error:\n%s", out)
+	}
+}
+
+func TestGenerateDocFromDeclarations(t *testing.T) {
+	filename := writeZyloFile(t, `// Suma dos números.
+func sumar(a int, b int): int {
+    return a + b
+}
+
+func sin_doc(x) {
+    return x
+}
+
+// Representa una persona.
+class Persona {
+    // Saluda a alguien.
+    func saludar(nombre string): string {
+        return "hola " + nombre
+    }
+}
+
+// Resta dos números.
+export func restar(a int, b int): int {
+    return a - b
+}
+
+// Un punto del plano.
+export class Punto {
+    // Distancia al origen.
+    func norma(): float {
+        return 0.0
+    }
+}
+`)
+
+	captureStdout(t, func() { generateDoc(filename, false) })
+
+	content, err := os.ReadFile(strings.TrimSuffix(filename, ".zylo") + "_doc.md")
+	if err != nil {
+		t.Fatalf("no se generó la documentación: %v", err)
+	}
+	doc := string(content)
+
+	for _, expected := range []string{
+		"# Documentación para main.zylo",
+		"### sumar\n\n```zylo\nfunc sumar(a int, b int): int\n```\n\nSuma dos números.\n",
+		"- `a`: int\n- `b`: int\n",
+		"**Retorna:** int",
+		"### sin_doc\n\n```zylo\nfunc sin_doc(x)\n```\n",
+		"- `x`: ANY",
+		"## Clases\n\n### Persona\n\nRepresenta una persona.\n",
+		"#### saludar\n\n```zylo\nfunc saludar(nombre string): string\n```\n\nSaluda a alguien.\n",
+		// Las declaraciones exportadas se documentan igual que las demás
+		"### restar\n\n```zylo\nfunc restar(a int, b int): int\n```\n\nResta dos números.\n",
+		"### Punto\n\nUn punto del plano.\n",
+		"#### norma\n\n```zylo\nfunc norma(): float\n```\n\nDistancia al origen.\n",
+	} {
+		if !strings.Contains(doc, expected) {
+			t.Errorf("la documentación no contiene %q:\n%s", expected, doc)
+		}
+	}
+	if strings.Contains(doc, "TODO") {
+		t.Errorf("la documentación no debe contener la plantilla anterior:\n%s", doc)
+	}
+}
+
+// runJSONSubprocess ejecuta runFile con --json en un proceso aparte, porque
+// termina con os.Exit, y devuelve su stderr
+func runJSONSubprocess(t *testing.T, filename string) string {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestRunJSONHelper$")
+	cmd.Env = append(os.Environ(), "ZYLO_RUN_JSON_FILE="+filename)
+	var stderr strings.Builder
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); !ok || exitErr.ExitCode() != 1 {
+		t.Fatalf("se esperaba que run terminara con código 1, obtenido %v\n%s", err, stderr.String())
+	}
+	return stderr.String()
+}
+
+// TestRunJSONHelper no es un test: es el proceso que lanza runJSONSubprocess
+func TestRunJSONHelper(t *testing.T) {
+	filename := os.Getenv("ZYLO_RUN_JSON_FILE")
+	if filename == "" {
+		t.Skip("solo se ejecuta como subproceso")
+	}
+	runFile(filename, nil, false, false, true, false, os.Getenv("ZYLO_RUN_STRICT") != "", os.Getenv("ZYLO_RUN_PROFILE") != "", "", "")
+	os.Exit(0)
+}
+
+func TestRunStrict(t *testing.T) {
+	source := "func leer() {\n    return \"5\"\n}\nvar x: int = leer()\nshow.log(x + 1)\n"
+	filename := writeZyloFile(t, source)
+
+	// Sin --strict el valor se convierte al tipo declarado
+	out := captureStdout(t, func() { runFile(filename, nil, false, false, false, false, false, false, "", "") })
+	if out != "6\n" {
+		t.Fatalf("se esperaba la conversión implícita, obtenido: %q", out)
+	}
+
+	t.Setenv("ZYLO_RUN_STRICT", "1")
+	var diags []lintDiagnostic
+	if err := json.Unmarshal([]byte(runJSONSubprocess(t, filename)), &diags); err != nil {
+		t.Fatalf("la salida no es JSON válido: %v", err)
+	}
+	if len(diags) != 1 || diags[0].Line != 4 || diags[0].Message != "tipo incompatible: esperado int, recibido string" {
+		t.Fatalf("diagnóstico incorrecto con --strict: %+v", diags)
+	}
+}
+
+func TestRunJSONDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected lintDiagnostic
+	}{
+		{"semántico", "func doble(n int): int {\n    return n * 2\n}\ndoble(\"x\")\n", lintDiagnostic{Line: 4, Column: 6, Severity: "error", Code: "ZYLO_ERR_003", Message: "argumento 1: esperado int, obtenido string"}},
+		{"parsing", "x := (1\n", lintDiagnostic{Severity: "error", Code: "ZYLO_ERR_001"}},
+		{"ejecución", "lista := [1]\nshow.log(lista[5])\n", lintDiagnostic{Line: 2, Column: 15, Severity: "error", Code: runtimeErrorCode}},
+	}
+
+	for _, tt := range tests {
+		filename := writeZyloFile(t, tt.source)
+		out := runJSONSubprocess(t, filename)
+
+		var diags []lintDiagnostic
+		if err := json.Unmarshal([]byte(out), &diags); err != nil {
+			t.Fatalf("%s: la salida no es JSON válido: %v\n%s", tt.name, err, out)
+		}
+		if len(diags) != 1 {
+			t.Fatalf("%s: se esperaba 1 diagnóstico, obtenidos %+v", tt.name, diags)
+		}
+		got := diags[0]
+		if got.File != filename || got.Severity != tt.expected.Severity || got.Code != tt.expected.Code || got.Line != tt.expected.Line || got.Column != tt.expected.Column {
+			t.Errorf("%s: diagnóstico incorrecto: %+v", tt.name, got)
+		}
+		if tt.expected.Message != "" && got.Message != tt.expected.Message {
+			t.Errorf("%s: mensaje %q, se esperaba %q", tt.name, got.Message, tt.expected.Message)
+		}
+	}
+}
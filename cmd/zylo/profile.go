@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/zylo-lang/zylo/internal/evaluator"
+)
+
+// printProfile muestra en w el tiempo de cada función de zylo run --profile,
+// de mayor a menor tiempo acumulado
+func printProfile(w io.Writer, functions []evaluator.FunctionProfile) {
+	fmt.Fprintln(w, colorize("⏱️  Perfil por función:", ColorCyan))
+	if len(functions) == 0 {
+		fmt.Fprintln(w, colorize("  El programa no llamó a ninguna función", ColorGray))
+		return
+	}
+	fmt.Fprintf(w, "  %-30s %10s %12s %12s\n", "función", "llamadas", "acumulado", "propio")
+	for _, f := range functions {
+		fmt.Fprintf(w, "  %-30s %10d %12s %12s\n", f.Name, f.Calls, formatDuration(f.Total), formatDuration(f.Self))
+	}
+}
+
+// formatDuration redondea d a microsegundos para la tabla del perfil
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Microsecond).String()
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/zylo-lang/zylo/internal/evaluator"
+)
+
+func TestPrintProfile(t *testing.T) {
+	var out strings.Builder
+	printProfile(&out, []evaluator.FunctionProfile{
+		{Name: "fib", Calls: 177, Total: 12345678 * time.Nanosecond, Self: 12345678 * time.Nanosecond},
+		{Name: "Tarea.correr", Calls: 1, Total: 1500 * time.Microsecond, Self: 2 * time.Microsecond},
+	})
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("se esperaban cabecera, columnas y 2 funciones:\n%s", out.String())
+	}
+	for i, expected := range [][]string{
+		{"fib", "177", "12.346ms", "12.346ms"},
+		{"Tarea.correr", "1", "1.5ms", "2µs"},
+	} {
+		if fields := strings.Fields(lines[i+2]); strings.Join(fields, " ") != strings.Join(expected, " ") {
+			t.Errorf("fila %d: %q, se esperaba %q", i, fields, expected)
+		}
+	}
+
+	out.Reset()
+	printProfile(&out, nil)
+	if !strings.Contains(out.String(), "no llamó a ninguna función") {
+		t.Errorf("un perfil vacío debería avisarlo:\n%s", out.String())
+	}
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// replSession es el estado de una sesión del REPL: el evaluador, que conserva
+// las definiciones entre entradas, y el historial de entradas evaluadas sin
+// errores, que .save escribe en un archivo
+type replSession struct {
+	eval    *evaluator.Evaluator
+	history []string
+}
+
+func newREPLSession() *replSession {
+	return &replSession{eval: evaluator.NewEvaluator()}
+}
+
+// replSyntaxError son los errores de parsing de una entrada del REPL
+type replSyntaxError struct {
+	errors []string
+}
+
+func (e *replSyntaxError) Error() string {
+	return "Error de sintaxis:\n  " + strings.Join(e.errors, "\n  ")
+}
+
+// evaluate parsea y evalúa source en el entorno de la sesión. Si no hay
+// errores, source se añade al historial.
+func (s *replSession) evaluate(source string) error {
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return &replSyntaxError{errors: p.Errors()}
+	}
+	if err := s.eval.EvaluateProgram(program); err != nil {
+		return err
+	}
+	s.history = append(s.history, source)
+	return nil
+}
+
+// load evalúa el archivo path en el entorno de la sesión. En el historial
+// queda el contenido del archivo, no el comando, para que lo que escriba
+// .save se pueda ejecutar sin el archivo original.
+func (s *replSession) load(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error leyendo archivo: %v", err)
+	}
+	return s.evaluate(strings.TrimRight(string(content), "\n"))
+}
+
+// save escribe en path las entradas del historial, una tras otra
+func (s *replSession) save(path string) error {
+	var b strings.Builder
+	for _, entry := range s.history {
+		b.WriteString(entry)
+		b.WriteString("\n")
+	}
+	return os.WriteFile(path, []byte(b.String()), 0644)
+}
+
+// runCommand ejecuta un comando del REPL (.load, .save). Devuelve false si
+// line no es uno de esos comandos.
+func (s *replSession) runCommand(line string) (bool, error) {
+	command, arg, _ := strings.Cut(line, " ")
+	arg = strings.TrimSpace(arg)
+	switch command {
+	case ".load":
+		if arg == "" {
+			return true, fmt.Errorf(".load necesita la ruta de un archivo")
+		}
+		return true, s.load(arg)
+	case ".save":
+		if arg == "" {
+			return true, fmt.Errorf(".save necesita la ruta de un archivo")
+		}
+		if err := s.save(arg); err != nil {
+			return true, err
+		}
+		fmt.Printf("%s✅ %d entradas guardadas en %s%s\n", ColorGreen, len(s.history), arg, ColorReset)
+		return true, nil
+	}
+	return false, nil
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestREPLLoadAndSave(t *testing.T) {
+	dir := t.TempDir()
+	defs := filepath.Join(dir, "defs.zylo")
+	if err := os.WriteFile(defs, []byte("func doble(n) {\n    return n * 2\n}\n"), 0644); err != nil {
+		t.Fatalf("error escribiendo archivo: %v", err)
+	}
+
+	session := newREPLSession()
+	if handled, err := session.runCommand(".load " + defs); !handled || err != nil {
+		t.Fatalf(".load falló: handled=%v err=%v", handled, err)
+	}
+	if err := session.evaluate("x := doble(21)"); err != nil {
+		t.Fatalf("las definiciones cargadas deberían estar disponibles: %v", err)
+	}
+
+	// Las entradas con errores no pasan al historial
+	var syntaxErr *replSyntaxError
+	if err := session.evaluate("y := ("); !errors.As(err, &syntaxErr) {
+		t.Fatalf("se esperaba un error de sintaxis, obtenido %v", err)
+	}
+	if err := session.evaluate("z := no_existe"); err == nil {
+		t.Fatal("se esperaba un error de ejecución")
+	}
+
+	saved := filepath.Join(dir, "sesion.zylo")
+	captureStdout(t, func() {
+		if handled, err := session.runCommand(".save " + saved); !handled || err != nil {
+			t.Fatalf(".save falló: handled=%v err=%v", handled, err)
+		}
+	})
+	data, err := os.ReadFile(saved)
+	if err != nil {
+		t.Fatalf("no se escribió el archivo: %v", err)
+	}
+	expected := "func doble(n) {\n    return n * 2\n}\nx := doble(21)\n"
+	if string(data) != expected {
+		t.Fatalf("historial guardado incorrecto:\n%q\nesperado:\n%q", data, expected)
+	}
+
+	// Lo guardado se puede cargar en una sesión nueva
+	other := newREPLSession()
+	if err := other.load(saved); err != nil {
+		t.Fatalf("error cargando lo guardado: %v", err)
+	}
+	if err := other.evaluate("assert_eq(x, 42)"); err != nil {
+		t.Fatalf("la sesión cargada no tiene x: %v", err)
+	}
+}
+
+func TestREPLCommandErrors(t *testing.T) {
+	tests := []struct {
+		line     string
+		handled  bool
+		expected string
+	}{
+		{".load", true, ".load necesita la ruta de un archivo"},
+		{".save", true, ".save necesita la ruta de un archivo"},
+		{".load " + filepath.Join(t.TempDir(), "no_existe.zylo"), true, "error leyendo archivo"},
+		{".otro", false, ""},
+	}
+
+	for _, tt := range tests {
+		handled, err := newREPLSession().runCommand(tt.line)
+		if handled != tt.handled {
+			t.Errorf("%s: handled=%v, se esperaba %v", tt.line, handled, tt.handled)
+		}
+		if tt.expected == "" {
+			if err != nil {
+				t.Errorf("%s: error inesperado: %v", tt.line, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: se esperaba el error %q, obtenido %v", tt.line, tt.expected, err)
+		}
+	}
+}
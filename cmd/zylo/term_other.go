@@ -0,0 +1,15 @@
+//go:build !linux
+
+package main
+
+import "errors"
+
+// En otras plataformas el REPL lee líneas completas, sin autocompletado
+
+func isTerminal(fd int) bool {
+	return false
+}
+
+func makeRaw(fd int) (func(), error) {
+	return nil, errors.New("modo sin buffer no soportado en esta plataforma")
+}
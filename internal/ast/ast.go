@@ -51,6 +51,63 @@ func (p *Program) String() string {
 	return out
 }
 
+// Exports devuelve los nombres de nivel superior que el programa, usado como
+// módulo, expone a quien lo importa. Si alguna declaración lleva export o
+// public, solo se exponen esas; si no, se exponen todas las funciones, clases
+// y variables salvo las privadas.
+func (p *Program) Exports() []string {
+	var explicit, implicit []string
+	for _, stmt := range p.Statements {
+		if export, ok := stmt.(*ExportStatement); ok {
+			if name, _ := declaration(export.Declaration); name != "" {
+				explicit = append(explicit, name)
+			}
+			continue
+		}
+		name, visibility := declaration(stmt)
+		switch {
+		case name == "":
+		case visibility == "public":
+			explicit = append(explicit, name)
+		case visibility != "private":
+			implicit = append(implicit, name)
+		}
+	}
+	if len(explicit) > 0 {
+		return explicit
+	}
+	return implicit
+}
+
+// declaration devuelve el nombre y la visibilidad de una declaración de
+// función, clase, enum, struct o variable, o un nombre vacío si stmt no
+// declara nada
+func declaration(stmt Statement) (name, visibility string) {
+	switch s := stmt.(type) {
+	case *FuncStatement:
+		if s.Name != nil {
+			return s.Name.Value, s.Visibility
+		}
+	case *ClassStatement:
+		if s.Name != nil {
+			return s.Name.Value, s.Visibility
+		}
+	case *EnumStatement:
+		if s.Name != nil {
+			return s.Name.Value, s.Visibility
+		}
+	case *StructStatement:
+		if s.Name != nil {
+			return s.Name.Value, s.Visibility
+		}
+	case *VarStatement:
+		if s.Name != nil {
+			return s.Name.Value, s.Visibility
+		}
+	}
+	return "", ""
+}
+
 // ImportStatement representa una declaración de import (e.g., import zyloruntime).
 type ImportStatement struct {
 	Token           lexer.Token // El token 'import'.
@@ -140,6 +197,8 @@ type Identifier struct {
 	Token          lexer.Token // El token IDENTIFIER.
 	Value          string
 	TypeAnnotation string
+	Default        Expression // Valor por defecto de un parámetro, o nil
+	Variadic       bool       // Parámetro variádico (...name) que recibe el resto de argumentos
 }
 
 func (i *Identifier) expressionNode()      {}
@@ -171,6 +230,8 @@ type FuncStatement struct {
 	IsAsync     bool   // Nuevo campo para indicar si la función es asíncrona
 	Visibility  string // "public", "private", o vacío para package-private
 	IsVoid      bool   // Nuevo campo para indicar si es una función void
+	Decorators  []Expression // Decoradores (@nombre) en el orden en que aparecen
+	Doc         string       // Comentario de documentación (líneas // anteriores)
 }
 func (fs *FuncStatement) statementNode()       {}
 func (fs *FuncStatement) TokenLiteral() string { return fs.Token.Lexeme }
@@ -195,7 +256,7 @@ func (fs *FuncStatement) String() string {
 	if fs.IsVoid {
 		voidPrefix = "void "
 	}
-	return fmt.Sprintf("%s%s%s%s(%s)%s %s", asyncPrefix, visibilityPrefix, voidPrefix, fs.Name.String(), formatStrings(params), returnType, fs.Body.String())
+	return fmt.Sprintf("%s%s%s%s%s(%s)%s %s", formatDecorators(fs.Decorators), asyncPrefix, visibilityPrefix, voidPrefix, fs.Name.String(), formatStrings(params), returnType, fs.Body.String())
 }
 
 // FunctionLiteral representa una función anónima como expresión (e.g., func() {}).
@@ -508,9 +569,10 @@ func (ie *InfixExpression) String() string {
 
 // CallExpression representa una llamada a función.
 type CallExpression struct {
-	Token     lexer.Token // El token '(' o el identificador de la función.
-	Function  Expression  // La expresión que evalúa a la función.
-	Arguments []Expression
+	Token          lexer.Token // El token '(' o el identificador de la función.
+	Function       Expression  // La expresión que evalúa a la función.
+	Arguments      []Expression
+	NamedArguments []*NamedArgument // Argumentos nombre: valor, después de los posicionales.
 }
 
 func (ce *CallExpression) expressionNode()      {}
@@ -519,7 +581,31 @@ func (ce *CallExpression) String() string {
 	if ce.Function == nil {
 		return "INVALID()"
 	}
-	return fmt.Sprintf("%s(%s)", ce.Function.String(), formatExpressions(ce.Arguments))
+	return fmt.Sprintf("%s(%s)", ce.Function.String(), formatArguments(ce.Arguments, ce.NamedArguments))
+}
+
+// NamedArgument representa un argumento con nombre en una llamada (e.g., port: 8080).
+type NamedArgument struct {
+	Token lexer.Token // El nombre del parámetro.
+	Name  string
+	Value Expression
+}
+
+func (na *NamedArgument) String() string {
+	return fmt.Sprintf("%s: %s", na.Name, na.Value.String())
+}
+
+// formatArguments da formato a los argumentos de una llamada: primero los
+// posicionales y después los que tienen nombre
+func formatArguments(args []Expression, named []*NamedArgument) string {
+	var parts []string
+	for _, arg := range args {
+		parts = append(parts, arg.String())
+	}
+	for _, arg := range named {
+		parts = append(parts, arg.String())
+	}
+	return formatStrings(parts)
 }
 
 // MethodCallExpression representa una llamada a método (e.g., obj.method(args)).
@@ -626,13 +712,14 @@ func (be *BlockExpression) String() string {
 	return be.Block.String()
 }
 
-// IfStatement representa una sentencia 'if'.
+// IfStatement representa una sentencia 'if'. Las ramas elif y else if se
+// guardan en orden en ElseIfs en lugar de anidarse en Alternative.
 type IfStatement struct {
 	Token       lexer.Token     // El token 'if'.
 	Condition   Expression      // La condición del if.
 	Consequence *BlockStatement // El bloque del if.
-	Alternative *BlockStatement // El bloque del else/elif (si existe).
-	// Note: Elif se maneja como IfStatement dentro del Alternative.
+	ElseIfs     []*ElseIfClause // Las ramas elif / else if, en orden.
+	Alternative *BlockStatement // El bloque del else final (si existe).
 }
 
 func (is *IfStatement) statementNode()       {}
@@ -646,12 +733,36 @@ func (is *IfStatement) String() string {
 	if is.Consequence != nil {
 		out += is.Consequence.String()
 	}
+	for _, clause := range is.ElseIfs {
+		out += " " + clause.String()
+	}
 	if is.Alternative != nil {
 		out += " else " + is.Alternative.String()
 	}
 	return out
 }
 
+// ElseIfClause representa una rama 'elif' o 'else if' de un IfStatement.
+type ElseIfClause struct {
+	Token       lexer.Token     // El token 'elif' o 'if'.
+	Condition   Expression      // La condición de la rama.
+	Consequence *BlockStatement // El bloque de la rama.
+}
+
+func (ec *ElseIfClause) statementNode()       {} // ElseIfClause es parte de IfStatement, no una sentencia independiente.
+func (ec *ElseIfClause) TokenLiteral() string { return ec.Token.Lexeme }
+func (ec *ElseIfClause) String() string {
+	out := "elif "
+	if ec.Condition != nil {
+		out += ec.Condition.String()
+	}
+	out += " "
+	if ec.Consequence != nil {
+		out += ec.Consequence.String()
+	}
+	return out
+}
+
 // IfExpression representa una expresión 'if' (e.g., if condition { true_exp } else { false_exp }).
 type IfExpression struct {
 	Token       lexer.Token     // El token 'if'.
@@ -716,6 +827,27 @@ func (ws *WhileStatement) String() string {
 	return out
 }
 
+// RepeatStatement representa un bucle 'repeat' (e.g., repeat 3 { ... }).
+type RepeatStatement struct {
+	Token lexer.Token     // El token 'repeat'.
+	Count Expression      // Cuántas veces se ejecuta el cuerpo.
+	Body  *BlockStatement // El cuerpo del bucle.
+}
+
+func (rs *RepeatStatement) statementNode()       {}
+func (rs *RepeatStatement) TokenLiteral() string { return rs.Token.Lexeme }
+func (rs *RepeatStatement) String() string {
+	out := "repeat "
+	if rs.Count != nil {
+		out += rs.Count.String()
+	}
+	out += " "
+	if rs.Body != nil {
+		out += rs.Body.String()
+	}
+	return out
+}
+
 // MethodStatement representa una declaración de método en una clase.
 type MethodStatement struct {
 	Token      lexer.Token // El token 'func'.
@@ -723,7 +855,8 @@ type MethodStatement struct {
 	Parameters []*Identifier
 	ReturnType string // Tipo de retorno
 	Body       *BlockStatement
-	IsAsync    bool // Nuevo campo para indicar si el método es asíncrono
+	IsAsync    bool   // Nuevo campo para indicar si el método es asíncrono
+	Doc        string // Comentario de documentación (líneas // anteriores)
 }
 
 func (ms *MethodStatement) statementNode()       {}
@@ -750,6 +883,7 @@ type ConstructorStatement struct {
 	Name       *Identifier // Debería ser 'init'
 	Parameters []*Identifier
 	Body       *BlockStatement
+	Doc        string // Comentario de documentación (líneas // anteriores)
 }
 
 func (cs *ConstructorStatement) statementNode()       {}
@@ -773,12 +907,14 @@ type ClassStatement struct {
 	InitMethod  *ConstructorStatement     // Método constructor (init)
 	Visibility  string                    // "public", "private", o vacío para package-private
 	IsVoid      bool                      // Nuevo campo para indicar si es una clase void
+	Decorators  []Expression              // Decoradores (@nombre) en el orden en que aparecen
+	Doc         string                    // Comentario de documentación (líneas // anteriores)
 }
 
 func (cs *ClassStatement) statementNode()       {}
 func (cs *ClassStatement) TokenLiteral() string { return cs.Token.Lexeme }
 func (cs *ClassStatement) String() string {
-	out := ""
+	out := formatDecorators(cs.Decorators)
 	if cs.Visibility != "" {
 		out += cs.Visibility + " "
 	}
@@ -806,6 +942,61 @@ func (cs *ClassStatement) String() string {
 	return out
 }
 
+// EnumStatement representa una declaración de enum (e.g., enum Color { Red, Green }).
+type EnumStatement struct {
+	Token      lexer.Token   // El token del modificador o 'enum'.
+	Name       *Identifier
+	Members    []*Identifier // Miembros en el orden en que se declaran
+	Visibility string        // "public", "private", o vacío para package-private
+	Doc        string        // Comentario de documentación (líneas // anteriores)
+}
+
+func (es *EnumStatement) statementNode()       {}
+func (es *EnumStatement) TokenLiteral() string { return es.Token.Lexeme }
+func (es *EnumStatement) String() string {
+	out := ""
+	if es.Visibility != "" {
+		out += es.Visibility + " "
+	}
+	if len(es.Members) == 0 {
+		return out + fmt.Sprintf("enum %s {}", es.Name.String())
+	}
+	members := make([]string, len(es.Members))
+	for i, member := range es.Members {
+		members[i] = member.String()
+	}
+	return out + fmt.Sprintf("enum %s { %s }", es.Name.String(), strings.Join(members, ", "))
+}
+
+// StructStatement representa una declaración de struct (e.g., struct Point { x: int, y: int }).
+type StructStatement struct {
+	Token      lexer.Token   // El token del modificador o 'struct'.
+	Name       *Identifier
+	Fields     []*Identifier // Campos en orden de declaración; el tipo va en TypeAnnotation
+	Visibility string        // "public", "private", o vacío para package-private
+	Doc        string        // Comentario de documentación (líneas // anteriores)
+}
+
+func (ss *StructStatement) statementNode()       {}
+func (ss *StructStatement) TokenLiteral() string { return ss.Token.Lexeme }
+func (ss *StructStatement) String() string {
+	out := ""
+	if ss.Visibility != "" {
+		out += ss.Visibility + " "
+	}
+	if len(ss.Fields) == 0 {
+		return out + fmt.Sprintf("struct %s {}", ss.Name.String())
+	}
+	fields := make([]string, len(ss.Fields))
+	for i, field := range ss.Fields {
+		fields[i] = field.Value
+		if field.TypeAnnotation != "" && field.TypeAnnotation != "ANY" {
+			fields[i] += ": " + field.TypeAnnotation
+		}
+	}
+	return out + fmt.Sprintf("struct %s { %s }", ss.Name.String(), strings.Join(fields, ", "))
+}
+
 // ListLiteral representa un literal de lista (e.g., [1, 2, 3]).
 type ListLiteral struct {
 	Token    lexer.Token // El token '['.
@@ -839,7 +1030,13 @@ func (sl *SetLiteral) String() string {
 // MapLiteral representa un literal de mapa (e.g., {key: value}).
 type MapLiteral struct {
 	Token lexer.Token // El token '{'.
-	Pairs map[string]Expression
+	Pairs []MapPair   // En el orden en que se escribieron, sin claves repetidas.
+}
+
+// MapPair es una clave de un literal de mapa con su valor.
+type MapPair struct {
+	Key   string
+	Value Expression
 }
 
 func (ml *MapLiteral) expressionNode()      {}
@@ -849,8 +1046,8 @@ func (ml *MapLiteral) String() string {
 		return "{}"
 	}
 	var pairs []string
-	for k, v := range ml.Pairs {
-		pairs = append(pairs, fmt.Sprintf("%s: %s", k, v.String()))
+	for _, pair := range ml.Pairs {
+		pairs = append(pairs, fmt.Sprintf("%s: %s", pair.Key, pair.Value.String()))
 	}
 	return fmt.Sprintf("{%s}", formatStrings(pairs))
 }
@@ -871,6 +1068,23 @@ func (ci *ClassInstantiation) String() string {
 	return fmt.Sprintf("%s(%s)", ci.ClassName.String(), formatExpressions(ci.Arguments))
 }
 
+// StructLiteral representa la construcción de un struct (e.g., Point{x: 1, y: 2}).
+type StructLiteral struct {
+	Token  lexer.Token      // El token del nombre del struct.
+	Name   *Identifier
+	Fields []*NamedArgument // Campos en el orden en que se escribieron, sin repetidos
+}
+
+func (sl *StructLiteral) expressionNode()      {}
+func (sl *StructLiteral) TokenLiteral() string { return sl.Token.Lexeme }
+func (sl *StructLiteral) String() string {
+	fields := make([]string, len(sl.Fields))
+	for i, field := range sl.Fields {
+		fields[i] = field.String()
+	}
+	return fmt.Sprintf("%s{%s}", sl.Name.String(), formatStrings(fields))
+}
+
 // ObjectLiteral representa un literal de objeto para clases (e.g., Result{value: 5}).
 type ObjectLiteral struct {
 	Token    lexer.Token              // El token '{'.
@@ -933,6 +1147,15 @@ func formatStrings(strs []string) string {
 	return result
 }
 
+// formatDecorators formatea los decoradores de una declaración, uno por línea.
+func formatDecorators(decorators []Expression) string {
+	var result string
+	for _, d := range decorators {
+		result += "@" + d.String() + "\n"
+	}
+	return result
+}
+
 // AssignmentExpression representa una asignación (e.g., x = 5, x += 1).
 type AssignmentExpression struct {
 	Token    lexer.Token
@@ -947,7 +1170,8 @@ func (a *AssignmentExpression) String() string {
 	return fmt.Sprintf("%s %s %s", a.Name.String(), a.Operator, a.Value.String())
 }
 
-// DestructuringAssignmentExpression represents a destructuring assignment expression (e.g., [a, b] = [1, 2]).
+// DestructuringAssignmentExpression represents a destructuring assignment expression (e.g., a, b = [1, 2]).
+// With several values on the right (a, b = b, a) Value is a ListLiteral.
 type DestructuringAssignmentExpression struct {
 	Token    lexer.Token  // El token '='.
 	Targets  []Expression // Los identificadores o patrones a la izquierda.
@@ -974,6 +1198,24 @@ func (de *DotExpression) String() string {
 	return fmt.Sprintf("%s.%s", de.Left.String(), de.Property.String())
 }
 
+// OptionalDotExpression representa un acceso que tolera null: obj?.prop, o
+// mapa?.[clave] cuando Index no es nil.
+type OptionalDotExpression struct {
+	Token    lexer.Token // El token '?.'
+	Left     Expression
+	Property *Identifier
+	Index    Expression
+}
+
+func (oe *OptionalDotExpression) expressionNode()      {}
+func (oe *OptionalDotExpression) TokenLiteral() string { return oe.Token.Lexeme }
+func (oe *OptionalDotExpression) String() string {
+	if oe.Index != nil {
+		return fmt.Sprintf("%s?.[%s]", oe.Left.String(), oe.Index.String())
+	}
+	return fmt.Sprintf("%s?.%s", oe.Left.String(), oe.Property.String())
+}
+
 // SwitchStatement representa una sentencia 'switch-case'.
 type SwitchStatement struct {
 	Token      lexer.Token // El token 'switch'.
@@ -1120,10 +1362,11 @@ func (ss *SpawnStatement) String() string {
 
 // CollectionMethodCall representa una llamada a método en una colección (e.g., arr.push(element)).
 type CollectionMethodCall struct {
-	Token     lexer.Token   // El token '('.
-	Object    Expression    // El objeto colección.
-	Method    *Identifier   // El nombre del método.
-	Arguments []Expression  // Los argumentos del método.
+	Token          lexer.Token      // El token '('.
+	Object         Expression       // El objeto colección.
+	Method         *Identifier      // El nombre del método.
+	Arguments      []Expression     // Los argumentos del método.
+	NamedArguments []*NamedArgument // Argumentos nombre: valor.
 }
 
 func (cmc *CollectionMethodCall) expressionNode()      {}
@@ -1132,7 +1375,7 @@ func (cmc *CollectionMethodCall) String() string {
 	if cmc.Object == nil || cmc.Method == nil {
 		return "INVALID.METHOD()"
 	}
-	return fmt.Sprintf("%s.%s(%s)", cmc.Object.String(), cmc.Method.String(), formatExpressions(cmc.Arguments))
+	return fmt.Sprintf("%s.%s(%s)", cmc.Object.String(), cmc.Method.String(), formatArguments(cmc.Arguments, cmc.NamedArguments))
 }
 
 // AsExpression representa una expresión de conversión de tipo (e.g., value as Type).
@@ -1150,3 +1393,19 @@ func (ae *AsExpression) String() string {
 	}
 	return fmt.Sprintf("%s as %s", ae.Left.String(), ae.TypeName)
 }
+
+// IsExpression representa una comprobación de tipo (e.g., value is int).
+type IsExpression struct {
+	Token    lexer.Token // El token 'is'.
+	Left     Expression  // La expresión cuyo tipo se comprueba.
+	TypeName string      // El nombre del tipo.
+}
+
+func (ie *IsExpression) expressionNode()      {}
+func (ie *IsExpression) TokenLiteral() string { return ie.Token.Lexeme }
+func (ie *IsExpression) String() string {
+	if ie.Left == nil {
+		return fmt.Sprintf("INVALID is %s", ie.TypeName)
+	}
+	return fmt.Sprintf("(%s is %s)", ie.Left.String(), ie.TypeName)
+}
@@ -0,0 +1,316 @@
+// Package build genera el código Go de un proyecto de varios archivos de forma
+// incremental. Cada módulo local (un archivo .zylo) se genera por separado y
+// su resultado se guarda en una caché indexada por el hash del contenido. Un
+// módulo solo se vuelve a generar si cambió su código o si cambió la interfaz
+// pública (lo que exporta, con sus tipos) de alguno de los módulos que
+// importa. La caché solo vale para el ejecutable que la generó.
+package build
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/codegen"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/packages"
+	"github.com/zylo-lang/zylo/internal/parser"
+	"github.com/zylo-lang/zylo/internal/sema"
+)
+
+// indexFile es el archivo del directorio de la caché con las entradas
+const indexFile = "index.json"
+
+// indexFormat es la versión del formato de indexFile
+const indexFormat = 2
+
+// index es el contenido de indexFile
+type index struct {
+	Version string             `json:"version"`
+	Modules map[string]*Module `json:"modules"`
+}
+
+// Cache guarda el código Go generado para cada módulo, por ruta absoluta
+type Cache struct {
+	dir     string // Directorio donde se persiste la caché; vacío = solo en memoria
+	version string // Versión del compilador que guarda las entradas
+	modules map[string]*Module
+	sema    *sema.ModuleCache
+}
+
+// Module es la entrada de la caché de un módulo
+type Module struct {
+	SourceHash string             `json:"source_hash"`
+	Signature  string             `json:"signature"` // Hash de lo que exporta, con sus tipos
+	Imports    []string           `json:"imports"`   // Rutas absolutas de los módulos importados
+	DepSigs    map[string]string  `json:"dep_signatures"`
+	GoCode     string             `json:"go_code"`
+	SourceMap  *codegen.SourceMap `json:"source_map"`
+}
+
+// Result es el resultado de Build
+type Result struct {
+	GoCode      string             // Código Go del módulo de entrada
+	SourceMap   *codegen.SourceMap // Source map de GoCode hacia el archivo de entrada
+	Modules     []string           // Módulos del proyecto, las dependencias antes que quien las importa
+	Regenerated []string           // Módulos que se generaron de nuevo en esta compilación
+}
+
+// NewCache crea una caché. Si dir no está vacío, se cargan las entradas
+// guardadas ahí por compilaciones anteriores y Build guarda las nuevas.
+func NewCache(dir string) *Cache {
+	c := &Cache{dir: dir, version: compilerVersion(), modules: make(map[string]*Module), sema: sema.NewModuleCache()}
+	if dir == "" {
+		return c
+	}
+	// Una caché ilegible o de otra versión simplemente se regenera
+	if data, err := os.ReadFile(filepath.Join(dir, indexFile)); err == nil {
+		var saved index
+		if json.Unmarshal(data, &saved) == nil && saved.Version == c.version && saved.Modules != nil {
+			c.modules = saved.Modules
+		}
+	}
+	return c
+}
+
+var (
+	versionOnce sync.Once
+	version     string
+)
+
+// compilerVersion identifica el compilador que genera la caché: el formato
+// del índice y el hash del ejecutable, de modo que otra versión de zylo (o el
+// mismo código con otro codegen) no reutiliza el código Go generado
+func compilerVersion() string {
+	versionOnce.Do(func() {
+		version = fmt.Sprintf("%d", indexFormat)
+		exe, err := os.Executable()
+		if err != nil {
+			return
+		}
+		f, err := os.Open(exe)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+		h := sha256.New()
+		if _, err := io.Copy(h, f); err == nil {
+			version += "-" + hex.EncodeToString(h.Sum(nil))
+		}
+	})
+	return version
+}
+
+// Build genera el código Go de entry y de los módulos locales que importa,
+// reutilizando los que no cambiaron desde la compilación anterior
+func (c *Cache) Build(entry string) (*Result, error) {
+	entry, err := filepath.Abs(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	b := &builder{cache: c, result: result, done: make(map[string]bool)}
+	if err := b.build(entry); err != nil {
+		return nil, err
+	}
+	result.GoCode = c.modules[entry].GoCode
+	result.SourceMap = c.modules[entry].SourceMap
+
+	if c.dir != "" {
+		if err := c.save(); err != nil {
+			return nil, fmt.Errorf("error guardando la caché de compilación: %v", err)
+		}
+	}
+	return result, nil
+}
+
+// save escribe las entradas de la caché en su directorio
+func (c *Cache) save() error {
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(index{Version: c.version, Modules: c.modules})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(c.dir, indexFile), data, 0644)
+}
+
+// builder recorre el grafo de imports de una compilación
+type builder struct {
+	cache  *Cache
+	result *Result
+	done   map[string]bool
+	stack  []string // Módulos en curso, para detectar imports circulares
+}
+
+// build genera path después de sus dependencias
+func (b *builder) build(path string) error {
+	if b.done[path] {
+		return nil
+	}
+	for i, p := range b.stack {
+		if p == path {
+			var cycle []string
+			for _, m := range append(b.stack[i:], path) {
+				cycle = append(cycle, filepath.Base(m))
+			}
+			return fmt.Errorf("import circular: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	b.stack = append(b.stack, path)
+	defer func() { b.stack = b.stack[:len(b.stack)-1] }()
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("error leyendo %s: %v", path, err)
+	}
+	sum := sha256.Sum256(content)
+	sourceHash := hex.EncodeToString(sum[:])
+
+	module := b.cache.modules[path]
+	var program *ast.Program
+	if module == nil || module.SourceHash != sourceHash {
+		// El código cambió: hay que volver a parsear para conocer sus imports
+		program, err = parse(path, content)
+		if err != nil {
+			return err
+		}
+		module = &Module{
+			SourceHash: sourceHash,
+			Imports:    localImports(path, program),
+		}
+	}
+
+	for _, dep := range module.Imports {
+		if err := b.build(dep); err != nil {
+			return err
+		}
+	}
+
+	if program == nil && !b.depsChanged(module) {
+		b.finish(path)
+		return nil
+	}
+
+	// El código o la interfaz de alguna dependencia cambió: regenerar
+	if program == nil {
+		if program, err = parse(path, content); err != nil {
+			return err
+		}
+	}
+	// Los tipos exportados pueden depender de los de sus dependencias, así que
+	// la firma se recalcula cada vez que se regenera
+	if module.GoCode, module.SourceMap, module.Signature, err = b.generate(path, program); err != nil {
+		return err
+	}
+	module.DepSigs = make(map[string]string, len(module.Imports))
+	for _, dep := range module.Imports {
+		module.DepSigs[dep] = b.cache.modules[dep].Signature
+	}
+	b.cache.modules[path] = module
+	b.result.Regenerated = append(b.result.Regenerated, path)
+	b.finish(path)
+	return nil
+}
+
+// depsChanged indica si cambió la interfaz de alguna dependencia de module
+// desde que se generó
+func (b *builder) depsChanged(module *Module) bool {
+	for _, dep := range module.Imports {
+		if module.DepSigs[dep] != b.cache.modules[dep].Signature {
+			return true
+		}
+	}
+	return false
+}
+
+func (b *builder) finish(path string) {
+	b.done[path] = true
+	b.result.Modules = append(b.result.Modules, path)
+}
+
+// generate analiza el módulo y genera su código Go, su source map y la firma
+// de su interfaz pública
+func (b *builder) generate(path string, program *ast.Program) (string, *codegen.SourceMap, string, error) {
+	sa := sema.NewSemanticAnalyzer()
+	sa.SetModuleCache(b.cache.sema)
+	sa.SetBaseDir(filepath.Dir(path))
+	sa.Analyze(program)
+	if errs := sa.Errors(); len(errs) > 0 {
+		return "", nil, "", fmt.Errorf("%s: %s", path, errs[0])
+	}
+
+	cg := codegen.NewCodeGenerator(sa.GetSymbolTable())
+	goCode, err := cg.Generate(program)
+	if err != nil {
+		return "", nil, "", fmt.Errorf("%s: %v", path, err)
+	}
+	sourceMap := cg.SourceMap()
+	sourceMap.Source = path
+	return goCode, sourceMap, signature(sa, program), nil
+}
+
+// parse parsea el contenido de un módulo
+func parse(path string, content []byte) (*ast.Program, error) {
+	p := parser.New(lexer.New(string(content)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, fmt.Errorf("%s: %s", path, p.Errors()[0])
+	}
+	return program, nil
+}
+
+// localImports devuelve las rutas absolutas de los módulos locales que importa
+// program: import "./util" (relativo al archivo, con o sin .zylo), import util
+// si existe util.zylo junto al archivo y los paquetes de zylo_modules/. Los
+// módulos de std/ y los nativos no forman parte del grafo.
+func localImports(path string, program *ast.Program) []string {
+	dir := filepath.Dir(path)
+	var imports []string
+	for _, stmt := range program.Statements {
+		imp, ok := stmt.(*ast.ImportStatement)
+		if !ok {
+			continue
+		}
+
+		var modulePath string
+		switch {
+		case imp.ModulePath != "" && !strings.HasPrefix(imp.ModulePath, "std/"):
+			modulePath = filepath.Join(dir, imp.ModulePath)
+		case imp.ModuleName != nil:
+			modulePath = filepath.Join(dir, imp.ModuleName.Value)
+		default:
+			continue
+		}
+		if filepath.Ext(modulePath) != ".zylo" {
+			modulePath += ".zylo"
+		}
+		if _, err := os.Stat(modulePath); err != nil {
+			pkgPath, ok := packages.Resolve(dir, imp.ModulePath)
+			if imp.ModulePath == "" || !ok {
+				continue
+			}
+			modulePath = pkgPath
+		}
+		imports = append(imports, modulePath)
+	}
+	return imports
+}
+
+// signature devuelve el hash de la interfaz pública de un módulo: lo que
+// exporta según Program.Exports, con los tipos que resuelve el analizador
+// semántico. No depende de los cuerpos, así que cambiar la implementación de
+// una función no obliga a regenerar los módulos que la importan.
+func signature(sa *sema.SemanticAnalyzer, program *ast.Program) string {
+	sum := sha256.Sum256([]byte(sa.ExportSignature(program)))
+	return hex.EncodeToString(sum[:])
+}
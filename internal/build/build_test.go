@@ -0,0 +1,188 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func writeModules(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatalf("error escribiendo %s: %v", name, err)
+		}
+	}
+}
+
+// regenerated devuelve los nombres de los módulos regenerados, ordenados
+func regenerated(result *Result) []string {
+	var names []string
+	for _, path := range result.Regenerated {
+		names = append(names, filepath.Base(path))
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestIncrementalBuild(t *testing.T) {
+	dir := t.TempDir()
+	// main importa util y texto; util importa base
+	writeModules(t, dir, map[string]string{
+		"base.zylo":  "func doble(x int): int {\n    return x * 2\n}\n",
+		"util.zylo":  "import \"./base\"\nfunc cuadruple(x int): int {\n    return x * 4\n}\n",
+		"texto.zylo": "func saludo(): string {\n    return \"hola\"\n}\n",
+		"main.zylo":  "import \"./util\"\nimport \"./texto\"\nshow.log(\"main\")\n",
+	})
+	entry := filepath.Join(dir, "main.zylo")
+
+	tests := []struct {
+		name     string
+		change   map[string]string
+		expected string
+	}{
+		{"primera compilación", nil, "base.zylo main.zylo texto.zylo util.zylo"},
+		{"sin cambios", nil, ""},
+		// Cambia solo el cuerpo: los módulos que importan base no cambian
+		{"cuerpo de base", map[string]string{"base.zylo": "func doble(x int): int {\n    return x + x\n}\n"}, "base.zylo"},
+		// Cambia la interfaz de base: se regenera util, que lo importa, pero
+		// no main porque la interfaz de util sigue igual
+		{"interfaz de base", map[string]string{"base.zylo": "func doble(x int, y int): int {\n    return x * y\n}\n"}, "base.zylo util.zylo"},
+		{"interfaz de texto", map[string]string{"texto.zylo": "func saludo(nombre string): string {\n    return nombre\n}\n"}, "main.zylo texto.zylo"},
+		{"módulo de entrada", map[string]string{"main.zylo": "import \"./util\"\nimport \"./texto\"\nshow.log(\"otro\")\n"}, "main.zylo"},
+	}
+
+	cache := NewCache(t.TempDir())
+	for _, tt := range tests {
+		writeModules(t, dir, tt.change)
+		result, err := cache.Build(entry)
+		if err != nil {
+			t.Fatalf("%s: error inesperado: %v", tt.name, err)
+		}
+		if got := strings.Join(regenerated(result), " "); got != tt.expected {
+			t.Errorf("%s: regenerados %q, se esperaba %q", tt.name, got, tt.expected)
+		}
+		if len(result.Modules) != 4 {
+			t.Errorf("%s: se esperaban 4 módulos, obtenidos %v", tt.name, result.Modules)
+		}
+		if !strings.Contains(result.GoCode, "package main") {
+			t.Errorf("%s: el código del módulo de entrada no es un programa Go:\n%s", tt.name, result.GoCode)
+		}
+	}
+}
+
+func TestSignatureFollowsExports(t *testing.T) {
+	dir := t.TempDir()
+	writeModules(t, dir, map[string]string{
+		"lib.zylo":  "export func f(a int): int {\n    return a\n}\nfunc interna(a int): int {\n    return a\n}\n",
+		"main.zylo": "import \"./lib\"\nshow.log(\"main\")\n",
+	})
+	entry := filepath.Join(dir, "main.zylo")
+
+	tests := []struct {
+		name     string
+		lib      string
+		expected string
+	}{
+		{"primera compilación", "", "lib.zylo main.zylo"},
+		// Lo que no se exporta no forma parte de la interfaz
+		{"función no exportada", "export func f(a int): int {\n    return a\n}\nfunc interna(a int, b int): int {\n    return a\n}\n", "lib.zylo"},
+		{"función exportada", "export func f(a int, b int): int {\n    return a\n}\nfunc interna(a int, b int): int {\n    return a\n}\n", "lib.zylo main.zylo"},
+		{"struct exportado", "export struct Punto {\n    x int\n}\n", "lib.zylo main.zylo"},
+		{"campo del struct", "export struct Punto {\n    x int\n    y int\n}\n", "lib.zylo main.zylo"},
+		{"enum exportado", "export struct Punto {\n    x int\n    y int\n}\nexport enum Color { Rojo, Verde }\n", "lib.zylo main.zylo"},
+		{"miembro del enum", "export struct Punto {\n    x int\n    y int\n}\nexport enum Color { Rojo, Azul }\n", "lib.zylo main.zylo"},
+		// El tipo inferido de una variable también es parte de la interfaz
+		{"variable", "limite := 10\n", "lib.zylo main.zylo"},
+		{"valor de la variable", "limite := 20\n", "lib.zylo"},
+		{"tipo inferido", "limite := \"veinte\"\n", "lib.zylo main.zylo"},
+	}
+
+	cache := NewCache(t.TempDir())
+	for _, tt := range tests {
+		if tt.lib != "" {
+			writeModules(t, dir, map[string]string{"lib.zylo": tt.lib})
+		}
+		result, err := cache.Build(entry)
+		if err != nil {
+			t.Fatalf("%s: error inesperado: %v", tt.name, err)
+		}
+		if got := strings.Join(regenerated(result), " "); got != tt.expected {
+			t.Errorf("%s: regenerados %q, se esperaba %q", tt.name, got, tt.expected)
+		}
+	}
+}
+
+func TestBuildCachePersists(t *testing.T) {
+	dir := t.TempDir()
+	writeModules(t, dir, map[string]string{
+		"util.zylo": "func uno(): int {\n    return 1\n}\n",
+		"main.zylo": "import \"./util\"\nshow.log(\"main\")\n",
+	})
+	entry := filepath.Join(dir, "main.zylo")
+	cacheDir := t.TempDir()
+
+	if _, err := NewCache(cacheDir).Build(entry); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	// Una caché nueva sobre el mismo directorio reutiliza lo generado
+	result, err := NewCache(cacheDir).Build(entry)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if len(result.Regenerated) != 0 {
+		t.Errorf("no se esperaban módulos regenerados, obtenidos %v", result.Regenerated)
+	}
+	// El source map también se guarda en la caché
+	if result.SourceMap == nil || result.SourceMap.Source != entry || len(result.SourceMap.Mappings) == 0 {
+		t.Errorf("se esperaba el source map de %s, obtenido %+v", entry, result.SourceMap)
+	}
+}
+
+func TestBuildImportCycle(t *testing.T) {
+	dir := t.TempDir()
+	writeModules(t, dir, map[string]string{
+		"a.zylo": "import \"./b\"\n",
+		"b.zylo": "import \"./a\"\n",
+	})
+
+	_, err := NewCache("").Build(filepath.Join(dir, "a.zylo"))
+	if err == nil || err.Error() != "import circular: a.zylo -> b.zylo -> a.zylo" {
+		t.Errorf("se esperaba un error de import circular, obtenido %v", err)
+	}
+}
+
+func TestBuildCacheVersion(t *testing.T) {
+	dir := t.TempDir()
+	writeModules(t, dir, map[string]string{
+		"main.zylo": "show.log(\"main\")\n",
+	})
+	entry := filepath.Join(dir, "main.zylo")
+	cacheDir := t.TempDir()
+
+	if _, err := NewCache(cacheDir).Build(entry); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(cacheDir, indexFile))
+	if err != nil {
+		t.Fatalf("error leyendo el índice: %v", err)
+	}
+	if !strings.Contains(string(data), `"version":"`+compilerVersion()+`"`) {
+		t.Fatalf("el índice no guarda la versión del compilador:\n%s", data)
+	}
+
+	// Las entradas que guardó otra versión del compilador se descartan
+	stale := strings.Replace(string(data), compilerVersion(), "1-otra", 1)
+	if err := os.WriteFile(filepath.Join(cacheDir, indexFile), []byte(stale), 0644); err != nil {
+		t.Fatalf("error escribiendo el índice: %v", err)
+	}
+	result, err := NewCache(cacheDir).Build(entry)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if got := strings.Join(regenerated(result), " "); got != "main.zylo" {
+		t.Errorf("regenerados %q, se esperaba main.zylo", got)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
 	"github.com/zylo-lang/zylo/internal/sema"
 )
 
@@ -20,6 +21,8 @@ type CodeGenerator struct {
 	inVoidFunction     bool              // Track if we're generating code inside a void function
 	symbolTable        *sema.SymbolTable // AรADIDO: tabla de sรญmbolos para type info
 	imports            map[string]bool   // Track de imports necesarios
+	marks              []sourceMark      // Inicio del código de cada sentencia, para el source map
+	sourceMap          *SourceMap
 }
 
 // NewCodeGenerator crea un nuevo CodeGenerator.
@@ -113,6 +116,7 @@ func (cg *CodeGenerator) Generate(program *ast.Program) (string, error) {
 	cg.classNames = make([]string, 0)
 	cg.needsRuntimeImport = false
 	cg.currentOutput = &cg.mainOutput
+	cg.marks = nil
 
 	// First pass: categorize statements
 	for _, stmt := range program.Statements {
@@ -212,7 +216,20 @@ func (cg *CodeGenerator) Generate(program *ast.Program) (string, error) {
 	cg.mainOutput.WriteString("    return value\n")
 	cg.mainOutput.WriteString("}\n\n")
 
+	cg.mainOutput.WriteString("// zyloDestructure returns the n elements of a list being destructured\n")
+	cg.mainOutput.WriteString("func zyloDestructure(value interface{}, n int) []interface{} {\n")
+	cg.mainOutput.WriteString("    slice, ok := value.([]interface{})\n")
+	cg.mainOutput.WriteString("    if !ok {\n")
+	cg.mainOutput.WriteString("        panic(\"cannot destructure non-array value\")\n")
+	cg.mainOutput.WriteString("    }\n")
+	cg.mainOutput.WriteString("    if len(slice) != n {\n")
+	cg.mainOutput.WriteString("        panic(fmt.Sprintf(\"cannot destructure %d values into %d variables\", len(slice), n))\n")
+	cg.mainOutput.WriteString("    }\n")
+	cg.mainOutput.WriteString("    return slice\n")
+	cg.mainOutput.WriteString("}\n\n")
+
 	// Append all declarations (functions, classes)
+	declarationsStart := cg.mainOutput.Len()
 	cg.mainOutput.WriteString(cg.declarations.String())
 
 	// Generate main function with executable statements
@@ -231,10 +248,17 @@ func (cg *CodeGenerator) Generate(program *ast.Program) (string, error) {
 
 	// Limpiar imports no usados antes de retornar
 	finalCode := cleanUnusedImports(cg.mainOutput.String())
+	cg.sourceMap = cg.buildSourceMap(finalCode, declarationsStart)
 
 	return finalCode, nil
 }
 
+// SourceMap devuelve el source map del último código generado con Generate.
+// Su campo Source queda vacío: lo completa quien conoce el archivo Zylo.
+func (cg *CodeGenerator) SourceMap() *SourceMap {
+	return cg.sourceMap
+}
+
 // generateStatementInDeclarations generates a statement to the declarations buffer.
 func (cg *CodeGenerator) generateStatementInDeclarations(stmt ast.Statement) {
 	oldOutput := cg.currentOutput
@@ -249,6 +273,10 @@ func (cg *CodeGenerator) generateStatement(stmt ast.Statement) {
 		return
 	}
 
+	if token, ok := statementToken(stmt); ok {
+		cg.markStatement(token.StartLine, token.StartCol)
+	}
+
 	switch s := stmt.(type) {
 	case *ast.ImportStatement:
 		if s != nil {
@@ -278,6 +306,10 @@ func (cg *CodeGenerator) generateStatement(stmt ast.Statement) {
 		if s != nil {
 			cg.generateWhileStatement(s)
 		}
+	case *ast.RepeatStatement:
+		if s != nil {
+			cg.generateRepeatStatement(s)
+		}
 	case *ast.ForStatement:
 		if s != nil {
 			cg.generateForStatement(s)
@@ -295,6 +327,35 @@ func (cg *CodeGenerator) generateStatement(stmt ast.Statement) {
 	}
 }
 
+// statementToken devuelve el token con la posición de una sentencia
+func statementToken(stmt ast.Statement) (lexer.Token, bool) {
+	switch s := stmt.(type) {
+	case *ast.ImportStatement:
+		return s.Token, true
+	case *ast.VarStatement:
+		return s.Token, true
+	case *ast.ExpressionStatement:
+		return s.Token, true
+	case *ast.FuncStatement:
+		return s.Token, true
+	case *ast.ReturnStatement:
+		return s.Token, true
+	case *ast.IfStatement:
+		return s.Token, true
+	case *ast.WhileStatement:
+		return s.Token, true
+	case *ast.RepeatStatement:
+		return s.Token, true
+	case *ast.ForStatement:
+		return s.Token, true
+	case *ast.BreakStatement:
+		return s.Token, true
+	case *ast.ClassStatement:
+		return s.Token, true
+	}
+	return lexer.Token{}, false
+}
+
 // generateConstantDeclaration genera una declaraciรณn de constante global
 func (cg *CodeGenerator) generateConstantDeclaration(stmt *ast.VarStatement) {
 	if stmt == nil || stmt.Name == nil {
@@ -375,6 +436,17 @@ func (cg *CodeGenerator) generateVarStatement(stmt *ast.VarStatement) {
 		return
 	}
 
+	// El análisis semántico ya calculó el valor de un const, así que se
+	// declara como constante de Go, que puede quedar sin usar
+	if constType := constGoType(stmt); constType != "" {
+		cg.writeString("const ")
+		cg.generateExpression(stmt.Name)
+		cg.writeString(" " + constType + " = ")
+		cg.generateExpression(stmt.Value)
+		cg.writeString("\n")
+		return
+	}
+
 	// For explicit types, use typed variable declaration
 	if stmt.Name.TypeAnnotation != "" && stmt.Name.TypeAnnotation != "ANY" {
 		cg.writeString("var ")
@@ -563,6 +635,26 @@ func (cg *CodeGenerator) generateReturnStatement(stmt *ast.ReturnStatement) {
 	cg.writeString("\n")
 }
 
+// constGoType devuelve el tipo Go de una declaración const cuyo valor es un
+// literal, o "" si no se puede declarar como constante de Go
+func constGoType(stmt *ast.VarStatement) string {
+	if stmt.Token.Type != lexer.CONST || (stmt.Name.TypeAnnotation != "" && stmt.Name.TypeAnnotation != "ANY") {
+		return ""
+	}
+	switch v := stmt.Value.(type) {
+	case *ast.NumberLiteral:
+		if _, ok := v.Value.(float64); ok {
+			return "float64"
+		}
+		return "int64"
+	case *ast.StringLiteral:
+		return "string"
+	case *ast.BooleanLiteral:
+		return "bool"
+	}
+	return ""
+}
+
 // generateIfStatement genera cรณdigo Go para una sentencia 'if'.
 func (cg *CodeGenerator) generateIfStatement(stmt *ast.IfStatement) {
 	cg.writeString("if ")
@@ -579,6 +671,22 @@ func (cg *CodeGenerator) generateIfStatement(stmt *ast.IfStatement) {
 	cg.dedent()
 	cg.writeString("}")
 
+	for _, clause := range stmt.ElseIfs {
+		cg.writeString(" else if ")
+		cg.generateExpression(clause.Condition)
+		cg.writeString(" {\n")
+		cg.indent()
+
+		if clause.Consequence != nil {
+			for _, bodyStmt := range clause.Consequence.Statements {
+				cg.generateStatement(bodyStmt)
+			}
+		}
+
+		cg.dedent()
+		cg.writeString("}")
+	}
+
 	if stmt.Alternative != nil {
 		cg.writeString(" else {\n")
 		cg.indent()
@@ -593,6 +701,45 @@ func (cg *CodeGenerator) generateIfStatement(stmt *ast.IfStatement) {
 	cg.writeString("\n")
 }
 
+// generateIfExpression genera código Go para un if usado como expresión: una
+// función anónima que se llama en el acto y devuelve el último valor de la
+// rama elegida, o nil.
+func (cg *CodeGenerator) generateIfExpression(exp *ast.IfExpression) {
+	cg.writeString("func() interface{} {\n")
+	cg.indent()
+	cg.writeString("if ")
+	cg.generateExpression(exp.Condition)
+	cg.writeString(" {\n")
+	cg.generateBranchValue(exp.Consequence)
+	if exp.Alternative != nil {
+		cg.writeString("} else {\n")
+		cg.generateBranchValue(exp.Alternative)
+	}
+	cg.writeString("}\n")
+	cg.writeString("return nil\n")
+	cg.dedent()
+	cg.writeString("}()")
+}
+
+// generateBranchValue genera una rama de un if expresión; si termina en una
+// expresión, la rama la devuelve
+func (cg *CodeGenerator) generateBranchValue(block *ast.BlockStatement) {
+	cg.indent()
+	defer cg.dedent()
+	if block == nil {
+		return
+	}
+	for i, stmt := range block.Statements {
+		if last, ok := stmt.(*ast.ExpressionStatement); ok && i == len(block.Statements)-1 && last.Expression != nil {
+			cg.writeString("return ")
+			cg.generateExpression(last.Expression)
+			cg.writeString("\n")
+			continue
+		}
+		cg.generateStatement(stmt)
+	}
+}
+
 // generateWhileStatement genera cรณdigo Go para una sentencia 'while'.
 func (cg *CodeGenerator) generateWhileStatement(stmt *ast.WhileStatement) {
 	cg.writeString("for ")
@@ -610,6 +757,24 @@ func (cg *CodeGenerator) generateWhileStatement(stmt *ast.WhileStatement) {
 	cg.writeString("}\n")
 }
 
+// generateRepeatStatement genera código Go para un bucle 'repeat': un for con
+// un contador que el script no ve.
+func (cg *CodeGenerator) generateRepeatStatement(stmt *ast.RepeatStatement) {
+	cg.writeString("for _zyloRepeat := int64(0); _zyloRepeat < ")
+	cg.generateExpression(stmt.Count)
+	cg.writeString("; _zyloRepeat++ {\n")
+	cg.indent()
+
+	if stmt.Body != nil {
+		for _, bodyStmt := range stmt.Body.Statements {
+			cg.generateStatement(bodyStmt)
+		}
+	}
+
+	cg.dedent()
+	cg.writeString("}\n")
+}
+
 // generateForStatement genera código Go para una sentencia 'for' tradicional.
 // Necesita generar partes inline sin newlines para la sintaxis correcta de Go.
 func (cg *CodeGenerator) generateForStatement(stmt *ast.ForStatement) {
@@ -713,12 +878,75 @@ func (cg *CodeGenerator) generateAssignmentExpression(exp *ast.AssignmentExpress
 	cg.generateExpression(exp.Value)
 }
 
+// generateDestructuringAssignment genera código Go para a, b = valor. Con
+// una lista literal a la derecha es una asignación múltiple de Go, que evalúa
+// todos los valores antes de asignar; si no, la lista se desempaqueta con
+// zyloDestructure.
+func (cg *CodeGenerator) generateDestructuringAssignment(exp *ast.DestructuringAssignmentExpression) {
+	if list, ok := exp.Value.(*ast.ListLiteral); ok && len(list.Elements) == len(exp.Targets) {
+		for i, target := range exp.Targets {
+			if i > 0 {
+				cg.writeString(", ")
+			}
+			cg.generateExpression(target)
+		}
+		cg.writeString(" = ")
+		for i, element := range list.Elements {
+			if i > 0 {
+				cg.writeString(", ")
+			}
+			cg.generateExpression(element)
+		}
+		return
+	}
+
+	cg.writeString("func() {\n")
+	cg.indent()
+	cg.writeString("zyloValues := zyloDestructure(")
+	cg.generateExpression(exp.Value)
+	cg.writeString(fmt.Sprintf(", %d)\n", len(exp.Targets)))
+	for i, target := range exp.Targets {
+		cg.generateExpression(target)
+		cg.writeString(fmt.Sprintf(" = zyloValues[%d]\n", i))
+	}
+	cg.dedent()
+	cg.writeString("}()")
+}
+
 // generateInfixExpression genera cรณdigo Go para expresiones infijas (operaciones binarias).
 func (cg *CodeGenerator) generateInfixExpression(exp *ast.InfixExpression) {
 	if exp == nil || exp.Left == nil || exp.Right == nil {
 		return
 	}
 
+	switch exp.Operator {
+	case "xor":
+		cg.writeString("(")
+		cg.generateExpression(exp.Left)
+		cg.writeString(" != ")
+		cg.generateExpression(exp.Right)
+		cg.writeString(")")
+		return
+	case "??":
+		// Go no tiene ??: una función anónima evalúa el derecho solo si el
+		// izquierdo es nil
+		cg.writeString("func() interface{} { if v := interface{}(")
+		cg.generateExpression(exp.Left)
+		cg.writeString("); v != nil { return v }; return ")
+		cg.generateExpression(exp.Right)
+		cg.writeString(" }()")
+		return
+	case "&", "|", "^", "<<", ">>":
+		// Go da a estos operadores otra precedencia que Zylo: los operandos
+		// van entre paréntesis para conservar la agrupación del AST
+		cg.writeString("(")
+		cg.generateExpression(exp.Left)
+		cg.writeString(") " + exp.Operator + " (")
+		cg.generateExpression(exp.Right)
+		cg.writeString(")")
+		return
+	}
+
 	// Use direct Go operations for all basic operators
 	cg.generateExpression(exp.Left)
 	cg.writeString(" " + exp.Operator + " ")
@@ -859,10 +1087,14 @@ func (cg *CodeGenerator) generateExpression(exp ast.Expression) {
 		cg.generateInfixExpression(e)
 	case *ast.AssignmentExpression:
 		cg.generateAssignmentExpression(e)
+	case *ast.DestructuringAssignmentExpression:
+		cg.generateDestructuringAssignment(e)
 	case *ast.PrefixExpression:
 		cg.generatePrefixExpression(e)
 	case *ast.CollectionMethodCall:
 		cg.generateCollectionMethodCallSelfContained(e)
+	case *ast.IfExpression:
+		cg.generateIfExpression(e)
 	default:
 		cg.writeString(fmt.Sprintf("// TODO: Expresiรณn no soportada: %T", e))
 	}
@@ -1009,14 +1241,12 @@ func (cg *CodeGenerator) generateMapLiteral(exp *ast.MapLiteral) {
 	}
 
 	cg.writeString("map[string]interface{}{")
-	i := 0
-	for key, value := range exp.Pairs {
-		cg.writeString(fmt.Sprintf("%q: ", key))
-		cg.generateExpression(value)
+	for i, pair := range exp.Pairs {
+		cg.writeString(fmt.Sprintf("%q: ", pair.Key))
+		cg.generateExpression(pair.Value)
 		if i < len(exp.Pairs)-1 {
 			cg.writeString(", ")
 		}
-		i++
 	}
 	cg.writeString("}")
 }
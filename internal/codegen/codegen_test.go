@@ -2,6 +2,7 @@ package codegen
 
 import (
 	"bytes"
+	"go/format"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -399,6 +400,50 @@ complejo := (5 + 3) * 2
 	}
 }
 
+func TestConstDeclarations(t *testing.T) {
+	input := `
+const HORA = 60 * 60
+const DIA = 24 * HORA
+const MITAD = DIA / 2.0
+const SALUDO = "ho" + "la"
+const ACTIVO = !false
+show.log(DIA)
+`
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	sa := sema.NewSemanticAnalyzer()
+	sa.Analyze(program)
+	if len(sa.Errors()) > 0 {
+		t.Fatalf("Semantic analysis errors: %v", sa.Errors())
+	}
+
+	generated, err := NewCodeGenerator(sa.GetSymbolTable()).Generate(program)
+	if err != nil {
+		t.Fatalf("Code generation error: %v", err)
+	}
+	formatted, err := format.Source([]byte(generated))
+	if err != nil {
+		t.Fatalf("generated code is not valid Go: %v\n%s", err, generated)
+	}
+	generated = string(formatted)
+	for _, expected := range []string{
+		"const HORA int64 = int64(3600)",
+		"const DIA int64 = int64(86400)",
+		"const MITAD float64 = float64(43200.000000)",
+		`const SALUDO string = "hola"`,
+		"const ACTIVO bool = true",
+	} {
+		if !strings.Contains(generated, expected) {
+			t.Errorf("expected %q in generated code:\n%s", expected, generated)
+		}
+	}
+}
+
 func TestTypedFunctionParameters(t *testing.T) {
 	input := `
 func suma(a int, b int) {
@@ -515,3 +560,109 @@ func main() {
 	t.Logf("   - No compilation errors with typed parameters")
 	t.Logf("   - Correct results: suma(5, 3) = 8")
 }
+
+func TestIfExpression(t *testing.T) {
+	input := `
+x := 5
+tipo := if x > 3 { "grande" } else { "pequeño" }
+show.log(tipo)
+`
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	sa := sema.NewSemanticAnalyzer()
+	sa.Analyze(program)
+	if len(sa.Errors()) > 0 {
+		t.Fatalf("Semantic analysis errors: %v", sa.Errors())
+	}
+
+	cg := NewCodeGenerator(sa.GetSymbolTable())
+	generated, err := cg.Generate(program)
+	if err != nil {
+		t.Fatalf("Code generation error: %v", err)
+	}
+
+	formatted, err := format.Source([]byte(generated))
+	if err != nil {
+		t.Fatalf("generated code is not valid Go: %v\n%s", err, generated)
+	}
+	for _, want := range []string{"func() interface{} {", `return "grande"`, `return "pequeño"`, "}()"} {
+		if !strings.Contains(string(formatted), want) {
+			t.Errorf("expected %q in generated code:\n%s", want, formatted)
+		}
+	}
+}
+
+func TestRepeatStatement(t *testing.T) {
+	input := `
+n := 0
+repeat 3 {
+    n += 1
+}
+show.log(n)
+`
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	sa := sema.NewSemanticAnalyzer()
+	sa.Analyze(program)
+	if len(sa.Errors()) > 0 {
+		t.Fatalf("Semantic analysis errors: %v", sa.Errors())
+	}
+
+	generated, err := NewCodeGenerator(sa.GetSymbolTable()).Generate(program)
+	if err != nil {
+		t.Fatalf("Code generation error: %v", err)
+	}
+	formatted, err := format.Source([]byte(generated))
+	if err != nil {
+		t.Fatalf("generated code is not valid Go: %v\n%s", err, generated)
+	}
+	expected := "for _zyloRepeat := int64(0); _zyloRepeat < int64(3); _zyloRepeat++ {"
+	if !strings.Contains(string(formatted), expected) {
+		t.Errorf("expected %q in generated code:\n%s", expected, formatted)
+	}
+}
+
+func TestNullCoalescing(t *testing.T) {
+	input := `
+a := 3
+b := a ?? 5
+show.log(b)
+`
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	sa := sema.NewSemanticAnalyzer()
+	sa.Analyze(program)
+	if len(sa.Errors()) > 0 {
+		t.Fatalf("Semantic analysis errors: %v", sa.Errors())
+	}
+
+	generated, err := NewCodeGenerator(sa.GetSymbolTable()).Generate(program)
+	if err != nil {
+		t.Fatalf("Code generation error: %v", err)
+	}
+	formatted, err := format.Source([]byte(generated))
+	if err != nil {
+		t.Fatalf("generated code is not valid Go: %v\n%s", err, generated)
+	}
+	if strings.Contains(string(formatted), "??") {
+		t.Errorf("?? should not reach the generated code:\n%s", formatted)
+	}
+	if !strings.Contains(string(formatted), "if v := interface{}(a); v != nil {") {
+		t.Errorf("expected a nil check on a in generated code:\n%s", formatted)
+	}
+}
@@ -0,0 +1,103 @@
+package codegen
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// SourceMap relaciona las líneas del código Go generado con las sentencias
+// Zylo de las que salen, para traducir a posiciones Zylo los errores y panics
+// de un programa compilado
+type SourceMap struct {
+	Version  int       `json:"version"`
+	Source   string    `json:"source"` // Archivo Zylo; lo fija quien llama a Generate
+	Mappings []Mapping `json:"mappings"`
+}
+
+// Mapping indica que el código Go desde GoLine sale de la sentencia Zylo en
+// ZyloLine:ZyloColumn, hasta el siguiente mapping
+type Mapping struct {
+	GoLine     int `json:"go_line"`
+	ZyloLine   int `json:"zylo_line"`
+	ZyloColumn int `json:"zylo_column"`
+}
+
+// Lookup devuelve el mapping que cubre la línea goLine del código generado,
+// o false si la línea es código auxiliar anterior a cualquier sentencia
+func (m *SourceMap) Lookup(goLine int) (Mapping, bool) {
+	i := sort.Search(len(m.Mappings), func(i int) bool { return m.Mappings[i].GoLine > goLine })
+	if i == 0 {
+		return Mapping{}, false
+	}
+	return m.Mappings[i-1], true
+}
+
+// goPosition reconoce las posiciones archivo.go:línea de los mensajes de Go
+var goPosition = regexp.MustCompile(`(\S*\.go):(\d+)`)
+
+// Translate reemplaza en text (por ejemplo, la traza de un panic) las
+// posiciones del archivo Go goFile por las posiciones Zylo correspondientes
+func (m *SourceMap) Translate(text, goFile string) string {
+	return goPosition.ReplaceAllStringFunc(text, func(pos string) string {
+		match := goPosition.FindStringSubmatch(pos)
+		if filepath.Base(match[1]) != filepath.Base(goFile) {
+			return pos
+		}
+		line, _ := strconv.Atoi(match[2])
+		mapping, ok := m.Lookup(line)
+		if !ok {
+			return pos
+		}
+		return m.Source + ":" + strconv.Itoa(mapping.ZyloLine)
+	})
+}
+
+// sourceMark es la posición en un buffer de salida donde empieza el código de
+// una sentencia
+type sourceMark struct {
+	output *strings.Builder
+	offset int
+	line   int
+	column int
+}
+
+// markStatement anota que el código que se escriba a continuación sale de stmt
+func (cg *CodeGenerator) markStatement(line, column int) {
+	if line <= 0 || cg.currentOutput == nil {
+		return
+	}
+	cg.marks = append(cg.marks, sourceMark{output: cg.currentOutput, offset: cg.currentOutput.Len(), line: line, column: column})
+}
+
+// buildSourceMap convierte las marcas en mappings por línea del código final.
+// declarationsStart es el offset de las declaraciones dentro de mainOutput.
+func (cg *CodeGenerator) buildSourceMap(code string, declarationsStart int) *SourceMap {
+	sm := &SourceMap{Version: 1}
+	for _, mark := range cg.marks {
+		var offset int
+		switch mark.output {
+		case &cg.mainOutput:
+			offset = mark.offset
+		case &cg.declarations:
+			offset = declarationsStart + mark.offset
+		default:
+			continue // Salida temporal del análisis previo de imports
+		}
+		if offset > len(code) {
+			continue
+		}
+		goLine := strings.Count(code[:offset], "\n") + 1
+		mapping := Mapping{GoLine: goLine, ZyloLine: mark.line, ZyloColumn: mark.column}
+		// Una sentencia anidada en la misma línea Go reemplaza a la exterior
+		if n := len(sm.Mappings); n > 0 && sm.Mappings[n-1].GoLine == goLine {
+			sm.Mappings[n-1] = mapping
+			continue
+		}
+		sm.Mappings = append(sm.Mappings, mapping)
+	}
+	sort.SliceStable(sm.Mappings, func(i, j int) bool { return sm.Mappings[i].GoLine < sm.Mappings[j].GoLine })
+	return sm
+}
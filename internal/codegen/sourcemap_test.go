@@ -0,0 +1,122 @@
+package codegen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+	"github.com/zylo-lang/zylo/internal/sema"
+)
+
+func generateWithSourceMap(t *testing.T, input string) (string, *SourceMap) {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	sa := sema.NewSemanticAnalyzer()
+	sa.Analyze(program)
+	if len(sa.Errors()) > 0 {
+		t.Fatalf("Semantic analysis errors: %v", sa.Errors())
+	}
+
+	cg := NewCodeGenerator(sa.GetSymbolTable())
+	generated, err := cg.Generate(program)
+	if err != nil {
+		t.Fatalf("Code generation error: %v", err)
+	}
+	return generated, cg.SourceMap()
+}
+
+// goLineOf devuelve la primera línea (desde 1) del código que contiene text
+func goLineOf(t *testing.T, code, text string) int {
+	t.Helper()
+	for i, line := range strings.Split(code, "\n") {
+		if strings.Contains(line, text) {
+			return i + 1
+		}
+	}
+	t.Fatalf("Generated code does not contain %q:\n%s", text, code)
+	return 0
+}
+
+func TestSourceMap(t *testing.T) {
+	input := `func doble(x int): int {
+    return x * 2
+}
+
+lista := [1, 2, 3]
+show.log(doble(4))
+i := 0
+while i < 2 {
+    show.log(lista[i])
+    i = i + 1
+}
+`
+	generated, sm := generateWithSourceMap(t, input)
+
+	covered := make(map[int]bool)
+	for _, m := range sm.Mappings {
+		covered[m.ZyloLine] = true
+	}
+	for _, line := range []int{1, 2, 5, 6, 7, 8, 9, 10} {
+		if !covered[line] {
+			t.Errorf("Source map does not cover Zylo line %d: %+v", line, sm.Mappings)
+		}
+	}
+
+	tests := []struct {
+		goText   string
+		zyloLine int
+	}{
+		{"func doble(", 1},
+		{"x     *", 2},
+		{"lista", 5},
+		{"doble    (", 6},
+		{"zyloIndex(        lista", 9},
+		{"i         =", 10},
+	}
+	for _, tt := range tests {
+		goLine := goLineOf(t, generated, tt.goText)
+		mapping, ok := sm.Lookup(goLine)
+		if !ok {
+			t.Errorf("Go line %d (%q) has no mapping", goLine, tt.goText)
+			continue
+		}
+		if mapping.ZyloLine != tt.zyloLine {
+			t.Errorf("Go line %d (%q) maps to Zylo line %d, expected %d", goLine, tt.goText, mapping.ZyloLine, tt.zyloLine)
+		}
+	}
+
+	// El código auxiliar del encabezado no sale de ninguna sentencia
+	if _, ok := sm.Lookup(goLineOf(t, generated, "func zyloIndex")); ok {
+		t.Errorf("Helper code should not be mapped")
+	}
+}
+
+func TestSourceMapTranslate(t *testing.T) {
+	sm := &SourceMap{
+		Source:   "app.zylo",
+		Mappings: []Mapping{{GoLine: 40, ZyloLine: 3, ZyloColumn: 1}, {GoLine: 44, ZyloLine: 7, ZyloColumn: 5}},
+	}
+	trace := `panic: index out of bounds
+
+goroutine 1 [running]:
+main.zyloIndex(...)
+	/tmp/zylo_123.go:12
+main.main()
+	/tmp/zylo_123.go:45 +0x126
+runtime.main()
+	/usr/lib/go/src/runtime/proc.go:283 +0x28
+`
+	got := sm.Translate(trace, "/tmp/zylo_123.go")
+
+	for _, want := range []string{"\tapp.zylo:7 +0x126", "\t/tmp/zylo_123.go:12\n", "runtime/proc.go:283"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Translated trace should contain %q:\n%s", want, got)
+		}
+	}
+}
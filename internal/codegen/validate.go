@@ -0,0 +1,81 @@
+package codegen
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"strings"
+)
+
+// InvalidGoError indica que el código generado no es un programa Go válido.
+// Siempre es un error de codegen, no del script: el mensaje señala la línea
+// del código generado y Snippet muestra las líneas de alrededor.
+type InvalidGoError struct {
+	Line    int    // Línea del código generado; 0 si el error no tiene posición
+	Message string // Mensaje de go/parser o de la comprobación del paquete
+	Snippet string // Líneas alrededor de Line, numeradas y con Line marcada
+}
+
+func (e *InvalidGoError) Error() string {
+	message := fmt.Sprintf("error interno de codegen: generated invalid Go at line %d: %s", e.Line, e.Message)
+	if e.Line == 0 {
+		message = fmt.Sprintf("error interno de codegen: generated invalid Go: %s", e.Message)
+	}
+	if e.Snippet != "" {
+		message += "\n" + e.Snippet
+	}
+	return message
+}
+
+// Validate comprueba con go/parser que goCode es un programa Go completo: que
+// se puede parsear, que es el paquete main y que declara func main. Así un
+// error de codegen se detecta antes de llamar a go build y se devuelve como
+// *InvalidGoError con la zona del código que falla.
+func Validate(goCode string) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "main.go", goCode, parser.AllErrors)
+	if err != nil {
+		var list scanner.ErrorList
+		if errors.As(err, &list) && len(list) > 0 {
+			first := list[0]
+			return &InvalidGoError{Line: first.Pos.Line, Message: first.Msg, Snippet: snippet(goCode, first.Pos.Line)}
+		}
+		return &InvalidGoError{Message: err.Error()}
+	}
+
+	if file.Name.Name != "main" {
+		line := fset.Position(file.Name.Pos()).Line
+		return &InvalidGoError{Line: line, Message: fmt.Sprintf("package %s, expected package main", file.Name.Name), Snippet: snippet(goCode, line)}
+	}
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == "main" {
+			return nil
+		}
+	}
+	return &InvalidGoError{Message: "missing func main"}
+}
+
+// snippet devuelve las líneas de code alrededor de line, numeradas y con la
+// línea señalada marcada
+func snippet(code string, line int) string {
+	lines := strings.Split(code, "\n")
+	start, end := line-3, line+2
+	if start < 1 {
+		start = 1
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	var b strings.Builder
+	for n := start; n <= end; n++ {
+		marker := "  "
+		if n == line {
+			marker = "> "
+		}
+		fmt.Fprintf(&b, "%s%4d | %s\n", marker, n, lines[n-1])
+	}
+	return b.String()
+}
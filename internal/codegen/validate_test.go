@@ -0,0 +1,62 @@
+package codegen
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		line    int
+		message string
+	}{
+		{"válido", "package main\n\nfunc main() {\n}\n", 0, ""},
+		{"sintaxis", "package main\n\nfunc main() {\n\tx := \n}\n", 5, "expected operand"},
+		{"paquete", "package otro\n\nfunc main() {\n}\n", 1, "expected package main"},
+		{"sin main", "package main\n\nfunc f() {\n}\n", 0, "missing func main"},
+		// Un método main no sirve como punto de entrada
+		{"método main", "package main\n\ntype T struct{}\n\nfunc (T) main() {\n}\n", 0, "missing func main"},
+	}
+
+	for _, tt := range tests {
+		err := Validate(tt.code)
+		if tt.message == "" {
+			if err != nil {
+				t.Errorf("%s: error inesperado: %v", tt.name, err)
+			}
+			continue
+		}
+
+		var invalid *InvalidGoError
+		if !errors.As(err, &invalid) {
+			t.Errorf("%s: se esperaba *InvalidGoError, obtenido %v", tt.name, err)
+			continue
+		}
+		if invalid.Line != tt.line || !strings.Contains(invalid.Message, tt.message) {
+			t.Errorf("%s: esperado %q en la línea %d, obtenido %q en la línea %d", tt.name, tt.message, tt.line, invalid.Message, invalid.Line)
+		}
+	}
+}
+
+func TestValidateSnippet(t *testing.T) {
+	err := Validate("package main\n\nfunc main() {\n\tx := \n}\n")
+	if err == nil {
+		t.Fatal("se esperaba un error para código Go inválido")
+	}
+	for _, want := range []string{"generated invalid Go at line 5", "     4 | \tx := ", ">    5 | }"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("el error no contiene %q:\n%v", want, err)
+		}
+	}
+}
+
+// El código que genera codegen siempre tiene que pasar la validación
+func TestGeneratedCodeIsValid(t *testing.T) {
+	code, _ := generateWithSourceMap(t, "x := 1\nshow.log(x + 2)\n")
+	if err := Validate(code); err != nil {
+		t.Fatalf("el código generado no es válido: %v\n%s", err, code)
+	}
+}
@@ -0,0 +1,119 @@
+package evaluator
+
+// sum, avg, min y max reducen una lista de números. Si todos los elementos son
+// enteros, sum devuelve un entero; con algún float, un float. avg siempre
+// devuelve un float. min y max devuelven el elemento tal cual, así que
+// conservan su tipo. La lista vacía suma 0 y es un error en avg, min y max.
+
+import "fmt"
+
+// registerAggregateBuiltins registra sum, avg, min y max
+func (e *Evaluator) registerAggregateBuiltins() {
+	e.env.Set("sum", &BuiltinFunction{
+		Name: "sum",
+		Fn: func(args []Value) (Value, error) {
+			items, err := numberList("sum", args)
+			if err != nil {
+				return nil, err
+			}
+			return sumNumbers(items), nil
+		},
+	})
+
+	e.env.Set("avg", &BuiltinFunction{
+		Name: "avg",
+		Fn: func(args []Value) (Value, error) {
+			items, err := numberList("avg", args)
+			if err != nil {
+				return nil, err
+			}
+			if len(items) == 0 {
+				return nil, fmt.Errorf("avg() de una lista vacía")
+			}
+			total := floatValue(sumNumbers(items))
+			return &Float{Value: total / float64(len(items))}, nil
+		},
+	})
+
+	e.env.Set("min", &BuiltinFunction{
+		Name: "min",
+		Fn: func(args []Value) (Value, error) {
+			return extremeNumber("min", args, func(a, b float64) bool { return a < b })
+		},
+	})
+
+	e.env.Set("max", &BuiltinFunction{
+		Name: "max",
+		Fn: func(args []Value) (Value, error) {
+			return extremeNumber("max", args, func(a, b float64) bool { return a > b })
+		},
+	})
+}
+
+// numberList comprueba que args sea una sola lista de enteros y floats y
+// devuelve sus elementos
+func numberList(name string, args []Value) ([]Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%s() espera 1 argumento, obtenidos %d", name, len(args))
+	}
+	list, ok := args[0].(*List)
+	if !ok {
+		return nil, fmt.Errorf("%s() espera una lista, obtenido %s", name, getNormalizedType(args[0]))
+	}
+	for i, item := range list.Items {
+		switch item.(type) {
+		case *Integer, *Float:
+		default:
+			return nil, fmt.Errorf("%s() espera una lista de números, el elemento %d es %s", name, i, getNormalizedType(item))
+		}
+	}
+	return list.Items, nil
+}
+
+// sumNumbers suma items: un entero si todos lo son, si no un float
+func sumNumbers(items []Value) Value {
+	var intTotal int64
+	var floatTotal float64
+	isFloat := false
+	for _, item := range items {
+		switch n := item.(type) {
+		case *Integer:
+			intTotal += n.Value
+		case *Float:
+			floatTotal += n.Value
+			isFloat = true
+		}
+	}
+	if isFloat {
+		return &Float{Value: floatTotal + float64(intTotal)}
+	}
+	return &Integer{Value: intTotal}
+}
+
+// extremeNumber devuelve el elemento de la lista para el que better es
+// verdadero frente a todos los demás; ante un empate se queda con el primero
+func extremeNumber(name string, args []Value, better func(a, b float64) bool) (Value, error) {
+	items, err := numberList(name, args)
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%s() de una lista vacía", name)
+	}
+	best := items[0]
+	bestValue := floatValue(best)
+	for _, item := range items[1:] {
+		if value := floatValue(item); better(value, bestValue) {
+			best, bestValue = item, value
+		}
+	}
+	return best, nil
+}
+
+// floatValue devuelve un entero o float ya comprobado como float64
+func floatValue(v Value) float64 {
+	if n, ok := v.(*Integer); ok {
+		return float64(n.Value)
+	}
+	return v.(*Float).Value
+}
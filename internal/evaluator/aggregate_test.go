@@ -0,0 +1,46 @@
+package evaluator
+
+import "testing"
+
+func TestAggregateBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"sum([1, 2, 3])", 6},
+		{"sum([1, 2.5])", 3.5},
+		{"sum([])", 0},
+		{"avg([1, 2])", 1.5},
+		{"avg([4])", 4.0},
+		{"min([3, 1, 2])", 1},
+		{"max([3, 7, 2])", 7},
+		// min y max devuelven el elemento con su tipo
+		{"min([3, 1.5, 2])", 1.5},
+		{"max([1.5, 2, -1])", 2},
+		{"max([-2, -2.0])", -2},
+		{"l := [5, 10]\nsum(l) / len(l)", 7},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestAggregateErrors(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr string
+	}{
+		{"min([])", "min() de una lista vacía"},
+		{"max([])", "max() de una lista vacía"},
+		{"avg([])", "avg() de una lista vacía"},
+		{"sum([1, \"2\"])", "sum() espera una lista de números, el elemento 1 es string"},
+		{"max([true])", "max() espera una lista de números, el elemento 0 es bool"},
+		{"sum(3)", "sum() espera una lista, obtenido int"},
+		{"avg([1], [2])", "avg() espera 1 argumento, obtenidos 2"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expectedErr)
+	}
+}
@@ -0,0 +1,100 @@
+package evaluator
+
+// assert y assert_eq registran el resultado de cada aserción en el evaluador,
+// de modo que zylo test pueda contar las que pasaron y fallaron en cada
+// archivo. Una aserción fallida además lanza un error con su mensaje, que se
+// puede capturar con try/catch; aun así queda registrada como fallida.
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// AssertionResult es el resultado de una llamada a assert o assert_eq
+type AssertionResult struct {
+	Passed  bool
+	Message string
+}
+
+// assertionLog acumula los resultados; lo comparten los evaluadores de spawn
+type assertionLog struct {
+	mu      sync.Mutex
+	results []AssertionResult
+}
+
+func (l *assertionLog) record(passed bool, message string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.results = append(l.results, AssertionResult{Passed: passed, Message: message})
+}
+
+// Assertions devuelve los resultados de las aserciones evaluadas, en orden
+func (e *Evaluator) Assertions() []AssertionResult {
+	e.assertions.mu.Lock()
+	defer e.assertions.mu.Unlock()
+	return append([]AssertionResult(nil), e.assertions.results...)
+}
+
+// builtinAssert implementa assert(condición, mensaje?)
+func (e *Evaluator) builtinAssert(args []Value) (Value, error) {
+	if len(args) != 1 && len(args) != 2 {
+		return nil, fmt.Errorf("assert() espera 1 o 2 argumentos")
+	}
+	message, err := e.assertionMessage(args[1:], "assert falló")
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.isTruthy(args[0]) {
+		e.assertions.record(false, message)
+		return nil, fmt.Errorf("%s", message)
+	}
+	e.assertions.record(true, message)
+	return &Null{}, nil
+}
+
+// builtinAssertEq implementa assert_eq(obtenido, esperado, mensaje?) con la
+// misma igualdad estructural que ==
+func (e *Evaluator) builtinAssertEq(args []Value) (Value, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return nil, fmt.Errorf("assert_eq() espera 2 o 3 argumentos")
+	}
+	message, err := e.assertionMessage(args[2:], "assert_eq falló")
+	if err != nil {
+		return nil, err
+	}
+
+	if !valuesEqual(args[0], args[1]) {
+		got, err := e.describeAssertValue(args[0])
+		if err != nil {
+			return nil, err
+		}
+		expected, err := e.describeAssertValue(args[1])
+		if err != nil {
+			return nil, err
+		}
+		message = fmt.Sprintf("%s: esperado %s, obtenido %s", message, expected, got)
+		e.assertions.record(false, message)
+		return nil, fmt.Errorf("%s", message)
+	}
+	e.assertions.record(true, message)
+	return &Null{}, nil
+}
+
+// assertionMessage devuelve el mensaje opcional de una aserción o defaultMsg
+func (e *Evaluator) assertionMessage(args []Value, defaultMsg string) (string, error) {
+	if len(args) == 0 {
+		return defaultMsg, nil
+	}
+	return e.displayString(args[0])
+}
+
+// describeAssertValue muestra un valor en un mensaje de assert_eq; los
+// strings van entre comillas para distinguir "1" de 1
+func (e *Evaluator) describeAssertValue(v Value) (string, error) {
+	if s, ok := v.(*String); ok {
+		return strconv.Quote(s.Value), nil
+	}
+	return e.displayString(v)
+}
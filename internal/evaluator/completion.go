@@ -0,0 +1,123 @@
+package evaluator
+
+// Autocompletado para el REPL: la palabra al final de la línea se completa con
+// los nombres definidos en el entorno de la sesión y, después de "obj.", con
+// los miembros del valor obj según su tipo en tiempo de ejecución. Solo se
+// consultan variables y campos ya evaluados; nunca se ejecuta código.
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Complete devuelve los candidatos para completar la palabra al final de line
+// y la posición de line donde empieza esa palabra; cada candidato reemplaza
+// line[start:]
+func (e *Evaluator) Complete(line string) (start int, candidates []string) {
+	start = len(line)
+	for start > 0 {
+		r := rune(line[start-1])
+		if r != '_' && r != '.' && !unicode.IsLetter(r) && !unicode.IsDigit(r) && r < 0x80 {
+			break
+		}
+		start--
+	}
+	word := line[start:]
+
+	seen := make(map[string]bool)
+	add := func(candidate string) {
+		if strings.HasPrefix(candidate, word) && !seen[candidate] {
+			seen[candidate] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	// Los builtins con punto (show.log, http.get) se guardan con el nombre
+	// completo en el entorno
+	for _, name := range e.Names() {
+		add(name)
+	}
+
+	if dot := strings.LastIndex(word, "."); dot > 0 {
+		if value, ok := e.lookupPath(word[:dot]); ok {
+			for _, member := range members(value) {
+				add(word[:dot+1] + member)
+			}
+		}
+	}
+
+	sort.Strings(candidates)
+	return start, candidates
+}
+
+// Names devuelve ordenados y sin repetir los nombres visibles en el entorno
+// actual: variables, funciones, clases y builtins
+func (e *Evaluator) Names() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range e.env.names() {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// names devuelve los nombres definidos en el entorno y sus padres
+func (env *Environment) names() []string {
+	var names []string
+	for ; env != nil; env = env.parent {
+		for name := range env.variables {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// lookupPath busca el valor de una ruta a.b.c recorriendo variables, campos
+// de instancias y claves de mapas
+func (e *Evaluator) lookupPath(path string) (Value, bool) {
+	parts := strings.Split(path, ".")
+	value, ok := e.env.Get(parts[0])
+	for _, part := range parts[1:] {
+		if !ok {
+			break
+		}
+		switch v := value.(type) {
+		case *ZyloInstance:
+			value, ok = v.Fields[part]
+		case *MapObject:
+			value, ok = v.Pairs[part]
+		default:
+			ok = false
+		}
+	}
+	return value, ok
+}
+
+// members devuelve los nombres accesibles con obj.<nombre> para un valor
+func members(value Value) []string {
+	switch v := value.(type) {
+	case *ZyloInstance:
+		var names []string
+		for name := range v.Fields {
+			names = append(names, name)
+		}
+		for class := v.Class; class != nil; class = class.SuperClass {
+			for name := range class.Methods {
+				names = append(names, name)
+			}
+		}
+		return names
+	case *MapObject:
+		return v.Keys()
+	case *List:
+		return []string{"append", "fill", "length", "splice"}
+	case *StringBuilder:
+		return []string{"append", "build", "length", "reset"}
+	}
+	return nil
+}
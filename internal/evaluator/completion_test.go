@@ -0,0 +1,88 @@
+package evaluator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func TestComplete(t *testing.T) {
+	eval := NewEvaluator()
+	p := parser.New(lexer.New(`
+saludo := "hola"
+salario := 100
+class Perro {
+    func init(nombre) {
+        this.nombre = nombre
+    }
+    func ladrar() {
+        return "guau"
+    }
+}
+rex := Perro("Rex")
+lista := [1, 2]
+`))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	if err := eval.EvaluateProgram(program); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	tests := []struct {
+		line       string
+		start      int
+		candidates string
+	}{
+		{"sal", 0, "salario saludo"},
+		{"x := salu", 5, "saludo"},
+		{"rex.", 0, "rex.ladrar rex.nombre"},
+		{"show.log(rex.n", 9, "rex.nombre"},
+		{"lista.sp", 0, "lista.splice"},
+		{"show.l", 0, "show.log"},
+		{"noexiste.", 0, ""},
+		{"zzz", 0, ""},
+	}
+
+	for _, tt := range tests {
+		start, candidates := eval.Complete(tt.line)
+		if start != tt.start {
+			t.Errorf("%q: inicio %d, se esperaba %d", tt.line, start, tt.start)
+		}
+		if got := strings.Join(candidates, " "); got != tt.candidates {
+			t.Errorf("%q: candidatos %q, se esperaba %q", tt.line, got, tt.candidates)
+		}
+	}
+}
+
+func TestNames(t *testing.T) {
+	eval := NewEvaluator()
+	p := parser.New(lexer.New("zeta := 1\nalfa := 2\nfunc beta() {\n}\n"))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	if err := eval.EvaluateProgram(program); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	names := eval.Names()
+	index := make(map[string]int)
+	for i, name := range names {
+		if _, repeated := index[name]; repeated {
+			t.Fatalf("%s aparece dos veces", name)
+		}
+		index[name] = i
+	}
+	for _, name := range []string{"alfa", "beta", "zeta", "show.log", "len"} {
+		if _, ok := index[name]; !ok {
+			t.Errorf("falta %s en %v", name, names)
+		}
+	}
+	if !(index["alfa"] < index["beta"] && index["beta"] < index["zeta"]) {
+		t.Errorf("los nombres no están ordenados: %v", names)
+	}
+}
@@ -0,0 +1,224 @@
+package evaluator
+
+// Cobertura de código para zylo test --coverage. Los programas cuya cobertura
+// interesa se registran con Coverage.AddProgram, que anota el archivo y la
+// línea de cada sentencia, incluidas las de bloques anidados (funciones,
+// métodos, if, bucles, try). El evaluador marca cada sentencia que ejecuta;
+// una línea está cubierta si se ejecutó alguna de sus sentencias. Las
+// sentencias dentro de funciones anónimas no se registran.
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+)
+
+// Coverage acumula las sentencias ejecutadas de los programas registrados, a
+// lo largo de todos los evaluadores que la comparten
+type Coverage struct {
+	mu       sync.Mutex
+	sites    map[ast.Statement]coverageSite
+	executed map[coverageSite]bool
+}
+
+// coverageSite es una línea de un archivo con al menos una sentencia
+type coverageSite struct {
+	file string
+	line int
+}
+
+// FileCoverage es la cobertura de líneas de un archivo
+type FileCoverage struct {
+	File    string `json:"file"`
+	Lines   int    `json:"lines"`   // Líneas con sentencias
+	Covered int    `json:"covered"` // Líneas con alguna sentencia ejecutada
+	Missed  []int  `json:"missed"`  // Líneas sin ejecutar, en orden
+}
+
+// Percent devuelve el porcentaje de líneas cubiertas; un archivo sin
+// sentencias está cubierto por completo
+func (f FileCoverage) Percent() float64 {
+	if f.Lines == 0 {
+		return 100
+	}
+	return float64(f.Covered) * 100 / float64(f.Lines)
+}
+
+// NewCoverage crea un registro de cobertura vacío
+func NewCoverage() *Coverage {
+	return &Coverage{
+		sites:    make(map[ast.Statement]coverageSite),
+		executed: make(map[coverageSite]bool),
+	}
+}
+
+// SetCoverage activa el registro de cobertura en el evaluador; con nil se
+// desactiva (por defecto)
+func (e *Evaluator) SetCoverage(c *Coverage) {
+	e.coverage = c
+}
+
+// AddProgram registra las sentencias de program como pertenecientes a file
+func (c *Coverage) AddProgram(file string, program *ast.Program) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, stmt := range program.Statements {
+		c.addStatement(file, stmt)
+	}
+}
+
+func (c *Coverage) addStatement(file string, stmt ast.Statement) {
+	if stmt == nil {
+		return
+	}
+	if line := statementLine(stmt); line > 0 {
+		site := coverageSite{file: file, line: line}
+		c.sites[stmt] = site
+		if _, ok := c.executed[site]; !ok {
+			c.executed[site] = false
+		}
+	}
+
+	var blocks []*ast.BlockStatement
+	switch s := stmt.(type) {
+	case *ast.BlockStatement:
+		for _, inner := range s.Statements {
+			c.addStatement(file, inner)
+		}
+	case *ast.ExportStatement:
+		// La declaración exportada comparte línea con el export
+		c.addStatement(file, s.Declaration)
+	case *ast.FuncStatement:
+		blocks = append(blocks, s.Body)
+	case *ast.IfStatement:
+		blocks = append(blocks, s.Consequence)
+		for _, clause := range s.ElseIfs {
+			blocks = append(blocks, clause.Consequence)
+		}
+		blocks = append(blocks, s.Alternative)
+	case *ast.WhileStatement:
+		blocks = append(blocks, s.Body)
+	case *ast.RepeatStatement:
+		blocks = append(blocks, s.Body)
+	case *ast.ForStatement:
+		blocks = append(blocks, s.Body)
+	case *ast.ForInStatement:
+		blocks = append(blocks, s.Body)
+	case *ast.TryStatement:
+		blocks = append(blocks, s.TryBlock, s.FinallyBlock)
+		if s.CatchClause != nil {
+			blocks = append(blocks, s.CatchClause.CatchBlock)
+		}
+	case *ast.SpawnStatement:
+		blocks = append(blocks, s.Body)
+	case *ast.SwitchStatement:
+		for _, c := range s.Cases {
+			blocks = append(blocks, c.Body)
+		}
+	case *ast.MatchStatement:
+		for _, c := range s.Cases {
+			blocks = append(blocks, c.Body)
+		}
+	case *ast.ClassStatement:
+		if s.InitMethod != nil {
+			blocks = append(blocks, s.InitMethod.Body)
+		}
+		for _, method := range s.Methods {
+			blocks = append(blocks, method.Body)
+		}
+	}
+	for _, block := range blocks {
+		if block != nil {
+			c.addStatement(file, block)
+		}
+	}
+}
+
+// statementLine devuelve la línea de una sentencia, o 0 si no se conoce. Los
+// bloques no cuentan como líneas propias.
+func statementLine(stmt ast.Statement) int {
+	switch s := stmt.(type) {
+	case *ast.BlockStatement:
+		return 0
+	case *ast.VarStatement:
+		return s.Token.StartLine
+	case *ast.ExpressionStatement:
+		return s.Token.StartLine
+	case *ast.FuncStatement:
+		return s.Token.StartLine
+	case *ast.ExportStatement:
+		return s.Token.StartLine
+	case *ast.ReturnStatement:
+		return s.Token.StartLine
+	case *ast.IfStatement:
+		return s.Token.StartLine
+	case *ast.WhileStatement:
+		return s.Token.StartLine
+	case *ast.RepeatStatement:
+		return s.Token.StartLine
+	case *ast.ForStatement:
+		return s.Token.StartLine
+	case *ast.ForInStatement:
+		return s.Token.StartLine
+	case *ast.TryStatement:
+		return s.Token.StartLine
+	case *ast.ThrowStatement:
+		return s.Token.StartLine
+	case *ast.ClassStatement:
+		return s.Token.StartLine
+	case *ast.EnumStatement:
+		return s.Token.StartLine
+	case *ast.StructStatement:
+		return s.Token.StartLine
+	case *ast.SwitchStatement:
+		return s.Token.StartLine
+	case *ast.MatchStatement:
+		return s.Token.StartLine
+	case *ast.BreakStatement:
+		return s.Token.StartLine
+	case *ast.ContinueStatement:
+		return s.Token.StartLine
+	case *ast.SpawnStatement:
+		return s.Token.StartLine
+	}
+	return 0
+}
+
+// mark anota que se ejecutó stmt, si pertenece a un programa registrado
+func (c *Coverage) mark(stmt ast.Statement) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if site, ok := c.sites[stmt]; ok {
+		c.executed[site] = true
+	}
+}
+
+// Report devuelve la cobertura de cada archivo registrado, ordenada por nombre
+func (c *Coverage) Report() []FileCoverage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files := make(map[string]*FileCoverage)
+	for site, executed := range c.executed {
+		f, ok := files[site.file]
+		if !ok {
+			f = &FileCoverage{File: site.file, Missed: []int{}}
+			files[site.file] = f
+		}
+		f.Lines++
+		if executed {
+			f.Covered++
+		} else {
+			f.Missed = append(f.Missed, site.line)
+		}
+	}
+
+	report := make([]FileCoverage, 0, len(files))
+	for _, f := range files {
+		sort.Ints(f.Missed)
+		report = append(report, *f)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].File < report[j].File })
+	return report
+}
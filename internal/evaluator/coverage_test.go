@@ -0,0 +1,101 @@
+package evaluator
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func TestCoverage(t *testing.T) {
+	p := parser.New(lexer.New(`func clasificar(n) {
+    if n > 0 {
+        return "positivo"
+    } else {
+        return "no positivo"
+    }
+}
+
+func nunca() {
+    show.log("no se llama")
+}
+
+r := clasificar(3)
+`))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	coverage := NewCoverage()
+	coverage.AddProgram("util.zylo", program)
+	eval := NewEvaluator()
+	eval.SetCoverage(coverage)
+	if err := eval.EvaluateProgram(program); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	report := coverage.Report()
+	if len(report) != 1 {
+		t.Fatalf("se esperaba un archivo, obtenidos %+v", report)
+	}
+	expected := FileCoverage{File: "util.zylo", Lines: 7, Covered: 5, Missed: []int{5, 10}}
+	if !reflect.DeepEqual(report[0], expected) {
+		t.Errorf("esperado %+v, obtenido %+v", expected, report[0])
+	}
+	if percent := report[0].Percent(); percent < 71.4 || percent > 71.5 {
+		t.Errorf("porcentaje esperado 71.4, obtenido %.2f", percent)
+	}
+}
+
+// Un módulo solo expone lo que exporta, así que las declaraciones exportadas
+// y sus cuerpos tienen que contar como las demás
+func TestCoverageExportedDeclarations(t *testing.T) {
+	p := parser.New(lexer.New(`export func clasifica(n) {
+    if n > 0 {
+        return "positivo"
+    }
+    return "no positivo"
+}
+
+export class Contador {
+    func contar() {
+        return 1
+    }
+}
+
+r := clasifica(3)
+`))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	coverage := NewCoverage()
+	coverage.AddProgram("util.zylo", program)
+	eval := NewEvaluator()
+	eval.SetCoverage(coverage)
+	if err := eval.EvaluateProgram(program); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	expected := []FileCoverage{{File: "util.zylo", Lines: 7, Covered: 5, Missed: []int{5, 10}}}
+	if report := coverage.Report(); !reflect.DeepEqual(report, expected) {
+		t.Errorf("esperado %+v, obtenido %+v", expected, report)
+	}
+}
+
+func TestCoverageDisabledByDefault(t *testing.T) {
+	p := parser.New(lexer.New("x := 1\n"))
+	program := p.ParseProgram()
+
+	coverage := NewCoverage()
+	coverage.AddProgram("a.zylo", program)
+	if err := NewEvaluator().EvaluateProgram(program); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if report := coverage.Report(); report[0].Covered != 0 {
+		t.Errorf("sin SetCoverage no se deben marcar sentencias: %+v", report)
+	}
+}
@@ -0,0 +1,61 @@
+package evaluator
+
+// Enums: enum Color { Red, Green, Blue } define Color como un objeto cuyos
+// miembros se leen con Color.Red. Cada miembro es un valor distinto que solo
+// es igual a sí mismo y se muestra por su nombre.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+)
+
+// Enum representa un enum declarado
+type Enum struct {
+	Name    string
+	Members map[string]*EnumMember
+	Order   []string // Nombres de los miembros en orden de declaración
+}
+
+func (en *Enum) Type() string { return "ENUM_OBJ" }
+func (en *Enum) Inspect() string {
+	if len(en.Order) == 0 {
+		return fmt.Sprintf("enum %s {}", en.Name)
+	}
+	return fmt.Sprintf("enum %s { %s }", en.Name, strings.Join(en.Order, ", "))
+}
+
+// EnumMember representa un miembro de un enum
+type EnumMember struct {
+	Enum *Enum
+	Name string
+}
+
+func (m *EnumMember) Type() string    { return "ENUM_MEMBER_OBJ" }
+func (m *EnumMember) Inspect() string { return m.Name }
+
+// evaluateEnumStatement evalúa una declaración de enum. El nombre del enum es
+// constante, igual que el de una variable declarada con const.
+func (e *Evaluator) evaluateEnumStatement(stmt *ast.EnumStatement) (Value, error) {
+	enum := &Enum{
+		Name:    stmt.Name.Value,
+		Members: make(map[string]*EnumMember, len(stmt.Members)),
+	}
+	for _, member := range stmt.Members {
+		enum.Members[member.Value] = &EnumMember{Enum: enum, Name: member.Value}
+		enum.Order = append(enum.Order, member.Value)
+	}
+
+	e.env.Set(enum.Name, enum)
+	e.env.constants[enum.Name] = true
+	return &Null{}, nil
+}
+
+// evaluateEnumMember resuelve Color.name
+func evaluateEnumMember(enum *Enum, name string) (Value, error) {
+	if member, exists := enum.Members[name]; exists {
+		return member, nil
+	}
+	return nil, fmt.Errorf("el enum %s no tiene el miembro %s", enum.Name, name)
+}
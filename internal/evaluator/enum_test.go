@@ -0,0 +1,114 @@
+package evaluator
+
+import "testing"
+
+func TestEnums(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"enum Color { Red, Green, Blue }\nColor.Red == Color.Red", true},
+		{"enum Color { Red, Green, Blue }\nColor.Red == Color.Green", false},
+		{"enum Color { Red, Green, Blue }\nColor.Red != Color.Blue", true},
+		// Los miembros de enums distintos nunca son iguales, aunque se llamen igual
+		{"enum A { X }\nenum B { X }\nA.X == B.X", false},
+		{"enum Color { Red }\nColor.Red == \"Red\"", false},
+		{"enum Color { Red, Green }\nc := Color.Green\nformat(\"{}\", c)", "Green"},
+		{"enum Color { Red, Green }\nformat(\"{}\", Color)", "enum Color { Red, Green }"},
+		{"enum Color { Red, Green }\nColor.Green in [Color.Red, Color.Green]", true},
+		{"enum Color { Red, Green }\nvar c: Color = Color.Red\nc == Color.Red", true},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestSwitchAndMatch(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`
+enum Color { Red, Green, Blue }
+func nombre(c) {
+    switch c {
+    case Color.Red:
+        return "rojo"
+    case Color.Green:
+        return "verde"
+    default:
+        return "otro"
+    }
+}
+nombre(Color.Green) + "," + nombre(Color.Blue)
+`, "verde,otro"},
+		{`
+x := 0
+switch 2 {
+case 1:
+    x = 1
+case 2:
+    x = 2
+case 2:
+    x = 3
+}
+x
+`, 2},
+		{`
+enum Color { Red, Green }
+func nombre(c) {
+    match c {
+    case Color.Red:
+        return "rojo"
+    case otro:
+        return "no rojo: " + format("{}", otro)
+    }
+}
+nombre(Color.Red) + "," + nombre(Color.Green)
+`, "rojo,no rojo: Green"},
+		{`
+func f(v) {
+    match v {
+    case 1:
+        return "uno"
+    case _:
+        return "cualquiera"
+    }
+}
+f(1) + "," + f(5)
+`, "uno,cualquiera"},
+		// break y continue dentro de un case afectan al bucle que lo contiene
+		{`
+total := 0
+for i in [1, 2, 3, 4] {
+    switch i {
+    case 2:
+        continue
+    case 4:
+        break
+    }
+    total = total + i
+}
+total
+`, 4},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestEnumErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"enum Color { Red }\nColor.Purple", "el enum Color no tiene el miembro Purple"},
+		{"enum Color { Red }\nColor = 1", "no se puede reasignar constante: Color"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
@@ -0,0 +1,132 @@
+package evaluator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+)
+
+// RuntimeError es un error de ejecución con la posición del código que lo
+// produjo. Error() devuelve solo el mensaje, que es lo que recibe un catch;
+// la posición la añade quien informa de un error no capturado (FormatError).
+type RuntimeError struct {
+	Message string
+	Line    int
+	Column  int
+	Stack   []StackFrame // Llamadas activas al producirse el error, de la más interna a la más externa
+}
+
+// StackFrame es una llamada activa a una función Zylo, con la posición desde
+// la que se llamó
+type StackFrame struct {
+	Function string
+	Line     int
+	Column   int
+}
+
+// maxStackTrace es el número máximo de llamadas que muestra FormatStackTrace
+const maxStackTrace = 20
+
+func (e *RuntimeError) Error() string { return e.Message }
+
+// ExitError es el error con el que os.exit termina el programa. No lo
+// captura un catch ni se le añade posición: quien ejecuta el programa sale
+// con Code.
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string { return fmt.Sprintf("exit %d", e.Code) }
+
+// isExit indica si err es la salida de os.exit
+func isExit(err error) bool {
+	var exitErr *ExitError
+	return errors.As(err, &exitErr)
+}
+
+// newRuntimeError crea un error situado en la posición de token
+func newRuntimeError(token lexer.Token, format string, args ...interface{}) *RuntimeError {
+	return &RuntimeError{
+		Message: fmt.Sprintf(format, args...),
+		Line:    token.StartLine,
+		Column:  token.StartCol,
+	}
+}
+
+// withPosition sitúa err en la posición de token, salvo que ya tenga una
+// posición más precisa
+func withPosition(err error, token lexer.Token) error {
+	if err == nil || isExit(err) {
+		return err
+	}
+	var runtimeErr *RuntimeError
+	if errors.As(err, &runtimeErr) {
+		if runtimeErr.Line == 0 {
+			runtimeErr.Line, runtimeErr.Column = token.StartLine, token.StartCol
+		}
+		return err
+	}
+	return &RuntimeError{Message: err.Error(), Line: token.StartLine, Column: token.StartCol}
+}
+
+// withStack guarda en err la pila de llamadas stack, de la más externa a la
+// más interna, salvo que ya tenga una: la primera función que atraviesa el
+// error es la más interna y su pila es la completa
+func withStack(err error, stack []StackFrame) error {
+	if err == nil || len(stack) == 0 || isExit(err) {
+		return err
+	}
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		runtimeErr = &RuntimeError{Message: err.Error()}
+		err = runtimeErr
+	}
+	if runtimeErr.Stack == nil {
+		runtimeErr.Stack = make([]StackFrame, len(stack))
+		for i, frame := range stack {
+			runtimeErr.Stack[len(stack)-1-i] = frame
+		}
+	}
+	return err
+}
+
+// FormatError da formato a un error no capturado del programa filename:
+// "archivo:línea:columna: mensaje" si se conoce su posición, o solo el mensaje
+func FormatError(filename string, err error) string {
+	var runtimeErr *RuntimeError
+	if errors.As(err, &runtimeErr) && runtimeErr.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d: %s", filename, runtimeErr.Line, runtimeErr.Column, runtimeErr.Message)
+	}
+	return err.Error()
+}
+
+// FormatStackTrace da formato a la pila de llamadas de un error no capturado
+// del programa filename, una llamada por línea empezando por la más interna,
+// o devuelve "" si el error no ocurrió dentro de una función
+func FormatStackTrace(filename string, err error) string {
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) || len(runtimeErr.Stack) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Traza de llamadas (la más reciente primero):")
+	for i, frame := range runtimeErr.Stack {
+		if i == maxStackTrace {
+			fmt.Fprintf(&b, "\n  ... %d llamadas más", len(runtimeErr.Stack)-maxStackTrace)
+			break
+		}
+		fmt.Fprintf(&b, "\n  en %s, llamada desde %s:%d:%d", frame.Function, filename, frame.Line, frame.Column)
+	}
+	return b.String()
+}
+
+// argumentCount describe n argumentos para los mensajes de error: "1
+// argumento", "2 argumentos"
+func argumentCount(n int) string {
+	if n == 1 {
+		return "1 argumento"
+	}
+	return fmt.Sprintf("%d argumentos", n)
+}
@@ -2,16 +2,24 @@ package evaluator
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"math"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"sort"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
 )
 
 // ZyloObject representa un objeto en tiempo de ejecución de Zylo
@@ -29,6 +37,248 @@ func pow(a, b float64) float64 {
     return math.Pow(a, b)
 }
 
+// memoize envuelve una función en un builtin que cachea sus resultados según
+// los argumentos. Solo tiene sentido para funciones puras: los efectos de la
+// función se producen una única vez por combinación de argumentos.
+func (e *Evaluator) memoize(fn Value) (Value, error) {
+	var name string
+	switch f := fn.(type) {
+	case *ZyloFunction:
+		name = f.Name
+	case *BuiltinFunction:
+		name = f.Name
+	case *BoundMethod:
+		name = f.Method.Name
+	default:
+		return nil, fmt.Errorf("memoize() espera una función, obtenido %T", fn)
+	}
+
+	var mu sync.Mutex
+	cache := make(map[string]Value)
+	return &BuiltinFunction{
+		Name: name,
+		Fn: func(args []Value) (Value, error) {
+			var key strings.Builder
+			for _, arg := range args {
+				writeMemoKey(&key, arg)
+				key.WriteByte(',')
+			}
+
+			mu.Lock()
+			result, ok := cache[key.String()]
+			mu.Unlock()
+			if ok {
+				return result, nil
+			}
+
+			result, err := e.callFunction(fn, args)
+			if err != nil {
+				return nil, err
+			}
+			mu.Lock()
+			cache[key.String()] = result
+			mu.Unlock()
+			return result, nil
+		},
+	}, nil
+}
+
+// writeMemoKey escribe una representación de v que distingue tipos ("1" de 1)
+// y no depende del orden de iteración de los mapas. Las funciones, instancias
+// y demás valores con identidad se distinguen por dirección. Las listas y mapas
+// que se contienen a sí mismos no están soportados.
+func writeMemoKey(key *strings.Builder, v Value) {
+	switch val := v.(type) {
+	case *String:
+		key.WriteString(strconv.Quote(val.Value))
+	case *Integer:
+		key.WriteString(strconv.FormatInt(val.Value, 10))
+	case *Float:
+		key.WriteString(strconv.FormatFloat(val.Value, 'g', -1, 64))
+		key.WriteByte('f')
+	case *Boolean:
+		key.WriteString(strconv.FormatBool(val.Value))
+	case *Null, nil:
+		key.WriteString("null")
+	case *List:
+		key.WriteByte('[')
+		for _, item := range val.Items {
+			writeMemoKey(key, item)
+			key.WriteByte(',')
+		}
+		key.WriteByte(']')
+	case *MapObject:
+		keys := val.Keys()
+		sort.Strings(keys)
+		key.WriteByte('{')
+		for _, k := range keys {
+			key.WriteString(strconv.Quote(k))
+			key.WriteByte(':')
+			writeMemoKey(key, val.Pairs[k])
+			key.WriteByte(',')
+		}
+		key.WriteByte('}')
+	case *Set:
+		// Las claves de los elementos ya son representaciones; ordenadas, no
+		// dependen del orden de inserción
+		keys := append([]string(nil), val.Order...)
+		sort.Strings(keys)
+		key.WriteString("set{")
+		for _, k := range keys {
+			key.WriteString(k)
+			key.WriteByte(',')
+		}
+		key.WriteByte('}')
+	default:
+		fmt.Fprintf(key, "%T@%p", v, v)
+	}
+}
+
+// valuesEqual compara dos valores estructuralmente: las listas son iguales si
+// tienen la misma longitud y elementos iguales, y los mapas si tienen las mismas
+// claves con valores iguales. Las estructuras que se contienen a sí mismas se
+// consideran iguales al volver a un par ya visitado, así que la comparación
+// siempre termina.
+func valuesEqual(a, b Value) bool {
+	return valuesEqualSeen(a, b, make(map[[2]Value]bool))
+}
+
+func valuesEqualSeen(a, b Value, seen map[[2]Value]bool) bool {
+	switch l := a.(type) {
+	case *String:
+		if r, ok := b.(*String); ok {
+			return l.Value == r.Value
+		}
+	case *Integer:
+		switch r := b.(type) {
+		case *Integer:
+			return l.Value == r.Value
+		case *Float:
+			return float64(l.Value) == r.Value
+		}
+	case *Float:
+		switch r := b.(type) {
+		case *Integer:
+			return l.Value == float64(r.Value)
+		case *Float:
+			return l.Value == r.Value
+		}
+	case *Boolean:
+		if r, ok := b.(*Boolean); ok {
+			return l.Value == r.Value
+		}
+	case *Null:
+		_, ok := b.(*Null)
+		return ok
+	case *EnumMember:
+		return a == b
+	case *StructValue:
+		r, ok := b.(*StructValue)
+		if !ok || l.Def != r.Def {
+			return false
+		}
+		key := [2]Value{l, r}
+		if l == r || seen[key] {
+			return true
+		}
+		seen[key] = true
+		for name, lv := range l.Fields {
+			if !valuesEqualSeen(lv, r.Fields[name], seen) {
+				return false
+			}
+		}
+		return true
+	case *List:
+		r, ok := b.(*List)
+		if !ok {
+			return false
+		}
+		if l == r {
+			return true
+		}
+		key := [2]Value{l, r}
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+		if len(l.Items) != len(r.Items) {
+			return false
+		}
+		for i := range l.Items {
+			if !valuesEqualSeen(l.Items[i], r.Items[i], seen) {
+				return false
+			}
+		}
+		return true
+	case *Set:
+		r, ok := b.(*Set)
+		if !ok {
+			return false
+		}
+		if len(l.Items) != len(r.Items) {
+			return false
+		}
+		for key := range l.Items {
+			if _, exists := r.Items[key]; !exists {
+				return false
+			}
+		}
+		return true
+	case *MapObject:
+		r, ok := b.(*MapObject)
+		if !ok {
+			return false
+		}
+		if l == r {
+			return true
+		}
+		key := [2]Value{l, r}
+		if seen[key] {
+			return true
+		}
+		seen[key] = true
+		if len(l.Pairs) != len(r.Pairs) {
+			return false
+		}
+		for k, lv := range l.Pairs {
+			rv, exists := r.Pairs[k]
+			if !exists || !valuesEqualSeen(lv, rv, seen) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// floatOperands convierte dos operandos numéricos a float64. Falla si alguno
+// no es numérico o si ambos son enteros, que tienen su propio tratamiento.
+func floatOperands(left, right Value) (float64, float64, bool) {
+	var l, r float64
+	_, leftInt := left.(*Integer)
+	_, rightInt := right.(*Integer)
+	if leftInt && rightInt {
+		return 0, 0, false
+	}
+	switch v := left.(type) {
+	case *Integer:
+		l = float64(v.Value)
+	case *Float:
+		l = v.Value
+	default:
+		return 0, 0, false
+	}
+	switch v := right.(type) {
+	case *Integer:
+		r = float64(v.Value)
+	case *Float:
+		r = v.Value
+	default:
+		return 0, 0, false
+	}
+	return l, r, true
+}
+
 func (s *String) Type() string    { return "STRING_OBJ" }
 func (s *String) Inspect() string { return s.Value }
 
@@ -69,14 +319,47 @@ func (l *List) Inspect() string {
 // MapObject representa un objeto map
 type MapObject struct {
 	Pairs map[string]Value
+	Order []string // Orden de inserción de las claves añadidas con Set
 }
 
 func (m *MapObject) Type() string { return "Map" }
+
+// Set asigna key, que si es nueva queda detrás de las existentes
+func (m *MapObject) Set(key string, value Value) {
+	if _, exists := m.Pairs[key]; !exists {
+		m.Order = append(m.Order, key)
+	}
+	m.Pairs[key] = value
+}
+
+// Keys devuelve las claves del map en orden de inserción, para que mostrar o
+// recorrer un map dé siempre el mismo resultado. Las claves que no se
+// añadieron con Set (maps construidos directamente) van al final, ordenadas.
+func (m *MapObject) Keys() []string {
+	keys := make([]string, 0, len(m.Pairs))
+	listed := make(map[string]bool, len(m.Order))
+	for _, k := range m.Order {
+		if _, ok := m.Pairs[k]; ok && !listed[k] {
+			listed[k] = true
+			keys = append(keys, k)
+		}
+	}
+	inserted := len(keys)
+	for k := range m.Pairs {
+		if !listed[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys[inserted:])
+	return keys
+}
+
 func (m *MapObject) Inspect() string {
 	var out strings.Builder
 	out.WriteString("{")
 	first := true
-	for k, v := range m.Pairs {
+	for _, k := range m.Keys() {
+		v := m.Pairs[k]
 		if !first {
 			out.WriteString(", ")
 		}
@@ -109,16 +392,75 @@ func (n *Null) Inspect() string { return "null" }
 // Value representa un valor en tiempo de ejecución de Zylo
 type Value interface{}
 
-// Future representa un resultado de una operación asíncrona
+// Future representa un resultado de una operación asíncrona. El resultado se
+// guarda en value la primera vez que se resuelve, así que se puede esperar
+// más de una vez.
 type Future struct {
-	Result chan ZyloObject
-	value  ZyloObject
+	Result chan Value
+	value  Value
 	once   bool
+	mu     sync.Mutex
 }
 
 func (f *Future) Type() string { return "FUTURE_OBJ" }
 func (f *Future) Inspect() string { return "future" }
 
+// resolve guarda v como resultado si el Future aún no tenía uno y devuelve el
+// resultado guardado
+func (f *Future) resolve(v Value) Value {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.once {
+		f.value = v
+		f.once = true
+	}
+	return f.value
+}
+
+// Error representa un error entregado como valor, por ejemplo el resultado de
+// una llamada asíncrona que falló
+type Error struct {
+	Message string
+}
+
+func (er *Error) Type() string    { return "ERROR_OBJ" }
+func (er *Error) Inspect() string { return "ERROR: " + er.Message }
+
+// newFuture ejecuta fn en una goroutine y devuelve un Future con su resultado.
+// La goroutine nunca entra en pánico ni se bloquea: siempre entrega al canal
+// (con buffer) un valor o un *Error, aunque nadie llegue a esperarlo. Después
+// de entregarlo cierra el canal, para que las esperas siguientes no se
+// bloqueen y lean el resultado guardado.
+func newFuture(fn func() (Value, error)) *Future {
+	future := &Future{
+		Result: make(chan Value, 1),
+		value:  nil,
+		once:   false,
+	}
+	go func() {
+		var result Value
+		defer func() {
+			if r := recover(); r != nil {
+				result = &Error{Message: fmt.Sprintf("pánico en llamada asíncrona: %v", r)}
+			}
+			future.resolve(result)
+			future.Result <- result
+			close(future.Result)
+		}()
+
+		value, err := fn()
+		if err != nil {
+			result = &Error{Message: err.Error()}
+			return
+		}
+		if value == nil {
+			value = &Null{}
+		}
+		result = value
+	}()
+	return future
+}
+
 // Environment representa el entorno de ejecución con variables
 type Environment struct {
 	variables map[string]Value
@@ -220,6 +562,22 @@ type Evaluator struct {
 	evaluateDepth  int
 	httpHandler    *ZyloFunction
 	httpServer     *http.Server
+	httpMux        *http.ServeMux
+	httpDone       chan struct{}
+	httpActive     int32
+	assertions     *assertionLog
+	modules        *moduleRegistry
+	baseDir        string // Directorio desde el que se resuelven los imports
+	loadingModule  bool   // Evaluador de un módulo que se está cargando
+	localImports   bool   // import nombre carga el módulo local ./nombre.zylo (zylo test)
+	coverage       *Coverage // Cobertura de zylo test --coverage; nil si no se mide
+	callStack      []StackFrame // Llamadas a funciones Zylo activas, la más interna al final
+	callSite       lexer.Token  // Posición de la llamada que se está evaluando
+	args           []string     // Argumentos del script, sin su nombre (os.args)
+	trace          io.Writer    // Destino de la traza de llamadas; nil si está desactivada
+	strict         bool         // Modo estricto: sin conversiones implícitas a tipos declarados
+	profile        *Profile     // Perfil de zylo run --profile; nil si no se mide
+	profileStack   []profileFrame // Llamadas activas que se están perfilando
 }
 
 // EvaluateProgram evalúa un programa completo
@@ -242,6 +600,10 @@ func NewEvaluator() *Evaluator {
 		evaluateDepth:  0,
 		httpHandler:    nil,
 		httpServer:     nil,
+		assertions:     &assertionLog{},
+		modules:        newModuleRegistry(),
+		baseDir:        ".",
+		trace:          traceFromEnv(),
 	}
 	eval.InitBuiltins()
 	return eval
@@ -254,42 +616,8 @@ func (e *Evaluator) InitBuiltins() {
 	e.env.Set("true", &Boolean{Value: true})
 	e.env.Set("false", &Boolean{Value: false})
 
-	// show.log
-	e.env.Set("show.log", &BuiltinFunction{
-		Name: "show.log",
-		Fn: func(args []Value) (Value, error) {
-			for i, arg := range args {
-				if i > 0 {
-					fmt.Print(" ")
-				}
-				if obj, ok := arg.(ZyloObject); ok {
-					fmt.Print(obj.Inspect())
-				} else {
-					fmt.Print(arg)
-				}
-			}
-			fmt.Println()
-			os.Stdout.Sync()
-			return &Null{}, nil
-		},
-	})
-
-	// show.error
-	e.env.Set("show.error", &BuiltinFunction{
-		Name: "show.error",
-		Fn: func(args []Value) (Value, error) {
-			if len(args) != 1 {
-				return nil, fmt.Errorf("show.error expects 1 argument")
-			}
-			if str, ok := args[0].(*String); ok {
-				// Error message is already formatted, print it directly
-				fmt.Println(str.Value)
-				os.Stdout.Sync()
-				return &Null{}, nil
-			}
-			return nil, fmt.Errorf("show.error expects a string argument")
-		},
-	})
+	// show.log, show.error, show.warn y show.debug
+	e.registerShowBuiltins()
 
 	// read.line
 	e.env.Set("read.line", &BuiltinFunction{
@@ -306,16 +634,21 @@ func (e *Evaluator) InitBuiltins() {
 	e.env.Set("read.int", &BuiltinFunction{
 		Name: "read.int",
 		Fn: func(args []Value) (Value, error) {
-			for {
-				fmt.Print("> ")
-				os.Stdout.Sync()
-				input, _ := e.reader.ReadString('\n')
-				input = strings.TrimSpace(input)
-				if n, err := strconv.Atoi(input); err == nil {
-					return &Integer{Value: int64(n)}, nil
-				}
-				fmt.Println("Error: no es un número válido")
-			}
+			return e.readNumber("read.int", func(input string) (Value, bool) {
+				n, err := strconv.ParseInt(input, 10, 64)
+				return &Integer{Value: n}, err == nil
+			})
+		},
+	})
+
+	// read.float
+	e.env.Set("read.float", &BuiltinFunction{
+		Name: "read.float",
+		Fn: func(args []Value) (Value, error) {
+			return e.readNumber("read.float", func(input string) (Value, bool) {
+				f, err := strconv.ParseFloat(input, 64)
+				return &Float{Value: f}, err == nil
+			})
 		},
 	})
 
@@ -336,10 +669,11 @@ func (e *Evaluator) InitBuiltins() {
 			case *Boolean:
 				return &String{Value: fmt.Sprintf("%t", arg.Value)}, nil
 			default:
-				if obj, ok := arg.(ZyloObject); ok {
-					return &String{Value: obj.Inspect()}, nil
+				text, err := e.displayString(arg)
+				if err != nil {
+					return nil, err
 				}
-				return &String{Value: fmt.Sprintf("%v", arg)}, nil
+				return &String{Value: text}, nil
 			}
 		},
 	})
@@ -372,42 +706,6 @@ func (e *Evaluator) InitBuiltins() {
 		},
 	})
 
-	// map_keys() - Retorna una lista con las claves del mapa
-	e.env.Set("map_keys", &BuiltinFunction{
-		Name: "map_keys",
-		Fn: func(args []Value) (Value, error) {
-			if len(args) != 1 {
-				return nil, fmt.Errorf("map_keys() espera 1 argumento")
-			}
-			if m, ok := args[0].(*MapObject); ok {
-				keys := make([]Value, 0, len(m.Pairs))
-				for k := range m.Pairs {
-					keys = append(keys, &String{Value: k})
-				}
-				return &List{Items: keys}, nil
-			}
-			return nil, fmt.Errorf("map_keys() espera un mapa")
-		},
-	})
-
-	// map_values() - Retorna una lista con los valores del mapa
-	e.env.Set("map_values", &BuiltinFunction{
-		Name: "map_values",
-		Fn: func(args []Value) (Value, error) {
-			if len(args) != 1 {
-				return nil, fmt.Errorf("map_values() espera 1 argumento")
-			}
-			if m, ok := args[0].(*MapObject); ok {
-				values := make([]Value, 0, len(m.Pairs))
-				for _, v := range m.Pairs {
-					values = append(values, v)
-				}
-				return &List{Items: values}, nil
-			}
-			return nil, fmt.Errorf("map_values() espera un mapa")
-		},
-	})
-
 	// int() - Convierte a entero
 	e.env.Set("int", &BuiltinFunction{
 		Name: "int",
@@ -470,24 +768,6 @@ func (e *Evaluator) InitBuiltins() {
 		},
 	})
 
-	// len()
-	e.env.Set("len", &BuiltinFunction{
-		Name: "len",
-		Fn: func(args []Value) (Value, error) {
-			if len(args) != 1 {
-				return nil, fmt.Errorf("len() espera 1 argumento")
-			}
-			switch arg := args[0].(type) {
-			case *List:
-				return &Integer{Value: int64(len(arg.Items))}, nil
-			case *String:
-				return &Integer{Value: int64(len(arg.Value))}, nil
-			default:
-				return nil, fmt.Errorf("len() no soportado para %T", arg)
-			}
-		},
-	})
-
 	// ReadLine - Alias de read.line
 	e.env.Set("ReadLine", &BuiltinFunction{
 		Name: "ReadLine",
@@ -605,99 +885,6 @@ func (e *Evaluator) InitBuiltins() {
 		},
 	})
 
-	// Add - Suma dos valores
-	e.env.Set("Add", &BuiltinFunction{
-		Name: "Add",
-		Fn: func(args []Value) (Value, error) {
-			if len(args) != 2 {
-				return nil, fmt.Errorf("Add() espera 2 argumentos")
-			}
-			left, right := args[0], args[1]
-
-			if l, ok := left.(*Integer); ok {
-				if r, ok := right.(*Integer); ok {
-					return &Integer{Value: l.Value + r.Value}, nil
-				}
-				if r, ok := right.(*Float); ok {
-					return &Float{Value: float64(l.Value) + r.Value}, nil
-				}
-			}
-
-			if l, ok := left.(*Float); ok {
-				if r, ok := right.(*Float); ok {
-					return &Float{Value: l.Value + r.Value}, nil
-				}
-				if r, ok := right.(*Integer); ok {
-					return &Float{Value: l.Value + float64(r.Value)}, nil
-				}
-			}
-
-			return nil, fmt.Errorf("Add: tipos incompatibles")
-		},
-	})
-
-	// Subtract - Resta dos valores
-	e.env.Set("Subtract", &BuiltinFunction{
-		Name: "Subtract",
-		Fn: func(args []Value) (Value, error) {
-			if len(args) != 2 {
-				return nil, fmt.Errorf("Subtract() espera 2 argumentos")
-			}
-			left, right := args[0], args[1]
-
-			if l, ok := left.(*Integer); ok {
-				if r, ok := right.(*Integer); ok {
-					return &Integer{Value: l.Value - r.Value}, nil
-				}
-				if r, ok := right.(*Float); ok {
-					return &Float{Value: float64(l.Value) - r.Value}, nil
-				}
-			}
-
-			if l, ok := left.(*Float); ok {
-				if r, ok := right.(*Float); ok {
-					return &Float{Value: l.Value - r.Value}, nil
-				}
-				if r, ok := right.(*Integer); ok {
-					return &Float{Value: l.Value - float64(r.Value)}, nil
-				}
-			}
-
-			return nil, fmt.Errorf("Subtract: tipos incompatibles")
-		},
-	})
-
-	// Multiply - Multiplica dos valores
-	e.env.Set("Multiply", &BuiltinFunction{
-		Name: "Multiply",
-		Fn: func(args []Value) (Value, error) {
-			if len(args) != 2 {
-				return nil, fmt.Errorf("Multiply() espera 2 argumentos")
-			}
-			left, right := args[0], args[1]
-
-			if l, ok := left.(*Integer); ok {
-				if r, ok := right.(*Integer); ok {
-					return &Integer{Value: l.Value * r.Value}, nil
-				}
-				if r, ok := right.(*Float); ok {
-					return &Float{Value: float64(l.Value) * r.Value}, nil
-				}
-			}
-
-			if l, ok := left.(*Float); ok {
-				if r, ok := right.(*Float); ok {
-					return &Float{Value: l.Value * r.Value}, nil
-				}
-				if r, ok := right.(*Integer); ok {
-					return &Float{Value: l.Value * float64(r.Value)}, nil
-				}
-			}
-
-			return nil, fmt.Errorf("Multiply: tipos incompatibles")
-		},
-	})
-
 	// Divide - Divide dos valores
 	e.env.Set("Divide", &BuiltinFunction{
 		Name: "Divide",
@@ -789,20 +976,53 @@ func (e *Evaluator) InitBuiltins() {
 	e.env.Set("http.listen", &BuiltinFunction{
 		Name: "http.listen",
 		Fn: func(args []Value) (Value, error) {
-			if len(args) != 2 {
-				return nil, fmt.Errorf("http.listen expects 2 arguments, got %d", len(args))
+			if len(args) != 1 && len(args) != 2 {
+				return nil, fmt.Errorf("http.listen expects 1 or 2 arguments, got %d", len(args))
 			}
 			port, ok := args[0].(*Integer)
 			if !ok {
 				return nil, fmt.Errorf("http.listen expects an integer port")
 			}
-			handler, ok := args[1].(*ZyloFunction)
-			if !ok {
-				return nil, fmt.Errorf("http.listen expects a function handler")
+			var handler *ZyloFunction
+			if len(args) == 2 {
+				handler, ok = args[1].(*ZyloFunction)
+				if !ok {
+					return nil, fmt.Errorf("http.listen expects a function handler")
+				}
 			}
 			return e.httpListen(port.Value, handler)
 		},
 	})
+	e.env.Set("http.route", &BuiltinFunction{
+		Name: "http.route",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 3 {
+				return nil, fmt.Errorf("http.route expects 3 arguments, got %d", len(args))
+			}
+			method, ok := args[0].(*String)
+			if !ok {
+				return nil, fmt.Errorf("http.route expects a string method")
+			}
+			path, ok := args[1].(*String)
+			if !ok {
+				return nil, fmt.Errorf("http.route expects a string path")
+			}
+			handler, ok := args[2].(*ZyloFunction)
+			if !ok {
+				return nil, fmt.Errorf("http.route expects a function handler")
+			}
+			return e.httpRoute(method.Value, path.Value, handler)
+		},
+	})
+	e.env.Set("http.stop", &BuiltinFunction{
+		Name: "http.stop",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("http.stop expects 0 arguments, got %d", len(args))
+			}
+			return e.httpStop()
+		},
+	})
 	e.env.Set("http.get_async", &BuiltinFunction{
 		Name: "http.get_async",
 		Fn: func(args []Value) (Value, error) {
@@ -864,6 +1084,12 @@ func (e *Evaluator) InitBuiltins() {
 	if listenFn, exists := e.env.Get("http.listen"); exists {
 		httpObj.Pairs["listen"] = listenFn
 	}
+	if routeFn, exists := e.env.Get("http.route"); exists {
+		httpObj.Pairs["route"] = routeFn
+	}
+	if stopFn, exists := e.env.Get("http.stop"); exists {
+		httpObj.Pairs["stop"] = stopFn
+	}
 	if getAsyncFn, exists := e.env.Get("http.get_async"); exists {
 		httpObj.Pairs["get_async"] = getAsyncFn
 	}
@@ -871,6 +1097,195 @@ func (e *Evaluator) InitBuiltins() {
 		httpObj.Pairs["post_json_async"] = postAsyncFn
 	}
 	e.env.Set("http", httpObj)
+
+	// string_builder() - Crea un StringBuilder vacío
+	e.env.Set("string_builder", &BuiltinFunction{
+		Name: "string_builder",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("string_builder() no espera argumentos")
+			}
+			return &StringBuilder{}, nil
+		},
+	})
+
+	// assert(cond, mensaje?) y assert_eq(a, b, mensaje?) - Aserciones para tests
+	e.env.Set("assert", &BuiltinFunction{Name: "assert", Fn: e.builtinAssert})
+	e.env.Set("assert_eq", &BuiltinFunction{Name: "assert_eq", Fn: e.builtinAssertEq})
+
+	// fields(instancia) y methods(instancia) - Campos y métodos de una instancia
+	e.env.Set("fields", &BuiltinFunction{Name: "fields", Fn: builtinFields})
+	e.env.Set("methods", &BuiltinFunction{Name: "methods", Fn: builtinMethods})
+
+	// memoize(fn) - Devuelve una versión de fn que cachea sus resultados
+	e.env.Set("memoize", &BuiltinFunction{
+		Name: "memoize",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("memoize() espera 1 argumento")
+			}
+			return e.memoize(args[0])
+		},
+	})
+
+	// compose(f, g) - Devuelve una función que calcula f(g(x))
+	e.env.Set("compose", &BuiltinFunction{
+		Name: "compose",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("compose() espera 2 argumentos")
+			}
+			for _, fn := range args {
+				if !isCallable(fn) {
+					return nil, fmt.Errorf("compose() espera funciones, obtenido %T", fn)
+				}
+			}
+			f, g := args[0], args[1]
+			return &BuiltinFunction{
+				Name: "compose",
+				Fn: func(callArgs []Value) (Value, error) {
+					inner, err := e.callFunction(g, callArgs)
+					if err != nil {
+						return nil, err
+					}
+					return e.callFunction(f, []Value{inner})
+				},
+			}, nil
+		},
+	})
+
+	// partial(f, args...) - Devuelve f con los primeros argumentos ya fijados
+	e.env.Set("partial", &BuiltinFunction{
+		Name: "partial",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) < 1 {
+				return nil, fmt.Errorf("partial() espera al menos 1 argumento")
+			}
+			f := args[0]
+			if !isCallable(f) {
+				return nil, fmt.Errorf("partial() espera una función, obtenido %T", f)
+			}
+			bound := append([]Value(nil), args[1:]...)
+			return &BuiltinFunction{
+				Name: "partial",
+				Fn: func(callArgs []Value) (Value, error) {
+					all := make([]Value, 0, len(bound)+len(callArgs))
+					all = append(all, bound...)
+					all = append(all, callArgs...)
+					return e.callFunction(f, all)
+				},
+			}, nil
+		},
+	})
+
+	// await_timeout(future, ms) - Espera un Future como máximo ms milisegundos
+	e.env.Set("await_timeout", &BuiltinFunction{
+		Name: "await_timeout",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("await_timeout() espera 2 argumentos")
+			}
+			future, ok := args[0].(*Future)
+			if !ok {
+				return nil, fmt.Errorf("await_timeout() espera un future, obtenido %T", args[0])
+			}
+			ms, ok := args[1].(*Integer)
+			if !ok || ms.Value <= 0 {
+				return nil, fmt.Errorf("await_timeout() espera un tiempo positivo en milisegundos")
+			}
+			return e.awaitFuture(future, time.Duration(ms.Value)*time.Millisecond)
+		},
+	})
+
+	// format(plantilla, ...args) - Sustituye {} y {n} por los argumentos
+	e.env.Set("format", &BuiltinFunction{
+		Name: "format",
+		Fn:   e.formatTemplate,
+	})
+
+	// await_all(futures) - Espera una lista de Futures y devuelve sus resultados
+	e.env.Set("await_all", &BuiltinFunction{
+		Name: "await_all",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("await_all() espera 1 argumento")
+			}
+			list, ok := args[0].(*List)
+			if !ok {
+				return nil, fmt.Errorf("await_all() espera una lista de futures, obtenido %T", args[0])
+			}
+			return e.awaitAll(list)
+		},
+	})
+
+	// json.parse
+	e.env.Set("json.parse", &BuiltinFunction{
+		Name: "json.parse",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("json.parse expects 1 argument, got %d", len(args))
+			}
+			str, ok := args[0].(*String)
+			if !ok {
+				return nil, fmt.Errorf("json.parse expects a string")
+			}
+			return e.jsonParse(str.Value)
+		},
+	})
+	// json.stringify
+	e.env.Set("json.stringify", &BuiltinFunction{
+		Name: "json.stringify",
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 && len(args) != 2 {
+				return nil, fmt.Errorf("json.stringify expects 1 or 2 arguments, got %d", len(args))
+			}
+			indent := 0
+			if len(args) == 2 {
+				n, ok := args[1].(*Integer)
+				if !ok {
+					return nil, fmt.Errorf("json.stringify expects an integer indent")
+				}
+				indent = int(n.Value)
+			}
+			return e.jsonStringify(args[0], indent)
+		},
+	})
+
+	jsonObj := &MapObject{Pairs: make(map[string]Value)}
+	if parseFn, exists := e.env.Get("json.parse"); exists {
+		jsonObj.Pairs["parse"] = parseFn
+	}
+	if stringifyFn, exists := e.env.Get("json.stringify"); exists {
+		jsonObj.Pairs["stringify"] = stringifyFn
+	}
+	e.env.Set("json", jsonObj)
+
+	// fs.read(path), fs.write(path, s), fs.append(path, s), fs.exists(path), fs.listdir(path)
+	e.env.Set("fs", newFSModule())
+
+	// os.getenv(name), os.setenv(name, value), os.args(), os.exit(code)
+	e.env.Set("os", e.newOSModule())
+
+	// time.now(), time.sleep(ms), time.format(ts, layout), time.parse(s, layout)
+	e.env.Set("time", newTimeModule())
+
+	// random.int(min, max), random.float(), random.choice(l), random.shuffle(l), random.seed(n)
+	e.env.Set("random", newRandomModule())
+
+	// math - sqrt, pow, abs, floor, ceil, round, min, max, sin, cos, pi, e...
+	e.env.Set("math", newMathModule())
+
+	// abs, round, floor, ceil, sqrt y pow sin el prefijo math.
+	e.registerMathBuiltins()
+
+	// Biblioteca estándar compartida con el código compilado
+	e.registerRuntimeBuiltins()
+
+	// set(lista) y len() de un set
+	e.registerSetBuiltins()
+
+	// sum, avg, min y max de una lista de números
+	e.registerAggregateBuiltins()
 }
 
 
@@ -879,6 +1294,9 @@ func (e *Evaluator) evaluateStatement(stmt ast.Statement) (Value, error) {
 	if stmt == nil {
 		return nil, fmt.Errorf("nil statement")
 	}
+	if e.coverage != nil {
+		e.coverage.mark(stmt)
+	}
 
 	switch s := stmt.(type) {
 	case *ast.VarStatement:
@@ -904,22 +1322,38 @@ func (e *Evaluator) evaluateStatement(stmt ast.Statement) (Value, error) {
 		return e.evaluateIfStatement(s)
 	case *ast.WhileStatement:
 		return e.evaluateWhileStatement(s)
+	case *ast.RepeatStatement:
+		return e.evaluateRepeatStatement(s)
 	case *ast.ForInStatement:
 		return e.evaluateForInStatement(s)
+	case *ast.ForStatement:
+		return e.evaluateForStatement(s)
 	case *ast.BreakStatement:
 		return &BreakValue{}, nil
 	case *ast.ContinueStatement:
 		return &ContinueValue{}, nil
 	case *ast.ClassStatement:
 		return e.evaluateClassStatement(s)
+	case *ast.EnumStatement:
+		return e.evaluateEnumStatement(s)
+	case *ast.StructStatement:
+		return e.evaluateStructStatement(s)
+	case *ast.SwitchStatement:
+		return e.evaluateSwitchStatement(s)
+	case *ast.MatchStatement:
+		return e.evaluateMatchStatement(s)
 	case *ast.TryStatement:
 		return e.evaluateTryStatement(s)
 	case *ast.ThrowStatement:
 		return e.evaluateThrowStatement(s)
 	case *ast.ImportStatement:
 		return e.evaluateImportStatement(s)
+	case *ast.ExportStatement:
+		return e.evaluateExportStatement(s)
 	case *ast.BlockStatement:
 		return e.evaluateBlockStatement(s)
+	case *ast.SpawnStatement:
+		return e.evaluateSpawnStatement(s)
 	default:
 		return nil, fmt.Errorf("sentencia no soportada: %T", s)
 	}
@@ -939,18 +1373,9 @@ func (e *Evaluator) evaluateVarStatement(stmt *ast.VarStatement) (Value, error)
 
 // Para variables tipadas, aseguramos compatibilidad de runtime
 	expectedType := unifyType(stmt.Name.TypeAnnotation)
-	actualType := getNormalizedType(value)
-
-	if expectedType != "" && expectedType != "any" && actualType != expectedType {
-		// Intentar conversion automática si es compatible
-		converted, err := e.convertToTypeAuto(value, expectedType)
-		if err == nil {
-			value = converted
-			// Actualizar el tipo después de conversión
-			actualType = getNormalizedType(converted)
-		} else {
-			return nil, fmt.Errorf("tipo incompatible: esperado %s, recibido %s", expectedType, actualType)
-		}
+	value, err = e.coerceToType(value, expectedType)
+	if err != nil {
+		return nil, withPosition(err, stmt.Name.Token)
 	}
 
 	e.env.Set(stmt.Name.Value, value)
@@ -969,62 +1394,138 @@ func (e *Evaluator) evaluateFuncStatement(stmt *ast.FuncStatement) (Value, error
 		Body:       stmt.Body,
 		Env:        e.env,
 		IsAsync:    stmt.IsAsync,
+		Variadic:   isVariadic(stmt.Parameters),
+	}
+	decorated, err := e.applyDecorators(stmt.Decorators, zyloFunc)
+	if err != nil {
+		return nil, err
 	}
-	e.env.Set(stmt.Name.Value, zyloFunc)
+	e.env.Set(stmt.Name.Value, decorated)
 	return &Null{}, nil
 }
 
-// evaluateIfStatement evalúa una sentencia if
+// applyDecorators pasa value por cada decorador y devuelve el resultado. Las
+// expresiones de los decoradores se evalúan de arriba abajo, pero se aplican
+// desde el más cercano a la declaración: @a @b func f equivale a a(b(f)).
+func (e *Evaluator) applyDecorators(decorators []ast.Expression, value Value) (Value, error) {
+	fns := make([]Value, len(decorators))
+	for i, decorator := range decorators {
+		fn, err := e.evaluateExpression(decorator)
+		if err != nil {
+			return nil, err
+		}
+		if !isCallable(fn) {
+			return nil, fmt.Errorf("el decorador @%s no es una función", decorator.String())
+		}
+		fns[i] = fn
+	}
+
+	for i := len(fns) - 1; i >= 0; i-- {
+		result, err := e.callFunction(fns[i], []Value{value})
+		if err != nil {
+			return nil, err
+		}
+		value = result
+	}
+	return value, nil
+}
+
+// evaluateIfStatement evalúa una sentencia if. Las condiciones del if y de
+// cada elif se prueban en orden en el entorno actual; solo la rama elegida
+// abre un ámbito nuevo.
 func (e *Evaluator) evaluateIfStatement(stmt *ast.IfStatement) (Value, error) {
 	condition, err := e.evaluateExpression(stmt.Condition)
 	if err != nil {
 		return nil, err
 	}
-
 	if e.isTruthy(condition) {
 		return e.evaluateBlockStatement(stmt.Consequence)
-	} else if stmt.Alternative != nil {
-		return e.evaluateBlockStatement(stmt.Alternative)
 	}
 
+	for _, clause := range stmt.ElseIfs {
+		condition, err := e.evaluateExpression(clause.Condition)
+		if err != nil {
+			return nil, err
+		}
+		if e.isTruthy(condition) {
+			return e.evaluateBlockStatement(clause.Consequence)
+		}
+	}
+
+	if stmt.Alternative != nil {
+		return e.evaluateBlockStatement(stmt.Alternative)
+	}
 	return &Null{}, nil
 }
 
-// evaluateTryStatement evalúa una sentencia try-catch
+// evaluateTryStatement evalúa try/catch/finally. El bloque finally se ejecuta
+// siempre: tras completar el try, tras el catch (aunque este vuelva a lanzar
+// un error) y antes de que un return del try o del catch tenga efecto. Un
+// error en finally se propaga, y un return, break o continue en finally
+// sustituye al resultado pendiente. os.exit termina sin ejecutar finally.
 func (e *Evaluator) evaluateTryStatement(stmt *ast.TryStatement) (Value, error) {
 	if stmt.TryBlock == nil {
 		return nil, fmt.Errorf("nil try block")
 	}
 
 	result, err := e.evaluateBlockStatement(stmt.TryBlock)
+	if isExit(err) {
+		return nil, err
+	}
 
 	if err != nil && stmt.CatchClause != nil {
-		childEnv := e.env.NewChildEnvironment()
-		oldEnv := e.env
-		e.env = childEnv
-
-		if stmt.CatchClause.Parameter != nil {
-			e.env.Set(stmt.CatchClause.Parameter.Value, &String{Value: err.Error()})
+		result, err = e.evaluateCatchClause(stmt.CatchClause, err)
+		if isExit(err) {
+			return nil, err
 		}
-
-		result, _ = e.evaluateBlockStatement(stmt.CatchClause.CatchBlock)
-		e.env = oldEnv
 	}
 
 	if stmt.FinallyBlock != nil {
-		_, _ = e.evaluateBlockStatement(stmt.FinallyBlock)
+		finallyResult, finallyErr := e.evaluateBlockStatement(stmt.FinallyBlock)
+		if finallyErr != nil {
+			return nil, finallyErr
+		}
+		switch finallyResult.(type) {
+		case *ReturnValue, *BreakValue, *ContinueValue:
+			return finallyResult, nil
+		}
 	}
 
-	return result, nil
+	return result, err
+}
+
+// evaluateCatchClause ejecuta el bloque catch con el mensaje de err en su
+// parámetro
+func (e *Evaluator) evaluateCatchClause(clause *ast.CatchClause, err error) (Value, error) {
+	oldEnv := e.env
+	e.env = e.env.NewChildEnvironment()
+	defer func() { e.env = oldEnv }()
+
+	if clause.Parameter != nil {
+		e.env.Set(clause.Parameter.Value, &String{Value: err.Error()})
+	}
+	return e.evaluateBlockStatement(clause.CatchBlock)
+}
+
+// evaluateSpawnStatement ejecuta el cuerpo de un spawn en una goroutine y
+// devuelve el Future con su resultado
+func (e *Evaluator) evaluateSpawnStatement(stmt *ast.SpawnStatement) (Value, error) {
+	if stmt.Body == nil {
+		return nil, fmt.Errorf("spawn sin cuerpo")
+	}
+	spawned := e.fork()
+	return newFuture(func() (Value, error) {
+		return spawned.evaluateBlockStatement(stmt.Body)
+	}), nil
 }
 
 // evaluateThrowStatement evalúa una sentencia throw
 func (e *Evaluator) evaluateThrowStatement(stmt *ast.ThrowStatement) (Value, error) {
 	value, _ := e.evaluateExpression(stmt.Exception)
 	if str, ok := value.(*String); ok {
-		return nil, fmt.Errorf("%s", str.Value)
+		return nil, newRuntimeError(stmt.Token, "%s", str.Value)
 	}
-	return nil, fmt.Errorf("thrown exception")
+	return nil, newRuntimeError(stmt.Token, "thrown exception")
 }
 
 // evaluateBlockStatement evalúa un bloque de sentencias
@@ -1072,24 +1573,114 @@ func (e *Evaluator) evaluateWhileStatement(stmt *ast.WhileStatement) (Value, err
 			break
 		}
 
-		for _, bodyStmt := range stmt.Body.Statements {
-			value, err := e.evaluateStatement(bodyStmt)
+		result, stop, err := e.evaluateLoopBody(stmt.Body)
+		if err != nil || stop {
+			return result, err
+		}
+	}
+
+	return &Null{}, nil
+}
+
+// evaluateRepeatStatement evalúa repeat n { ... }. n se evalúa una sola vez y
+// tiene que ser un entero no negativo; cada vuelta tiene su propio scope.
+func (e *Evaluator) evaluateRepeatStatement(stmt *ast.RepeatStatement) (Value, error) {
+	count, err := e.evaluateExpression(stmt.Count)
+	if err != nil {
+		return nil, err
+	}
+	n, ok := count.(*Integer)
+	if !ok {
+		return nil, newRuntimeError(stmt.Token, "repeat espera un entero, recibido %s", getNormalizedType(count))
+	}
+	if n.Value < 0 {
+		return nil, newRuntimeError(stmt.Token, "repeat espera un entero no negativo, recibido %d", n.Value)
+	}
+
+	for i := int64(0); i < n.Value; i++ {
+		result, stop, err := e.evaluateLoopBody(stmt.Body)
+		if err != nil || stop {
+			return result, err
+		}
+	}
+	return &Null{}, nil
+}
+
+// evaluateForStatement evalúa un for tradicional. La inicialización vive en
+// un scope propio que envuelve al bucle, así que su variable no sale de él.
+func (e *Evaluator) evaluateForStatement(stmt *ast.ForStatement) (Value, error) {
+	oldEnv := e.env
+	e.env = e.env.NewChildEnvironment()
+	defer func() { e.env = oldEnv }()
+
+	if stmt.Init != nil {
+		if _, err := e.evaluateStatement(stmt.Init); err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		if stmt.Condition != nil {
+			condition, err := e.evaluateExpression(stmt.Condition)
 			if err != nil {
 				return nil, err
 			}
-
-			if _, ok := value.(*BreakValue); ok {
-				return &Null{}, nil
-			}
-			if _, ok := value.(*ContinueValue); ok {
+			if !e.isTruthy(condition) {
 				break
 			}
 		}
+
+		result, stop, err := e.evaluateLoopBody(stmt.Body)
+		if err != nil || stop {
+			return result, err
+		}
+
+		if stmt.Post != nil {
+			if _, err := e.evaluateStatement(stmt.Post); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	return &Null{}, nil
 }
 
+// evaluateLoopBody evalúa una iteración del cuerpo de un bucle con
+// evaluateBlockStatement, que le da un scope nuevo. stop indica que el bucle
+// termina: por un break, en cuyo caso el bucle vale null, o por un return,
+// cuyo ReturnValue se devuelve para que llegue hasta la función.
+func (e *Evaluator) evaluateLoopBody(body *ast.BlockStatement) (Value, bool, error) {
+	result, err := e.evaluateBlockStatement(body)
+	if err != nil {
+		return nil, true, err
+	}
+	switch result.(type) {
+	case *BreakValue:
+		return &Null{}, true, nil
+	case *ReturnValue:
+		return result, true, nil
+	}
+	return nil, false, nil
+}
+
+// evaluateIfExpression evalúa un if usado como expresión: solo se evalúa la
+// rama elegida y su valor es el último de esa rama. Sin else, un if cuya
+// condición es falsa vale null.
+func (e *Evaluator) evaluateIfExpression(exp *ast.IfExpression) (Value, error) {
+	condition, err := e.evaluateExpression(exp.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.isTruthy(condition) {
+		return e.evaluateBlockStatement(exp.Consequence)
+	}
+	if exp.Alternative != nil {
+		return e.evaluateBlockStatement(exp.Alternative)
+	}
+	return &Null{}, nil
+}
+
 // evaluateForInStatement evalúa una sentencia for in
 func (e *Evaluator) evaluateForInStatement(stmt *ast.ForInStatement) (Value, error) {
 	iterable, err := e.evaluateExpression(stmt.Iterable)
@@ -1097,54 +1688,204 @@ func (e *Evaluator) evaluateForInStatement(stmt *ast.ForInStatement) (Value, err
 		return nil, err
 	}
 
+	var items []Value
 	switch iter := iterable.(type) {
 	case *List:
-		for _, element := range iter.Items {
-			e.env.Set(stmt.Identifier.Value, element)
+		items = iter.Items
+	case *String:
+		for _, char := range iter.Value {
+			items = append(items, &String{Value: string(char)})
+		}
+	case *Set:
+		items = iter.Values()
+	default:
+		return nil, fmt.Errorf("cannot iterate over %T", iterable)
+	}
 
-			result, err := e.evaluateBlockStatement(stmt.Body)
-			if err != nil {
-				return nil, err
-			}
+	// Cada iteración tiene su propia variable, en un scope que envuelve al
+	// del cuerpo, para que no quede definida después del bucle
+	oldEnv := e.env
+	defer func() { e.env = oldEnv }()
+	for _, item := range items {
+		e.env = oldEnv.NewChildEnvironment()
+		e.env.Set(stmt.Identifier.Value, item)
 
-			if _, ok := result.(*BreakValue); ok {
-				break
-			}
-			if _, ok := result.(*ContinueValue); ok {
-				continue
-			}
+		result, stop, err := e.evaluateLoopBody(stmt.Body)
+		if err != nil || stop {
+			return result, err
 		}
-	case *String:
-		for _, char := range iter.Value {
-			e.env.Set(stmt.Identifier.Value, &String{Value: string(char)})
+	}
+
+	return &Null{}, nil
+}
+
+// evaluateSwitchStatement evalúa un switch: se ejecuta el primer case cuyo
+// valor es igual (==) al del switch, o el default si ninguno coincide. No hay
+// fallthrough.
+func (e *Evaluator) evaluateSwitchStatement(stmt *ast.SwitchStatement) (Value, error) {
+	value, err := e.evaluateExpression(stmt.Expression)
+	if err != nil {
+		return nil, err
+	}
 
-			result, err := e.evaluateBlockStatement(stmt.Body)
+	var defaultCase *ast.CaseClause
+	for _, c := range stmt.Cases {
+		if c.Expression == nil {
+			defaultCase = c
+			continue
+		}
+		caseValue, err := e.evaluateExpression(c.Expression)
+		if err != nil {
+			return nil, err
+		}
+		if valuesEqual(value, caseValue) {
+			return e.evaluateCaseBody(c.Body)
+		}
+	}
+	if defaultCase != nil {
+		return e.evaluateCaseBody(defaultCase.Body)
+	}
+	return &Null{}, nil
+}
+
+// evaluateMatchStatement evalúa un match: se ejecuta el primer case cuyo
+// patrón coincide. Un patrón literal (1, "a", Color.Red) compara con ==; un
+// nombre coincide siempre y enlaza el valor en el cuerpo del case, salvo _, que
+// no enlaza nada.
+func (e *Evaluator) evaluateMatchStatement(stmt *ast.MatchStatement) (Value, error) {
+	value, err := e.evaluateExpression(stmt.Expression)
+	if err != nil {
+		return nil, err
+	}
+
+	oldEnv := e.env
+	defer func() { e.env = oldEnv }()
+
+	var defaultCase *ast.PatternCase
+	for _, c := range stmt.Cases {
+		if c.Pattern == nil {
+			defaultCase = c
+			continue
+		}
+		e.env = oldEnv.NewChildEnvironment()
+		matched, err := e.matchPattern(c.Pattern, value)
+		if err != nil {
+			return nil, err
+		}
+		if matched && c.Guard != nil {
+			guard, err := e.evaluateExpression(c.Guard)
 			if err != nil {
 				return nil, err
 			}
+			matched = e.isTruthy(guard)
+		}
+		if matched {
+			return e.evaluateCaseBody(c.Body)
+		}
+	}
+	e.env = oldEnv
+	if defaultCase != nil {
+		return e.evaluateCaseBody(defaultCase.Body)
+	}
+	return &Null{}, nil
+}
 
-			if _, ok := result.(*BreakValue); ok {
-				break
-			}
-			if _, ok := result.(*ContinueValue); ok {
-				continue
-			}
+// matchPattern indica si value coincide con pattern y enlaza en el entorno
+// actual la variable del patrón, si la hay
+func (e *Evaluator) matchPattern(pattern ast.Pattern, value Value) (bool, error) {
+	switch p := pattern.(type) {
+	case *ast.VariablePattern:
+		if p.Name != nil && p.Name.Value != "_" {
+			e.env.Set(p.Name.Value, value)
 		}
-	default:
-		return nil, fmt.Errorf("cannot iterate over %T", iterable)
+		return true, nil
+	case *ast.LiteralPattern:
+		expected, err := e.evaluateExpression(p.Value)
+		if err != nil {
+			return false, err
+		}
+		return valuesEqual(value, expected), nil
+	case *ast.TypePattern:
+		if !strings.EqualFold(getNormalizedType(value), p.TypeName) {
+			return false, nil
+		}
+		if p.Variable != nil {
+			e.env.Set(p.Variable.Value, value)
+		}
+		return true, nil
 	}
+	return false, fmt.Errorf("patrón no soportado: %T", pattern)
+}
 
+// evaluateCaseBody evalúa el cuerpo de un case de switch o match. break,
+// continue y return se propagan al bucle o la función que lo contiene.
+func (e *Evaluator) evaluateCaseBody(body *ast.BlockStatement) (Value, error) {
+	result, err := e.evaluateBlockStatement(body)
+	if err != nil {
+		return nil, err
+	}
+	switch result.(type) {
+	case *BreakValue, *ContinueValue, *ReturnValue:
+		return result, nil
+	}
 	return &Null{}, nil
 }
 
 // evaluateImportStatement evalúa una declaración de import
 func (e *Evaluator) evaluateImportStatement(stmt *ast.ImportStatement) (Value, error) {
-	if stmt.ModuleName == nil {
+	if stmt.ModuleName != nil {
+		// import string enlaza el módulo nativo; en zylo test, import helpers
+		// carga ./helpers.zylo como lo haría import "./helpers"
+		name := stmt.ModuleName.Value
+		if module, ok := nativeModule(name); ok {
+			e.bindModule(name, module)
+		} else if e.localImports {
+			if _, _, ok := localModule(e.baseDir, name); ok {
+				module, err := e.importModule("./" + name)
+				if err != nil {
+					return nil, withPosition(err, stmt.Token)
+				}
+				e.bindModule(name, module)
+			}
+		}
+		return &Null{}, nil
+	}
+	if stmt.ModulePath == "" {
 		return nil, fmt.Errorf("import sin nombre de módulo")
 	}
+
+	module, err := e.importModule(stmt.ModulePath)
+	if err != nil {
+		return nil, withPosition(err, stmt.Token)
+	}
+	e.bindModule(moduleBindingName(stmt.ModulePath), module)
 	return &Null{}, nil
 }
 
+// bindModule enlaza module con name. Si name ya es un builtin, como la
+// conversión string(), las funciones del módulo se enlazan como name.función,
+// igual que show.log, para que import "std/string" no oculte string(5).
+func (e *Evaluator) bindModule(name string, module *MapObject) {
+	if existing, ok := e.env.Get(name); ok {
+		if _, isBuiltin := existing.(*BuiltinFunction); isBuiltin {
+			for key, value := range module.Pairs {
+				e.env.Set(name+"."+key, value)
+			}
+			return
+		}
+	}
+	e.env.Set(name, module)
+}
+
+// evaluateExportStatement evalúa la declaración exportada. Qué nombres ve
+// quien importa el módulo lo decide Program.Exports al cargarlo.
+func (e *Evaluator) evaluateExportStatement(stmt *ast.ExportStatement) (Value, error) {
+	if stmt.Declaration == nil {
+		return nil, withPosition(fmt.Errorf("export sin declaración"), stmt.Token)
+	}
+	return e.evaluateStatement(stmt.Declaration)
+}
+
 // evaluateClassStatement evalúa una declaración de clase
 func (e *Evaluator) evaluateClassStatement(stmt *ast.ClassStatement) (Value, error) {
 	classObj := &ZyloClass{
@@ -1173,6 +1914,7 @@ func (e *Evaluator) evaluateClassStatement(stmt *ast.ClassStatement) (Value, err
 			Body:       method.Body,
 			Env:        e.env,
 			IsAsync:    method.IsAsync,
+			Variadic:   isVariadic(method.Parameters),
 		}
 		classObj.Methods[method.Name.Value] = zyloFunc
 	}
@@ -1183,6 +1925,7 @@ func (e *Evaluator) evaluateClassStatement(stmt *ast.ClassStatement) (Value, err
 			Parameters: stmt.InitMethod.Parameters,
 			Body:       stmt.InitMethod.Body,
 			Env:        e.env,
+			Variadic:   isVariadic(stmt.InitMethod.Parameters),
 		}
 		classObj.InitMethod = zyloFunc
 	}
@@ -1196,7 +1939,11 @@ func (e *Evaluator) evaluateClassStatement(stmt *ast.ClassStatement) (Value, err
 		}
 	}
 
-	e.env.Set(stmt.Name.Value, classObj)
+	decorated, err := e.applyDecorators(stmt.Decorators, classObj)
+	if err != nil {
+		return nil, err
+	}
+	e.env.Set(stmt.Name.Value, decorated)
 	return &Null{}, nil
 }
 
@@ -1240,6 +1987,8 @@ func (e *Evaluator) evaluateExpression(exp ast.Expression) (Value, error) {
 		return &Null{}, nil
 	case *ast.CallExpression:
 		return e.evaluateCallExpression(ex)
+	case *ast.CollectionMethodCall:
+		return e.evaluateCollectionMethodCall(ex)
 	case *ast.DotExpression:
 		return e.evaluateDotExpression(ex)
 	case *ast.MemberExpression:
@@ -1254,16 +2003,30 @@ func (e *Evaluator) evaluateExpression(exp ast.Expression) (Value, error) {
 			}
 		}
 		return &List{Items: elements}, nil
+	case *ast.StructLiteral:
+		return e.evaluateStructLiteral(ex)
+	case *ast.SetLiteral:
+		elements := make([]Value, len(ex.Elements))
+		for i, el := range ex.Elements {
+			var err error
+			elements[i], err = e.evaluateExpression(el)
+			if err != nil {
+				return nil, err
+			}
+		}
+		return newSet(elements), nil
 	case *ast.MapLiteral:
-	    pairs := make(map[string]Value)
-	    for k, v := range ex.Pairs {
-	        value, err := e.evaluateExpression(v)
-	        if err != nil {
-	            return nil, err
-	        }
-	        pairs[k] = value
-	    }
-	    return &MapObject{Pairs: pairs}, nil
+		m := &MapObject{Pairs: make(map[string]Value, len(ex.Pairs))}
+		for _, pair := range ex.Pairs {
+			value, err := e.evaluateExpression(pair.Value)
+			if err != nil {
+				return nil, err
+			}
+			m.Set(pair.Key, value)
+		}
+		return m, nil
+	case *ast.OptionalDotExpression:
+		return e.evaluateOptionalDotExpression(ex)
 	case *ast.IndexExpression:
 		left, err := e.evaluateExpression(ex.Left)
 		if err != nil {
@@ -1273,7 +2036,11 @@ func (e *Evaluator) evaluateExpression(exp ast.Expression) (Value, error) {
 		if err != nil {
 			return nil, err
 		}
-		return e.indexValue(left, index)
+		value, err := e.indexValue(left, index)
+		if err != nil {
+			return nil, withPosition(err, ex.Token)
+		}
+		return value, nil
 	case *ast.RangeExpression:
 		return e.evaluateRangeExpression(ex)
 	case *ast.InfixExpression:
@@ -1282,6 +2049,8 @@ func (e *Evaluator) evaluateExpression(exp ast.Expression) (Value, error) {
 		return e.evaluatePrefixExpression(ex)
 	case *ast.AssignmentExpression:
 		return e.evaluateAssignmentExpression(ex)
+	case *ast.DestructuringAssignmentExpression:
+		return e.evaluateDestructuringAssignment(ex)
 	case *ast.ThisExpression:
 		return e.evaluateThisExpression(ex)
 	case *ast.SuperExpression:
@@ -1290,6 +2059,10 @@ func (e *Evaluator) evaluateExpression(exp ast.Expression) (Value, error) {
 		return e.evaluateAwaitExpression(ex)
 	case *ast.AsExpression:
 		return e.evaluateAsExpression(ex)
+	case *ast.IsExpression:
+		return e.evaluateIsExpression(ex)
+	case *ast.IfExpression:
+		return e.evaluateIfExpression(ex)
 	case *ast.BlockExpression:
 		// Un BlockExpression en contexto de expresión evalúa el bloque y retorna su último valor
 		if ex.Block != nil {
@@ -1302,6 +2075,7 @@ func (e *Evaluator) evaluateExpression(exp ast.Expression) (Value, error) {
 			Parameters: ex.Parameters,
 			Body:       ex.Body,
 			Env:        e.env,
+			Variadic:   isVariadic(ex.Parameters),
 		}
 		return zyloFunc, nil
 	default:
@@ -1309,6 +2083,22 @@ func (e *Evaluator) evaluateExpression(exp ast.Expression) (Value, error) {
 	}
 }
 
+// evaluateCollectionMethodCall evalúa llamadas a método como lista.append(x) o
+// http.get(url). Se resuelve como una llamada sobre la expresión de punto.
+func (e *Evaluator) evaluateCollectionMethodCall(exp *ast.CollectionMethodCall) (Value, error) {
+	if exp.Object == nil || exp.Method == nil {
+		return nil, fmt.Errorf("llamada a método inválida")
+	}
+
+	call := &ast.CallExpression{
+		Token:          exp.Token,
+		Function:       &ast.DotExpression{Token: exp.Token, Left: exp.Object, Property: exp.Method},
+		Arguments:      exp.Arguments,
+		NamedArguments: exp.NamedArguments,
+	}
+	return e.evaluateCallExpression(call)
+}
+
 // evaluateDotExpression evalúa expresiones de punto como show.log
 func (e *Evaluator) evaluateDotExpression(exp *ast.DotExpression) (Value, error) {
 	if exp.Left == nil {
@@ -1332,9 +2122,52 @@ func (e *Evaluator) evaluateDotExpression(exp *ast.DotExpression) (Value, error)
 	if err != nil {
 		return nil, err
 	}
+	return e.propertyValue(obj, exp.Property)
+}
+
+// evaluateOptionalDotExpression evalúa obj?.prop y obj?.[clave]. Si obj es
+// null el resultado es null en vez de un error, y en un mapa una clave que no
+// existe también da null.
+func (e *Evaluator) evaluateOptionalDotExpression(exp *ast.OptionalDotExpression) (Value, error) {
+	obj, err := e.evaluateExpression(exp.Left)
+	if err != nil {
+		return nil, err
+	}
+	if _, isNull := obj.(*Null); isNull {
+		return &Null{}, nil
+	}
+
+	if exp.Index != nil {
+		index, err := e.evaluateExpression(exp.Index)
+		if err != nil {
+			return nil, err
+		}
+		value, err := e.indexValue(obj, index)
+		if err != nil {
+			return nil, withPosition(err, exp.Token)
+		}
+		return value, nil
+	}
 
+	if mapObj, ok := obj.(*MapObject); ok {
+		if _, exists := mapObj.Pairs[exp.Property.Value]; !exists {
+			if _, isMethod := evaluateMapMember(mapObj, exp.Property.Value); !isMethod {
+				return &Null{}, nil
+			}
+		}
+	}
+	value, err := e.propertyValue(obj, exp.Property)
+	if err != nil {
+		return nil, withPosition(err, exp.Token)
+	}
+	return value, nil
+}
+
+// propertyValue devuelve la propiedad property de obj: un campo o método de
+// una lista, mapa, instancia o super
+func (e *Evaluator) propertyValue(obj Value, property *ast.Identifier) (Value, error) {
 	if list, ok := obj.(*List); ok {
-		switch exp.Property.Value {
+		switch property.Value {
 		case "length":
 			return &Integer{Value: int64(len(list.Items))}, nil
 		case "append":
@@ -1348,44 +2181,161 @@ func (e *Evaluator) evaluateDotExpression(exp *ast.DotExpression) (Value, error)
 					return &Null{}, nil
 				},
 			}, nil
+		case "splice":
+			return &BuiltinFunction{
+				Name: "List.splice",
+				Fn: func(args []Value) (Value, error) {
+					return listSplice(list, args)
+				},
+			}, nil
+		case "fill":
+			return &BuiltinFunction{
+				Name: "List.fill",
+				Fn: func(args []Value) (Value, error) {
+					return listFill(list, args)
+				},
+			}, nil
+		case "pop":
+			return &BuiltinFunction{
+				Name: "List.pop",
+				Fn: func(args []Value) (Value, error) {
+					return listPop(list, args)
+				},
+			}, nil
+		case "insert":
+			return &BuiltinFunction{
+				Name: "List.insert",
+				Fn: func(args []Value) (Value, error) {
+					return listInsert(list, args)
+				},
+			}, nil
+		case "remove":
+			return &BuiltinFunction{
+				Name: "List.remove",
+				Fn: func(args []Value) (Value, error) {
+					if len(args) != 1 {
+						return nil, fmt.Errorf("remove() espera 1 argumento")
+					}
+					i := listIndexOf(list, args[0])
+					if i < 0 {
+						return &Boolean{Value: false}, nil
+					}
+					list.Items = listWithout(list.Items, i)
+					return &Boolean{Value: true}, nil
+				},
+			}, nil
+		case "indexOf":
+			return &BuiltinFunction{
+				Name: "List.indexOf",
+				Fn: func(args []Value) (Value, error) {
+					if len(args) != 1 {
+						return nil, fmt.Errorf("indexOf() espera 1 argumento")
+					}
+					return &Integer{Value: int64(listIndexOf(list, args[0]))}, nil
+				},
+			}, nil
+		case "contains":
+			return &BuiltinFunction{
+				Name: "List.contains",
+				Fn: func(args []Value) (Value, error) {
+					if len(args) != 1 {
+						return nil, fmt.Errorf("contains() espera 1 argumento")
+					}
+					return &Boolean{Value: listIndexOf(list, args[0]) >= 0}, nil
+				},
+			}, nil
+		}
+	}
+
+	if set, ok := obj.(*Set); ok {
+		if member, ok := evaluateSetMember(set, property.Value); ok {
+			return member, nil
+		}
+	}
+
+	if enum, ok := obj.(*Enum); ok {
+		return evaluateEnumMember(enum, property.Value)
+	}
+
+	if structValue, ok := obj.(*StructValue); ok {
+		return structField(structValue, property.Value)
+	}
+
+	if sb, ok := obj.(*StringBuilder); ok {
+		switch property.Value {
+		case "append":
+			return &BuiltinFunction{
+				Name: "StringBuilder.append",
+				Fn: func(args []Value) (Value, error) {
+					for _, arg := range args {
+						if str, ok := arg.(*String); ok {
+							sb.builder.WriteString(str.Value)
+						} else if obj, ok := arg.(ZyloObject); ok {
+							sb.builder.WriteString(obj.Inspect())
+						} else {
+							sb.builder.WriteString(fmt.Sprintf("%v", arg))
+						}
+					}
+					return sb, nil
+				},
+			}, nil
+		case "build":
+			return &BuiltinFunction{
+				Name: "StringBuilder.build",
+				Fn: func(args []Value) (Value, error) {
+					if len(args) != 0 {
+						return nil, fmt.Errorf("build() no espera argumentos")
+					}
+					return &String{Value: sb.builder.String()}, nil
+				},
+			}, nil
+		case "length":
+			return &Integer{Value: int64(sb.builder.Len())}, nil
+		case "reset":
+			return &BuiltinFunction{
+				Name: "StringBuilder.reset",
+				Fn: func(args []Value) (Value, error) {
+					sb.builder.Reset()
+					return sb, nil
+				},
+			}, nil
+		}
+	}
+
+	if mapObj, ok := obj.(*MapObject); ok {
+		if value, exists := mapObj.Pairs[property.Value]; exists {
+			return value, nil
+		}
+		if method, ok := evaluateMapMember(mapObj, property.Value); ok {
+			return method, nil
 		}
 	}
 
 	if instance, ok := obj.(*ZyloInstance); ok {
-		if field, exists := instance.Fields[exp.Property.Value]; exists {
+		if field, exists := instance.Fields[property.Value]; exists {
 			return field, nil
 		}
 		// Check methods in class and superclasses
-		currentClass := instance.Class
-		for currentClass != nil {
-			if method, exists := currentClass.Methods[exp.Property.Value]; exists {
-				return &BoundMethod{
-					Instance: instance,
-					Method:   method,
-				}, nil
-			}
-			currentClass = currentClass.SuperClass
+		if method, class := findMethod(instance.Class, property.Value); method != nil {
+			return &BoundMethod{
+				Instance: instance,
+				Method:   method,
+				Class:    class,
+			}, nil
 		}
 	}
 
 	if superObj, ok := obj.(*SuperObject); ok {
-		if exp.Property.Value == "init" {
-			if superObj.Instance.Class.SuperClass.InitMethod != nil {
-				return &BoundMethod{
-					Instance: superObj.Instance,
-					Method:   superObj.Instance.Class.SuperClass.InitMethod,
-				}, nil
-			}
-		}
-		if method, exists := superObj.Instance.Class.SuperClass.Methods[exp.Property.Value]; exists {
+		if method, class := findMethod(superObj.Class, property.Value); method != nil {
 			return &BoundMethod{
 				Instance: superObj.Instance,
 				Method:   method,
+				Class:    class,
 			}, nil
 		}
 	}
 
-	return nil, fmt.Errorf("property '%s' not found", exp.Property.Value)
+	return nil, fmt.Errorf("property '%s' not found", property.Value)
 }
 
 // evaluateIdentifier evalúa un identificador
@@ -1397,17 +2347,12 @@ func (e *Evaluator) evaluateIdentifier(exp *ast.Identifier) (Value, error) {
 
 	// Manejar 'super'
 	if exp.Value == "super" {
-		if this, exists := e.env.Get("this"); exists {
-			if instance, ok := this.(*ZyloInstance); ok && instance.Class.SuperClass != nil {
-				return &SuperObject{Instance: instance}, nil
-			}
-		}
-		return nil, fmt.Errorf("'super' no disponible en este contexto")
+		return e.lookupSuper()
 	}
 
 	value, exists := e.env.Get(exp.Value)
 	if !exists {
-		return nil, fmt.Errorf("variable no definida: %s", exp.Value)
+		return nil, newRuntimeError(exp.Token, "variable no definida: %s", exp.Value)
 	}
 	return value, nil
 }
@@ -1432,8 +2377,8 @@ func (e *Evaluator) evaluateMemberExpression(exp *ast.MemberExpression) (Value,
 					if !ok {
 						return nil, fmt.Errorf("índice debe ser integer")
 					}
-					if idx.Value < 0 || int(idx.Value) >= len(list.Items) {
-						return nil, fmt.Errorf("índice fuera de rango")
+					if err := checkIndex("array", idx.Value, len(list.Items)); err != nil {
+						return nil, err
 					}
 					return list.Items[idx.Value], nil
 				},
@@ -1450,16 +2395,23 @@ func (e *Evaluator) evaluateCallExpression(exp *ast.CallExpression) (Value, erro
 	if e.callDepth > MaxCallDepth {
 		return nil, fmt.Errorf("stack overflow: recursion too deep")
 	}
-	e.callDepth++
-	defer func() { e.callDepth-- }()
 
 	fn, err := e.evaluateExpression(exp.Function)
 	if err != nil {
 		return nil, err
 	}
 
+	// obj?.metodo() con obj null no llama a nada y da null
+	if _, optional := exp.Function.(*ast.OptionalDotExpression); optional {
+		if _, isNull := fn.(*Null); isNull {
+			return &Null{}, nil
+		}
+	}
+
 	if class, ok := fn.(*ZyloClass); ok {
-		return e.instantiateClass(class, exp.Arguments)
+		e.callDepth++
+		defer func() { e.callDepth-- }()
+		return e.instantiateClass(class, exp.Arguments, exp.NamedArguments)
 	}
 
 	args := make([]Value, len(exp.Arguments))
@@ -1469,8 +2421,38 @@ func (e *Evaluator) evaluateCallExpression(exp *ast.CallExpression) (Value, erro
 			return nil, err
 		}
 	}
+	named, err := e.evaluateNamedArguments(exp.NamedArguments)
+	if err != nil {
+		return nil, err
+	}
+
+	// La profundidad cuenta desde aquí para que los argumentos, que se
+	// evalúan antes de entrar en la función, no queden un nivel por debajo
+	e.callDepth++
+	defer func() { e.callDepth-- }()
 
-	return e.callFunction(fn, args)
+	token := exp.Token
+	switch function := exp.Function.(type) {
+	case *ast.Identifier:
+		token = function.Token
+	case *ast.DotExpression:
+		token = function.Property.Token
+	}
+	callSite := e.callSite
+	e.callSite = token
+	result, err := e.callFunctionNamed(fn, args, named)
+	e.callSite = callSite
+	if err != nil {
+		// Los errores de los builtins y de llamar a algo que no es una función
+		// se sitúan en la llamada; los de una función Zylo ya vienen de su cuerpo
+		switch fn.(type) {
+		case *ZyloFunction, *BoundMethod:
+		default:
+			err = withPosition(err, token)
+		}
+		return nil, err
+	}
+	return result, nil
 }
 
 // evaluateInfixExpression evalúa una expresión infija
@@ -1480,31 +2462,29 @@ func (e *Evaluator) evaluateInfixExpression(exp *ast.InfixExpression) (Value, er
 		return nil, err
 	}
 
-	// Short-circuit evaluation para && y ||
+	// Short-circuit evaluation para && y ||. Devuelven uno de los operandos,
+	// no un booleano, así que nombre = entrada or "anónimo" funciona.
 	switch exp.Operator {
 	case "and", "&&":
-		// Si el izquierdo es falso, retornar false sin evaluar el derecho
+		// Si el izquierdo es falso, retornarlo sin evaluar el derecho
 		if !e.isTruthy(left) {
-			return &Boolean{Value: false}, nil
-		}
-		// Si el izquierdo es verdadero, evaluar el derecho
-		right, err := e.evaluateExpression(exp.Right)
-		if err != nil {
-			return nil, err
+			return left, nil
 		}
-		return &Boolean{Value: e.isTruthy(right)}, nil
+		return e.evaluateExpression(exp.Right)
 
 	case "or", "||":
-		// Si el izquierdo es verdadero, retornar true sin evaluar el derecho
+		// Si el izquierdo es verdadero, retornarlo sin evaluar el derecho
 		if e.isTruthy(left) {
-			return &Boolean{Value: true}, nil
+			return left, nil
 		}
-		// Si el izquierdo es falso, evaluar el derecho
-		right, err := e.evaluateExpression(exp.Right)
-		if err != nil {
-			return nil, err
+		return e.evaluateExpression(exp.Right)
+
+	case "??":
+		// Solo null cede el paso al derecho: 0, "" y false se devuelven
+		if _, isNull := left.(*Null); !isNull {
+			return left, nil
 		}
-		return &Boolean{Value: e.isTruthy(right)}, nil
+		return e.evaluateExpression(exp.Right)
 
 	default:
 		// Para otros operadores, evaluar normalmente
@@ -1547,29 +2527,51 @@ func (e *Evaluator) evaluateAssignmentExpression(exp *ast.AssignmentExpression)
 	if err != nil {
 		return nil, err
 	}
+	return e.assignTarget(exp.Name, exp.Operator, value)
+}
+
+// evaluateDestructuringAssignment evalúa a, b = lista: el lado derecho se
+// evalúa entero antes de asignar, así que a, b = b, a intercambia los valores
+func (e *Evaluator) evaluateDestructuringAssignment(exp *ast.DestructuringAssignmentExpression) (Value, error) {
+	value, err := e.evaluateExpression(exp.Value)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := value.(*List)
+	if !ok {
+		return nil, fmt.Errorf("no se puede desestructurar %s: se esperaba una lista", e.getValueType(value))
+	}
+	if len(list.Items) != len(exp.Targets) {
+		return nil, fmt.Errorf("no se puede desestructurar: %d variables para %d valores", len(exp.Targets), len(list.Items))
+	}
+	items := append([]Value(nil), list.Items...)
+	for i, target := range exp.Targets {
+		if _, err := e.assignTarget(target, exp.Operator, items[i]); err != nil {
+			return nil, err
+		}
+	}
+	return list, nil
+}
 
+// assignTarget asigna value al destino target (identificador, índice o
+// propiedad) con el operador de asignación operator
+func (e *Evaluator) assignTarget(target ast.Expression, operator string, value Value) (Value, error) {
+	var err error
 	// Determine the target of the assignment (identifier, index, or dot expression)
-	switch nameExp := exp.Name.(type) {
+	switch nameExp := target.(type) {
 	case *ast.Identifier:
 		// Check if it's a constant
 		if e.env.IsConstant(nameExp.Value) {
 			return nil, fmt.Errorf("no se puede reasignar constante: %s", nameExp.Value)
 		}
-		// Check type compatibility - only if explicit type annotation was used
-		expectedType, exists := e.env.GetType(nameExp.Value)
-		if exists && expectedType != "" && expectedType != "ANY" {
-			if !e.isTypeCompatible(value, expectedType) {
-				return nil, fmt.Errorf("tipo incompatible: esperado %s, recibido %s", expectedType, e.getValueType(value))
-			}
-		}
 		// Handle simple identifier assignment
-		if exp.Operator != "=" {
+		if operator != "=" {
 			oldValue, exists := e.env.Get(nameExp.Value)
 			if !exists {
 				return nil, fmt.Errorf("variable no definida: %s", nameExp.Value)
 			}
 			var baseOp string
-			switch exp.Operator {
+			switch operator {
 			case "+=":
 				baseOp = "+"
 			case "-=":
@@ -1581,13 +2583,21 @@ func (e *Evaluator) evaluateAssignmentExpression(exp *ast.AssignmentExpression)
 			case "%=":
 				baseOp = "%"
 			default:
-				return nil, fmt.Errorf("operador de asignación no soportado: %s", exp.Operator)
+				return nil, fmt.Errorf("operador de asignación no soportado: %s", operator)
 			}
 			value, err = e.applyOperator(baseOp, oldValue, value)
 			if err != nil {
 				return nil, err
 			}
 		}
+		// Con anotación de tipo explícita, el valor resultante tiene que
+		// ser de ese tipo
+		if expectedType, exists := e.env.GetType(nameExp.Value); exists {
+			value, err = e.coerceToType(value, expectedType)
+			if err != nil {
+				return nil, withPosition(err, nameExp.Token)
+			}
+		}
 		if !e.env.Update(nameExp.Value, value) {
 			return nil, fmt.Errorf("variable no definida: %s", nameExp.Value)
 		}
@@ -1601,31 +2611,57 @@ func (e *Evaluator) evaluateAssignmentExpression(exp *ast.AssignmentExpression)
 		if err != nil {
 			return nil, err
 		}
-		return e.assignIndexValue(left, index, value, exp.Operator)
+		return e.assignIndexValue(left, index, value, operator, e.constantRoot(nameExp))
 	case *ast.DotExpression:
 		// Handle dot assignment (e.g., obj.prop = 10)
 		obj, err := e.evaluateExpression(nameExp.Left)
 		if err != nil {
 			return nil, err
 		}
-		return e.assignDotValue(obj, nameExp.Property.Value, value, exp.Operator)
+		return e.assignDotValue(obj, nameExp.Property.Value, value, operator, e.constantRoot(nameExp))
 	default:
-		return nil, fmt.Errorf("lado izquierdo de la asignación no es asignable: %T", exp.Name)
+		return nil, fmt.Errorf("lado izquierdo de la asignación no es asignable: %T", target)
 	}
 
 	return value, nil
 }
 
-// assignIndexValue asigna un valor a un índice de una lista o mapa
-func (e *Evaluator) assignIndexValue(left, index, value Value, operator string) (Value, error) {
+// constantRoot devuelve el nombre de la variable en la raíz de un destino de
+// asignación como a[0].b si es una constante, o "" si no lo es
+func (e *Evaluator) constantRoot(target ast.Expression) string {
+	for {
+		switch t := target.(type) {
+		case *ast.IndexExpression:
+			target = t.Left
+		case *ast.DotExpression:
+			target = t.Left
+		case *ast.Identifier:
+			if e.env.IsConstant(t.Value) {
+				return t.Value
+			}
+			return ""
+		default:
+			return ""
+		}
+	}
+}
+
+// assignIndexValue asigna un valor a un índice de una lista o mapa. constRoot
+// es el nombre de la constante de la que cuelga el destino, si la hay. Con un
+// operador compuesto sobre una clave de mapa que no existe se parte del valor
+// vacío del tipo del lado derecho, así que cuenta[k] += 1 sirve de contador.
+func (e *Evaluator) assignIndexValue(left, index, value Value, operator, constRoot string) (Value, error) {
+	if constRoot != "" {
+		return nil, fmt.Errorf("no se puede reasignar constante: %s", constRoot)
+	}
 	switch l := left.(type) {
 	case *List:
 		idx, ok := index.(*Integer)
 		if !ok {
 			return nil, fmt.Errorf("índice de lista debe ser integer")
 		}
-		if idx.Value < 0 || int(idx.Value) >= len(l.Items) {
-			return nil, fmt.Errorf("índice de lista fuera de rango")
+		if err := checkIndex("array", idx.Value, len(l.Items)); err != nil {
+			return nil, err
 		}
 		if operator != "=" {
 			oldValue := l.Items[idx.Value]
@@ -1646,15 +2682,17 @@ func (e *Evaluator) assignIndexValue(left, index, value Value, operator string)
 		if operator != "=" {
 			oldValue, exists := l.Pairs[key.Value]
 			if !exists {
-				return nil, fmt.Errorf("clave de mapa no definida: %s", key.Value)
+				if oldValue, exists = zeroValueFor(value); !exists {
+					return nil, fmt.Errorf("clave de mapa no definida: %s", key.Value)
+				}
 			}
 			newValue, err := e.applyOperator(strings.TrimSuffix(operator, "="), oldValue, value)
 			if err != nil {
 				return nil, err
 			}
-			l.Pairs[key.Value] = newValue
+			l.Set(key.Value, newValue)
 		} else {
-			l.Pairs[key.Value] = value
+			l.Set(key.Value, value)
 		}
 		return value, nil
 	default:
@@ -1662,8 +2700,27 @@ func (e *Evaluator) assignIndexValue(left, index, value Value, operator string)
 	}
 }
 
-// assignDotValue asigna un valor a una propiedad de un objeto
-func (e *Evaluator) assignDotValue(obj Value, property string, value Value, operator string) (Value, error) {
+// zeroValueFor devuelve el valor vacío del tipo de value: 0, 0.0 o "". Para
+// otros tipos devuelve false.
+func zeroValueFor(value Value) (Value, bool) {
+	switch value.(type) {
+	case *Integer:
+		return &Integer{Value: 0}, true
+	case *Float:
+		return &Float{Value: 0}, true
+	case *String:
+		return &String{Value: ""}, true
+	default:
+		return nil, false
+	}
+}
+
+// assignDotValue asigna un valor a una propiedad de un objeto. constRoot es
+// el nombre de la constante de la que cuelga el destino, si la hay.
+func (e *Evaluator) assignDotValue(obj Value, property string, value Value, operator, constRoot string) (Value, error) {
+	if constRoot != "" {
+		return nil, fmt.Errorf("no se puede reasignar constante: %s", constRoot)
+	}
 	switch o := obj.(type) {
 	case *ZyloInstance:
 		if operator != "=" {
@@ -1680,27 +2737,219 @@ func (e *Evaluator) assignDotValue(obj Value, property string, value Value, oper
 			o.Fields[property] = value
 		}
 		return value, nil
+	case *StructValue:
+		oldValue, err := structField(o, property)
+		if err != nil {
+			return nil, err
+		}
+		if operator != "=" {
+			value, err = e.applyOperator(strings.TrimSuffix(operator, "="), oldValue, value)
+			if err != nil {
+				return nil, err
+			}
+		}
+		o.Fields[property] = value
+		return value, nil
 	default:
 		return nil, fmt.Errorf("no se puede asignar a propiedad de tipo %T", obj)
 	}
 }
 
+// listSplice implementa list.splice(start, deleteCount, ...items): elimina
+// deleteCount elementos desde start, inserta items en su lugar y devuelve los
+// eliminados. Sin deleteCount elimina hasta el final. Modifica la lista, igual
+// que append.
+func listSplice(list *List, args []Value) (Value, error) {
+	if len(args) < 1 {
+		return nil, fmt.Errorf("splice() espera al menos 1 argumento")
+	}
+	start, err := listIndexArg("splice", "start", args[0], len(list.Items))
+	if err != nil {
+		return nil, err
+	}
+	deleteCount := len(list.Items) - start
+	if len(args) > 1 {
+		n, ok := args[1].(*Integer)
+		if !ok {
+			return nil, fmt.Errorf("splice() espera un entero como deleteCount")
+		}
+		if n.Value < 0 || n.Value > int64(len(list.Items)-start) {
+			return nil, fmt.Errorf("splice(): deleteCount fuera de rango: %d", n.Value)
+		}
+		deleteCount = int(n.Value)
+	}
+	var items []Value
+	if len(args) > 2 {
+		items = args[2:]
+	}
+
+	removed := make([]Value, deleteCount)
+	copy(removed, list.Items[start:start+deleteCount])
+
+	result := make([]Value, 0, len(list.Items)-deleteCount+len(items))
+	result = append(result, list.Items[:start]...)
+	result = append(result, items...)
+	result = append(result, list.Items[start+deleteCount:]...)
+	list.Items = result
+
+	return &List{Items: removed}, nil
+}
+
+// listFill implementa list.fill(value, start, end): asigna value a las
+// posiciones de start (incluido) a end (excluido), por defecto toda la lista.
+// Modifica la lista y la devuelve.
+func listFill(list *List, args []Value) (Value, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, fmt.Errorf("fill() espera entre 1 y 3 argumentos")
+	}
+	start, end := 0, len(list.Items)
+	var err error
+	if len(args) > 1 {
+		if start, err = listIndexArg("fill", "start", args[1], len(list.Items)); err != nil {
+			return nil, err
+		}
+	}
+	if len(args) > 2 {
+		if end, err = listIndexArg("fill", "end", args[2], len(list.Items)); err != nil {
+			return nil, err
+		}
+	}
+	if start > end {
+		return nil, fmt.Errorf("fill(): start (%d) mayor que end (%d)", start, end)
+	}
+
+	for i := start; i < end; i++ {
+		list.Items[i] = args[0]
+	}
+	return list, nil
+}
+
+// listPop implementa list.pop(index): quita y devuelve el elemento en index,
+// por defecto el último. Modifica la lista.
+func listPop(list *List, args []Value) (Value, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("pop() espera 0 o 1 argumentos")
+	}
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("pop(): la lista está vacía")
+	}
+	index := len(list.Items) - 1
+	if len(args) == 1 {
+		n, ok := args[0].(*Integer)
+		if !ok {
+			return nil, fmt.Errorf("pop() espera un entero como index")
+		}
+		if n.Value < 0 || n.Value >= int64(len(list.Items)) {
+			return nil, fmt.Errorf("pop(): index fuera de rango: %d", n.Value)
+		}
+		index = int(n.Value)
+	}
+	item := list.Items[index]
+	list.Items = listWithout(list.Items, index)
+	return item, nil
+}
+
+// listWithout devuelve una copia de items sin el elemento en index. Como en
+// splice, no se reutiliza el array de items, que otra lista podría compartir.
+func listWithout(items []Value, index int) []Value {
+	result := make([]Value, 0, len(items)-1)
+	result = append(result, items[:index]...)
+	return append(result, items[index+1:]...)
+}
+
+// listInsert implementa list.insert(index, value): inserta value antes de la
+// posición index; con index igual a length lo añade al final. Modifica la
+// lista, igual que append.
+func listInsert(list *List, args []Value) (Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("insert() espera 2 argumentos")
+	}
+	index, err := listIndexArg("insert", "index", args[0], len(list.Items))
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Value, 0, len(list.Items)+1)
+	result = append(result, list.Items[:index]...)
+	result = append(result, args[1])
+	list.Items = append(result, list.Items[index:]...)
+	return &Null{}, nil
+}
+
+// listIndexOf devuelve la posición del primer elemento igual a value (con
+// igualdad estructural, como in), o -1 si no está
+func listIndexOf(list *List, value Value) int {
+	for i, item := range list.Items {
+		if valuesEqual(item, value) {
+			return i
+		}
+	}
+	return -1
+}
+
+// listIndexArg valida un argumento de posición de un método de lista, que
+// debe ser un entero entre 0 y length (incluido)
+func listIndexArg(method, name string, arg Value, length int) (int, error) {
+	n, ok := arg.(*Integer)
+	if !ok {
+		return 0, fmt.Errorf("%s() espera un entero como %s", method, name)
+	}
+	if n.Value < 0 || n.Value > int64(length) {
+		return 0, fmt.Errorf("%s(): %s fuera de rango: %d", method, name, n.Value)
+	}
+	return int(n.Value), nil
+}
+
 // callFunction llama a una función
 func (e *Evaluator) callFunction(fn Value, args []Value) (Value, error) {
+	return e.callFunctionNamed(fn, args, nil)
+}
+
+// callFunctionNamed llama a una función con argumentos posicionales y con
+// nombre. Solo las funciones Zylo tienen nombres de parámetros, así que un
+// builtin no admite argumentos con nombre.
+func (e *Evaluator) callFunctionNamed(fn Value, args []Value, named map[string]Value) (Value, error) {
 	switch f := fn.(type) {
 	case *ZyloFunction:
-		return e.callZyloFunction(f, args)
+		return e.callZyloFunction(f, args, named)
 	case *BuiltinFunction:
+		if len(named) > 0 {
+			return nil, fmt.Errorf("%s no admite argumentos con nombre", f.Name)
+		}
 		return f.Fn(args)
 	case *BoundMethod:
-		return e.callBoundMethod(f, args)
+		return e.callBoundMethod(f, args, named)
 	default:
 		return nil, fmt.Errorf("no se puede llamar a: %T", fn)
 	}
 }
 
+// evaluateNamedArguments evalúa los argumentos con nombre de una llamada
+func (e *Evaluator) evaluateNamedArguments(args []*ast.NamedArgument) (map[string]Value, error) {
+	if len(args) == 0 {
+		return nil, nil
+	}
+	named := make(map[string]Value, len(args))
+	for _, arg := range args {
+		value, err := e.evaluateExpression(arg.Value)
+		if err != nil {
+			return nil, err
+		}
+		named[arg.Name] = value
+	}
+	return named, nil
+}
+
+// isCallable indica si callFunction puede llamar a v
+func isCallable(v Value) bool {
+	switch v.(type) {
+	case *ZyloFunction, *BuiltinFunction, *BoundMethod:
+		return true
+	}
+	return false
+}
+
 // instantiateClass crea una instancia de una clase
-func (e *Evaluator) instantiateClass(class *ZyloClass, args []ast.Expression) (Value, error) {
+func (e *Evaluator) instantiateClass(class *ZyloClass, args []ast.Expression, namedArgs []*ast.NamedArgument) (Value, error) {
 	instance := &ZyloInstance{
 		Class:  class,
 		Fields: make(map[string]Value),
@@ -1710,6 +2959,10 @@ func (e *Evaluator) instantiateClass(class *ZyloClass, args []ast.Expression) (V
 		instance.Fields[name] = value
 	}
 
+	if class.InitMethod == nil && len(namedArgs) > 0 {
+		return nil, fmt.Errorf("parámetro desconocido: %s", namedArgs[0].Name)
+	}
+
 	if class.InitMethod != nil {
 		evalArgs := make([]Value, len(args))
 		for i, arg := range args {
@@ -1719,22 +2972,23 @@ func (e *Evaluator) instantiateClass(class *ZyloClass, args []ast.Expression) (V
 				return nil, err
 			}
 		}
+		named, err := e.evaluateNamedArguments(namedArgs)
+		if err != nil {
+			return nil, err
+		}
 
 		funcEnv := class.InitMethod.Env.NewChildEnvironment()
-		funcEnv.Set("this", instance)
+		bindThis(funcEnv, instance, class)
 
-		for i, param := range class.InitMethod.Parameters {
-			if i < len(evalArgs) {
-				funcEnv.Set(param.Value, evalArgs[i])
-			}
+		if err := e.bindParameters(funcEnv, class.InitMethod, evalArgs, named); err != nil {
+			return nil, err
 		}
 
 		oldEnv := e.env
 		e.env = funcEnv
 		defer func() { e.env = oldEnv }()
 
-		_, err := e.evaluateBlockStatement(class.InitMethod.Body)
-		if err != nil {
+		if _, err := e.evaluateBlockStatement(class.InitMethod.Body); err != nil {
 			return nil, err
 		}
 	}
@@ -1743,39 +2997,44 @@ func (e *Evaluator) instantiateClass(class *ZyloClass, args []ast.Expression) (V
 }
 
 // callZyloFunction llama a una función Zylo
-func (e *Evaluator) callZyloFunction(fn *ZyloFunction, args []Value) (Value, error) {
+func (e *Evaluator) callZyloFunction(fn *ZyloFunction, args []Value, named map[string]Value) (Value, error) {
 	if fn.IsAsync {
-		future := &Future{
-			Result: make(chan ZyloObject, 1),
-			value:  nil,
-			once:   false,
-		}
-		go func() {
-			result, _ := e.callZyloFunctionSync(fn, args)
-			future.Result <- result.(ZyloObject)
-		}()
-		return future, nil
+		async := e.fork()
+		return newFuture(func() (Value, error) {
+			return async.callZyloFunctionSync(fn, args, named)
+		}), nil
 	}
-	return e.callZyloFunctionSync(fn, args)
+	return e.callZyloFunctionSync(fn, args, named)
 }
 
 // callZyloFunctionSync llama a una función Zylo de forma síncrona
-func (e *Evaluator) callZyloFunctionSync(fn *ZyloFunction, args []Value) (Value, error) {
+func (e *Evaluator) callZyloFunctionSync(fn *ZyloFunction, args []Value, named map[string]Value) (result Value, err error) {
+	name := fn.Name
+	if name == "" {
+		name = "<función anónima>"
+	}
+	defer e.pushFrame(name)()
+	if e.profile != nil {
+		defer e.profileCall(name)()
+	}
+	if e.trace != nil {
+		e.traceCall(name, args, named)
+		defer func() { e.traceReturn(name, result, err) }()
+	}
+
 	funcEnv := NewEnclosedEnvironment(fn.Env)
 
-	for i, param := range fn.Parameters {
-		if i < len(args) {
-			funcEnv.Set(param.Value, args[i])
-		}
+	if err := e.bindParameters(funcEnv, fn, args, named); err != nil {
+		return nil, withStack(err, e.callStack)
 	}
 
 	oldEnv := e.env
 	e.env = funcEnv
 	defer func() { e.env = oldEnv }()
 
-	result, err := e.evaluateBlockStatement(fn.Body)
+	result, err = e.evaluateBlockStatement(fn.Body)
 	if err != nil {
-		return nil, err
+		return nil, withStack(err, e.callStack)
 	}
 
 	// Desenvolver ReturnValue
@@ -1786,24 +3045,139 @@ func (e *Evaluator) callZyloFunctionSync(fn *ZyloFunction, args []Value) (Value,
 	return result, nil
 }
 
-// callBoundMethod llama a un método ligado
-func (e *Evaluator) callBoundMethod(boundMethod *BoundMethod, args []Value) (Value, error) {
-	funcEnv := boundMethod.Method.Env.NewChildEnvironment()
-	funcEnv.Set("this", boundMethod.Instance)
+// pushFrame apila una llamada a la función name desde la posición de la
+// llamada en curso y devuelve la función que la desapila
+func (e *Evaluator) pushFrame(name string) func() {
+	e.callStack = append(e.callStack, StackFrame{Function: name, Line: e.callSite.StartLine, Column: e.callSite.StartCol})
+	return func() { e.callStack = e.callStack[:len(e.callStack)-1] }
+}
+
+// readNumber lee líneas de la entrada hasta que parse acepta una. Una línea
+// que no es un número se avisa y se vuelve a pedir; si la entrada se acaba
+// antes de leer un número válido, devuelve un error en lugar de seguir
+// esperando.
+func (e *Evaluator) readNumber(name string, parse func(string) (Value, bool)) (Value, error) {
+	for {
+		fmt.Print("> ")
+		os.Stdout.Sync()
+		input, err := e.reader.ReadString('\n')
+		input = strings.TrimSpace(input)
+		if input != "" || err == nil {
+			if value, ok := parse(input); ok {
+				return value, nil
+			}
+			fmt.Printf("Error: %q no es un número válido\n", input)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: fin de la entrada sin un número válido", name)
+		}
+	}
+}
+
+// bindParameters define en env los parámetros de una llamada a fn. Los
+// argumentos posicionales se asignan en orden y los de named por nombre. Un
+// parámetro sin argumento toma su valor por defecto, que se evalúa en env para
+// que pueda usar la clausura y los parámetros anteriores; si no lo tiene, la
+// llamada falla. Si fn es variádica, su último parámetro recibe una lista con
+// los argumentos posicionales restantes.
+func (e *Evaluator) bindParameters(env *Environment, fn *ZyloFunction, args []Value, named map[string]Value) error {
+	if err := checkNamedArguments(fn, len(args), named); err != nil {
+		return err
+	}
+
+	oldEnv := e.env
+	e.env = env
+	defer func() { e.env = oldEnv }()
 
-	for i, param := range boundMethod.Method.Parameters {
+	for i, param := range fn.Parameters {
+		if fn.Variadic && i == len(fn.Parameters)-1 {
+			rest := []Value{}
+			if i < len(args) {
+				rest = append(rest, args[i:]...)
+			}
+			env.Set(param.Value, &List{Items: rest})
+			break
+		}
 		if i < len(args) {
-			funcEnv.Set(param.Value, args[i])
+			env.Set(param.Value, args[i])
+			continue
+		}
+		if value, ok := named[param.Value]; ok {
+			env.Set(param.Value, value)
+			continue
+		}
+		if param.Default == nil {
+			return fmt.Errorf("falta el argumento %s", param.Value)
+		}
+		value, err := e.evaluateExpression(param.Default)
+		if err != nil {
+			return err
+		}
+		env.Set(param.Value, value)
+	}
+	return nil
+}
+
+// checkNamedArguments comprueba que cada nombre de named es un parámetro de
+// fn que no recibe ya uno de los primeros positional argumentos. El parámetro
+// variádico no se puede pasar por nombre.
+func checkNamedArguments(fn *ZyloFunction, positional int, named map[string]Value) error {
+	names := make([]string, 0, len(named))
+	for name := range named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		index := -1
+		for i, param := range fn.Parameters {
+			if param.Value == name {
+				index = i
+				break
+			}
 		}
+		switch {
+		case index < 0:
+			return fmt.Errorf("parámetro desconocido: %s", name)
+		case fn.Variadic && index == len(fn.Parameters)-1:
+			return fmt.Errorf("el parámetro variádico %s no se puede pasar por nombre", name)
+		case index < positional:
+			return fmt.Errorf("el argumento %s se pasó por posición y por nombre", name)
+		}
+	}
+	return nil
+}
+
+// isVariadic indica si el último parámetro de params es variádico
+func isVariadic(params []*ast.Identifier) bool {
+	return len(params) > 0 && params[len(params)-1].Variadic
+}
+
+// callBoundMethod llama a un método ligado
+func (e *Evaluator) callBoundMethod(boundMethod *BoundMethod, args []Value, named map[string]Value) (result Value, err error) {
+	name := boundMethod.Class.Name + "." + boundMethod.Method.Name
+	defer e.pushFrame(name)()
+	if e.profile != nil {
+		defer e.profileCall(name)()
+	}
+	if e.trace != nil {
+		e.traceCall(name, args, named)
+		defer func() { e.traceReturn(name, result, err) }()
+	}
+
+	funcEnv := boundMethod.Method.Env.NewChildEnvironment()
+	bindThis(funcEnv, boundMethod.Instance, boundMethod.Class)
+
+	if err := e.bindParameters(funcEnv, boundMethod.Method, args, named); err != nil {
+		return nil, withStack(err, e.callStack)
 	}
 
 	oldEnv := e.env
 	e.env = funcEnv
 	defer func() { e.env = oldEnv }()
 
-	result, err := e.evaluateBlockStatement(boundMethod.Method.Body)
+	result, err = e.evaluateBlockStatement(boundMethod.Method.Body)
 	if err != nil {
-		return nil, err
+		return nil, withStack(err, e.callStack)
 	}
 
 	// Desenvolver ReturnValue
@@ -1814,6 +3188,101 @@ func (e *Evaluator) callBoundMethod(boundMethod *BoundMethod, args []Value) (Val
 	return result, nil
 }
 
+// displayMethods son los métodos con los que una clase controla cómo se
+// muestran sus instancias, en orden de preferencia
+var displayMethods = []string{"to_string", "str"}
+
+// displayString devuelve el texto con el que se muestra un valor. Las
+// instancias cuya clase, o alguna superclase, define to_string o str se
+// muestran con el resultado de ese método; el resto usa Inspect.
+func (e *Evaluator) displayString(v Value) (string, error) {
+	instance, ok := v.(*ZyloInstance)
+	if !ok {
+		if obj, ok := v.(ZyloObject); ok {
+			return obj.Inspect(), nil
+		}
+		return fmt.Sprintf("%v", v), nil
+	}
+
+	for class := instance.Class; class != nil; class = class.SuperClass {
+		for _, name := range displayMethods {
+			method, exists := class.Methods[name]
+			if !exists {
+				continue
+			}
+			result, err := e.callBoundMethod(&BoundMethod{Instance: instance, Method: method, Class: class}, nil, nil)
+			if err != nil {
+				return "", err
+			}
+			str, ok := result.(*String)
+			if !ok {
+				return "", fmt.Errorf("%s.%s() debe devolver un string, obtenido %T", instance.Class.Name, name, result)
+			}
+			return str.Value, nil
+		}
+	}
+	return instance.Inspect(), nil
+}
+
+// builtinFields implementa fields(instancia): un mapa con los campos de la
+// instancia, incluidos los atributos heredados que no se asignaron
+func builtinFields(args []Value) (Value, error) {
+	instance, err := instanceArgument("fields", args)
+	if err != nil {
+		return nil, err
+	}
+	result := &MapObject{Pairs: make(map[string]Value, len(instance.Fields))}
+	for class := instance.Class.SuperClass; class != nil; class = class.SuperClass {
+		for name, value := range class.Attributes {
+			if _, exists := result.Pairs[name]; !exists {
+				result.Pairs[name] = value
+			}
+		}
+	}
+	for name, value := range instance.Fields {
+		result.Pairs[name] = value
+	}
+	return result, nil
+}
+
+// builtinMethods implementa methods(instancia): los nombres de los métodos de
+// la clase de la instancia y de sus superclases, ordenados y sin repetir
+func builtinMethods(args []Value) (Value, error) {
+	instance, err := instanceArgument("methods", args)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var names []string
+	for class := instance.Class; class != nil; class = class.SuperClass {
+		for name := range class.Methods {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	result := &List{Items: make([]Value, len(names))}
+	for i, name := range names {
+		result.Items[i] = &String{Value: name}
+	}
+	return result, nil
+}
+
+// instanceArgument comprueba que un builtin reciba una sola instancia
+func instanceArgument(name string, args []Value) (*ZyloInstance, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%s() espera 1 argumento", name)
+	}
+	instance, ok := args[0].(*ZyloInstance)
+	if !ok {
+		return nil, fmt.Errorf("%s() espera una instancia, obtenido %T", name, args[0])
+	}
+	return instance, nil
+}
+
 // evaluateThisExpression evalúa una expresión 'this'
 func (e *Evaluator) evaluateThisExpression(exp *ast.ThisExpression) (Value, error) {
 	value, exists := e.env.Get("this")
@@ -1825,14 +3294,45 @@ func (e *Evaluator) evaluateThisExpression(exp *ast.ThisExpression) (Value, erro
 
 // evaluateSuperExpression evalúa una expresión 'super'
 func (e *Evaluator) evaluateSuperExpression(exp *ast.SuperExpression) (Value, error) {
-	if this, exists := e.env.Get("this"); exists {
-		if instance, ok := this.(*ZyloInstance); ok && instance.Class.SuperClass != nil {
-			return &SuperObject{Instance: instance}, nil
-		}
+	return e.lookupSuper()
+}
+
+// lookupSuper devuelve el 'super' del método en ejecución. Se guarda en el
+// entorno del método al llamarlo, igual que 'this', porque depende de la clase
+// que define el método y no de la clase de la instancia.
+func (e *Evaluator) lookupSuper() (Value, error) {
+	if superObj, exists := e.env.Get("super"); exists {
+		return superObj, nil
 	}
 	return nil, fmt.Errorf("'super' no disponible en este contexto")
 }
 
+// bindThis define 'this' y, si la clase que define el método tiene
+// superclase, 'super' en el entorno de un método
+func bindThis(env *Environment, instance *ZyloInstance, class *ZyloClass) {
+	env.Set("this", instance)
+	if class != nil && class.SuperClass != nil {
+		env.Set("super", &SuperObject{Instance: instance, Class: class.SuperClass})
+	}
+}
+
+// findMethod busca un método en class y sus superclases y devuelve también la
+// clase que lo define. "init" se busca en el constructor de cada clase.
+func findMethod(class *ZyloClass, name string) (*ZyloFunction, *ZyloClass) {
+	for ; class != nil; class = class.SuperClass {
+		if name == "init" {
+			if class.InitMethod != nil {
+				return class.InitMethod, class
+			}
+			continue
+		}
+		if method, exists := class.Methods[name]; exists {
+			return method, class
+		}
+	}
+	return nil, nil
+}
+
 // evaluateAwaitExpression evalúa una expresión 'await'
 func (e *Evaluator) evaluateAwaitExpression(exp *ast.AwaitExpression) (Value, error) {
 	arg, err := e.evaluateExpression(exp.Argument)
@@ -1840,14 +3340,89 @@ func (e *Evaluator) evaluateAwaitExpression(exp *ast.AwaitExpression) (Value, er
 		return nil, err
 	}
 
-	if future, ok := arg.(*Future); ok {
-		result := <-future.Result
-		return result, nil
+	switch value := arg.(type) {
+	case *Future:
+		return e.awaitFuture(value, 0)
+	case *List:
+		return e.awaitAll(value)
 	}
 
 	return nil, fmt.Errorf("await expects a future, got %T", arg)
 }
 
+// awaitFuture espera el resultado de un Future. Con timeout > 0 falla si el
+// resultado no llega a tiempo; el Future puede volver a esperarse después.
+// Un *Error entregado por la llamada asíncrona se reporta como error.
+func (e *Evaluator) awaitFuture(future *Future, timeout time.Duration) (Value, error) {
+	var received Value
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		select {
+		case received = <-future.Result:
+		case <-timer.C:
+			return nil, fmt.Errorf("await: tiempo de espera agotado tras %v", timeout)
+		}
+	} else {
+		received = <-future.Result
+	}
+	// Solo la primera espera recibe el valor del canal; las siguientes lo
+	// encuentran cerrado y usan el resultado guardado
+	result := future.resolve(received)
+
+	if errObj, ok := result.(*Error); ok {
+		return nil, fmt.Errorf("%s", errObj.Message)
+	}
+	return result, nil
+}
+
+// awaitAll espera todos los Futures de list y devuelve una lista con sus
+// resultados en el mismo orden. Espera a todos aunque alguno falle, para no
+// dejar resultados sin recoger, y después reporta el primer error de la lista.
+func (e *Evaluator) awaitAll(list *List) (Value, error) {
+	// Se comprueba antes de esperar para no consumir unos Futures y fallar
+	// a mitad de la lista
+	for i, item := range list.Items {
+		if _, ok := item.(*Future); !ok {
+			return nil, fmt.Errorf("await_all() espera una lista de futures, el elemento %d es %T", i, item)
+		}
+	}
+
+	results := make([]Value, len(list.Items))
+	var firstErr error
+	for i, item := range list.Items {
+		value, err := e.awaitFuture(item.(*Future), 0)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		results[i] = value
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return &List{Items: results}, nil
+}
+
+// fork crea un evaluador que comparte el entorno y los builtins de e pero
+// tiene su propio estado de ejecución, para evaluar código en otra goroutine
+// sin alterar el entorno activo de e.
+func (e *Evaluator) fork() *Evaluator {
+	return &Evaluator{
+		env:          e.env,
+		reader:       e.reader,
+		assertions:   e.assertions,
+		modules:      e.modules,
+		baseDir:      e.baseDir,
+		localImports: e.localImports,
+		coverage:     e.coverage,
+		callStack:    append([]StackFrame(nil), e.callStack...),
+		callSite:     e.callSite,
+		trace:        e.trace,
+		strict:       e.strict,
+		profile:      e.profile,
+	}
+}
+
 // applyOperator aplica un operador binario
 func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, error) {
 	if left == nil || right == nil {
@@ -1869,12 +3444,28 @@ func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, er
 			if _, ok := right.(*Null); ok {
 				return &String{Value: leftStr.Value + "null"}, nil
 			}
+			if rightInstance, ok := right.(*ZyloInstance); ok {
+				text, err := e.displayString(rightInstance)
+				if err != nil {
+					return nil, err
+				}
+				return &String{Value: leftStr.Value + text}, nil
+			}
 		}
 		if _, ok := left.(*Null); ok {
 			if rightStr, ok := right.(*String); ok {
 				return &String{Value: "null" + rightStr.Value}, nil
 			}
 		}
+		if leftInstance, ok := left.(*ZyloInstance); ok {
+			if rightStr, ok := right.(*String); ok {
+				text, err := e.displayString(leftInstance)
+				if err != nil {
+					return nil, err
+				}
+				return &String{Value: text + rightStr.Value}, nil
+			}
+		}
 		if leftNum, ok := left.(*Integer); ok {
 			if rightNum, ok := right.(*Integer); ok {
 				return &Integer{Value: leftNum.Value + rightNum.Value}, nil
@@ -1963,7 +3554,13 @@ func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, er
 				return &Integer{Value: leftNum.Value % rightNum.Value}, nil
 			}
 		}
-	case "**", "^":
+		if l, r, ok := floatOperands(left, right); ok {
+			if r == 0 {
+				return nil, fmt.Errorf("módulo por cero")
+			}
+			return &Float{Value: math.Mod(l, r)}, nil
+		}
+	case "**":
 		switch l := left.(type) {
 		case *Integer:
 			switch r := right.(type) {
@@ -1982,33 +3579,7 @@ func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, er
 		}
 	
 	case "==":
-		if leftStr, ok := left.(*String); ok {
-			if rightStr, ok := right.(*String); ok {
-				return &Boolean{Value: leftStr.Value == rightStr.Value}, nil
-			}
-		}
-		if leftNum, ok := left.(*Integer); ok {
-			if rightNum, ok := right.(*Integer); ok {
-				return &Boolean{Value: leftNum.Value == rightNum.Value}, nil
-			}
-			if rightFloat, ok := right.(*Float); ok {
-				return &Boolean{Value: float64(leftNum.Value) == rightFloat.Value}, nil
-			}
-		}
-		if leftFloat, ok := left.(*Float); ok {
-			if rightNum, ok := right.(*Integer); ok {
-				return &Boolean{Value: leftFloat.Value == float64(rightNum.Value)}, nil
-			}
-			if rightFloat, ok := right.(*Float); ok {
-				return &Boolean{Value: leftFloat.Value == rightFloat.Value}, nil
-			}
-		}
-		if leftBool, ok := left.(*Boolean); ok {
-			if rightBool, ok := right.(*Boolean); ok {
-				return &Boolean{Value: leftBool.Value == rightBool.Value}, nil
-			}
-		}
-		return &Boolean{Value: false}, nil
+		return &Boolean{Value: valuesEqual(left, right)}, nil
 	case "!=":
 		result, err := e.applyOperator("==", left, right)
 		if err != nil {
@@ -2020,6 +3591,11 @@ func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, er
 		return &Boolean{Value: true}, nil
 		
 	case "<":
+		if leftStr, ok := left.(*String); ok {
+			if rightStr, ok := right.(*String); ok {
+				return &Boolean{Value: leftStr.Value < rightStr.Value}, nil
+			}
+		}
 		if leftNum, ok := left.(*Integer); ok {
 			if rightNum, ok := right.(*Integer); ok {
 				return &Boolean{Value: leftNum.Value < rightNum.Value}, nil
@@ -2037,6 +3613,11 @@ func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, er
 			}
 		}
 	case ">":
+		if leftStr, ok := left.(*String); ok {
+			if rightStr, ok := right.(*String); ok {
+				return &Boolean{Value: leftStr.Value > rightStr.Value}, nil
+			}
+		}
 		if leftNum, ok := left.(*Integer); ok {
 			if rightNum, ok := right.(*Integer); ok {
 				return &Boolean{Value: leftNum.Value > rightNum.Value}, nil
@@ -2054,6 +3635,11 @@ func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, er
 			}
 		}
 	case "<=":
+		if leftStr, ok := left.(*String); ok {
+			if rightStr, ok := right.(*String); ok {
+				return &Boolean{Value: leftStr.Value <= rightStr.Value}, nil
+			}
+		}
 		if leftNum, ok := left.(*Integer); ok {
 			if rightNum, ok := right.(*Integer); ok {
 				return &Boolean{Value: leftNum.Value <= rightNum.Value}, nil
@@ -2071,6 +3657,11 @@ func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, er
 			}
 		}
 	case ">=":
+		if leftStr, ok := left.(*String); ok {
+			if rightStr, ok := right.(*String); ok {
+				return &Boolean{Value: leftStr.Value >= rightStr.Value}, nil
+			}
+		}
 		if leftNum, ok := left.(*Integer); ok {
 			if rightNum, ok := right.(*Integer); ok {
 				return &Boolean{Value: leftNum.Value >= rightNum.Value}, nil
@@ -2088,25 +3679,89 @@ func (e *Evaluator) applyOperator(operator string, left, right Value) (Value, er
 			}
 		}
 	case "and", "&&":
-		leftBool := e.isTruthy(left)
-		if !leftBool {
-			return &Boolean{Value: false}, nil
+		if !e.isTruthy(left) {
+			return left, nil
 		}
-		rightBool := e.isTruthy(right)
-		return &Boolean{Value: rightBool}, nil
+		return right, nil
 	case "or", "||":
-		leftBool := e.isTruthy(left)
-		if leftBool {
-			return &Boolean{Value: true}, nil
+		if e.isTruthy(left) {
+			return left, nil
+		}
+		return right, nil
+	case "xor":
+		if leftBool, ok := left.(*Boolean); ok {
+			if rightBool, ok := right.(*Boolean); ok {
+				return &Boolean{Value: leftBool.Value != rightBool.Value}, nil
+			}
+		}
+	case "&", "|", "^", "<<", ">>":
+		if leftNum, ok := left.(*Integer); ok {
+			if rightNum, ok := right.(*Integer); ok {
+				return bitwise(operator, leftNum.Value, rightNum.Value)
+			}
+		}
+	case "in", "not in":
+		found, err := contains(right, left)
+		if err != nil {
+			return nil, err
 		}
-		rightBool := e.isTruthy(right)
-		return &Boolean{Value: rightBool}, nil
+		return &Boolean{Value: found == (operator == "in")}, nil
 	}
 
 	return nil, fmt.Errorf("operador '%s' no soportado para %T y %T", operator, left, right)
 	
 }
 
+// bitwise aplica un operador de bits a dos enteros
+func bitwise(operator string, left, right int64) (Value, error) {
+	switch operator {
+	case "&":
+		return &Integer{Value: left & right}, nil
+	case "|":
+		return &Integer{Value: left | right}, nil
+	case "^":
+		return &Integer{Value: left ^ right}, nil
+	}
+	if right < 0 {
+		return nil, fmt.Errorf("desplazamiento negativo: %d", right)
+	}
+	if operator == "<<" {
+		return &Integer{Value: left << uint64(right)}, nil
+	}
+	return &Integer{Value: left >> uint64(right)}, nil
+}
+
+// contains implementa el operador in: pertenencia de un elemento a una lista
+// o a un set (con igualdad estructural), de un substring a un string o de una
+// clave a un mapa
+func contains(container, item Value) (bool, error) {
+	switch c := container.(type) {
+	case *Set:
+		return c.Has(item), nil
+	case *List:
+		for _, element := range c.Items {
+			if valuesEqual(element, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case *String:
+		sub, ok := item.(*String)
+		if !ok {
+			return false, fmt.Errorf("'in' con un string requiere un string a la izquierda, obtenido %T", item)
+		}
+		return strings.Contains(c.Value, sub.Value), nil
+	case *MapObject:
+		key, ok := item.(*String)
+		if !ok {
+			return false, nil
+		}
+		_, exists := c.Pairs[key.Value]
+		return exists, nil
+	}
+	return false, fmt.Errorf("operador 'in' no soportado para %T", container)
+}
+
 // isTruthy determina si un valor es "verdadero"
 func (e *Evaluator) isTruthy(value Value) bool {
 	if value == nil {
@@ -2149,15 +3804,6 @@ func (e *Evaluator) getValueType(value Value) string {
 	}
 }
 
-// isTypeCompatible verifica si un valor es compatible con un tipo esperado
-func (e *Evaluator) isTypeCompatible(value Value, expectedType string) bool {
-	actualType := e.getValueType(value)
-	if expectedType == "ANY" {
-		return true
-	}
-	return actualType == expectedType
-}
-
 // evaluateRangeExpression evalúa una expresión de rango (e.g., 0..10)
 func (e *Evaluator) evaluateRangeExpression(exp *ast.RangeExpression) (Value, error) {
 	start, err := e.evaluateExpression(exp.Start)
@@ -2185,6 +3831,18 @@ func (e *Evaluator) evaluateRangeExpression(exp *ast.RangeExpression) (Value, er
 	return &List{Items: items}, nil
 }
 
+// checkIndex comprueba que index es válido en un contenedor de length
+// elementos; los mensajes siguen el formato de runtime.ValidateArrayBounds
+func checkIndex(kind string, index int64, length int) error {
+	if index < 0 {
+		return fmt.Errorf("%s index cannot be negative: %d", kind, index)
+	}
+	if index >= int64(length) {
+		return fmt.Errorf("%s index out of bounds: %d (length: %d)", kind, index, length)
+	}
+	return nil
+}
+
 // indexValue handles indexing for arrays and strings
 func (e *Evaluator) indexValue(left, index Value) (Value, error) {
 	if left == nil {
@@ -2202,8 +3860,8 @@ func (e *Evaluator) indexValue(left, index Value) (Value, error) {
 		if !ok {
 			return nil, fmt.Errorf("índice debe ser integer")
 		}
-		if idx.Value < 0 || int(idx.Value) >= len(l.Items) {
-			return nil, fmt.Errorf("índice fuera de rango")
+		if err := checkIndex("array", idx.Value, len(l.Items)); err != nil {
+			return nil, err
 		}
 		return l.Items[idx.Value], nil
 	case *String:
@@ -2211,8 +3869,8 @@ func (e *Evaluator) indexValue(left, index Value) (Value, error) {
 		if !ok {
 			return nil, fmt.Errorf("índice debe ser integer")
 		}
-		if idx.Value < 0 || int(idx.Value) >= len(l.Value) {
-			return nil, fmt.Errorf("índice fuera de rango")
+		if err := checkIndex("string", idx.Value, len(l.Value)); err != nil {
+			return nil, err
 		}
 		return &String{Value: string(l.Value[idx.Value])}, nil
 	case *MapObject:
@@ -2238,6 +3896,7 @@ type ZyloFunction struct {
 	Body       *ast.BlockStatement
 	Env        *Environment
 	IsAsync    bool
+	Variadic   bool // El último parámetro recibe en una lista los argumentos sobrantes
 }
 
 // BuiltinFunction representa una función built-in
@@ -2275,6 +3934,7 @@ func (i *ZyloInstance) Inspect() string {
 type BoundMethod struct {
 	Instance *ZyloInstance
 	Method   *ZyloFunction
+	Class    *ZyloClass // Clase que define Method, para resolver 'super'
 }
 
 func (b *BoundMethod) Type() string { return "BOUND_METHOD_OBJ" }
@@ -2282,9 +3942,21 @@ func (b *BoundMethod) Inspect() string {
 	return fmt.Sprintf("bound method %s", b.Method.Name)
 }
 
-// SuperObject representa el acceso a la superclase
+// StringBuilder construye strings de forma eficiente, evitando el costo
+// cuadrático de concatenar con + dentro de un bucle
+type StringBuilder struct {
+	builder strings.Builder
+}
+
+func (sb *StringBuilder) Type() string    { return "STRING_BUILDER_OBJ" }
+func (sb *StringBuilder) Inspect() string { return sb.builder.String() }
+
+// SuperObject representa el acceso a la superclase. Class es la clase desde
+// la que se empiezan a buscar los métodos: la superclase de la clase que
+// define el método en ejecución.
 type SuperObject struct {
 	Instance *ZyloInstance
+	Class    *ZyloClass
 }
 
 func (s *SuperObject) Type() string { return "SUPER_OBJ" }
@@ -2336,7 +4008,7 @@ func unifyType(t string) string {
 
 // getNormalizedType obtiene el tipo normalizado de un valor
 func getNormalizedType(value Value) string {
-	switch value.(type) {
+	switch v := value.(type) {
 	case *Integer:
 		return "int"
 	case *Float:
@@ -2355,6 +4027,11 @@ func getNormalizedType(value Value) string {
 		return "class"
 	case *ZyloInstance:
 		return "instance"
+	case *EnumMember:
+		// Permite declarar var c: Color = Color.Red
+		return v.Enum.Name
+	case *StructValue:
+		return v.Def.Name
 	default:
 		return "unknown"
 	}
@@ -2515,7 +4192,7 @@ func (e *Evaluator) httpPostJSON(url string, data Value, headers map[string]stri
 
 	switch d := data.(type) {
 	case *MapObject:
-		jsonData, err = json.Marshal(e.mapToGoMap(d))
+		jsonData, err = json.Marshal(e.mapToJSONObject(d))
 		if err != nil {
 			return &String{Value: fmt.Sprintf("Error marshaling JSON: %v", err)}, nil
 		}
@@ -2569,27 +4246,39 @@ func (e *Evaluator) httpPostJSON(url string, data Value, headers map[string]stri
 	return responseMap, nil
 }
 
-// httpListen inicia un servidor HTTP
+// httpListen inicia un servidor HTTP. Si se pasa un handler, atiende todas las
+// rutas que no tengan un handler propio registrado con http.route.
 func (e *Evaluator) httpListen(port int64, handler *ZyloFunction) (Value, error) {
 	if e.httpServer != nil {
 		return &String{Value: "Server already running"}, nil
 	}
 
-	e.httpHandler = handler
-
-	mux := http.NewServeMux()
-	mux.HandleFunc("/", e.httpHandleRequest)
+	mux := e.httpEnsureMux()
+	if handler != nil {
+		if e.httpHandler == nil {
+			if err := httpHandle(mux, "/", e.httpHandleRequest); err != nil {
+				return nil, fmt.Errorf("http.listen: %v", err)
+			}
+		}
+		e.httpHandler = handler
+	}
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", port),
 		Handler: mux,
 	}
 
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("http.listen: %v", err)
+	}
+
 	e.httpServer = server
+	e.httpDone = make(chan struct{})
 
 	go func() {
 		fmt.Printf("HTTP server listening on port %d\n", port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			fmt.Printf("Server error: %v\n", err)
 		}
 	}()
@@ -2597,12 +4286,117 @@ func (e *Evaluator) httpListen(port int64, handler *ZyloFunction) (Value, error)
 	return &String{Value: "Server started"}, nil
 }
 
-// httpHandleRequest maneja las peticiones HTTP entrantes
+// httpRoute registra un handler para un método y una ruta. La ruta admite
+// parámetros con la forma /users/:id o /users/{id}; un método vacío o "*"
+// acepta cualquier método.
+func (e *Evaluator) httpRoute(method, path string, handler *ZyloFunction) (Value, error) {
+	pattern, params := httpRoutePattern(path)
+	if method != "" && method != "*" {
+		pattern = strings.ToUpper(method) + " " + pattern
+	}
+
+	err := httpHandle(e.httpEnsureMux(), pattern, func(w http.ResponseWriter, r *http.Request) {
+		e.httpServe(w, r, handler, params)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("http.route: %v", err)
+	}
+	return &Null{}, nil
+}
+
+// httpStop detiene el servidor con server.Shutdown. Las rutas registradas se
+// conservan para un próximo http.listen.
+func (e *Evaluator) httpStop() (Value, error) {
+	server := e.httpServer
+	if server == nil {
+		return &String{Value: "Server not running"}, nil
+	}
+	e.httpServer = nil
+	done := e.httpDone
+
+	shutdown := func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		err := server.Shutdown(ctx)
+		close(done)
+		return err
+	}
+
+	// Shutdown espera a que terminen las peticiones en curso; si se llama desde
+	// un handler esperaría por sí mismo, así que se completa en segundo plano.
+	if atomic.LoadInt32(&e.httpActive) > 0 {
+		go shutdown()
+		return &String{Value: "Server stopping"}, nil
+	}
+
+	if err := shutdown(); err != nil {
+		return nil, fmt.Errorf("http.stop: %v", err)
+	}
+	return &String{Value: "Server stopped"}, nil
+}
+
+// WaitHTTPServer bloquea hasta que el servidor iniciado con http.listen se
+// detenga. No hace nada si no se inició ningún servidor.
+func (e *Evaluator) WaitHTTPServer() {
+	if e.httpDone != nil {
+		<-e.httpDone
+	}
+}
+
+// httpEnsureMux crea el mux de rutas si todavía no existe
+func (e *Evaluator) httpEnsureMux() *http.ServeMux {
+	if e.httpMux == nil {
+		e.httpMux = http.NewServeMux()
+	}
+	return e.httpMux
+}
+
+// httpHandle registra un handler en el mux convirtiendo en error los
+// patrones inválidos o duplicados, que net/http reporta con panic
+func httpHandle(mux *http.ServeMux, pattern string, handler http.HandlerFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	mux.HandleFunc(pattern, handler)
+	return nil
+}
+
+// httpRoutePattern traduce una ruta de Zylo a un patrón de net/http y
+// devuelve los nombres de sus parámetros
+func httpRoutePattern(path string) (string, []string) {
+	var params []string
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		switch {
+		case strings.HasPrefix(segment, ":") && len(segment) > 1:
+			params = append(params, segment[1:])
+			segments[i] = "{" + segment[1:] + "}"
+		case strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}"):
+			name := strings.TrimSuffix(segment[1:len(segment)-1], "...")
+			if name != "$" {
+				params = append(params, name)
+			}
+		}
+	}
+	return strings.Join(segments, "/"), params
+}
+
+// httpHandleRequest maneja las peticiones HTTP entrantes con el handler de http.listen
 func (e *Evaluator) httpHandleRequest(w http.ResponseWriter, r *http.Request) {
 	if e.httpHandler == nil {
 		http.Error(w, "No handler", http.StatusInternalServerError)
 		return
 	}
+	e.httpServe(w, r, e.httpHandler, nil)
+}
+
+// httpServe construye el mapa de petición, llama al handler de Zylo y escribe
+// su respuesta
+func (e *Evaluator) httpServe(w http.ResponseWriter, r *http.Request, handler *ZyloFunction, params []string) {
+	atomic.AddInt32(&e.httpActive, 1)
+	defer atomic.AddInt32(&e.httpActive, -1)
 
 	body, err := ioutil.ReadAll(r.Body)
 	if err != nil {
@@ -2614,6 +4408,7 @@ func (e *Evaluator) httpHandleRequest(w http.ResponseWriter, r *http.Request) {
 	reqMap := &MapObject{Pairs: make(map[string]Value)}
 	reqMap.Pairs["method"] = &String{Value: r.Method}
 	reqMap.Pairs["url"] = &String{Value: r.URL.String()}
+	reqMap.Pairs["path"] = &String{Value: r.URL.Path}
 	reqMap.Pairs["body"] = &String{Value: string(body)}
 
 	headersMap := &MapObject{Pairs: make(map[string]Value)}
@@ -2622,9 +4417,23 @@ func (e *Evaluator) httpHandleRequest(w http.ResponseWriter, r *http.Request) {
 	}
 	reqMap.Pairs["headers"] = headersMap
 
+	paramsMap := &MapObject{Pairs: make(map[string]Value)}
+	for _, name := range params {
+		paramsMap.Pairs[name] = &String{Value: r.PathValue(name)}
+	}
+	reqMap.Pairs["params"] = paramsMap
+
+	queryMap := &MapObject{Pairs: make(map[string]Value)}
+	for key, values := range r.URL.Query() {
+		if len(values) > 0 {
+			queryMap.Pairs[key] = &String{Value: values[0]}
+		}
+	}
+	reqMap.Pairs["query"] = queryMap
+
 	// Llamar al handler de Zylo
 	args := []Value{reqMap}
-	result, err := e.callZyloFunction(e.httpHandler, args)
+	result, err := e.callZyloFunction(handler, args, nil)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Handler error: %v", err), http.StatusInternalServerError)
 		return
@@ -2665,15 +4474,46 @@ func (e *Evaluator) httpHandleRequest(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// mapToGoMap convierte un Map de Zylo a map[string]interface{}
-func (e *Evaluator) mapToGoMap(m *MapObject) map[string]interface{} {
-	result := make(map[string]interface{})
+// mapToJSONObject convierte un Map de Zylo a un objeto JSON que conserva el
+// orden de sus claves
+func (e *Evaluator) mapToJSONObject(m *MapObject) jsonObject {
+	result := jsonObject{keys: m.Keys(), values: make(map[string]interface{}, len(m.Pairs))}
 	for k, v := range m.Pairs {
-		result[k] = e.valueToInterface(v)
+		result.values[k] = e.valueToInterface(v)
 	}
 	return result
 }
 
+// jsonObject es un objeto JSON cuyas claves se serializan en el orden de keys
+// y no ordenadas alfabéticamente, como haría encoding/json con un map
+type jsonObject struct {
+	keys   []string
+	values map[string]interface{}
+}
+
+func (o jsonObject) MarshalJSON() ([]byte, error) {
+	var out bytes.Buffer
+	out.WriteByte('{')
+	for i, k := range o.keys {
+		if i > 0 {
+			out.WriteByte(',')
+		}
+		key, err := json.Marshal(k)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(o.values[k])
+		if err != nil {
+			return nil, err
+		}
+		out.Write(key)
+		out.WriteByte(':')
+		out.Write(value)
+	}
+	out.WriteByte('}')
+	return out.Bytes(), nil
+}
+
 // listToGoSlice convierte un List de Zylo a []interface{}
 func (e *Evaluator) listToGoSlice(l *List) []interface{} {
 	result := make([]interface{}, len(l.Items))
@@ -2695,7 +4535,7 @@ func (e *Evaluator) valueToInterface(v Value) interface{} {
 	case *Boolean:
 		return val.Value
 	case *MapObject:
-		return e.mapToGoMap(val)
+		return e.mapToJSONObject(val)
 	case *List:
 		return e.listToGoSlice(val)
 	case *Null:
@@ -2705,30 +4545,136 @@ func (e *Evaluator) valueToInterface(v Value) interface{} {
 	}
 }
 
+// jsonParse convierte un texto JSON en valores de Zylo
+func (e *Evaluator) jsonParse(text string) (Value, error) {
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+
+	value, err := decodeJSON(decoder)
+	if err != nil {
+		return nil, err
+	}
+	if decoder.More() {
+		return nil, fmt.Errorf("json.parse: JSON inválido: datos adicionales después del valor")
+	}
+	return value, nil
+}
+
+// decodeJSON lee el siguiente valor de decoder token a token, para que los
+// objetos conserven el orden de sus claves
+func decodeJSON(decoder *json.Decoder) (Value, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("json.parse: JSON inválido: %v", err)
+	}
+	switch val := token.(type) {
+	case json.Delim:
+		if val == '[' {
+			items := []Value{}
+			for decoder.More() {
+				item, err := decodeJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+			}
+			if _, err := decoder.Token(); err != nil {
+				return nil, fmt.Errorf("json.parse: JSON inválido: %v", err)
+			}
+			return &List{Items: items}, nil
+		}
+		// El decoder solo devuelve '{' aquí: los cierres los consume quien
+		// abrió la lista o el objeto
+		object := &MapObject{Pairs: make(map[string]Value)}
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, fmt.Errorf("json.parse: JSON inválido: %v", err)
+			}
+			item, err := decodeJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.Set(key.(string), item)
+		}
+		if _, err := decoder.Token(); err != nil {
+			return nil, fmt.Errorf("json.parse: JSON inválido: %v", err)
+		}
+		return object, nil
+	case nil:
+		return &Null{}, nil
+	case bool:
+		return &Boolean{Value: val}, nil
+	case string:
+		return &String{Value: val}, nil
+	case json.Number:
+		if i, err := val.Int64(); err == nil {
+			return &Integer{Value: i}, nil
+		}
+		f, err := val.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("json.parse: número inválido: %s", val)
+		}
+		return &Float{Value: f}, nil
+	default:
+		return nil, fmt.Errorf("json.parse: tipo no soportado: %T", token)
+	}
+}
+
+// jsonStringify serializa un valor de Zylo como JSON. Con indent > 0 el
+// resultado se formatea con esa cantidad de espacios.
+func (e *Evaluator) jsonStringify(value Value, indent int) (Value, error) {
+	if err := jsonCheckValue(value); err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	var err error
+	if indent > 0 {
+		data, err = json.MarshalIndent(e.valueToInterface(value), "", strings.Repeat(" ", indent))
+	} else {
+		data, err = json.Marshal(e.valueToInterface(value))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("json.stringify: %v", err)
+	}
+	return &String{Value: string(data)}, nil
+}
+
+// jsonCheckValue verifica que un valor pueda representarse en JSON
+func jsonCheckValue(value Value) error {
+	switch val := value.(type) {
+	case *String, *Integer, *Float, *Boolean, *Null:
+		return nil
+	case *List:
+		for _, item := range val.Items {
+			if err := jsonCheckValue(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *MapObject:
+		for _, item := range val.Pairs {
+			if err := jsonCheckValue(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("json.stringify: no se puede serializar un valor de tipo %T", value)
+	}
+}
+
 // httpGetAsync realiza una petición GET asíncrona
 func (e *Evaluator) httpGetAsync(url string, headers map[string]string, timeout int) *Future {
-	future := &Future{
-		Result: make(chan ZyloObject, 1),
-		value:  nil,
-		once:   false,
-	}
-	go func() {
-		result, _ := e.httpGet(url, headers, timeout)
-		future.Result <- result.(ZyloObject)
-	}()
-	return future
+	return newFuture(func() (Value, error) {
+		return e.httpGet(url, headers, timeout)
+	})
 }
 
 // httpPostJSONAsync realiza una petición POST JSON asíncrona
 func (e *Evaluator) httpPostJSONAsync(url string, data Value, headers map[string]string, timeout int) *Future {
-	future := &Future{
-		Result: make(chan ZyloObject, 1),
-		value:  nil,
-		once:   false,
-	}
-	go func() {
-		result, _ := e.httpPostJSON(url, data, headers, timeout)
-		future.Result <- result.(ZyloObject)
-	}()
-	return future
+	return newFuture(func() (Value, error) {
+		return e.httpPostJSON(url, data, headers, timeout)
+	})
 }
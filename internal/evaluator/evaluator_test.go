@@ -1,8 +1,14 @@
 package evaluator
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
 	"testing"
+	"time"
 	"github.com/zylo-lang/zylo/internal/lexer"
 	"github.com/zylo-lang/zylo/internal/parser"
 )
@@ -49,6 +55,65 @@ NOMBRE = "Pedro";
 	}
 }
 
+func TestConstantMutationThroughIndexAndDot(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"LISTA := [1, 2, 3]\nLISTA[0] = 10", "no se puede reasignar constante: LISTA"},
+		{"LISTA := [1, 2, 3]\nLISTA[1] += 1", "no se puede reasignar constante: LISTA"},
+		{"MATRIZ := [[1], [2]]\nMATRIZ[0][0] = 5", "no se puede reasignar constante: MATRIZ"},
+		{"CONFIG := json.parse(\"{\\\"a\\\": 1}\")\nCONFIG[\"a\"] = 2", "no se puede reasignar constante: CONFIG"},
+		{"class Punto {\n}\nORIGEN := Punto()\nORIGEN.x = 1", "no se puede reasignar constante: ORIGEN"},
+	}
+
+	for _, tt := range tests {
+		testEvalErrorExact(t, tt.input, tt.expected)
+	}
+
+	// Las variables no constantes siguen siendo mutables
+	testObjectLiteral(t, testEval("lista := [1, 2, 3]\nlista[0] = 10\nlista[0]"), 10)
+}
+
+func TestCompoundAssignMissingMapKey(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"m := {\"a\": 1}\nm[\"b\"] += 2\nm[\"b\"]", 2},
+		{"m := {\"a\": 1}\nm[\"b\"] -= 2\nm[\"b\"]", -2},
+		{"m := {\"a\": 1}\nm[\"b\"] *= 3\nm[\"b\"]", 0},
+		{"m := {\"a\": 1}\nm[\"b\"] += 1.5\nm[\"b\"]", 1.5},
+		{"m := {\"a\": 1}\nm[\"s\"] += \"hola\"\nm[\"s\"]", "hola"},
+		{"cuenta := {\"x\": 0}\nfor p in [\"a\", \"b\", \"a\"] {\n    cuenta[p] += 1\n}\ncuenta[\"a\"]", 2},
+		// Una clave que existe conserva su valor
+		{"m := {\"a\": 1}\nm[\"a\"] += 2\nm[\"a\"]", 3},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+
+	// Sin valor vacío para el tipo del lado derecho sigue siendo un error
+	testEvalErrorExact(t, "m := {\"a\": 1}\nm[\"b\"] += true", "clave de mapa no definida: b")
+}
+
+func TestTypedConstants(t *testing.T) {
+	testObjectLiteral(t, testEval("MAX int := 5\nMAX"), 5)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`MAX int := "x"`, "tipo incompatible: esperado int, recibido string"},
+		{"MAX int := 5\nMAX = 6", "no se puede reasignar constante: MAX"},
+	}
+
+	for _, tt := range tests {
+		testEvalErrorExact(t, tt.input, tt.expected)
+	}
+}
+
 func TestVariableReassignment(t *testing.T) {
 	input := `
 edad := 25;
@@ -59,6 +124,183 @@ edad = 30;
 	testObjectLiteral(t, evaluated, 30)
 }
 
+func TestLoopScoping(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		// Las asignaciones dentro del cuerpo cambian la variable de fuera
+		{"total := 0\ni := 0\nwhile i < 4 {\n    total = total + i\n    i = i + 1\n}\ntotal", 6},
+		{"total := 0\nfor i := 1; i <= 3; i = i + 1 {\n    total = total + i\n}\ntotal", 6},
+		// Cada iteración empieza con un scope nuevo
+		{"vistos := 0\ni := 0\nwhile i < 3 {\n    if i > 0 and vistos == 0 {\n        vistos = 1\n    }\n    x := i\n    i = i + 1\n}\nvistos", 1},
+		// Las funciones creadas en el cuerpo capturan el valor de su iteración
+		{"fs := []\nfor n in [1, 2, 3] {\n    doble := n * 2\n    func leer() {\n        return doble\n    }\n    fs.append(leer)\n}\nfs[0]() + fs[2]()", 8},
+		// break, continue y return atraviesan los bloques anidados
+		{"s := 0\nfor i := 0; i < 10; i = i + 1 {\n    if i % 2 == 0 {\n        continue\n    }\n    if i > 6 {\n        break\n    }\n    s = s + i\n}\ns", 9},
+		{"i := 0\nwhile true {\n    i = i + 1\n    if i == 5 {\n        break\n    }\n}\ni", 5},
+		{"func buscar(l, x) {\n    i := 0\n    while i < len(l) {\n        if l[i] == x {\n            return i\n        }\n        i = i + 1\n    }\n    return -1\n}\nbuscar([4, 5, 6], 6)", 2},
+		{"func primero(l) {\n    for x in l {\n        return x\n    }\n    return 0\n}\nprimero([7, 8])", 7},
+		{"func cuenta() {\n    for i := 0; i < 10; i = i + 1 {\n        if i == 3 {\n            return i\n        }\n    }\n    return -1\n}\ncuenta()", 3},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestForStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"s := 0\nfor i := 0; i < 5; i += 1 {\n    s = s + i\n}\ns", 10},
+		// Cada parte es opcional
+		{"i := 0\nfor ; i < 3; i = i + 1 {\n}\ni", 3},
+		{"n := 0\nfor j := 0; j < 4; {\n    j = j + 2\n    n = n + 1\n}\nn", 2},
+		{"n := 0\nfor ;; {\n    n = n + 1\n    if n == 3 {\n        break\n    }\n}\nn", 3},
+		// continue sigue ejecutando Post, así que el bucle avanza
+		{"s := 0\nfor i := 0; i < 6; i = i + 1 {\n    if i % 2 == 1 {\n        continue\n    }\n    s = s + i\n}\ns", 6},
+		// break y continue solo afectan al bucle más interno
+		{`pares := ""
+for a := 0; a < 3; a = a + 1 {
+    for b := 0; b < 3; b = b + 1 {
+        if b == a {
+            continue
+        }
+        if b > 1 {
+            break
+        }
+        pares = pares + a + b + " "
+    }
+}
+pares`, "01 10 20 21 "},
+		{`n := 0
+for a := 0; a < 3; a = a + 1 {
+    i := 0
+    while true {
+        i = i + 1
+        if i > a {
+            break
+        }
+        n = n + 1
+    }
+}
+n`, 3},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestRepeatStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"n := 0\nrepeat 4 {\n    n = n + 1\n}\nn", 4},
+		{"n := 0\nrepeat 0 {\n    n = n + 1\n}\nn", 0},
+		// La cuenta se evalúa una sola vez
+		{"k := 3\nn := 0\nrepeat k {\n    k = k + 1\n    n = n + 1\n}\nn", 3},
+		{"s := 0\ni := 0\nrepeat 10 {\n    i = i + 1\n    if i % 2 == 0 {\n        continue\n    }\n    if i > 6 {\n        break\n    }\n    s = s + i\n}\ns", 9},
+		{"n := 0\nrepeat 2 {\n    repeat 3 {\n        n = n + 1\n    }\n}\nn", 6},
+		{"func f() {\n    repeat 5 {\n        return 7\n    }\n    return 0\n}\nf()", 7},
+		// Cada vuelta empieza con un scope nuevo
+		{"n := 0\nrepeat 3 {\n    x := n\n    n = n + 1\n}\nn", 3},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+
+	errorTests := []struct {
+		input       string
+		expectedErr string
+	}{
+		{"repeat \"3\" {\n}", "repeat espera un entero, recibido string"},
+		{"repeat -1 {\n}", "repeat espera un entero no negativo, recibido -1"},
+	}
+	for _, tt := range errorTests {
+		testEvalError(t, tt.input, tt.expectedErr)
+	}
+}
+
+func TestOptionalDotExpression(t *testing.T) {
+	user := "user := {\"name\": \"Ana\", \"address\": {\"city\": \"Lima\"}}\nnadie := nil\n"
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{user + "user?.address?.city", "Lima"},
+		{user + "nadie?.address?.city", nil},
+		{user + "user?.phone?.number", nil},
+		{user + "user?.[\"name\"]", "Ana"},
+		{user + "nadie?.[\"name\"]", nil},
+		{user + "user?.[\"phone\"]", nil},
+		{user + "user?.keys().length", 2},
+		{user + "nadie?.keys()", nil},
+		{"l := [1, 2]\nl?.length + l?.[1]", 4},
+		// La parte izquierda se evalúa una sola vez
+		{"n := 0\nfunc f() {\n    n = n + 1\n    return {\"a\": 1}\n}\nf()?.a + n", 2},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if tt.expected == nil {
+			if _, ok := evaluated.(*Null); !ok {
+				t.Errorf("%q: se esperaba null, obtenido %T (%+v)", tt.input, evaluated, evaluated)
+			}
+			continue
+		}
+		testObjectLiteral(t, evaluated, tt.expected)
+	}
+
+	// Con un objeto que no es null, una propiedad que no existe sigue siendo un error
+	testEvalError(t, "class P {\n    func init() {\n        this.n = 1\n    }\n}\nP()?.x", "property 'x' not found")
+}
+
+func TestLoopVariablesDoNotLeak(t *testing.T) {
+	tests := []struct {
+		input    string
+		variable string
+	}{
+		{"i := 0\nwhile i < 2 {\n    dentro := i\n    i = i + 1\n}\ndentro", "dentro"},
+		{"for i := 0; i < 2; i = i + 1 {\n}\ni", "i"},
+		{"for x in [1, 2] {\n}\nx", "x"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, "variable no definida: "+tt.variable)
+	}
+}
+
+func TestElifChains(t *testing.T) {
+	chain := "r := \"\"\nif x < 3 {\n    r = \"a\"\n} elif x < 6 {\n    r = \"b\"\n} else if x < 9 {\n    r = \"c\"\n} else {\n    r = \"d\"\n}\nr"
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"x := 1\n" + chain, "a"},
+		{"x := 4\n" + chain, "b"},
+		{"x := 7\n" + chain, "c"},
+		{"x := 10\n" + chain, "d"},
+		// Sin else, ninguna rama se ejecuta
+		{"r := 0\nif false {\n    r = 1\n} elif false {\n    r = 2\n}\nr", 0},
+		// Solo se evalúan las condiciones hasta la primera verdadera
+		{"n := 0\nfunc paso() {\n    n = n + 1\n    return true\n}\nif paso() {\n} elif paso() {\n}\nn", 1},
+		// Una variable declarada en una rama oculta a la de fuera solo en esa rama
+		{"y := 1\nif false {\n} elif true {\n    y := 2\n}\ny", 1},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+
+	// Las variables de una rama elif no existen fuera de ella
+	testEvalError(t, "if false {\n} elif true {\n    dentro := 1\n}\ndentro", "variable no definida: dentro")
+}
+
 func TestTypedVariables(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -116,42 +358,2018 @@ func TestTypeErrors(t *testing.T) {
 	}
 }
 
-func testEval(input string) Value {
-	eval := NewEvaluator()
-	l := lexer.New(input)
-	p := parser.New(l)
-	program := p.ParseProgram()
+func TestStringComparison(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`"apple" < "banana"`, true},
+		{`"banana" > "apple"`, true},
+		{`"apple" > "banana"`, false},
+		{`"zylo" <= "zylo"`, true},
+		{`"zylo" >= "zylo"`, true},
+		{`"zylo" < "zylo"`, false},
+		{`"zy" < "zylo"`, true},
+		{`"zylo" > "zy"`, true},
+		{`"zylo" <= "zy"`, false},
+		{`"" < "a"`, true},
+		{`"" <= ""`, true},
+		{`"" > ""`, false},
+		{`"a" >= ""`, true},
+		{`"Z" < "a"`, true},
+	}
 
-	if len(p.Errors()) > 0 {
-		panic("Parser errors: " + fmt.Sprintf("%v", p.Errors()))
+	for _, tt := range tests {
+		result, ok := testEval(tt.input).(*Boolean)
+		if !ok {
+			t.Errorf("%s: el resultado no es Boolean", tt.input)
+			continue
+		}
+		if result.Value != tt.expected {
+			t.Errorf("%s: esperado %t, obtenido %t", tt.input, tt.expected, result.Value)
+		}
 	}
+}
 
-	var lastValue Value = &Null{}
-	for _, stmt := range program.Statements {
-		value, err := eval.evaluateStatement(stmt)
-		if err != nil {
-			panic("Evaluation error: " + err.Error())
+func TestDeepEquality(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{`[1, 2, 3] == [1, 2, 3]`, true},
+		{`[1, 2, 3] == [1, 2]`, false},
+		{`[1, 2, 3] != [1, 2, 4]`, true},
+		{`[1, "a", true] == [1.0, "a", true]`, true},
+		{`[[1, [2]], []] == [[1, [2]], []]`, true},
+		{`[[1, [2]]] == [[1, [3]]]`, false},
+		{`[] == []`, true},
+		{`[1] == 1`, false},
+		{`json.parse("{\"a\": [1, {\"b\": 2}]}") == json.parse("{\"a\": [1, {\"b\": 2}]}")`, true},
+		{`json.parse("{\"a\": 1}") == json.parse("{\"a\": 1, \"b\": 2}")`, false},
+		{`json.parse("{\"a\": 1}") != json.parse("{\"b\": 1}")`, true},
+		{`json.parse("{\"a\": [1]}") == json.parse("{\"a\": [2]}")`, false},
+		{`nil == nil`, true},
+		{`nil != nil`, false},
+		{`nil == 0`, false},
+		{`false == nil`, false},
+		{`[1, nil] == [1, nil]`, true},
+		{"r := \"\"\nswitch nil {\ncase 0:\n    r = \"cero\"\ncase nil:\n    r = \"nil\"\ndefault:\n    r = \"otro\"\n}\nr == \"nil\"", true},
+		{"func f(v) {\n    match v {\n    case nil:\n        return \"nil\"\n    case _:\n        return \"otro\"\n    }\n}\nf(nil) == \"nil\" and f(0) == \"otro\"", true},
+	}
+
+	for _, tt := range tests {
+		result, ok := testEval(tt.input).(*Boolean)
+		if !ok {
+			t.Errorf("%s: el resultado no es Boolean", tt.input)
+			continue
 		}
-		if value != nil {
-			lastValue = value
+		if result.Value != tt.expected {
+			t.Errorf("%s: esperado %v, obtenido %v", tt.input, tt.expected, result.Value)
 		}
 	}
-	return lastValue
 }
 
-func testObjectLiteral(t *testing.T, obj Value, expected interface{}) bool {
-	switch expected := expected.(type) {
-	case int:
-		return testIntegerObject(t, obj, int64(expected))
-	case int64:
-		return testIntegerObject(t, obj, expected)
-	case float64:
-		return testFloatObject(t, obj, expected)
-	case string:
-		return testStringObject(t, obj, expected)
+func TestDeepEqualityCycles(t *testing.T) {
+	a := &List{Items: []Value{&Integer{Value: 1}}}
+	a.Items = append(a.Items, a)
+	b := &List{Items: []Value{&Integer{Value: 1}}}
+	b.Items = append(b.Items, b)
+	if !valuesEqual(a, b) {
+		t.Errorf("las listas cíclicas con la misma forma deben ser iguales")
 	}
-	t.Errorf("type of expected not handled. got=%T", expected)
-	return false
+
+	m := &MapObject{Pairs: map[string]Value{"x": &Integer{Value: 1}}}
+	m.Pairs["self"] = m
+	n := &MapObject{Pairs: map[string]Value{"x": &Integer{Value: 2}}}
+	n.Pairs["self"] = n
+	if valuesEqual(m, n) {
+		t.Errorf("los mapas cíclicos con valores distintos no deben ser iguales")
+	}
+}
+
+func TestMemoize(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`
+calls := 0
+func fib(n) {
+    calls = calls + 1
+    if n < 2 {
+        return n
+    }
+    return fib(n - 1) + fib(n - 2)
+}
+fib = memoize(fib)
+fib(30)`, 832040},
+		{`
+calls := 0
+func fib(n) {
+    calls = calls + 1
+    if n < 2 {
+        return n
+    }
+    return fib(n - 1) + fib(n - 2)
+}
+fib = memoize(fib)
+fib(30)
+fib(30)
+calls`, 31},
+		{`
+calls := 0
+func total(xs) {
+    calls = calls + 1
+    return len(xs)
+}
+total = memoize(total)
+total([1, [2, 3]])
+total([1, [2, 3]])
+total([1, [2, 4]])
+total(["1", [2, 3]])
+calls`, 3},
+		{`
+calls := 0
+func id(x) {
+    calls = calls + 1
+    return x
+}
+id = memoize(id)
+id(json.parse("{\"a\": 1, \"b\": 2}"))
+id(json.parse("{\"b\": 2, \"a\": 1}"))
+id(1)
+id(1.0)
+calls`, 3},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestComposeAndPartial(t *testing.T) {
+	functions := `
+func double(x) {
+    return x * 2
+}
+func inc(x) {
+    return x + 1
+}
+func sub(a, b) {
+    return a - b
+}
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"h := compose(double, inc)\nh(5)", 12},
+		{"h := compose(inc, double)\nh(5)", 11},
+		{"from10 := partial(sub, 10)\nfrom10(3)", 7},
+		{"always := partial(sub, 10, 4)\nalways()", 6},
+		{"same := partial(sub)\nsame(9, 4)", 5},
+		{"h := compose(partial(sub, 100), double)\nh(5)", 90},
+		{"h := compose(len, partial(split, \"a,b,c\"))\nh(\",\")", 3},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(functions+tt.input), tt.expected)
+	}
+}
+
+func TestComposeAndPartialErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`compose(1, len)`, "compose() espera funciones, obtenido *evaluator.Integer"},
+		{`compose(len)`, "compose() espera 2 argumentos"},
+		{`partial("x", 1)`, "partial() espera una función, obtenido *evaluator.String"},
+		{`partial()`, "partial() espera al menos 1 argumento"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
+func TestDefaultParameters(t *testing.T) {
+	functions := `
+func greet(name, greeting = "Hello") {
+    return greeting + ", " + name
+}
+prefijo := ">"
+func etiqueta(texto, marca = prefijo, fin = marca) {
+    return marca + texto + fin
+}
+class Contador {
+    func init(inicio = 10) {
+        this.valor = inicio
+    }
+    func sumar(n = 1) {
+        this.valor = this.valor + n
+        return this.valor
+    }
+}
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`greet("Ana")`, "Hello, Ana"},
+		{`greet("Ana", "Hola")`, "Hola, Ana"},
+		{`etiqueta("x")`, ">x>"},
+		{`etiqueta("x", "[", "]")`, "[x]"},
+		// marca usa el parámetro anterior
+		{`etiqueta("x", "*")`, "*x*"},
+		{"c := Contador()\nc.sumar()", 11},
+		{"c := Contador(1)\nc.sumar(5)", 6},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(functions+tt.input), tt.expected)
+	}
+
+	// Los valores por defecto se evalúan en cada llamada, en la clausura
+	testObjectLiteral(t, testEval(functions+"prefijo = \"#\"\netiqueta(\"x\")"), "#x#")
+}
+
+func TestNamedArguments(t *testing.T) {
+	functions := `
+func etiqueta(texto, marca = "[", fin = "]") {
+    return marca + texto + fin
+}
+func unir(sep, ...partes) {
+    return join(partes, sep)
+}
+class Punto {
+    func init(x = 0, y = 0) {
+        this.x = x
+        this.y = y
+    }
+    func mover(dx = 0, dy = 0) {
+        return Punto(x: this.x + dx, y: this.y + dy)
+    }
+}
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`etiqueta(texto: "x")`, "[x]"},
+		// Los parámetros omitidos toman su valor por defecto
+		{`etiqueta("x", fin: ">")`, "[x>"},
+		{`etiqueta(fin: ")", marca: "(", texto: "x")`, "(x)"},
+		{`unir("-", "a", "b")`, "a-b"},
+		{`unir(sep: "-")`, ""},
+		{"p := Punto(y: 5)\np.x * 10 + p.y", 5},
+		{"p := Punto(1, 2).mover(dy: 3)\np.x * 10 + p.y", 15},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(functions+tt.input), tt.expected)
+	}
+}
+
+func TestNamedArgumentErrors(t *testing.T) {
+	functions := `
+func etiqueta(texto, marca = "[") {
+    return marca + texto
+}
+func unir(sep, ...partes) {
+    return join(partes, sep)
+}
+class Vacia {
+}
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`etiqueta(texto: "x", color: "rojo")`, "parámetro desconocido: color"},
+		{`etiqueta("x", texto: "y")`, "el argumento texto se pasó por posición y por nombre"},
+		{`unir(sep: "-", partes: ["a"])`, "el parámetro variádico partes no se puede pasar por nombre"},
+		{`len(x: [1])`, "len no admite argumentos con nombre"},
+		{`Vacia(x: 1)`, "parámetro desconocido: x"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, functions + tt.input, tt.expected)
+	}
+}
+
+func TestVariadicParameters(t *testing.T) {
+	functions := `
+func contar(...items) {
+    return len(items)
+}
+func unir(sep, ...partes) {
+    return join(partes, sep)
+}
+func primero(a, b = "b", ...resto) {
+    return a + b + len(resto)
+}
+func sumar(...nums) {
+    total := 0
+    for n in nums {
+        total = total + n
+    }
+    return total
+}
+class Registro {
+    func init(...entradas) {
+        this.entradas = entradas
+    }
+    func agregar(...mas) {
+        return len(this.entradas) + len(mas)
+    }
+}
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"contar()", 0},
+		{"contar(1, 2, 3)", 3},
+		{`unir("-", "a", "b", "c")`, "a-b-c"},
+		{`unir(",")`, ""},
+		{`primero("a")`, "ab0"},
+		{`primero("a", "x", 1, 2)`, "ax2"},
+		{"sumar(1, 2, 3, 4)", 10},
+		{"r := Registro(1, 2)\nr.agregar(3, 4, 5)", 5},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(functions+tt.input), tt.expected)
+	}
+}
+
+func TestMissingArgumentError(t *testing.T) {
+	testEvalErrorExact(t, "func f(a, b = 2) {\n    return a + b\n}\nf()", "falta el argumento a")
+}
+
+const displayClasses = `
+class Punto {
+    func init(x, y) {
+        this.x = x
+        this.y = y
+    }
+    func to_string() {
+        return "(" + this.x + ", " + this.y + ")"
+    }
+}
+class Punto3 extends Punto {
+    func init(x, y) {
+        this.x = x
+        this.y = y
+    }
+}
+class Nombre {
+    func str() {
+        return "nombre"
+    }
+}
+class Nada {
+}
+`
+
+func TestInstanceToString(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"p = " + Punto(1, 2)`, "p = (1, 2)"},
+		{`Punto(1, 2) + "!"`, "(1, 2)!"},
+		{`"" + Punto3(3, 4)`, "(3, 4)"},
+		{`"" + Nombre()`, "nombre"},
+		{`"" + Nada()`, "instance of Nada"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(displayClasses+tt.input), tt.expected)
+	}
+}
+
+func TestInstanceToStringBuiltins(t *testing.T) {
+	eval := NewEvaluator()
+	p := parser.New(lexer.New(displayClasses + "p := Punto(1, 2)\nn := Nada()"))
+	if err := eval.EvaluateProgram(p.ParseProgram()); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	stringFn, _ := eval.env.Get("string")
+	for name, expected := range map[string]string{"p": "(1, 2)", "n": "instance of Nada"} {
+		instance, _ := eval.env.Get(name)
+		result, err := stringFn.(*BuiltinFunction).Fn([]Value{instance})
+		if err != nil {
+			t.Fatalf("string(%s): error inesperado: %v", name, err)
+		}
+		testStringObject(t, result, expected)
+
+		text, err := eval.displayString(instance)
+		if err != nil || text != expected {
+			t.Errorf("show.log(%s): esperado %q, obtenido %q (%v)", name, expected, text, err)
+		}
+	}
+}
+
+func TestDecorators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`
+calls := 0
+@memoize
+func fib(n) {
+    calls = calls + 1
+    if n < 2 {
+        return n
+    }
+    return fib(n - 1) + fib(n - 2)
+}
+fib(30)
+calls`, 31},
+		{`
+func twice(f) {
+    func wrapper(x) {
+        return f(f(x))
+    }
+    return wrapper
+}
+@twice
+func inc(x) {
+    return x + 1
+}
+inc(1)`, 3},
+		{`
+order := ""
+func mark(name) {
+    func deco(f) {
+        order = order + name
+        return f
+    }
+    return deco
+}
+@mark("a")
+@mark("b")
+func f() {
+    return 1
+}
+order`, "ba"},
+		{`
+registro := []
+func registrar(c) {
+    registro = append(registro, c)
+    return c
+}
+@registrar
+class Usuario {
+}
+len(registro)`, 1},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+const superClasses = `
+class A {
+    func init(x) {
+        this.x = x
+    }
+    func foo() {
+        return "A"
+    }
+    func bar() {
+        return "A.bar"
+    }
+}
+class B extends A {
+    func init(x) {
+        super.init(x + 1)
+    }
+    func foo() {
+        return "B>" + super.foo()
+    }
+    func baz() {
+        return super.bar()
+    }
+}
+class C extends B {
+    func init(x) {
+        super.init(x * 10)
+    }
+    func foo() {
+        return "C>" + super.foo()
+    }
+    func bar() {
+        return "C.bar"
+    }
+}
+`
+
+func TestSuperMethodResolution(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"C(1).foo()", "C>B>A"},
+		{"B(1).foo()", "B>A"},
+		{"C(1).x", 11},
+		{"B(1).x", 2},
+		// baz está definido en B, así que su super es A aunque la instancia sea C
+		{"C(1).baz()", "A.bar"},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(superClasses+tt.input), tt.expected)
+	}
+}
+
+func TestPipeOperator(t *testing.T) {
+	functions := `
+func double(x) {
+    return x * 2
+}
+func sub(a, b) {
+    return a - b
+}
+func concat(a, b, c) {
+    return a + b + c
+}
+func f(x, y = 10, z = 1) {
+    return x + y + z
+}
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"5 |> double", 10},
+		{"10 |> sub(3)", 7},
+		{"5 |> double |> sub(4) |> double", 12},
+		{`"a" |> concat("b", "c")`, "abc"},
+		{"[3, 1, 2] |> sort |> len", 3},
+		{"r := 1 + 2 |> double\nr", 6},
+		{"5 |> f(z: 100)", 115},
+		{"5 |> f(1, z: 100)", 106},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(functions+tt.input), tt.expected)
+	}
+}
+
+func TestListSpliceAndFill(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"xs := [1, 2, 3, 4, 5]\nr := xs.splice(1, 2)\nstring_list([r, xs])", "[[2, 3], [1, 4, 5]]"},
+		{"xs := [1, 2, 3]\nr := xs.splice(1, 0, 8, 9)\nstring_list([r, xs])", "[[], [1, 8, 9, 2, 3]]"},
+		{"xs := [1, 2, 3, 4]\nr := xs.splice(1, 2, 0)\nstring_list([r, xs])", "[[2, 3], [1, 0, 4]]"},
+		{"xs := [1, 2, 3, 4]\nr := xs.splice(2)\nstring_list([r, xs])", "[[3, 4], [1, 2]]"},
+		{"xs := [1, 2]\nxs.splice(2, 0, 3)\nstring_list(xs)", "[1, 2, 3]"},
+		{"xs := [0, 0, 0, 0]\nxs.fill(7, 1, 3)\nstring_list(xs)", "[0, 7, 7, 0]"},
+		{"xs := [0, 0, 0]\nxs.fill(1)\nstring_list(xs)", "[1, 1, 1]"},
+		{"xs := [0, 0, 0]\nxs.fill(1, 2)\nstring_list(xs)", "[0, 0, 1]"},
+		{"xs := [0, 0]\nxs.fill(1, 1, 1)\nstring_list(xs)", "[0, 0]"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestListSpliceAndFillBounds(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[1, 2].splice(3)", "splice(): start fuera de rango: 3"},
+		{"[1, 2].splice(-1)", "splice(): start fuera de rango: -1"},
+		{"[1, 2].splice(1, 2)", "splice(): deleteCount fuera de rango: 2"},
+		{"[1, 2].splice(0, -1)", "splice(): deleteCount fuera de rango: -1"},
+		{"[1, 2].splice()", "splice() espera al menos 1 argumento"},
+		{"[1, 2].fill(0, 0, 3)", "fill(): end fuera de rango: 3"},
+		{"[1, 2].fill(0, 2, 1)", "fill(): start (2) mayor que end (1)"},
+		{`[1, 2].fill(0, "a")`, "fill() espera un entero como start"},
+	}
+
+	for _, tt := range tests {
+		testEvalErrorExact(t, tt.input, tt.expected)
+	}
+}
+
+func TestListMethods(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"xs := [1, 2, 3]\nr := xs.pop()\nstring_list([r, xs])", "[3, [1, 2]]"},
+		{"xs := [1, 2, 3]\nr := xs.pop(0)\nstring_list([r, xs])", "[1, [2, 3]]"},
+		{"xs := [1, 3]\nxs.insert(1, 2)\nstring_list(xs)", "[1, 2, 3]"},
+		{"xs := [2]\nxs.insert(0, 1)\nxs.insert(2, 3)\nstring_list(xs)", "[1, 2, 3]"},
+		// remove quita solo la primera aparición
+		{"xs := [1, 2, 1]\nr := xs.remove(1)\nstring_list([r, xs])", "[true, [2, 1]]"},
+		{"xs := [1, 2]\nr := xs.remove(5)\nstring_list([r, xs])", "[false, [1, 2]]"},
+		{"xs := [[1], [2]]\nxs.remove([2])\nstring_list(xs)", "[[1]]"},
+		{"string_list([[\"a\", \"b\"].indexOf(\"b\"), [1].indexOf(2), [1, 2.0].indexOf(2)])", "[1, -1, 1]"},
+		{"string_list([[1, 2].contains(2), [1, 2].contains(\"2\")])", "[true, false]"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestListMethodErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[].pop()", "pop(): la lista está vacía"},
+		{"[1, 2].pop(2)", "pop(): index fuera de rango: 2"},
+		{"[1, 2].pop(-1)", "pop(): index fuera de rango: -1"},
+		{`[1].pop("0")`, "pop() espera un entero como index"},
+		{"[1].insert(2, 0)", "insert(): index fuera de rango: 2"},
+		{"[1].insert(0)", "insert() espera 2 argumentos"},
+		{"[1].remove()", "remove() espera 1 argumento"},
+		{"[1].indexOf(1, 2)", "indexOf() espera 1 argumento"},
+	}
+
+	for _, tt := range tests {
+		testEvalErrorExact(t, tt.input, tt.expected)
+	}
+}
+
+func TestIndexBounds(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"lista := [1, 2, 3]\nlista[3]", "array index out of bounds: 3 (length: 3)"},
+		{"lista := [1, 2, 3]\nlista[-1]", "array index cannot be negative: -1"},
+		{"lista := []\nlista[0]", "array index out of bounds: 0 (length: 0)"},
+		{"lista := [1, 2]\nlista[2] = 5", "array index out of bounds: 2 (length: 2)"},
+		{"lista := [1, 2]\nlista[-3] += 1", "array index cannot be negative: -3"},
+		{`s := "hola"` + "\ns[4]", "string index out of bounds: 4 (length: 4)"},
+		{`s := "hola"` + "\ns[-2]", "string index cannot be negative: -2"},
+	}
+
+	for _, tt := range tests {
+		testEvalErrorExact(t, tt.input, tt.expected)
+	}
+
+	// Los extremos válidos siguen funcionando
+	testObjectLiteral(t, testEval("lista := [1, 2, 3]\nlista[0] + lista[2]"), 4)
+	testObjectLiteral(t, testEval(`s := "hola"`+"\ns[3]"), "a")
+}
+
+func TestDeepMerge(t *testing.T) {
+	base := `base := json.parse("{\"db\": {\"host\": \"localhost\", \"port\": 5432, \"opts\": {\"ssl\": false}}, \"tags\": [\"a\"], \"debug\": false}")
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{
+			`json.stringify(deep_merge(base, json.parse("{\"db\": {\"port\": 6543, \"opts\": {\"timeout\": 5}}, \"debug\": true}")))`,
+			`{"db":{"host":"localhost","port":6543,"opts":{"ssl":false,"timeout":5}},"tags":["a"],"debug":true}`,
+		},
+		// Las listas se reemplazan y los escalares sustituyen a los mapas
+		{
+			`json.stringify(deep_merge(base, json.parse("{\"tags\": [\"b\"], \"db\": null}")))`,
+			`{"db":null,"tags":["b"],"debug":false}`,
+		},
+		{
+			`json.stringify(deep_merge(base, json.parse("{\"cache\": {\"ttl\": 60}}")))`,
+			`{"db":{"host":"localhost","port":5432,"opts":{"ssl":false}},"tags":["a"],"debug":false,"cache":{"ttl":60}}`,
+		},
+		// Los argumentos no se modifican
+		{
+			"merged := deep_merge(base, json.parse(\"{\\\"db\\\": {\\\"port\\\": 1}}\"))\njson.stringify(base)",
+			`{"db":{"host":"localhost","port":5432,"opts":{"ssl":false}},"tags":["a"],"debug":false}`,
+		},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(base+tt.input), tt.expected)
+	}
+}
+
+func TestGetAndSetPath(t *testing.T) {
+	data := `data := json.parse("{\"users\": [{\"name\": \"ana\", \"tags\": [\"a\", \"b\"]}, {\"name\": \"luis\"}], \"meta\": {\"count\": 2}}")
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`get_path(data, "meta.count")`, 2},
+		{`get_path(data, "users.0.name")`, "ana"},
+		{`get_path(data, "users.0.tags.1")`, "b"},
+		{`get_path(data, "users.1.name")`, "luis"},
+		{`json.stringify(get_path(data, "users.5.name"))`, "null"},
+		{`json.stringify(get_path(data, "meta.missing.deep"))`, "null"},
+		{`json.stringify(get_path(data, "users.x"))`, "null"},
+		{`json.stringify(get_path(data, "meta"))`, `{"count":2}`},
+		{`json.stringify(set_path(data, "meta.count", 3))`, `{"users":[{"name":"ana","tags":["a","b"]},{"name":"luis"}],"meta":{"count":3}}`},
+		{`json.stringify(get_path(set_path(data, "users.0.tags.0", "z"), "users.0.tags"))`, `["z","b"]`},
+		{`json.stringify(get_path(set_path(data, "users.1.age", 30), "users.1"))`, `{"name":"luis","age":30}`},
+		// set_path devuelve una copia: data no cambia
+		{"copy := set_path(data, \"users.0.name\", \"eva\")\nget_path(data, \"users.0.name\")", "ana"},
+		{"copy := set_path(data, \"users.0.name\", \"eva\")\nget_path(copy, \"users.0.name\")", "eva"},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(data+tt.input), tt.expected)
+	}
+}
+
+func TestSetPathErrors(t *testing.T) {
+	data := `data := json.parse("{\"users\": [{\"name\": \"ana\"}]}")
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`set_path(data, "users.3.name", 1)`, "set_path: index 3 out of range in path 'users.3.name'"},
+		{`set_path(data, "users.first", 1)`, "set_path: 'first' is not a list index in path 'users.first'"},
+		{`set_path(data, "meta.count", 1)`, "set_path: key 'meta' not found in path 'meta.count'"},
+		{`set_path(data, "users.0.name.x", 1)`, "set_path: cannot traverse 'x' in path 'users.0.name.x'"},
+	}
+
+	for _, tt := range tests {
+		testEvalErrorExact(t, data + tt.input, tt.expected)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	schema := `schema := json.parse("{\"name\": \"string\", \"age\": \"int\", \"tags\": [\"string\"], \"email\": \"string?\"}")
+`
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		// Objeto válido: sin errores
+		{
+			`json.stringify(validate(json.parse("{\"name\": \"ana\", \"age\": 30, \"tags\": [\"a\", \"b\"]}"), schema))`,
+			`[]`,
+		},
+		// Campo obligatorio ausente
+		{
+			`json.stringify(validate(json.parse("{\"name\": \"ana\", \"tags\": []}"), schema))`,
+			`["value.age: missing field"]`,
+		},
+		// Campo con tipo incorrecto, también dentro de listas
+		{
+			`json.stringify(validate(json.parse("{\"name\": 1, \"age\": \"30\", \"tags\": [\"a\", true]}"), schema))`,
+			`["value.age: expected int, got string","value.name: expected string, got int","value.tags.1: expected string, got bool"]`,
+		},
+		// Los campos opcionales aceptan null, pero no otro tipo
+		{
+			`json.stringify(validate(json.parse("{\"name\": \"ana\", \"age\": 1, \"tags\": [], \"email\": null}"), schema))`,
+			`[]`,
+		},
+		{
+			`json.stringify(validate(json.parse("{\"name\": \"ana\", \"age\": 1, \"tags\": [], \"email\": 5}"), schema))`,
+			`["value.email: expected string, got int"]`,
+		},
+		{
+			`json.stringify(validate("texto", schema))`,
+			`["value: expected map, got string"]`,
+		},
+		{`json.stringify(validate(2.5, "number"))`, `[]`},
+		{`json.stringify(validate(null, "any"))`, `[]`},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(schema+tt.input), tt.expected)
+	}
+}
+
+func TestValidateInvalidSchema(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`validate(1, "entero")`, "validate: unknown type 'entero' in schema at 'value'"},
+		{`validate([1], ["int", "string"])`, "validate: list schema at 'value' must have exactly 1 element, got 2"},
+		{`validate(1, 5)`, "validate: invalid schema at 'value': INTEGER"},
+	}
+
+	for _, tt := range tests {
+		testEvalErrorExact(t, tt.input, tt.expected)
+	}
+}
+
+func TestLocaleNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`parse_number("1.234,56", "de")`, 1234.56},
+		{`parse_number("1,234.56", "en")`, 1234.56},
+		{`parse_number("1.234,56", "de") == parse_number("1,234.56", "en-US")`, true},
+		{`parse_number("1.000.000", "es_ES")`, 1000000},
+		{`parse_number("-3,5", "fr")`, -3.5},
+		{`parse_number("1 234,5", "fr")`, 1234.5},
+		{`format_number(1234567, "en")`, "1,234,567"},
+		{`format_number(1234.56, "de")`, "1.234,56"},
+		{`format_number(-999, "de")`, "-999"},
+		{`format_number(-1000.5, "en")`, "-1,000.5"},
+		{`parse_number("1'234.5", "de-CH")`, 1234.5},
+		{`format_number(1234567, "de_CH")`, "1'234'567"},
+		{`format_number(1234.5, "de-AT")`, "1.234,5"},
+		{`equals_ignore_case("Hola", "hOLA")`, true},
+		{`equals_ignore_case("straße", "STRASSE")`, true},
+		{`equals_ignore_case("Ñandú", "ñANDÚ")`, true},
+		{`equals_ignore_case("a", "b")`, false},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if b, ok := tt.expected.(bool); ok {
+			if got, isBool := evaluated.(*Boolean); !isBool || got.Value != b {
+				t.Errorf("%s: esperado %v, obtenido %v", tt.input, b, evaluated)
+			}
+			continue
+		}
+		testObjectLiteral(t, evaluated, tt.expected)
+	}
+}
+
+func TestLocaleNumberErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`parse_number("1.234,56", "xx")`, "parse_number: unsupported locale 'xx'"},
+		{`parse_number("1,2,3.4.5", "en")`, "parse_number: invalid number '1,2,3.4.5'"},
+		{`parse_number("abc", "de")`, "parse_number: invalid number 'abc'"},
+		{`parse_number("1.234,56", "en")`, "parse_number: invalid number '1.234,56'"},
+		{`parse_number("12,34", "en")`, "parse_number: invalid number '12,34'"},
+		{`parse_number("1,234.56", "de")`, "parse_number: invalid number '1,234.56'"},
+		{`format_number(1, "ch")`, "format_number: unsupported locale 'ch'"},
+		{`format_number("1", "en")`, "format_number expects a number, got STRING"},
+	}
+
+	for _, tt := range tests {
+		testEvalErrorExact(t, tt.input, tt.expected)
+	}
+}
+
+func TestAssertions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string // error esperado; vacío si no debe fallar
+		results  []AssertionResult
+	}{
+		{`assert(1 < 2, "orden")`, "", []AssertionResult{{true, "orden"}}},
+		{`assert(false)`, "assert falló", []AssertionResult{{false, "assert falló"}}},
+		{`assert(len([]) > 0, "lista vacía")`, "lista vacía", []AssertionResult{{false, "lista vacía"}}},
+		{`assert_eq([1, 2], [1, 2])`, "", []AssertionResult{{true, "assert_eq falló"}}},
+		{`assert_eq(1 + 1, 3, "suma")`, "suma: esperado 3, obtenido 2", []AssertionResult{{false, "suma: esperado 3, obtenido 2"}}},
+		{`assert_eq("1", 1)`, `assert_eq falló: esperado 1, obtenido "1"`, []AssertionResult{{false, `assert_eq falló: esperado 1, obtenido "1"`}}},
+		{"x := nil\nassert_eq(x, nil)", "", []AssertionResult{{true, "assert_eq falló"}}},
+		// Un fallo capturado no detiene el programa pero queda registrado
+		{
+			"try {\n    assert(false, \"capturado\")\n} catch (e) {\n    assert_eq(e, \"capturado\")\n}",
+			"",
+			[]AssertionResult{{false, "capturado"}, {true, "assert_eq falló"}},
+		},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("%s: parser errors: %v", tt.input, p.Errors())
+		}
+		eval := NewEvaluator()
+		err := eval.EvaluateProgram(program)
+
+		if tt.expected == "" && err != nil {
+			t.Errorf("%s: error inesperado: %v", tt.input, err)
+		}
+		if tt.expected != "" && (err == nil || err.Error() != tt.expected) {
+			t.Errorf("%s: se esperaba el error %q, obtenido %v", tt.input, tt.expected, err)
+		}
+
+		results := eval.Assertions()
+		if len(results) != len(tt.results) {
+			t.Errorf("%s: se esperaban %d aserciones, obtenidas %v", tt.input, len(tt.results), results)
+			continue
+		}
+		for i := range results {
+			if results[i] != tt.results[i] {
+				t.Errorf("%s: aserción %d: esperado %+v, obtenido %+v", tt.input, i, tt.results[i], results[i])
+			}
+		}
+	}
+}
+
+func TestTryFinally(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"log := \"\"\ntry {\n    log = log + \"t\"\n} finally {\n    log = log + \"f\"\n}\nlog", "tf"},
+		{"log := \"\"\ntry {\n    throw \"x\"\n} catch (e) {\n    log = log + \"c\"\n} finally {\n    log = log + \"f\"\n}\nlog", "cf"},
+		// finally se ejecuta antes de que el return del try tenga efecto
+		{`
+log := ""
+func f() {
+    try {
+        return "try"
+    } finally {
+        log = log + "finally"
+    }
+    return "después"
+}
+r := f()
+r + "," + log
+`, "try,finally"},
+		{`
+log := ""
+func f() {
+    try {
+        throw "x"
+    } catch (e) {
+        return "catch"
+    } finally {
+        log = "finally"
+    }
+}
+f() + "," + log
+`, "catch,finally"},
+		// Un return en finally sustituye al del try
+		{"func f() {\n    try {\n        return 1\n    } finally {\n        return 2\n    }\n}\nf()", 2},
+		{"func f() {\n    try {\n        throw \"x\"\n    } finally {\n        return 3\n    }\n}\nf()", 3},
+		// finally también se ejecuta si el error sigue propagándose
+		{`
+log := ""
+func f() {
+    try {
+        throw "interno"
+    } finally {
+        log = "finally"
+    }
+}
+try {
+    f()
+} catch (e) {
+    log = log + "," + e
+}
+log
+`, "finally,interno"},
+		{`
+log := ""
+try {
+    try {
+        throw "uno"
+    } catch (e) {
+        throw "dos"
+    } finally {
+        log = "finally"
+    }
+} catch (e) {
+    log = log + "," + e
+}
+log
+`, "finally,dos"},
+		{`
+total := 0
+for i in [1, 2, 3] {
+    try {
+        if i == 2 {
+            continue
+        }
+    } finally {
+        total = total + 10
+    }
+    total = total + i
+}
+total
+`, 34},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestTryFinallyErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"try {\n    throw \"sin catch\"\n} finally {\n}", "sin catch"},
+		{"try {\n    throw \"x\"\n} catch (e) {\n    throw \"desde catch\"\n}", "desde catch"},
+		{"try {\n} finally {\n    throw \"desde finally\"\n}", "desde finally"},
+		// El error de finally sustituye al del try
+		{"try {\n    throw \"try\"\n} finally {\n    throw \"finally\"\n}", "finally"},
+	}
+
+	for _, tt := range tests {
+		testEvalErrorExact(t, tt.input, tt.expected)
+	}
+}
+
+func TestRuntimeErrorPositions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x := 1\nshow.log(y)", "main.zylo:2:10: variable no definida: y"},
+		{"lista := [1, 2]\nlista[5]", "main.zylo:2:6: array index out of bounds: 5 (length: 2)"},
+		{"func f() {\n    return len(1, 2)\n}\nf()", "main.zylo:2:12: len expects 1 argument, got 2"},
+		{"n := 3\nn()", "main.zylo:2:1: no se puede llamar a: *evaluator.Integer"},
+		// El error se sitúa donde ocurre, no en la llamada a la función que lo contiene
+		{"func f(l) {\n    return l[\"a\"]\n}\nf([1])", "main.zylo:2:13: índice debe ser integer"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("%s: parser errors: %v", tt.input, p.Errors())
+		}
+		err := NewEvaluator().EvaluateProgram(program)
+		if err == nil {
+			t.Errorf("%s: se esperaba un error", tt.input)
+			continue
+		}
+		if got := FormatError("main.zylo", err); got != tt.expected {
+			t.Errorf("%s: esperado %q, obtenido %q", tt.input, tt.expected, got)
+		}
+	}
+
+	// catch recibe solo el mensaje, sin la posición
+	testStringObject(t, testEval("r := \"\"\ntry {\n    show.log(no_existe)\n} catch (e) {\n    r = e\n}\nr"), "variable no definida: no_existe")
+}
+
+func TestStackTrace(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"func f() {\n    throw \"fallo\"\n}\nfunc g() {\n    return f()\n}\ng()",
+			"Traza de llamadas (la más reciente primero):\n  en f, llamada desde main.zylo:5:12\n  en g, llamada desde main.zylo:7:1"},
+		{"class A {\n    func m() {\n        return 1 / 0\n    }\n}\na := A()\na.m()",
+			"Traza de llamadas (la más reciente primero):\n  en A.m, llamada desde main.zylo:7:3"},
+		// Un error fuera de cualquier función no tiene traza
+		{"throw \"fallo\"", ""},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("%s: parser errors: %v", tt.input, p.Errors())
+		}
+		err := NewEvaluator().EvaluateProgram(program)
+		if err == nil {
+			t.Errorf("%s: se esperaba un error", tt.input)
+			continue
+		}
+		if got := FormatStackTrace("main.zylo", err); got != tt.expected {
+			t.Errorf("%s: esperado %q, obtenido %q", tt.input, tt.expected, got)
+		}
+	}
+}
+
+func TestStackTraceDepth(t *testing.T) {
+	p := parser.New(lexer.New("func r(n) {\n    if n == 0 {\n        throw \"fondo\"\n    }\n    return r(n - 1)\n}\nr(50)"))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("parser errors: %v", p.Errors())
+	}
+	eval := NewEvaluator()
+	err := eval.EvaluateProgram(program)
+	if err == nil {
+		t.Fatal("se esperaba un error")
+	}
+	trace := FormatStackTrace("main.zylo", err)
+	lines := strings.Split(trace, "\n")
+	if len(lines) != maxStackTrace+2 {
+		t.Fatalf("se esperaban %d líneas, obtenidas %d:\n%s", maxStackTrace+2, len(lines), trace)
+	}
+	if last := lines[len(lines)-1]; last != "  ... 31 llamadas más" {
+		t.Errorf("última línea inesperada: %q", last)
+	}
+	if len(eval.callStack) != 0 {
+		t.Errorf("la pila de llamadas debería quedar vacía, tiene %d llamadas", len(eval.callStack))
+	}
+
+	// Un error capturado no deja llamadas en la pila
+	eval = NewEvaluator()
+	p = parser.New(lexer.New("func f() {\n    throw \"x\"\n}\ntry {\n    f()\n} catch (e) {\n}"))
+	if err := eval.EvaluateProgram(p.ParseProgram()); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if len(eval.callStack) != 0 {
+		t.Errorf("la pila de llamadas debería quedar vacía, tiene %d llamadas", len(eval.callStack))
+	}
+}
+
+func TestReadNumbers(t *testing.T) {
+	tests := []struct {
+		input    string
+		stdin    string
+		expected interface{}
+	}{
+		{"read.int()", "42\n", 42},
+		{"read.int()", "  -7  \n", -7},
+		{"read.int()", "abc\n\n5\n", 5},
+		{"read.int()", "8", 8},
+		{"read.float()", "2.5\n", 2.5},
+		{"read.float()", "x\n3\n", 3.0},
+		{"read.int() + read.int()", "1\n2\n", 3},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("%s: parser errors: %v", tt.input, p.Errors())
+		}
+		eval := NewEvaluator()
+		eval.reader = bufio.NewReader(strings.NewReader(tt.stdin))
+		var result Value
+		for _, stmt := range program.Statements {
+			var err error
+			result, err = eval.evaluateStatement(stmt)
+			if err != nil {
+				t.Fatalf("%s: error inesperado: %v", tt.input, err)
+			}
+		}
+		testObjectLiteral(t, result, tt.expected)
+	}
+}
+
+func TestReadNumbersEOF(t *testing.T) {
+	tests := []struct {
+		input    string
+		stdin    string
+		expected string
+	}{
+		{"read.int()", "", "read.int: fin de la entrada sin un número válido"},
+		{"read.int()", "abc\nxyz", "read.int: fin de la entrada sin un número válido"},
+		{"read.float()", "\n", "read.float: fin de la entrada sin un número válido"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("%s: parser errors: %v", tt.input, p.Errors())
+		}
+		eval := NewEvaluator()
+		eval.reader = bufio.NewReader(strings.NewReader(tt.stdin))
+		err := eval.EvaluateProgram(program)
+		if err == nil {
+			t.Errorf("%s con %q: se esperaba un error", tt.input, tt.stdin)
+			continue
+		}
+		if !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: mensaje de error inesperado: %s", tt.input, err.Error())
+		}
+	}
+}
+
+func TestStringBuilder(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"sb := string_builder()\nsb.build()", ""},
+		{"sb := string_builder()\nsb.append(\"a\")\nsb.append(\"b\")\nsb.build()", "ab"},
+		{"sb := string_builder()\nsb.append(\"x\", 1, 2.5, true)\nsb.build()", "x12.5true"},
+		{"sb := string_builder()\nsb.append(\"hola\")\nsb.length", 4},
+		{"sb := string_builder()\nsb.append(\"a\")\nsb.reset()\nsb.append(\"b\")\nsb.build()", "b"},
+		{`
+sb := string_builder()
+i := 0
+while i < 5 {
+    sb.append(i)
+    i = i + 1
+}
+sb.build()`, "01234"},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+// La concatenación con + en un bucle es cuadrática; string_builder es lineal.
+const concatIterations = 2000
+
+func benchmarkProgram(b *testing.B, input string) {
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		b.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := NewEvaluator().EvaluateProgram(program); err != nil {
+			b.Fatalf("Evaluation error: %v", err)
+		}
+	}
+}
+
+func BenchmarkStringConcat(b *testing.B) {
+	benchmarkProgram(b, fmt.Sprintf(`
+s := ""
+i := 0
+while i < %d {
+    s = s + "abcdefghij"
+    i = i + 1
+}
+`, concatIterations))
+}
+
+func BenchmarkStringBuilder(b *testing.B) {
+	benchmarkProgram(b, fmt.Sprintf(`
+sb := string_builder()
+i := 0
+while i < %d {
+    sb.append("abcdefghij")
+    i = i + 1
+}
+s := sb.build()
+`, concatIterations))
+}
+
+func TestModuloMixedTypes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"7 % 3", 7 % 3},
+		{"7.5 % 2", 1.5},
+		{"7 % 2.5", 2.0},
+		{"-7.5 % 2", -1.5},
+		{"7.5 % 2.5", 0.0},
+		// "//" empieza un comentario, no es un operador
+		{"7 % 4 // 2", 3},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestModuloByZero(t *testing.T) {
+	for _, input := range []string{"5 % 0", "5.5 % 0", "5 % 0.0"} {
+		testEvalError(t, input, "módulo por cero")
+	}
+}
+
+func TestAwaitTimeout(t *testing.T) {
+	eval := NewEvaluator()
+	// Un future que nunca se completa
+	eval.env.Set("pendiente", &Future{Result: make(chan Value, 1)})
+
+	p := parser.New(lexer.New(`await_timeout(pendiente, 20)`))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- eval.EvaluateProgram(program) }()
+
+	select {
+	case err := <-done:
+		if err == nil || err.Error() != "await: tiempo de espera agotado tras 20ms" {
+			t.Fatalf("se esperaba un error de tiempo agotado, obtenido %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatalf("await_timeout no respetó el tiempo de espera")
+	}
+}
+
+func TestAsyncErrors(t *testing.T) {
+	input := `
+async func falla() {
+    throw "boom"
+}
+async func doble(n) {
+    return n * 2
+}
+resultado := await doble(21)
+mensaje := ""
+try {
+    await falla()
+} catch (e) {
+    mensaje = e
+}
+`
+	eval := NewEvaluator()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	if err := eval.EvaluateProgram(program); err != nil {
+		t.Fatalf("Evaluation error: %v", err)
+	}
+
+	resultado, _ := eval.env.Get("resultado")
+	testIntegerObject(t, resultado, 42)
+	mensaje, _ := eval.env.Get("mensaje")
+	testStringObject(t, mensaje, "boom")
+}
+
+func TestAwaitAll(t *testing.T) {
+	functions := `
+async func doble(n) {
+    return n * 2
+}
+async func falla(msg) {
+    throw msg
+}
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"r := await_all([doble(1), doble(2), doble(3)])\nr[0] + r[1] * 10 + r[2] * 100", 642},
+		{"r := await [doble(4), doble(5)]\nr[0] + r[1]", 18},
+		{"r := await_all([])\nlen(r)", 0},
+		// Los futures se pueden construir en un bucle
+		{"fs := []\nfor n in [1, 2, 3] {\n    fs.append(doble(n))\n}\nsum := 0\nfor v in await fs {\n    sum = sum + v\n}\nsum", 12},
+		// Si varios fallan, se reporta el primero de la lista
+		{"m := \"\"\ntry {\n    await_all([doble(1), falla(\"a\"), falla(\"b\")])\n} catch (e) {\n    m = e\n}\nm", "a"},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(functions+tt.input), tt.expected)
+	}
+}
+
+func TestAwaitAllErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"await_all(1)", "await_all() espera una lista de futures, obtenido *evaluator.Integer"},
+		{"await_all([1])", "await_all() espera una lista de futures, el elemento 0 es *evaluator.Integer"},
+		{"await [2, 3]", "await_all() espera una lista de futures, el elemento 0 es *evaluator.Integer"},
+		{"await_all()", "await_all() espera 1 argumento"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
+func TestAwaitFutureTwice(t *testing.T) {
+	functions := `
+async func doble(n) {
+    return n * 2
+}
+async func falla() {
+    throw "boom"
+}
+`
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"f := doble(5)\na := await f\nb := await f\na + b", 20},
+		{"f := doble(1)\nawait_all([f, f, f])\nawait f", 2},
+		{"f := doble(2)\nawait_timeout(f, 1000)\nawait_timeout(f, 1000)", 4},
+		// Un future que falló vuelve a fallar con el mismo error
+		{"f := falla()\nm := \"\"\ntry {\n    await f\n} catch (e) {\n    m = e\n}\ntry {\n    await f\n} catch (e) {\n    m = m + \" \" + e\n}\nm", "boom boom"},
+	}
+
+	for _, tt := range tests {
+		done := make(chan Value, 1)
+		go func() { done <- testEval(functions + tt.input) }()
+		select {
+		case result := <-done:
+			testObjectLiteral(t, result, tt.expected)
+		case <-time.After(2 * time.Second):
+			t.Fatalf("%s: esperar dos veces el mismo future se bloqueó", tt.input)
+		}
+	}
+}
+
+func TestAwaitFutureConcurrently(t *testing.T) {
+	future := newFuture(func() (Value, error) {
+		time.Sleep(10 * time.Millisecond)
+		return &Integer{Value: 7}, nil
+	})
+
+	eval := NewEvaluator()
+	results := make(chan Value, 5)
+	for i := 0; i < 5; i++ {
+		go func() {
+			value, err := eval.fork().awaitFuture(future, 2*time.Second)
+			if err != nil {
+				t.Errorf("error inesperado: %v", err)
+			}
+			results <- value
+		}()
+	}
+	for i := 0; i < 5; i++ {
+		testIntegerObject(t, <-results, 7)
+	}
+}
+
+func TestAsyncPanicIsDelivered(t *testing.T) {
+	future := newFuture(func() (Value, error) {
+		var list *List
+		return list.Items[0], nil
+	})
+
+	_, err := NewEvaluator().awaitFuture(future, time.Second)
+	if err == nil || !strings.HasPrefix(err.Error(), "pánico en llamada asíncrona") {
+		t.Fatalf("se esperaba el pánico como error, obtenido %v", err)
+	}
+}
+
+func TestHTTPRoutes(t *testing.T) {
+	listener, err := net.Listen("tcp", ":0")
+	if err != nil {
+		t.Fatalf("no se pudo reservar un puerto: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
+	input := fmt.Sprintf(`
+func user(req) {
+    return "user " + req.params.id + " tab=" + req.query.tab
+}
+func created(req) {
+    return req.method + " " + req.path
+}
+func fallback(req) {
+    return "fallback " + req.path
+}
+http.route("GET", "/users/:id", user)
+http.route("POST", "/users", created)
+http.listen(%d, fallback)
+`, port)
+
+	eval := NewEvaluator()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	if err := eval.EvaluateProgram(program); err != nil {
+		t.Fatalf("Evaluation error: %v", err)
+	}
+
+	base := fmt.Sprintf("http://127.0.0.1:%d", port)
+	fetch := func(method, path string) string {
+		req, err := http.NewRequest(method, base+path, nil)
+		if err != nil {
+			t.Fatalf("error creando petición: %v", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatalf("error en %s %s: %v", method, path, err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	tests := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{"GET", "/users/42?tab=perfil", "user 42 tab=perfil"},
+		{"POST", "/users", "POST /users"},
+		{"GET", "/otra", "fallback /otra"},
+	}
+	for _, tt := range tests {
+		if got := fetch(tt.method, tt.path); got != tt.expected {
+			t.Errorf("%s %s: esperado %q, obtenido %q", tt.method, tt.path, tt.expected, got)
+		}
+	}
+
+	stopped, err := eval.httpStop()
+	if err != nil {
+		t.Fatalf("http.stop falló: %v", err)
+	}
+	testStringObject(t, stopped, "Server stopped")
+	eval.WaitHTTPServer()
+
+	if _, err := http.Get(base + "/users/1"); err == nil {
+		t.Errorf("el servidor sigue aceptando peticiones después de http.stop")
+	}
+}
+
+func TestRuntimeBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`split("a,b,c", ",")`, "[a, b, c]"},
+		{`join(split("a-b", "-"), "+")`, "a+b"},
+		{`sort([3, 1, 2])`, "[1, 2, 3]"},
+		{`sort(["pera", "anana", "manzana"])`, "[anana, manzana, pera]"},
+		{`reverse([1, 2, 3])`, "[3, 2, 1]"},
+		{`trim("  hola  ")`, "hola"},
+		{`to_upper("zylo")`, "ZYLO"},
+		{`contains("zylo lang", "lang")`, "true"},
+		{`substring("zylo", 1, 3)`, "yl"},
+		{`append([1], 2)`, "[1, 2]"},
+		{`Max(3, 7)`, "7"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		obj, ok := evaluated.(ZyloObject)
+		if !ok {
+			t.Errorf("%s: resultado no es un objeto Zylo: %T", tt.input, evaluated)
+			continue
+		}
+		if obj.Inspect() != tt.expected {
+			t.Errorf("%s: esperado %s, obtenido %s", tt.input, tt.expected, obj.Inspect())
+		}
+	}
+}
+
+func TestRuntimeBuiltinErrors(t *testing.T) {
+	eval := NewEvaluator()
+	p := parser.New(lexer.New(`trim(42)`))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	err := eval.EvaluateProgram(program)
+	if err == nil {
+		t.Fatalf("se esperaba un error al llamar trim con un entero")
+	}
+	if err.Error() != "trim expects a string, got INTEGER" {
+		t.Errorf("mensaje de error inesperado: %s", err.Error())
+	}
+}
+
+const reflectionClasses = `
+class Animal {
+    patas := 4
+    func init(nombre) {
+        this.nombre = nombre
+    }
+    func hablar() {
+        return "..."
+    }
+    func describir() {
+        return this.nombre
+    }
+}
+class Perro extends Animal {
+    raza := "mestizo"
+    func init(nombre) {
+        super.init(nombre)
+    }
+    func hablar() {
+        return "guau"
+    }
+    func traer() {
+        return "pelota"
+    }
+}
+p := Perro("Rex")
+`
+
+func TestFieldsAndMethods(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`len(fields(p))`, "3"},
+		{`fields(p)["nombre"]`, "Rex"},
+		{`fields(p)["raza"]`, "mestizo"},
+		// Atributo heredado de Animal
+		{`fields(p)["patas"]`, "4"},
+		{`methods(p)`, "[describir, hablar, traer]"},
+		{`methods(Animal("Tom"))`, "[describir, hablar]"},
+		{`len(fields(Animal("Tom")))`, "2"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(reflectionClasses + tt.input)
+		obj, ok := evaluated.(ZyloObject)
+		if !ok {
+			t.Errorf("%s: resultado no es un objeto Zylo: %T", tt.input, evaluated)
+			continue
+		}
+		if obj.Inspect() != tt.expected {
+			t.Errorf("%s: esperado %s, obtenido %s", tt.input, tt.expected, obj.Inspect())
+		}
+	}
+}
+
+func TestFieldsAndMethodsErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`fields(42)`, "fields() espera una instancia, obtenido *evaluator.Integer"},
+		{`methods("hola")`, "methods() espera una instancia, obtenido *evaluator.String"},
+		{`fields()`, "fields() espera 1 argumento"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
+func TestLogicalOperatorsReturnOperands(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`"" or "anónimo"`, "anónimo"},
+		{`"Ana" or "anónimo"`, "Ana"},
+		{`null or 0`, 0},
+		{`0 or null or 5`, 5},
+		{`false or 0`, 0},
+		{`1 and "sí"`, "sí"},
+		{`0 and "sí"`, 0},
+		{`"a" and "" and "b"`, ""},
+		{`x := 0
+x or "por defecto"`, "por defecto"},
+		{`true and false`, false},
+		{`false or true`, true},
+		// El operando derecho no se evalúa si no hace falta
+		{`1 or noexiste`, 1},
+		{`0 and noexiste`, 0},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if b, ok := tt.expected.(bool); ok {
+			if got, isBool := evaluated.(*Boolean); !isBool || got.Value != b {
+				t.Errorf("%s: esperado %v, obtenido %v", tt.input, b, evaluated)
+			}
+			continue
+		}
+		testObjectLiteral(t, evaluated, tt.expected)
+	}
+}
+
+func TestNullCoalescing(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`null ?? 5`, 5},
+		{`x := null
+x ?? "por defecto"`, "por defecto"},
+		// A diferencia de or, solo null cede el paso al derecho
+		{`0 ?? 5`, 0},
+		{`0 or 5`, 5},
+		{`"" ?? "anónimo"`, ""},
+		{`"" or "anónimo"`, "anónimo"},
+		{`false ?? true`, false},
+		{`false or true`, true},
+		{`null ?? null ?? 3`, 3},
+		{`m := {"a": 1}
+m["b"] ?? m["a"]`, 1},
+		{`m := {"a": {"b": 2}}
+m?.x?.b ?? m?.a?.b`, 2},
+		// El operando derecho no se evalúa si el izquierdo no es null
+		{`1 ?? noexiste`, 1},
+		{`n := 0
+func paso() {
+    n = n + 1
+    return n
+}
+0 ?? paso()
+n`, 0},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if b, ok := tt.expected.(bool); ok {
+			if got, isBool := evaluated.(*Boolean); !isBool || got.Value != b {
+				t.Errorf("%s: esperado %v, obtenido %v", tt.input, b, evaluated)
+			}
+			continue
+		}
+		testObjectLiteral(t, evaluated, tt.expected)
+	}
+}
+
+func TestMembershipOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`2 in [1, 2, 3]`, "true"},
+		{`5 in [1, 2, 3]`, "false"},
+		{`[1, 2] in [[1, 2], [3]]`, "true"},
+		{`"b" in ["a", "b"]`, "true"},
+		{`5 not in [1, 2, 3]`, "true"},
+		{`"ol" in "hola"`, "true"},
+		{`"x" in "hola"`, "false"},
+		{`"x" not in "hola"`, "true"},
+		{`"a" in json.parse("{\"a\": 1}")`, "true"},
+		{`"b" in json.parse("{\"a\": 1}")`, "false"},
+		{`"b" not in json.parse("{\"a\": 1}")`, "true"},
+		{`1 in [1] and 2 not in [1]`, "true"},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		obj, ok := evaluated.(ZyloObject)
+		if !ok {
+			t.Errorf("%s: resultado no es un objeto Zylo: %T", tt.input, evaluated)
+			continue
+		}
+		if obj.Inspect() != tt.expected {
+			t.Errorf("%s: esperado %s, obtenido %s", tt.input, tt.expected, obj.Inspect())
+		}
+	}
+}
+
+func TestIfExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`x := if 5 > 3 { "grande" } else { "pequeño" }
+x`, "grande"},
+		{`x := if 1 > 3 { "grande" } else { "pequeño" }
+x`, "pequeño"},
+		{`x := if true { a := 2
+a * 10 } else { 0 }
+x`, 20},
+		{`n := 5
+x := if n == 1 { "uno" } else if n == 5 { "cinco" } else { "otro" }
+x`, "cinco"},
+		// Solo se evalúa la rama elegida
+		{`lista := []
+x := if true { lista.append(1) } else { lista.append(2) }
+len(lista) * 10 + lista[0]`, 11},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+
+	if _, ok := testEval("x := if false { 1 }\nx").(*Null); !ok {
+		t.Errorf("un if sin else con condición falsa debería valer null")
+	}
+}
+
+func TestBitwiseOperators(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"12 & 10", 8},
+		{"12 | 10", 14},
+		{"12 ^ 10", 6},
+		{"1 << 4", 16},
+		{"256 >> 2", 64},
+		{"-8 >> 1", -4},
+		{"1 + 2 & 3", 3},
+		{"6 & 3 == 2", true},
+		{"1 | 2 ^ 3 & 4", 3},
+		{"true xor false", true},
+		{"true xor true", false},
+		{"false xor false xor true", true},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestBitwiseOperatorErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1.5 & 1", "operador '&' no soportado para *evaluator.Float y *evaluator.Integer"},
+		{"1 << -1", "desplazamiento negativo: -1"},
+		{"1 xor true", "operador 'xor' no soportado para *evaluator.Integer y *evaluator.Boolean"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
+func TestDestructuringAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`a := 0
+b := 0
+a, b = [1, 2]
+a * 10 + b`, 12},
+		{`a := 1
+b := 2
+a, b = b, a
+a * 10 + b`, 21},
+		{`func pair() {
+	return ["x", "y"]
+}
+a := ""
+b := ""
+a, b = pair()
+a + b`, "xy"},
+		{`l := [0, 0]
+m := {"k": 0}
+l[1], m["k"] = [5, 7]
+l[1] * 10 + m["k"]`, 57},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestDestructuringAssignmentErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a := 0\nb := 0\na, b = [1, 2, 3]", "no se puede desestructurar: 2 variables para 3 valores"},
+		{"a := 0\nb := 0\na, b = 5", "no se puede desestructurar INT: se esperaba una lista"},
+		{"LIMITE := 0\nb := 0\nLIMITE, b = [1, 2]", "no se puede reasignar constante: LIMITE"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
+func TestMembershipOperatorErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`1 in "hola"`, "'in' con un string requiere un string a la izquierda, obtenido *evaluator.Integer"},
+		{`1 in 2`, "operador 'in' no soportado para *evaluator.Integer"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json.stringify(json.parse("{\"a\": [1, 2.5, {\"b\": null}], \"c\": true}"))`, `{"a":[1,2.5,{"b":null}],"c":true}`},
+		{`json.stringify(json.parse("[[1, [2, [3]]], \"x\"]"))`, `[[1,[2,[3]]],"x"]`},
+		{`json.stringify([1, "dos", 3.5, false, null])`, `[1,"dos",3.5,false,null]`},
+		{`json.stringify(json.parse("{\"a\": {\"b\": 1}}"), 2)`, "{\n  \"a\": {\n    \"b\": 1\n  }\n}"},
+		// Las claves se escriben en orden de inserción, como las muestra print
+		{`json.stringify({"z": 1, "a": {"y": 2, "b": [3]}})`, `{"z":1,"a":{"y":2,"b":[3]}}`},
+		{`json.stringify(json.parse("{\"b\": 1, \"a\": 2}"))`, `{"b":1,"a":2}`},
+		{`json.stringify({"z": 1, "a": 2}, 2)`, "{\n  \"z\": 1,\n  \"a\": 2\n}"},
+		{`json.stringify({"<": "&"})`, `{"\u003c":"\u0026"}`},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestJSONParseTypes(t *testing.T) {
+	parsed := testEval(`json.parse("{\"n\": 3, \"f\": 1.5, \"s\": \"hola\", \"l\": [true]}")`)
+	m, ok := parsed.(*MapObject)
+	if !ok {
+		t.Fatalf("json.parse no devolvió un MapObject: %T", parsed)
+	}
+	testIntegerObject(t, m.Pairs["n"], 3)
+	testFloatObject(t, m.Pairs["f"], 1.5)
+	testStringObject(t, m.Pairs["s"], "hola")
+	list, ok := m.Pairs["l"].(*List)
+	if !ok || len(list.Items) != 1 {
+		t.Fatalf("se esperaba una lista de un elemento, obtenido %T", m.Pairs["l"])
+	}
+	if b, ok := list.Items[0].(*Boolean); !ok || !b.Value {
+		t.Errorf("se esperaba true, obtenido %v", list.Items[0])
+	}
+}
+
+func TestJSONParseErrors(t *testing.T) {
+	inputs := []string{
+		`json.parse("{\"a\": ")`,
+		`json.parse("[1, 2] 3")`,
+		`json.parse("no es json")`,
+	}
+
+	for _, input := range inputs {
+		eval := NewEvaluator()
+		p := parser.New(lexer.New(input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+		if err := eval.EvaluateProgram(program); err == nil {
+			t.Errorf("%s: se esperaba un error de JSON inválido", input)
+		}
+	}
+}
+
+func testEval(input string) Value {
+	eval := NewEvaluator()
+	l := lexer.New(input)
+	p := parser.New(l)
+	program := p.ParseProgram()
+
+	if len(p.Errors()) > 0 {
+		panic("Parser errors: " + fmt.Sprintf("%v", p.Errors()))
+	}
+
+	var lastValue Value = &Null{}
+	for _, stmt := range program.Statements {
+		value, err := eval.evaluateStatement(stmt)
+		if err != nil {
+			panic("Evaluation error: " + err.Error())
+		}
+		if value != nil {
+			lastValue = value
+		}
+	}
+	return lastValue
+}
+
+// testEvalError evalúa input y comprueba que termina con un error que
+// contiene want
+func testEvalError(t *testing.T, input string, want string) {
+	t.Helper()
+	err := evalError(t, input)
+	if err == nil || !strings.Contains(err.Error(), want) {
+		t.Errorf("%s: se esperaba un error con %q, obtenido %v", input, want, err)
+	}
+}
+
+// testEvalErrorExact es como testEvalError, pero el mensaje del error tiene
+// que ser exactamente want
+func testEvalErrorExact(t *testing.T, input string, want string) {
+	t.Helper()
+	err := evalError(t, input)
+	if err == nil || err.Error() != want {
+		t.Errorf("%s: se esperaba el error %q, obtenido %v", input, want, err)
+	}
+}
+
+// evalError evalúa input, que no debe tener errores de parsing, y devuelve el
+// error con el que termina
+func evalError(t *testing.T, input string) error {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("%s: parser errors: %v", input, p.Errors())
+	}
+	return NewEvaluator().EvaluateProgram(program)
+}
+
+func testObjectLiteral(t *testing.T, obj Value, expected interface{}) bool {
+	switch expected := expected.(type) {
+	case int:
+		return testIntegerObject(t, obj, int64(expected))
+	case int64:
+		return testIntegerObject(t, obj, expected)
+	case float64:
+		return testFloatObject(t, obj, expected)
+	case string:
+		return testStringObject(t, obj, expected)
+	case bool:
+		return testBooleanObject(t, obj, expected)
+	}
+	t.Errorf("type of expected not handled. got=%T", expected)
+	return false
+}
+
+func testBooleanObject(t *testing.T, obj Value, expected bool) bool {
+	result, ok := obj.(*Boolean)
+	if !ok {
+		t.Errorf("object is not Boolean. got=%T (%+v)", obj, obj)
+		return false
+	}
+	if result.Value != expected {
+		t.Errorf("object has wrong value. got=%t, want=%t", result.Value, expected)
+		return false
+	}
+	return true
 }
 
 func testIntegerObject(t *testing.T, obj Value, expected int64) bool {
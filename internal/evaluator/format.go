@@ -0,0 +1,89 @@
+package evaluator
+
+// format(plantilla, ...args) sustituye los huecos de la plantilla por los
+// argumentos convertidos a string: {} toma el siguiente argumento y {n} el
+// argumento n (desde 0). {{ y }} escriben una llave literal.
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// formatTemplate implementa el builtin format
+func (e *Evaluator) formatTemplate(args []Value) (Value, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("format() espera una plantilla")
+	}
+	template, ok := args[0].(*String)
+	if !ok {
+		return nil, fmt.Errorf("format() espera una plantilla string, obtenido %T", args[0])
+	}
+	values := args[1:]
+
+	var out strings.Builder
+	text := template.Value
+	next := 0 // Siguiente argumento para {}
+	indexed := false
+	for i := 0; i < len(text); i++ {
+		switch text[i] {
+		case '{':
+			if i+1 < len(text) && text[i+1] == '{' {
+				out.WriteByte('{')
+				i++
+				continue
+			}
+			end := strings.IndexByte(text[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("format(): '{' sin cerrar en la posición %d", i)
+			}
+			field := text[i+1 : i+end]
+			i += end
+
+			var index int
+			if field == "" {
+				if indexed {
+					return nil, fmt.Errorf("format(): no se pueden mezclar {} y {n}")
+				}
+				index = next
+				next++
+				if index >= len(values) {
+					continue // Se cuenta y se reporta al final
+				}
+			} else {
+				n, err := strconv.Atoi(field)
+				if err != nil || n < 0 {
+					return nil, fmt.Errorf("format(): hueco no válido {%s}", field)
+				}
+				if next > 0 {
+					return nil, fmt.Errorf("format(): no se pueden mezclar {} y {n}")
+				}
+				indexed = true
+				if n >= len(values) {
+					return nil, fmt.Errorf("format(): el hueco {%d} no tiene argumento, recibidos %d", n, len(values))
+				}
+				index = n
+			}
+
+			str, err := e.convertToString(values[index])
+			if err != nil {
+				return nil, err
+			}
+			out.WriteString(str.(*String).Value)
+		case '}':
+			if i+1 < len(text) && text[i+1] == '}' {
+				out.WriteByte('}')
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("format(): '}' sin abrir en la posición %d; usa }} para una llave literal", i)
+		default:
+			out.WriteByte(text[i])
+		}
+	}
+
+	if !indexed && next != len(values) {
+		return nil, fmt.Errorf("format(): la plantilla tiene %d huecos y se recibieron %d argumentos", next, len(values))
+	}
+	return &String{Value: out.String()}, nil
+}
@@ -0,0 +1,44 @@
+package evaluator
+
+import "testing"
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`format("hola")`, "hola"},
+		{`format("{} + {} = {}", 1, 2, 3)`, "1 + 2 = 3"},
+		{`format("{}, {}, {}, {}", 1.5, true, null, [1, 2])`, "1.5, true, null, [1, 2]"},
+		{`format("{1} {0} {1}", "a", "b")`, "b a b"},
+		// Con índices no hace falta usar todos los argumentos
+		{`format("{0}", "a", "b")`, "a"},
+		{`format("{{}} {} }}{{", "x")`, "{} x }{"},
+		{`format("ñ{}ñ", "é")`, "ñéñ"},
+	}
+
+	for _, tt := range tests {
+		testStringObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`format("{} {}", 1)`, "format(): la plantilla tiene 2 huecos y se recibieron 1 argumentos"},
+		{`format("{}", 1, 2)`, "format(): la plantilla tiene 1 huecos y se recibieron 2 argumentos"},
+		{`format("{2}", 1)`, "format(): el hueco {2} no tiene argumento, recibidos 1"},
+		{`format("{} {0}", 1)`, "format(): no se pueden mezclar {} y {n}"},
+		{`format("{0} {}", 1)`, "format(): no se pueden mezclar {} y {n}"},
+		{`format("{x}", 1)`, "format(): hueco no válido {x}"},
+		{`format("{", 1)`, "format(): '{' sin cerrar en la posición 0"},
+		{`format("a}", 1)`, "format(): '}' sin abrir en la posición 1; usa }} para una llave literal"},
+		{`format(1)`, "format() espera una plantilla string, obtenido *evaluator.Integer"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
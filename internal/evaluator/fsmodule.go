@@ -0,0 +1,115 @@
+package evaluator
+
+// El objeto fs lee y escribe archivos. Las rutas relativas se resuelven desde
+// el directorio de trabajo del proceso. Los fallos del sistema de archivos
+// (un archivo que no existe, falta de permisos) son errores de Zylo normales,
+// así que un catch los puede capturar.
+
+import (
+	"fmt"
+	"os"
+)
+
+// newFSModule crea el objeto fs
+func newFSModule() *MapObject {
+	module := &MapObject{Pairs: make(map[string]Value)}
+	module.Set("read", &BuiltinFunction{Name: "fs.read", Fn: fsRead})
+	module.Set("write", &BuiltinFunction{Name: "fs.write", Fn: fsWrite})
+	module.Set("append", &BuiltinFunction{Name: "fs.append", Fn: fsAppend})
+	module.Set("exists", &BuiltinFunction{Name: "fs.exists", Fn: fsExists})
+	module.Set("listdir", &BuiltinFunction{Name: "fs.listdir", Fn: fsListdir})
+	return module
+}
+
+// fsStringArgs comprueba que name recibió count argumentos, todos strings, y
+// devuelve sus valores. El primero es siempre la ruta.
+func fsStringArgs(name string, args []Value, count int) ([]string, error) {
+	if len(args) != count {
+		return nil, fmt.Errorf("%s espera %s, obtenidos %d", name, argumentCount(count), len(args))
+	}
+	values := make([]string, count)
+	for i, arg := range args {
+		str, ok := arg.(*String)
+		if !ok {
+			if i == 0 {
+				return nil, fmt.Errorf("%s espera una ruta string, obtenido %T", name, arg)
+			}
+			return nil, fmt.Errorf("%s espera un contenido string, obtenido %T", name, arg)
+		}
+		values[i] = str.Value
+	}
+	return values, nil
+}
+
+// fsRead implementa fs.read(path): el contenido del archivo
+func fsRead(args []Value) (Value, error) {
+	values, err := fsStringArgs("fs.read", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	content, err := os.ReadFile(values[0])
+	if err != nil {
+		return nil, fmt.Errorf("fs.read: %v", err)
+	}
+	return &String{Value: string(content)}, nil
+}
+
+// fsWrite implementa fs.write(path, content): crea o reemplaza el archivo
+func fsWrite(args []Value) (Value, error) {
+	values, err := fsStringArgs("fs.write", args, 2)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(values[0], []byte(values[1]), 0644); err != nil {
+		return nil, fmt.Errorf("fs.write: %v", err)
+	}
+	return &Null{}, nil
+}
+
+// fsAppend implementa fs.append(path, content): añade al final del archivo,
+// que se crea si no existe
+func fsAppend(args []Value) (Value, error) {
+	values, err := fsStringArgs("fs.append", args, 2)
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.OpenFile(values[0], os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("fs.append: %v", err)
+	}
+	_, err = file.WriteString(values[1])
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("fs.append: %v", err)
+	}
+	return &Null{}, nil
+}
+
+// fsExists implementa fs.exists(path)
+func fsExists(args []Value) (Value, error) {
+	values, err := fsStringArgs("fs.exists", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	_, err = os.Stat(values[0])
+	return &Boolean{Value: err == nil}, nil
+}
+
+// fsListdir implementa fs.listdir(path): los nombres del directorio, ordenados
+func fsListdir(args []Value) (Value, error) {
+	values, err := fsStringArgs("fs.listdir", args, 1)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(values[0])
+	if err != nil {
+		return nil, fmt.Errorf("fs.listdir: %v", err)
+	}
+	names := make([]Value, len(entries))
+	for i, entry := range entries {
+		names[i] = &String{Value: entry.Name()}
+	}
+	return &List{Items: names}, nil
+}
@@ -0,0 +1,63 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestFSModule(t *testing.T) {
+	dir := t.TempDir()
+	file := strconv.Quote(filepath.Join(dir, "notas.txt"))
+
+	testStringObject(t, testEval("fs.write("+file+", \"uno\\n\")\nfs.read("+file+")"), "uno\n")
+	testStringObject(t, testEval("fs.append("+file+", \"dos\\n\")\nfs.read("+file+")"), "uno\ndos\n")
+	testBooleanObject(t, testEval("fs.exists("+file+")"), true)
+	testBooleanObject(t, testEval("fs.exists("+strconv.Quote(filepath.Join(dir, "otro.txt"))+")"), false)
+
+	// append crea el archivo si no existe
+	nuevo := strconv.Quote(filepath.Join(dir, "nuevo.txt"))
+	testStringObject(t, testEval("fs.append("+nuevo+", \"x\")\nfs.read("+nuevo+")"), "x")
+
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	list, ok := testEval("fs.listdir(" + strconv.Quote(dir) + ")").(*List)
+	if !ok {
+		t.Fatal("fs.listdir() debería devolver una lista")
+	}
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.(*String).Value)
+	}
+	if got := strings.Join(names, ","); got != "notas.txt,nuevo.txt,sub" {
+		t.Errorf("fs.listdir() = %s", got)
+	}
+}
+
+func TestFSModuleErrors(t *testing.T) {
+	missing := strconv.Quote(filepath.Join(t.TempDir(), "no_existe.txt"))
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"fs.read(" + missing + ")", "fs.read: open "},
+		{"fs.listdir(" + missing + ")", "fs.listdir: open "},
+		{"fs.read(1)", "fs.read espera una ruta string, obtenido *evaluator.Integer"},
+		{"fs.write(\"a.txt\", 5)", "fs.write espera un contenido string, obtenido *evaluator.Integer"},
+		{"fs.exists()", "fs.exists espera 1 argumento, obtenidos 0"},
+	}
+
+	for _, tt := range tests {
+		err := evalError(t, tt.input)
+		if err == nil || !strings.HasPrefix(err.Error(), tt.expected) {
+			t.Errorf("%s: se esperaba un error que empiece por %q, obtenido %v", tt.input, tt.expected, err)
+		}
+	}
+
+	// Los errores del sistema de archivos se pueden capturar
+	input := "r := \"\"\ntry {\n    fs.read(" + missing + ")\n} catch (e) {\n    r = \"capturado\"\n}\nr"
+	testStringObject(t, testEval(input), "capturado")
+}
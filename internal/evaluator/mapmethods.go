@@ -0,0 +1,118 @@
+package evaluator
+
+// Métodos de los maps: m.get(key), m.set(key, value), m.has(key),
+// m.delete(key), m.keys() y m.values(). set y delete modifican el map. Una
+// clave con el mismo nombre que un método tiene prioridad sobre él al usar
+// m.nombre, igual que en los objetos de módulo como fs o time; en ese caso
+// sigue disponible la indexación m["nombre"].
+
+import "fmt"
+
+// Delete quita key del map y devuelve si existía
+func (m *MapObject) Delete(key string) bool {
+	if _, exists := m.Pairs[key]; !exists {
+		return false
+	}
+	delete(m.Pairs, key)
+	for i, k := range m.Order {
+		if k == key {
+			m.Order = append(m.Order[:i:i], m.Order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// evaluateMapMember resuelve m.name cuando name es uno de los métodos de los
+// maps
+func evaluateMapMember(m *MapObject, name string) (Value, bool) {
+	switch name {
+	case "get":
+		return &BuiltinFunction{Name: "Map.get", Fn: func(args []Value) (Value, error) {
+			if len(args) < 1 || len(args) > 2 {
+				return nil, fmt.Errorf("get() espera 1 o 2 argumentos")
+			}
+			key, err := mapKeyArg("get", args[0])
+			if err != nil {
+				return nil, err
+			}
+			if value, exists := m.Pairs[key]; exists {
+				return value, nil
+			}
+			// El segundo argumento es el valor por defecto
+			if len(args) == 2 {
+				return args[1], nil
+			}
+			return &Null{}, nil
+		}}, true
+	case "set":
+		return &BuiltinFunction{Name: "Map.set", Fn: func(args []Value) (Value, error) {
+			if len(args) != 2 {
+				return nil, fmt.Errorf("set() espera 2 argumentos")
+			}
+			key, err := mapKeyArg("set", args[0])
+			if err != nil {
+				return nil, err
+			}
+			m.Set(key, args[1])
+			return &Null{}, nil
+		}}, true
+	case "has":
+		return &BuiltinFunction{Name: "Map.has", Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("has() espera 1 argumento")
+			}
+			key, err := mapKeyArg("has", args[0])
+			if err != nil {
+				return nil, err
+			}
+			_, exists := m.Pairs[key]
+			return &Boolean{Value: exists}, nil
+		}}, true
+	case "delete":
+		return &BuiltinFunction{Name: "Map.delete", Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("delete() espera 1 argumento")
+			}
+			key, err := mapKeyArg("delete", args[0])
+			if err != nil {
+				return nil, err
+			}
+			return &Boolean{Value: m.Delete(key)}, nil
+		}}, true
+	case "keys":
+		return &BuiltinFunction{Name: "Map.keys", Fn: func(args []Value) (Value, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("keys() no espera argumentos")
+			}
+			keys := m.Keys()
+			items := make([]Value, len(keys))
+			for i, k := range keys {
+				items[i] = &String{Value: k}
+			}
+			return &List{Items: items}, nil
+		}}, true
+	case "values":
+		return &BuiltinFunction{Name: "Map.values", Fn: func(args []Value) (Value, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("values() no espera argumentos")
+			}
+			keys := m.Keys()
+			items := make([]Value, len(keys))
+			for i, k := range keys {
+				items[i] = m.Pairs[k]
+			}
+			return &List{Items: items}, nil
+		}}, true
+	}
+	return nil, false
+}
+
+// mapKeyArg valida la clave que recibe un método de map
+func mapKeyArg(method string, arg Value) (string, error) {
+	key, ok := arg.(*String)
+	if !ok {
+		return "", fmt.Errorf("%s() espera una clave string, obtenido %T", method, arg)
+	}
+	return key.Value, nil
+}
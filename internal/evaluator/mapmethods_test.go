@@ -0,0 +1,51 @@
+package evaluator
+
+import "testing"
+
+func TestMapMethods(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`{"a": 1}.get("a")`, 1},
+		{`{"a": 1}.get("b", 5)`, 5},
+		{`{"a": 1}.has("a")`, true},
+		{`{"a": 1}.has("b")`, false},
+		{"m := {\"a\": 1}\nm.set(\"b\", 2)\nm[\"b\"]", 2},
+		{"m := {\"a\": 1}\nm.set(\"a\", 3)\nlen(m.keys())", 1},
+		{"m := {\"a\": 1}\nm.delete(\"a\")", true},
+		{"m := {\"a\": 1}\nm.delete(\"b\")", false},
+		{"m := {\"a\": 1, \"b\": 2}\nm.delete(\"a\")\nm.has(\"a\")", false},
+		// Las claves se recorren en orden de inserción; una clave borrada y
+		// vuelta a añadir pasa al final
+		{"m := {\"a\": 1, \"b\": 2}\nm.delete(\"a\")\nm.set(\"a\", 3)\njoin(m.keys(), \",\")", "b,a"},
+		{"m := {\"x\": 1, \"y\": 2}\nm.values()[1]", 2},
+		// Una clave con el nombre de un método tiene prioridad
+		{"m := {\"keys\": 7}\nm.keys", 7},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+
+	if _, ok := testEval(`{"a": 1}.get("b")`).(*Null); !ok {
+		t.Error("get() de una clave que no existe debería devolver null")
+	}
+}
+
+func TestMapMethodErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`{"a": 1}.get(1)`, "get() espera una clave string, obtenido *evaluator.Integer"},
+		{`{"a": 1}.get()`, "get() espera 1 o 2 argumentos"},
+		{`{"a": 1}.set("b")`, "set() espera 2 argumentos"},
+		{`{"a": 1}.keys(1)`, "keys() no espera argumentos"},
+		{`{"a": 1}.delete(true)`, "delete() espera una clave string, obtenido *evaluator.Boolean"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
@@ -0,0 +1,192 @@
+package evaluator
+
+// El módulo math está disponible sin import, como http y json. Sus funciones
+// aceptan enteros o floats y devuelven floats, igual que los tipos que
+// anuncia el analizador semántico.
+
+import (
+	"fmt"
+	"math"
+)
+
+// newMathModule crea el objeto math con las funciones del paquete math de Go
+func newMathModule() *MapObject {
+	unary := map[string]func(float64) float64{
+		"sqrt":  math.Sqrt,
+		"abs":   math.Abs,
+		"floor": math.Floor,
+		"ceil":  math.Ceil,
+		"round": math.Round,
+		"sin":   math.Sin,
+		"cos":   math.Cos,
+		"tan":   math.Tan,
+	}
+	binary := map[string]func(float64, float64) float64{
+		"pow":   math.Pow,
+		"power": math.Pow,
+		"min":   math.Min,
+		"max":   math.Max,
+	}
+
+	module := &MapObject{Pairs: map[string]Value{
+		"pi": &Float{Value: math.Pi},
+		"e":  &Float{Value: math.E},
+		"PI": &Float{Value: math.Pi},
+		"E":  &Float{Value: math.E},
+	}}
+	for name, fn := range unary {
+		module.Pairs[name] = mathFunction(name, 1, func(x []float64) (float64, error) {
+			if name == "sqrt" && x[0] < 0 {
+				return 0, fmt.Errorf("math.sqrt espera un número no negativo, obtenido %g", x[0])
+			}
+			return fn(x[0]), nil
+		})
+	}
+	for name, fn := range binary {
+		module.Pairs[name] = mathFunction(name, 2, func(x []float64) (float64, error) {
+			return fn(x[0], x[1]), nil
+		})
+	}
+	return module
+}
+
+// mathFunction adapta una función de floats a un builtin de Zylo
+func mathFunction(name string, arity int, fn func([]float64) (float64, error)) *BuiltinFunction {
+	return &BuiltinFunction{
+		Name: "math." + name,
+		Fn: func(args []Value) (Value, error) {
+			if len(args) != arity {
+				return nil, fmt.Errorf("math.%s espera %s, obtenidos %d", name, argumentCount(arity), len(args))
+			}
+			x := make([]float64, arity)
+			for i, arg := range args {
+				switch n := arg.(type) {
+				case *Integer:
+					x[i] = float64(n.Value)
+				case *Float:
+					x[i] = n.Value
+				default:
+					return nil, fmt.Errorf("math.%s espera números, obtenido %T", name, arg)
+				}
+			}
+			result, err := fn(x)
+			if err != nil {
+				return nil, err
+			}
+			return &Float{Value: result}, nil
+		},
+	}
+}
+
+// registerMathBuiltins registra abs, round, floor, ceil, sqrt, pow y
+// floor_div como funciones globales. A diferencia de las del objeto math, abs, round, floor
+// y ceil conservan el tipo: con un entero devuelven el mismo entero.
+func (e *Evaluator) registerMathBuiltins() {
+	rounding := map[string]func(float64) float64{
+		"abs":   math.Abs,
+		"round": math.Round,
+		"floor": math.Floor,
+		"ceil":  math.Ceil,
+	}
+	for name, fn := range rounding {
+		name, fn := name, fn
+		e.env.Set(name, &BuiltinFunction{
+			Name: name,
+			Fn: func(args []Value) (Value, error) {
+				if len(args) != 1 {
+					return nil, fmt.Errorf("%s() espera 1 argumento, obtenidos %d", name, len(args))
+				}
+				switch n := args[0].(type) {
+				case *Integer:
+					if name != "abs" || n.Value >= 0 {
+						return n, nil
+					}
+					if n.Value == math.MinInt64 {
+						return nil, fmt.Errorf("abs() desborda int: %d", n.Value)
+					}
+					return &Integer{Value: -n.Value}, nil
+				case *Float:
+					return &Float{Value: fn(n.Value)}, nil
+				}
+				return nil, fmt.Errorf("%s() espera un número, obtenido %s", name, getNormalizedType(args[0]))
+			},
+		})
+	}
+
+	e.env.Set("sqrt", &BuiltinFunction{Name: "sqrt", Fn: func(args []Value) (Value, error) {
+		x, err := floatArgs("sqrt", args, 1)
+		if err != nil {
+			return nil, err
+		}
+		if x[0] < 0 {
+			return nil, fmt.Errorf("sqrt() espera un número no negativo, obtenido %g", x[0])
+		}
+		return &Float{Value: math.Sqrt(x[0])}, nil
+	}})
+
+	e.env.Set("pow", &BuiltinFunction{Name: "pow", Fn: func(args []Value) (Value, error) {
+		x, err := floatArgs("pow", args, 2)
+		if err != nil {
+			return nil, err
+		}
+		return &Float{Value: math.Pow(x[0], x[1])}, nil
+	}})
+
+	e.env.Set("floor_div", &BuiltinFunction{Name: "floor_div", Fn: func(args []Value) (Value, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("floor_div() espera 2 argumentos, obtenidos %d", len(args))
+		}
+		return floorDivide(args[0], args[1])
+	}})
+}
+
+// floorDivide divide redondeando hacia -infinito: con dos enteros devuelve un
+// entero y, si alguno es float, un float sin decimales
+func floorDivide(left, right Value) (Value, error) {
+	if l, ok := left.(*Integer); ok {
+		if r, ok := right.(*Integer); ok {
+			if r.Value == 0 {
+				return nil, fmt.Errorf("división por cero")
+			}
+			quotient := l.Value / r.Value
+			// La división entera de Go trunca hacia cero
+			if l.Value%r.Value != 0 && (l.Value < 0) != (r.Value < 0) {
+				quotient--
+			}
+			return &Integer{Value: quotient}, nil
+		}
+	}
+	l, r, ok := floatOperands(left, right)
+	if !ok {
+		for _, arg := range []Value{left, right} {
+			switch arg.(type) {
+			case *Integer, *Float:
+			default:
+				return nil, fmt.Errorf("floor_div() espera números, obtenido %s", getNormalizedType(arg))
+			}
+		}
+	}
+	if r == 0 {
+		return nil, fmt.Errorf("división por cero")
+	}
+	return &Float{Value: math.Floor(l / r)}, nil
+}
+
+// floatArgs comprueba que args sean arity números y los devuelve como floats
+func floatArgs(name string, args []Value, arity int) ([]float64, error) {
+	if len(args) != arity {
+		return nil, fmt.Errorf("%s() espera %s, obtenidos %d", name, argumentCount(arity), len(args))
+	}
+	x := make([]float64, arity)
+	for i, arg := range args {
+		switch n := arg.(type) {
+		case *Integer:
+			x[i] = float64(n.Value)
+		case *Float:
+			x[i] = n.Value
+		default:
+			return nil, fmt.Errorf("%s() espera números, obtenido %s", name, getNormalizedType(arg))
+		}
+	}
+	return x, nil
+}
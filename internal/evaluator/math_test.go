@@ -0,0 +1,115 @@
+package evaluator
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMathModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected float64
+	}{
+		{`math.sqrt(9)`, 3},
+		{`math.sqrt(2.25)`, 1.5},
+		{`math.pow(2, 10)`, 1024},
+		{`math.power(9, 0.5)`, 3},
+		{`math.abs(-3)`, 3},
+		{`math.abs(-2.5)`, 2.5},
+		{`math.floor(2.7)`, 2},
+		{`math.ceil(2.1)`, 3},
+		{`math.round(2.5)`, 3},
+		{`math.round(-2.5)`, -3},
+		{`math.min(3, 7)`, 3},
+		{`math.max(3, 7.5)`, 7.5},
+		{`math.sin(0)`, 0},
+		{`math.cos(0)`, 1},
+		{`math.pi`, math.Pi},
+		{`math.e`, math.E},
+		{`math.PI * 2`, 2 * math.Pi},
+		{`math.sqrt(math.pow(3, 2) + math.pow(4, 2))`, 5},
+	}
+
+	for _, tt := range tests {
+		testFloatObject(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMathModuleErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`math.sqrt(-1)`, "math.sqrt espera un número no negativo, obtenido -1"},
+		{`math.sqrt("9")`, "math.sqrt espera números, obtenido *evaluator.String"},
+		{`math.pow(2)`, "math.pow espera 2 argumentos, obtenidos 1"},
+		{`math.abs()`, "math.abs espera 1 argumento, obtenidos 0"},
+	}
+
+	for _, tt := range tests {
+		testEvalErrorExact(t, tt.input, tt.expected)
+	}
+}
+
+func TestMathBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		// Con un entero, abs, round, floor y ceil devuelven un entero
+		{`round(3)`, 3},
+		{`floor(-4)`, -4},
+		{`ceil(7)`, 7},
+		{`abs(-5)`, 5},
+		{`abs(5)`, 5},
+		// Con un float, un float
+		{`round(3.7)`, 4.0},
+		{`round(-2.5)`, -3.0},
+		{`floor(-2.5)`, -3.0},
+		{`ceil(2.1)`, 3.0},
+		{`abs(-1.5)`, 1.5},
+		// sqrt y pow siempre devuelven un float, como math.sqrt y math.pow
+		{`sqrt(16)`, 4.0},
+		{`pow(2, 10)`, 1024.0},
+		{`pow(9, 0.5)`, 3.0},
+		{`round(2.4) + floor(3)`, 5.0},
+		// floor_div redondea hacia -infinito y solo devuelve int con dos ints
+		{`floor_div(7, 2)`, 3},
+		{`floor_div(-7, 2)`, -4},
+		{`floor_div(7, -2)`, -4},
+		{`floor_div(-8, 2)`, -4},
+		{`floor_div(7.5, 2)`, 3.0},
+		{`floor_div(-7.5, 2)`, -4.0},
+		{`floor_div(7, 2.5)`, 2.0},
+		// Una función del script con el mismo nombre tiene prioridad
+		{"func abs(x) {\n    return 0\n}\nabs(-3)", 0},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestMathBuiltinErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`round("3")`, "round() espera un número, obtenido string"},
+		{`abs(true)`, "abs() espera un número, obtenido bool"},
+		{`floor(1, 2)`, "floor() espera 1 argumento, obtenidos 2"},
+		{`abs(-9223372036854775807 - 1)`, "abs() desborda int: -9223372036854775808"},
+		{`sqrt(-4)`, "sqrt() espera un número no negativo, obtenido -4"},
+		{`pow(2)`, "pow() espera 2 argumentos, obtenidos 1"},
+		{`pow(2, "3")`, "pow() espera números, obtenido string"},
+		{`floor_div(5, 0)`, "división por cero"},
+		{`floor_div(5.5, 0)`, "división por cero"},
+		{`floor_div(5, 0.0)`, "división por cero"},
+		{`floor_div(5)`, "floor_div() espera 2 argumentos, obtenidos 1"},
+		{`floor_div("5", 2)`, "floor_div() espera números, obtenido string"},
+	}
+
+	for _, tt := range tests {
+		testEvalErrorExact(t, tt.input, tt.expected)
+	}
+}
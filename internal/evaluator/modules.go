@@ -0,0 +1,165 @@
+package evaluator
+
+// import "std/<nombre>" carga un módulo de la biblioteca estándar escrito en
+// Zylo: primero se busca el archivo std/<nombre>.zylo relativo al directorio
+// base del programa y, si no existe, el módulo incluido en el ejecutable.
+// Cualquier otra ruta es un archivo local relativo al directorio base
+// (import "./utils") o, si no existe, un paquete instalado en zylo_modules/.
+// El módulo se evalúa una sola vez, en su propio entorno, y sus funciones,
+// clases y variables de nivel superior quedan en un objeto con el nombre del
+// módulo.
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/packages"
+	"github.com/zylo-lang/zylo/internal/parser"
+	"github.com/zylo-lang/zylo/internal/stdlib"
+)
+
+// moduleRegistry guarda los módulos ya cargados; lo comparten el evaluador,
+// los de spawn y los de los propios módulos
+type moduleRegistry struct {
+	mu        sync.Mutex
+	loaded    map[string]*MapObject
+	loading   []string // Módulos que se están cargando, para detectar imports circulares
+	snapshots []func() // Restauran el estado de cada módulo tras evaluarlo (zylo test)
+}
+
+func newModuleRegistry() *moduleRegistry {
+	return &moduleRegistry{loaded: make(map[string]*MapObject)}
+}
+
+// SetBaseDir fija el directorio desde el que se resuelven los imports,
+// normalmente el del archivo que se ejecuta
+func (e *Evaluator) SetBaseDir(dir string) {
+	e.baseDir = dir
+}
+
+// importModule carga el módulo modulePath de un import con ruta
+func (e *Evaluator) importModule(modulePath string) (*MapObject, error) {
+	if strings.HasPrefix(modulePath, "std/") {
+		if module, ok := nativeModule(moduleBindingName(modulePath)); ok {
+			return module, nil
+		}
+	}
+	path, content, err := e.findModule(modulePath)
+	if err != nil {
+		return nil, err
+	}
+
+	// El evaluador de un módulo en carga ya tiene el registro bloqueado
+	if !e.loadingModule {
+		e.modules.mu.Lock()
+		defer e.modules.mu.Unlock()
+	}
+	return e.loadModule(path, content)
+}
+
+// findModule devuelve la ruta y el código del módulo modulePath
+func (e *Evaluator) findModule(modulePath string) (string, []byte, error) {
+	if !strings.HasPrefix(modulePath, "std/") {
+		if path, content, ok := localModule(e.baseDir, modulePath); ok {
+			return path, content, nil
+		}
+		path, ok := packages.Resolve(e.baseDir, modulePath)
+		if !ok {
+			return "", nil, fmt.Errorf("módulo no encontrado: %s", modulePath)
+		}
+		content, err := os.ReadFile(path)
+		return path, content, err
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(modulePath, "std/"), ".zylo")
+
+	path := filepath.Join(e.baseDir, "std", name+".zylo")
+	if content, err := os.ReadFile(path); err == nil {
+		return path, content, nil
+	}
+	source, ok := stdlib.Source(name)
+	if !ok {
+		return "", nil, fmt.Errorf("módulo no encontrado: %s", modulePath)
+	}
+	return "std/" + name + ".zylo", []byte(source), nil
+}
+
+// localModule busca el archivo de un import local ("./utils", "lib/texto.zylo"),
+// relativo a baseDir y con o sin extensión .zylo. La ruta devuelta es absoluta
+// para que un mismo archivo importado por caminos distintos se cargue una vez.
+func localModule(baseDir, modulePath string) (string, []byte, bool) {
+	path := filepath.Join(baseDir, modulePath)
+	if filepath.Ext(path) != ".zylo" {
+		path += ".zylo"
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return "", nil, false
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, false
+	}
+	return path, content, true
+}
+
+// loadModule evalúa el módulo path si no se cargó antes. Se llama con el
+// registro bloqueado.
+func (e *Evaluator) loadModule(path string, content []byte) (*MapObject, error) {
+	if module, ok := e.modules.loaded[path]; ok {
+		return module, nil
+	}
+	for i, loading := range e.modules.loading {
+		if loading == path {
+			var cycle []string
+			for _, m := range append(e.modules.loading[i:], path) {
+				cycle = append(cycle, filepath.Base(m))
+			}
+			return nil, fmt.Errorf("import circular: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	e.modules.loading = append(e.modules.loading, path)
+	defer func() { e.modules.loading = e.modules.loading[:len(e.modules.loading)-1] }()
+
+	p := parser.New(lexer.New(string(content)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		return nil, fmt.Errorf("error en el módulo %s: %s", path, p.Errors()[0])
+	}
+
+	// Los imports del módulo se resuelven desde su propio directorio
+	eval := NewEvaluator()
+	eval.baseDir = filepath.Dir(path)
+	eval.modules = e.modules
+	eval.loadingModule = true
+	eval.localImports = e.localImports
+	eval.assertions = e.assertions
+	// Los módulos incluidos en el ejecutable no forman parte de la cobertura
+	if e.coverage != nil && !strings.HasPrefix(path, "std/") {
+		e.coverage.AddProgram(path, program)
+		eval.coverage = e.coverage
+	}
+	if err := eval.EvaluateProgram(program); err != nil {
+		return nil, fmt.Errorf("error en el módulo %s: %s", path, FormatError(path, err))
+	}
+
+	module := &MapObject{Pairs: make(map[string]Value)}
+	for _, name := range program.Exports() {
+		if value, ok := eval.env.Get(name); ok {
+			module.Pairs[name] = value
+		}
+	}
+	e.modules.loaded[path] = module
+	e.modules.snapshots = append(e.modules.snapshots, eval.env.snapshot())
+	return module, nil
+}
+
+// moduleBindingName devuelve el nombre con el que se enlaza un import con
+// ruta: el último elemento sin la extensión .zylo
+func moduleBindingName(modulePath string) string {
+	parts := strings.Split(modulePath, "/")
+	return strings.TrimSuffix(parts[len(parts)-1], ".zylo")
+}
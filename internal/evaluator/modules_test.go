@@ -0,0 +1,211 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func TestStdlibImports(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"import \"std/collections\"\nlen(collections.chunk([1, 2, 3, 4, 5], 2))", 3},
+		{"import \"std/collections\"\njoin(collections.unique([\"a\", \"b\", \"a\"]), \",\")", "a,b"},
+		{"import \"std/collections.zylo\"\nlen(collections.sequence(0, 6, 2))", 3},
+		{"import \"std/text\"\ntext.pad_left(\"7\", 3, \"0\")", "007"},
+		{"import \"std/text\"\ntext.capitalize(\"zylo\") + text.repeat(\"!\", 2)", "Zylo!!"},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestStringModuleImport(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"import \"std/string\"\nlen(string.split(\"a,b,c\", \",\"))", 3},
+		{"import \"std/string\"\nstring.trim(\"  hola \")", "hola"},
+		{"import \"std/string\"\nstring.upper(\"hola\") + string.lower(\"ZYLO\")", "HOLAzylo"},
+		{"import \"std/string.zylo\"\nstring.replace(\"a-b\", \"-\", \"+\")", "a+b"},
+		{"import string\nstring.join([\"a\", \"b\"], \"/\")", "a/b"},
+		// El módulo no oculta la conversión string()
+		{"import \"std/string\"\nstring(5) + string.upper(\"a\")", "5A"},
+		{"import string\nimport \"std/string\"\nlen(string([1, 2])) + len(string.split(\"a,b\", \",\"))", 8},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+
+	result := testEval("import \"std/string\"\nstring.contains(\"hola\", \"ol\")")
+	if b, ok := result.(*Boolean); !ok || !b.Value {
+		t.Errorf("se esperaba true, obtenido %v", result)
+	}
+}
+
+// El módulo nativo string tiene prioridad sobre std/string.zylo, como en el
+// analizador semántico
+func TestStringModulePrefersNative(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "std"), 0755); err != nil {
+		t.Fatalf("error creando std/: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "std", "string.zylo"), []byte("func upper(s) {\n    return \"local\"\n}\n"), 0644); err != nil {
+		t.Fatalf("error escribiendo el módulo: %v", err)
+	}
+
+	p := parser.New(lexer.New("import \"std/string\"\nr := string.upper(\"a\")"))
+	eval := NewEvaluator()
+	eval.SetBaseDir(dir)
+	if err := eval.EvaluateProgram(p.ParseProgram()); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	r, _ := eval.env.Get("r")
+	testStringObject(t, r, "A")
+}
+
+func TestStdlibImportPrefersLocalFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "std"), 0755); err != nil {
+		t.Fatalf("error creando std/: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "std", "text.zylo"), []byte("func repeat(s, n) {\n    return \"local\"\n}\n"), 0644); err != nil {
+		t.Fatalf("error escribiendo el módulo: %v", err)
+	}
+
+	p := parser.New(lexer.New("import \"std/text\"\nr := text.repeat(\"a\", 2)"))
+	eval := NewEvaluator()
+	eval.SetBaseDir(dir)
+	if err := eval.EvaluateProgram(p.ParseProgram()); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	r, _ := eval.env.Get("r")
+	testStringObject(t, r, "local")
+}
+
+func TestStdlibImportErrors(t *testing.T) {
+	input := "import \"std/no_existe\""
+	p := parser.New(lexer.New(input))
+	err := NewEvaluator().EvaluateProgram(p.ParseProgram())
+	if err == nil || FormatError("main.zylo", err) != "main.zylo:1:1: módulo no encontrado: std/no_existe" {
+		t.Errorf("se esperaba un error de módulo no encontrado, obtenido %v", err)
+	}
+}
+
+// writeModuleFiles escribe los archivos files (ruta relativa -> código) en dir
+func writeModuleFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, source := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("error creando %s: %v", filepath.Dir(path), err)
+		}
+		if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+			t.Fatalf("error escribiendo %s: %v", name, err)
+		}
+	}
+}
+
+// evalInDir evalúa input con dir como directorio base
+func evalInDir(dir, input string) (*Evaluator, error) {
+	p := parser.New(lexer.New(input))
+	eval := NewEvaluator()
+	eval.SetBaseDir(dir)
+	return eval, eval.EvaluateProgram(p.ParseProgram())
+}
+
+func TestLocalImports(t *testing.T) {
+	dir := t.TempDir()
+	writeModuleFiles(t, dir, map[string]string{
+		// Los imports de un módulo son relativos a su propio directorio
+		"utils.zylo":     "import \"./lib/texto\"\nfunc saludar(n) {\n    return texto.prefijo() + n\n}\nprivate func oculto() {\n    return 1\n}\n",
+		"lib/texto.zylo": "func prefijo() {\n    return \"Hola, \"\n}\n",
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"import \"./utils\"\nr := utils.saludar(\"Ana\")", "Hola, Ana"},
+		{"import \"./utils.zylo\"\nr := utils.saludar(\"Luis\")", "Hola, Luis"},
+		{"import \"lib/texto\"\nr := texto.prefijo()", "Hola, "},
+	}
+	for _, tt := range tests {
+		eval, err := evalInDir(dir, tt.input)
+		if err != nil {
+			t.Fatalf("%q: error inesperado: %v", tt.input, err)
+		}
+		r, _ := eval.env.Get("r")
+		testStringObject(t, r, tt.expected)
+	}
+
+	// Las funciones privadas no se exportan
+	eval, err := evalInDir(dir, "import \"./utils\"")
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	utils, _ := eval.env.Get("utils")
+	if _, ok := utils.(*MapObject).Pairs["oculto"]; ok {
+		t.Errorf("la función privada oculto no debería exportarse")
+	}
+}
+
+func TestExportedImports(t *testing.T) {
+	dir := t.TempDir()
+	writeModuleFiles(t, dir, map[string]string{
+		"saludo.zylo": "func ayuda() {\n    return \"Hola, \"\n}\nexport func saludar(n) {\n    return ayuda() + n\n}\nexport VERSION := 2\npublic class Saludo {\n}\n",
+	})
+
+	eval, err := evalInDir(dir, "import \"./saludo\"\nr := saludo.saludar(\"Ana\")")
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	r, _ := eval.env.Get("r")
+	testStringObject(t, r, "Hola, Ana")
+
+	module, _ := eval.env.Get("saludo")
+	var names []string
+	for name := range module.(*MapObject).Pairs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	if strings.Join(names, " ") != "Saludo VERSION saludar" {
+		t.Errorf("solo se deberían exportar saludar, VERSION y Saludo, obtenidos %v", names)
+	}
+
+	if _, err := evalInDir(dir, "import \"./saludo\"\nsaludo.ayuda()"); err == nil {
+		t.Errorf("ayuda no está exportada: se esperaba un error")
+	}
+}
+
+func TestLocalImportErrors(t *testing.T) {
+	dir := t.TempDir()
+	writeModuleFiles(t, dir, map[string]string{
+		"a.zylo": "import \"./b\"\n",
+		"b.zylo": "import \"./a\"\n",
+	})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"import \"./no_existe\"", "main.zylo:1:1: módulo no encontrado: ./no_existe"},
+		{"import \"./a\"", "import circular: a.zylo -> b.zylo -> a.zylo"},
+	}
+	for _, tt := range tests {
+		_, err := evalInDir(dir, tt.input)
+		if err == nil || !strings.Contains(FormatError("main.zylo", err), tt.expected) {
+			t.Errorf("%q: se esperaba el error %q, obtenido %v", tt.input, tt.expected, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package evaluator
+
+// El objeto os da acceso al entorno del proceso: variables de entorno, los
+// argumentos con los que se ejecutó el script y el código de salida.
+
+import (
+	"fmt"
+	"os"
+)
+
+// SetArgs establece los argumentos del script que devuelve os.args()
+func (e *Evaluator) SetArgs(args []string) {
+	e.args = args
+}
+
+// newOSModule crea el objeto os
+func (e *Evaluator) newOSModule() *MapObject {
+	module := &MapObject{Pairs: make(map[string]Value)}
+	module.Set("getenv", &BuiltinFunction{Name: "os.getenv", Fn: osGetenv})
+	module.Set("setenv", &BuiltinFunction{Name: "os.setenv", Fn: osSetenv})
+	module.Set("args", &BuiltinFunction{Name: "os.args", Fn: e.osArgs})
+	module.Set("exit", &BuiltinFunction{Name: "os.exit", Fn: osExit})
+	return module
+}
+
+// osGetenv implementa os.getenv(name): el valor de la variable, o null si no
+// está definida
+func osGetenv(args []Value) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("os.getenv espera 1 argumento, obtenidos %d", len(args))
+	}
+	name, ok := args[0].(*String)
+	if !ok {
+		return nil, fmt.Errorf("os.getenv espera un string")
+	}
+	value, exists := os.LookupEnv(name.Value)
+	if !exists {
+		return &Null{}, nil
+	}
+	return &String{Value: value}, nil
+}
+
+// osSetenv implementa os.setenv(name, value)
+func osSetenv(args []Value) (Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("os.setenv espera 2 argumentos, obtenidos %d", len(args))
+	}
+	name, ok := args[0].(*String)
+	if !ok {
+		return nil, fmt.Errorf("os.setenv espera un nombre string")
+	}
+	value, ok := args[1].(*String)
+	if !ok {
+		return nil, fmt.Errorf("os.setenv espera un valor string")
+	}
+	if err := os.Setenv(name.Value, value.Value); err != nil {
+		return nil, fmt.Errorf("os.setenv: %v", err)
+	}
+	return &Null{}, nil
+}
+
+// osArgs implementa os.args(): los argumentos que siguen al nombre del script
+func (e *Evaluator) osArgs(args []Value) (Value, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("os.args espera 0 argumentos, obtenidos %d", len(args))
+	}
+	items := make([]Value, len(e.args))
+	for i, arg := range e.args {
+		items[i] = &String{Value: arg}
+	}
+	return &List{Items: items}, nil
+}
+
+// osExit implementa os.exit(code?): termina el programa con code, 0 si no se
+// indica
+func osExit(args []Value) (Value, error) {
+	if len(args) > 1 {
+		return nil, fmt.Errorf("os.exit espera 0 o 1 argumentos, obtenidos %d", len(args))
+	}
+	code := 0
+	if len(args) == 1 {
+		n, ok := args[0].(*Integer)
+		if !ok {
+			return nil, fmt.Errorf("os.exit espera un código de salida entero")
+		}
+		code = int(n.Value)
+	}
+	return nil, &ExitError{Code: code}
+}
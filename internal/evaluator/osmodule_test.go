@@ -0,0 +1,92 @@
+package evaluator
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func TestOSModule(t *testing.T) {
+	t.Setenv("ZYLO_OS_TEST", "valor")
+	os.Unsetenv("ZYLO_OS_NO_DEFINIDA")
+
+	testStringObject(t, testEval(`os.getenv("ZYLO_OS_TEST")`), "valor")
+	if _, ok := testEval(`os.getenv("ZYLO_OS_NO_DEFINIDA")`).(*Null); !ok {
+		t.Error("os.getenv de una variable no definida debería devolver null")
+	}
+
+	t.Setenv("ZYLO_OS_SET", "")
+	testStringObject(t, testEval("os.setenv(\"ZYLO_OS_SET\", \"nuevo\")\nos.getenv(\"ZYLO_OS_SET\")"), "nuevo")
+	if got := os.Getenv("ZYLO_OS_SET"); got != "nuevo" {
+		t.Errorf("os.setenv no cambió el entorno del proceso: %q", got)
+	}
+}
+
+func TestOSArgs(t *testing.T) {
+	eval := NewEvaluator()
+	eval.SetArgs([]string{"uno", "--dos"})
+	p := parser.New(lexer.New("args := os.args()"))
+	if err := eval.EvaluateProgram(p.ParseProgram()); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	value, _ := eval.env.Get("args")
+	list, ok := value.(*List)
+	if !ok || len(list.Items) != 2 {
+		t.Fatalf("se esperaba una lista de 2 argumentos, obtenido %v", value)
+	}
+	testStringObject(t, list.Items[0], "uno")
+	testStringObject(t, list.Items[1], "--dos")
+
+	if list, ok := testEval("os.args()").(*List); !ok || len(list.Items) != 0 {
+		t.Errorf("sin argumentos os.args() debería ser una lista vacía")
+	}
+}
+
+func TestOSExit(t *testing.T) {
+	tests := []struct {
+		input string
+		code  int
+	}{
+		{"os.exit(3)", 3},
+		{"os.exit()", 0},
+		// Ni un catch ni una función que lo atraviese cambian la salida
+		{"try {\n    os.exit(2)\n} catch (e) {\n    show.log(e)\n}", 2},
+		{"func salir() {\n    os.exit(4)\n}\nsalir()\nshow.log(\"no llega\")", 4},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("%s: parser errors: %v", tt.input, p.Errors())
+		}
+		err := NewEvaluator().EvaluateProgram(program)
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) {
+			t.Errorf("%s: se esperaba un ExitError, obtenido %v", tt.input, err)
+			continue
+		}
+		if exitErr.Code != tt.code {
+			t.Errorf("%s: código esperado %d, obtenido %d", tt.input, tt.code, exitErr.Code)
+		}
+	}
+}
+
+func TestOSModuleErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`os.getenv(1)`, "os.getenv espera un string"},
+		{`os.setenv("A")`, "os.setenv espera 2 argumentos, obtenidos 1"},
+		{`os.exit("1")`, "os.exit espera un código de salida entero"},
+		{`os.args(1)`, "os.args espera 0 argumentos, obtenidos 1"},
+	}
+
+	for _, tt := range tests {
+		testEvalErrorExact(t, tt.input, tt.expected)
+	}
+}
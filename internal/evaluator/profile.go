@@ -0,0 +1,105 @@
+package evaluator
+
+// Perfilado de funciones (zylo run --profile). Cada llamada a una función o
+// método Zylo anota su tiempo de reloj al entrar y al salir. El tiempo propio
+// de una llamada es el suyo menos el de las llamadas que hace; el acumulado
+// cuenta solo la llamada más externa de cada función en la pila, para que la
+// recursión no sume el mismo tiempo varias veces.
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Profile acumula el tiempo por función a lo largo de todos los evaluadores
+// que lo comparten
+type Profile struct {
+	mu        sync.Mutex
+	functions map[string]*FunctionProfile
+}
+
+// FunctionProfile es el tiempo de una función en todas sus llamadas
+type FunctionProfile struct {
+	Name  string
+	Calls int
+	Total time.Duration // Tiempo acumulado, incluidas las llamadas que hace
+	Self  time.Duration // Tiempo en el cuerpo de la función, sin sus llamadas
+}
+
+// profileFrame es una llamada activa en la pila del perfilado
+type profileFrame struct {
+	name     string
+	start    time.Time
+	children time.Duration // Tiempo de las llamadas hechas desde esta
+}
+
+// NewProfile crea un perfil vacío
+func NewProfile() *Profile {
+	return &Profile{functions: make(map[string]*FunctionProfile)}
+}
+
+// SetProfile activa el perfilado en el evaluador; con nil se desactiva (por
+// defecto)
+func (e *Evaluator) SetProfile(p *Profile) {
+	e.profile = p
+}
+
+// profileCall anota la entrada a name y devuelve la función que anota la
+// salida
+func (e *Evaluator) profileCall(name string) func() {
+	e.profileStack = append(e.profileStack, profileFrame{name: name, start: time.Now()})
+	return func() {
+		last := len(e.profileStack) - 1
+		frame := e.profileStack[last]
+		e.profileStack = e.profileStack[:last]
+
+		elapsed := time.Since(frame.start)
+		recursive := false
+		for i := range e.profileStack {
+			if e.profileStack[i].name == name {
+				recursive = true
+			}
+		}
+		if last > 0 {
+			e.profileStack[last-1].children += elapsed
+		}
+		e.profile.record(name, elapsed, elapsed-frame.children, recursive)
+	}
+}
+
+// record suma una llamada a name. Si la función ya estaba en la pila, su
+// tiempo total lo cuenta la llamada más externa.
+func (p *Profile) record(name string, elapsed, self time.Duration, recursive bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	f, ok := p.functions[name]
+	if !ok {
+		f = &FunctionProfile{Name: name}
+		p.functions[name] = f
+	}
+	f.Calls++
+	f.Self += self
+	if !recursive {
+		f.Total += elapsed
+	}
+}
+
+// Report devuelve el perfil de cada función llamada, de mayor a menor tiempo
+// acumulado
+func (p *Profile) Report() []FunctionProfile {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	report := make([]FunctionProfile, 0, len(p.functions))
+	for _, f := range p.functions {
+		report = append(report, *f)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Total != report[j].Total {
+			return report[i].Total > report[j].Total
+		}
+		return report[i].Name < report[j].Name
+	})
+	return report
+}
@@ -0,0 +1,112 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// profileProgram evalúa input con el perfilado activo y devuelve el perfil
+// de cada función por nombre
+func profileProgram(t *testing.T, input string) map[string]FunctionProfile {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+	eval := NewEvaluator()
+	profile := NewProfile()
+	eval.SetProfile(profile)
+	if err := eval.EvaluateProgram(program); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	functions := make(map[string]FunctionProfile)
+	for _, f := range profile.Report() {
+		functions[f.Name] = f
+	}
+	return functions
+}
+
+func TestProfileSelfAndTotal(t *testing.T) {
+	const pause = 20 * time.Millisecond
+	functions := profileProgram(t, `func lento() {
+    time.sleep(20)
+}
+func padre() {
+    lento()
+    lento()
+}
+class Tarea {
+    func correr() {
+        padre()
+    }
+}
+Tarea().correr()`)
+
+	lento, padre, correr := functions["lento"], functions["padre"], functions["Tarea.correr"]
+	if lento.Calls != 2 || padre.Calls != 1 || correr.Calls != 1 {
+		t.Fatalf("llamadas incorrectas: %+v", functions)
+	}
+	if lento.Self < 2*pause {
+		t.Errorf("lento debería tener al menos %v de tiempo propio: %+v", 2*pause, lento)
+	}
+	// El tiempo de lento cuenta en el acumulado de padre, no en su tiempo propio
+	if padre.Total < 2*pause || padre.Self >= pause {
+		t.Errorf("tiempos de padre incorrectos: %+v", padre)
+	}
+	if correr.Total < padre.Total || correr.Self >= pause {
+		t.Errorf("tiempos de Tarea.correr incorrectos: %+v", correr)
+	}
+}
+
+func TestProfileRecursion(t *testing.T) {
+	const pause = 20 * time.Millisecond
+	functions := profileProgram(t, `func bajar(n) {
+    if n > 0 {
+        bajar(n - 1)
+    } else {
+        time.sleep(20)
+    }
+}
+bajar(3)`)
+
+	bajar := functions["bajar"]
+	if bajar.Calls != 4 {
+		t.Fatalf("se esperaban 4 llamadas a bajar, obtenidas %d", bajar.Calls)
+	}
+	// Contar cada nivel de la recursión sumaría la pausa cuatro veces
+	if bajar.Total < pause || bajar.Total >= 2*pause {
+		t.Errorf("el acumulado de bajar debería contar la pausa una vez: %v", bajar.Total)
+	}
+	if bajar.Self > bajar.Total {
+		t.Errorf("el tiempo propio no puede superar al acumulado: %+v", bajar)
+	}
+}
+
+func TestProfileReportOrder(t *testing.T) {
+	profile := NewProfile()
+	profile.record("a", time.Millisecond, time.Millisecond, false)
+	profile.record("c", 5*time.Millisecond, time.Millisecond, false)
+	profile.record("b", time.Millisecond, time.Millisecond, false)
+	// Una llamada recursiva suma a las llamadas y al tiempo propio, no al acumulado
+	profile.record("a", time.Millisecond, time.Millisecond, true)
+
+	report := profile.Report()
+	expected := []FunctionProfile{
+		{Name: "c", Calls: 1, Total: 5 * time.Millisecond, Self: time.Millisecond},
+		{Name: "a", Calls: 2, Total: time.Millisecond, Self: 2 * time.Millisecond},
+		{Name: "b", Calls: 1, Total: time.Millisecond, Self: time.Millisecond},
+	}
+	if len(report) != len(expected) {
+		t.Fatalf("se esperaban %d funciones, obtenidas %+v", len(expected), report)
+	}
+	for i := range expected {
+		if report[i] != expected[i] {
+			t.Errorf("posición %d: %+v, se esperaba %+v", i, report[i], expected[i])
+		}
+	}
+}
@@ -0,0 +1,135 @@
+package evaluator
+
+// El objeto random genera números pseudoaleatorios con math/rand. Al crearse
+// toma como semilla la hora actual; random.seed(n) fija la semilla para que
+// una ejecución se pueda repetir. random.shuffle devuelve una lista nueva y
+// deja la original como estaba.
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// randomSource es el generador del objeto random. Lo comparten los
+// evaluadores de spawn, así que se protege con un mutex.
+type randomSource struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// newRandomModule crea el objeto random con una semilla tomada de la hora
+func newRandomModule() *MapObject {
+	source := &randomSource{rng: rand.New(rand.NewSource(time.Now().UnixNano()))}
+	module := &MapObject{Pairs: make(map[string]Value)}
+	module.Set("seed", &BuiltinFunction{Name: "random.seed", Fn: source.seed})
+	module.Set("int", &BuiltinFunction{Name: "random.int", Fn: source.int})
+	module.Set("float", &BuiltinFunction{Name: "random.float", Fn: source.float})
+	module.Set("choice", &BuiltinFunction{Name: "random.choice", Fn: source.choice})
+	module.Set("shuffle", &BuiltinFunction{Name: "random.shuffle", Fn: source.shuffle})
+	return module
+}
+
+// seed implementa random.seed(n)
+func (s *randomSource) seed(args []Value) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("random.seed espera 1 argumento, obtenidos %d", len(args))
+	}
+	n, ok := args[0].(*Integer)
+	if !ok {
+		return nil, fmt.Errorf("random.seed espera un entero, obtenido %s", getNormalizedType(args[0]))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rng.Seed(n.Value)
+	return &Null{}, nil
+}
+
+// int implementa random.int(min, max): un entero entre min y max, ambos
+// incluidos
+func (s *randomSource) int(args []Value) (Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("random.int espera 2 argumentos, obtenidos %d", len(args))
+	}
+	min, minOk := args[0].(*Integer)
+	max, maxOk := args[1].(*Integer)
+	if !minOk || !maxOk {
+		return nil, fmt.Errorf("random.int espera enteros, obtenidos %s y %s", getNormalizedType(args[0]), getNormalizedType(args[1]))
+	}
+	if min.Value > max.Value {
+		return nil, fmt.Errorf("random.int espera min <= max, obtenidos %d y %d", min.Value, max.Value)
+	}
+	span := uint64(max.Value-min.Value) + 1
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if span == 0 {
+		// El rango ocupa todos los int64
+		return &Integer{Value: int64(s.rng.Uint64())}, nil
+	}
+	return &Integer{Value: min.Value + int64(s.uint64n(span))}, nil
+}
+
+// uint64n devuelve un número en [0, n) sin sesgo; n no puede ser 0
+func (s *randomSource) uint64n(n uint64) uint64 {
+	if n <= 1<<63-1 {
+		return uint64(s.rng.Int63n(int64(n)))
+	}
+	for {
+		if v := s.rng.Uint64(); v < n {
+			return v
+		}
+	}
+}
+
+// float implementa random.float(): un float en [0, 1)
+func (s *randomSource) float(args []Value) (Value, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("random.float espera 0 argumentos, obtenidos %d", len(args))
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &Float{Value: s.rng.Float64()}, nil
+}
+
+// choice implementa random.choice(lista): un elemento al azar
+func (s *randomSource) choice(args []Value) (Value, error) {
+	list, err := randomListArg("random.choice", args)
+	if err != nil {
+		return nil, err
+	}
+	if len(list.Items) == 0 {
+		return nil, fmt.Errorf("random.choice espera una lista no vacía")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return list.Items[s.rng.Intn(len(list.Items))], nil
+}
+
+// shuffle implementa random.shuffle(lista): una copia desordenada
+func (s *randomSource) shuffle(args []Value) (Value, error) {
+	list, err := randomListArg("random.shuffle", args)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Value, len(list.Items))
+	copy(items, list.Items)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rng.Shuffle(len(items), func(i, j int) { items[i], items[j] = items[j], items[i] })
+	return &List{Items: items}, nil
+}
+
+// randomListArg comprueba que args sea una sola lista
+func randomListArg(name string, args []Value) (*List, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("%s espera 1 argumento, obtenidos %d", name, len(args))
+	}
+	list, ok := args[0].(*List)
+	if !ok {
+		return nil, fmt.Errorf("%s espera una lista, obtenido %s", name, getNormalizedType(args[0]))
+	}
+	return list, nil
+}
@@ -0,0 +1,82 @@
+package evaluator
+
+import "testing"
+
+func TestRandomModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		// La misma semilla da la misma secuencia
+		{"random.seed(7)\na := [random.int(1, 100), random.float(), random.choice([1, 2, 3])]\nrandom.seed(7)\nb := [random.int(1, 100), random.float(), random.choice([1, 2, 3])]\na == b", true},
+		{"random.seed(7)\na := random.shuffle([1, 2, 3, 4, 5])\nrandom.seed(7)\na == random.shuffle([1, 2, 3, 4, 5])", true},
+		// shuffle no cambia la lista original y conserva sus elementos
+		{"l := [1, 2, 3, 4, 5]\ns := random.shuffle(l)\nl == [1, 2, 3, 4, 5] and len(s) == 5 and sum(s) == 15", true},
+		{"random.shuffle([]) == []", true},
+		{"random.int(4, 4)", 4},
+		{"random.choice([\"solo\"])", "solo"},
+		{"random.seed(1)", nil},
+	}
+
+	for _, tt := range tests {
+		evaluated := testEval(tt.input)
+		if tt.expected == nil {
+			if _, ok := evaluated.(*Null); !ok {
+				t.Errorf("%s: se esperaba null, obtenido %T", tt.input, evaluated)
+			}
+			continue
+		}
+		testObjectLiteral(t, evaluated, tt.expected)
+	}
+}
+
+func TestRandomRanges(t *testing.T) {
+	counts := map[int64]int{}
+	for i := 0; i < 500; i++ {
+		n, ok := testEval("random.int(-2, 2)").(*Integer)
+		if !ok {
+			t.Fatal("random.int() debería devolver un entero")
+		}
+		if n.Value < -2 || n.Value > 2 {
+			t.Fatalf("random.int(-2, 2) = %d, fuera del rango", n.Value)
+		}
+		counts[n.Value]++
+
+		f, ok := testEval("random.float()").(*Float)
+		if !ok {
+			t.Fatal("random.float() debería devolver un float")
+		}
+		if f.Value < 0 || f.Value >= 1 {
+			t.Fatalf("random.float() = %g, fuera de [0, 1)", f.Value)
+		}
+	}
+	// Los dos extremos están incluidos
+	if len(counts) != 5 {
+		t.Errorf("random.int(-2, 2) solo devolvió %v", counts)
+	}
+
+	// Un rango que ocupa todos los int64 no desborda
+	if _, ok := testEval("random.int(-9223372036854775807 - 1, 9223372036854775807)").(*Integer); !ok {
+		t.Error("random.int() con el rango completo debería devolver un entero")
+	}
+}
+
+func TestRandomModuleErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"random.int(5, 1)", "random.int espera min <= max, obtenidos 5 y 1"},
+		{"random.int(1.5, 3)", "random.int espera enteros, obtenidos float y int"},
+		{"random.int(1)", "random.int espera 2 argumentos, obtenidos 1"},
+		{"random.float(1)", "random.float espera 0 argumentos, obtenidos 1"},
+		{"random.choice([])", "random.choice espera una lista no vacía"},
+		{"random.choice(\"abc\")", "random.choice espera una lista, obtenido string"},
+		{"random.shuffle(3)", "random.shuffle espera una lista, obtenido int"},
+		{"random.seed(\"x\")", "random.seed espera un entero, obtenido string"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
@@ -0,0 +1,152 @@
+package evaluator
+
+// El intérprete y el código compilado comparten la biblioteca estándar: cada
+// builtin se implementa una sola vez en zyloruntime, sobre su modelo de objetos
+// (zyloruntime.ZyloObject), y el intérprete lo expone a través de un adaptador
+// que convierte los argumentos de Value a ZyloObject y el resultado de vuelta.
+//
+// Solo se definen en el intérprete los builtins que necesitan su estado
+// (entrada estándar, servidor HTTP, instancias de clases) o cuya semántica
+// depende de él, como ToBool, que sigue las reglas de isTruthy.
+
+import (
+	"fmt"
+
+	zyloruntime "github.com/zylo-lang/zylo/runtime"
+)
+
+// Builtins de zyloruntime que no se registran en el intérprete porque
+// dependen del modelo de ejecución del código compilado.
+var runtimeOnlyBuiltins = map[string]bool{
+	"Spawn": true,
+	"Await": true,
+}
+
+// registerRuntimeBuiltins registra en el entorno global los builtins de
+// zyloruntime, de modo que el intérprete y el código compilado compartan la
+// misma biblioteca estándar. Los builtins propios del intérprete tienen
+// prioridad sobre los del runtime con el mismo nombre.
+func (e *Evaluator) registerRuntimeBuiltins() {
+	for name, builtin := range zyloruntime.GetExtendedBuiltins() {
+		if runtimeOnlyBuiltins[name] {
+			continue
+		}
+		if _, exists := e.env.Get(name); exists {
+			continue
+		}
+		e.env.Set(name, adaptRuntimeBuiltin(name, builtin))
+	}
+}
+
+// adaptRuntimeBuiltin adapta la firma func(...ZyloObject) ZyloObject del
+// runtime a la de BuiltinFunction. Un *zyloruntime.Error se reporta como error.
+func adaptRuntimeBuiltin(name string, builtin *zyloruntime.Builtin) *BuiltinFunction {
+	return &BuiltinFunction{
+		Name: name,
+		Fn: func(args []Value) (Value, error) {
+			runtimeArgs := make([]zyloruntime.ZyloObject, len(args))
+			for i, arg := range args {
+				runtimeArgs[i] = toRuntimeObject(arg)
+			}
+			return fromRuntimeObject(builtin.Fn(runtimeArgs...))
+		},
+	}
+}
+
+// runtimeOpaque envuelve valores del intérprete sin equivalente en el runtime
+// (funciones, instancias, ...) para que atraviesen un builtin sin perderse.
+type runtimeOpaque struct {
+	value Value
+}
+
+func (o *runtimeOpaque) Type() zyloruntime.ObjectType {
+	if obj, ok := o.value.(ZyloObject); ok {
+		return zyloruntime.ObjectType(obj.Type())
+	}
+	return zyloruntime.ObjectType(fmt.Sprintf("%T", o.value))
+}
+
+func (o *runtimeOpaque) Inspect() string {
+	if obj, ok := o.value.(ZyloObject); ok {
+		return obj.Inspect()
+	}
+	return fmt.Sprintf("%v", o.value)
+}
+
+// toRuntimeObject convierte un Value del intérprete a un objeto de zyloruntime
+func toRuntimeObject(v Value) zyloruntime.ZyloObject {
+	switch val := v.(type) {
+	case nil:
+		return zyloruntime.NewNull()
+	case *String:
+		return zyloruntime.NewString(val.Value)
+	case *Integer:
+		return zyloruntime.NewInteger(val.Value)
+	case *Float:
+		return zyloruntime.NewFloat(val.Value)
+	case *Boolean:
+		return zyloruntime.NewBool(val.Value)
+	case *Null:
+		return zyloruntime.NewNull()
+	case *List:
+		elements := make([]zyloruntime.ZyloObject, len(val.Items))
+		for i, item := range val.Items {
+			elements[i] = toRuntimeObject(item)
+		}
+		return zyloruntime.NewList(elements...)
+	case *MapObject:
+		pairs := make(map[string]zyloruntime.ZyloObject, len(val.Pairs))
+		for k, item := range val.Pairs {
+			pairs[k] = toRuntimeObject(item)
+		}
+		m := zyloruntime.NewMap(pairs)
+		m.Order = val.Keys()
+		return m
+	default:
+		return &runtimeOpaque{value: v}
+	}
+}
+
+// fromRuntimeObject convierte un objeto de zyloruntime a un Value del intérprete
+func fromRuntimeObject(obj zyloruntime.ZyloObject) (Value, error) {
+	switch val := obj.(type) {
+	case nil:
+		return &Null{}, nil
+	case *zyloruntime.Error:
+		return nil, fmt.Errorf("%s", val.Message)
+	case *zyloruntime.String:
+		return &String{Value: val.Value}, nil
+	case *zyloruntime.Integer:
+		return &Integer{Value: val.Value}, nil
+	case *zyloruntime.Float:
+		return &Float{Value: val.Value}, nil
+	case *zyloruntime.Bool:
+		return &Boolean{Value: val.Value}, nil
+	case *zyloruntime.Null:
+		return &Null{}, nil
+	case *zyloruntime.List:
+		items := make([]Value, len(val.Elements))
+		for i, element := range val.Elements {
+			item, err := fromRuntimeObject(element)
+			if err != nil {
+				return nil, err
+			}
+			items[i] = item
+		}
+		return &List{Items: items}, nil
+	case *zyloruntime.Map:
+		pairs := make(map[string]Value, len(val.Pairs))
+		for k, element := range val.Pairs {
+			item, err := fromRuntimeObject(element)
+			if err != nil {
+				return nil, err
+			}
+			pairs[k] = item
+		}
+		return &MapObject{Pairs: pairs, Order: val.Keys()}, nil
+	case *runtimeOpaque:
+		return val.value, nil
+	default:
+		return nil, fmt.Errorf("tipo de runtime no soportado: %s", obj.Type())
+	}
+}
@@ -0,0 +1,184 @@
+package evaluator
+
+import (
+	"testing"
+
+	zyloruntime "github.com/zylo-lang/zylo/runtime"
+)
+
+// Los builtins compartidos deben comportarse igual en el runtime compilado y
+// en el intérprete: mismo resultado y mismo mensaje de error.
+func TestSharedBuiltinsBehaveIdentically(t *testing.T) {
+	rt := zyloruntime.GetExtendedBuiltins()
+	eval := NewEvaluator()
+
+	tests := []struct {
+		name      string
+		runtime   []zyloruntime.ZyloObject
+		evaluator []Value
+	}{
+		{
+			"split",
+			[]zyloruntime.ZyloObject{zyloruntime.NewString("a,b"), zyloruntime.NewString(",")},
+			[]Value{&String{Value: "a,b"}, &String{Value: ","}},
+		},
+		{
+			"len",
+			[]zyloruntime.ZyloObject{zyloruntime.NewList(zyloruntime.NewInteger(1), zyloruntime.NewInteger(2))},
+			[]Value{&List{Items: []Value{&Integer{Value: 1}, &Integer{Value: 2}}}},
+		},
+		{
+			"len",
+			[]zyloruntime.ZyloObject{zyloruntime.NewMap(map[string]zyloruntime.ZyloObject{"a": zyloruntime.NewInteger(1)})},
+			[]Value{&MapObject{Pairs: map[string]Value{"a": &Integer{Value: 1}}}},
+		},
+		{
+			"len",
+			[]zyloruntime.ZyloObject{zyloruntime.NewInteger(3)},
+			[]Value{&Integer{Value: 3}},
+		},
+		{
+			"Add",
+			[]zyloruntime.ZyloObject{zyloruntime.NewInteger(2), zyloruntime.NewFloat(0.5)},
+			[]Value{&Integer{Value: 2}, &Float{Value: 0.5}},
+		},
+		{
+			"Multiply",
+			[]zyloruntime.ZyloObject{zyloruntime.NewInteger(6), zyloruntime.NewInteger(7)},
+			[]Value{&Integer{Value: 6}, &Integer{Value: 7}},
+		},
+		{
+			"Subtract",
+			[]zyloruntime.ZyloObject{zyloruntime.NewString("a"), zyloruntime.NewInteger(1)},
+			[]Value{&String{Value: "a"}, &Integer{Value: 1}},
+		},
+		{
+			"map_keys",
+			[]zyloruntime.ZyloObject{zyloruntime.NewMap(map[string]zyloruntime.ZyloObject{"k": zyloruntime.NewBool(true)})},
+			[]Value{&MapObject{Pairs: map[string]Value{"k": &Boolean{Value: true}}}},
+		},
+		{
+			"map_values",
+			[]zyloruntime.ZyloObject{zyloruntime.NewMap(map[string]zyloruntime.ZyloObject{"k": zyloruntime.NewBool(true)})},
+			[]Value{&MapObject{Pairs: map[string]Value{"k": &Boolean{Value: true}}}},
+		},
+		{
+			"deep_merge",
+			[]zyloruntime.ZyloObject{zyloruntime.NewMap(nil), zyloruntime.NewInteger(1)},
+			[]Value{&MapObject{Pairs: map[string]Value{}}, &Integer{Value: 1}},
+		},
+		{
+			"sort",
+			[]zyloruntime.ZyloObject{zyloruntime.NewList(zyloruntime.NewString("b"), zyloruntime.NewString("a"))},
+			[]Value{&List{Items: []Value{&String{Value: "b"}, &String{Value: "a"}}}},
+		},
+	}
+
+	for _, tt := range tests {
+		expected := rt[tt.name].Fn(tt.runtime...)
+
+		builtin, ok := eval.env.Get(tt.name)
+		if !ok {
+			t.Fatalf("%s no está registrado en el intérprete", tt.name)
+		}
+		got, err := builtin.(*BuiltinFunction).Fn(tt.evaluator)
+
+		if runtimeErr, isErr := expected.(*zyloruntime.Error); isErr {
+			if err == nil || err.Error() != runtimeErr.Message {
+				t.Errorf("%s: se esperaba el error %q, obtenido %v", tt.name, runtimeErr.Message, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: error inesperado: %v", tt.name, err)
+			continue
+		}
+		if got.(ZyloObject).Inspect() != expected.Inspect() {
+			t.Errorf("%s: runtime=%s, intérprete=%s", tt.name, expected.Inspect(), got.(ZyloObject).Inspect())
+		}
+	}
+}
+
+func TestRuntimeObjectConversionRoundTrip(t *testing.T) {
+	fn := &ZyloFunction{Name: "f"}
+	original := &List{Items: []Value{
+		&Integer{Value: 1},
+		&Float{Value: 2.5},
+		&String{Value: "tres"},
+		&Boolean{Value: true},
+		&Null{},
+		&MapObject{Pairs: map[string]Value{"f": fn}},
+	}}
+
+	converted, err := fromRuntimeObject(toRuntimeObject(original))
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	list, ok := converted.(*List)
+	if !ok || len(list.Items) != len(original.Items) {
+		t.Fatalf("conversión inválida: %#v", converted)
+	}
+	if list.Inspect() != original.Inspect() {
+		t.Errorf("esperado %s, obtenido %s", original.Inspect(), list.Inspect())
+	}
+	if list.Items[5].(*MapObject).Pairs["f"] != fn {
+		t.Errorf("los valores sin equivalente en el runtime deben conservarse")
+	}
+}
+
+func TestStringBuiltins(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`split("a,b,c", ",")`, "[a, b, c]"},
+		{`join(split("a,b,c", ","), "-")`, "a-b-c"},
+		{`trim("  hola  ")`, "hola"},
+		{`replace("hola mundo", "mundo", "zylo")`, "hola zylo"},
+		{`contains("hola", "ol")`, "true"},
+		{`contains("hola", "x")`, "false"},
+		{`starts_with("hola", "ho")`, "true"},
+		{`ends_with("hola", "ho")`, "false"},
+		{`to_upper("hola")`, "HOLA"},
+		{`to_lower("HoLa")`, "hola"},
+	}
+
+	for _, tt := range tests {
+		got := testEval(tt.input)
+		if got.(ZyloObject).Inspect() != tt.expected {
+			t.Errorf("%s: esperado %s, obtenido %s", tt.input, tt.expected, got.(ZyloObject).Inspect())
+		}
+	}
+}
+
+// Mostrar o recorrer un map debe dar siempre el mismo orden: el de inserción,
+// también para los maps que devuelven json.parse, deep_merge y set_path
+func TestMapOrderIsDeterministic(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`json.parse("{\"c\": 3, \"a\": 1, \"d\": 4, \"b\": 2}")`, "{c: 3, a: 1, d: 4, b: 2}"},
+		{`map_keys(json.parse("{\"c\": 3, \"a\": 1, \"d\": 4, \"b\": 2}"))`, "[c, a, d, b]"},
+		{`map_values(json.parse("{\"c\": 3, \"a\": 1, \"d\": 4, \"b\": 2}"))`, "[3, 1, 4, 2]"},
+		{`json.parse("{\"b\": {\"z\": 1, \"y\": 2}, \"a\": 3}")`, "{b: {z: 1, y: 2}, a: 3}"},
+		{"m := {\"b\": 1, \"a\": 2, \"c\": 3}\nm", "{b: 1, a: 2, c: 3}"},
+		{"m := {\"b\": 1, \"a\": 2}\nm[\"z\"] = 0\nm[\"a\"] = 9\nmap_keys(m)", "[b, a, z]"},
+		{"m := {\"b\": 1, \"a\": 2}\nmap_values(map_set(m, \"c\", 3))", "[1, 2, 3]"},
+		// deep_merge: primero las claves de la izquierda y después las nuevas
+		{`deep_merge({"b": 1, "a": 2}, {"c": 3})`, "{b: 1, a: 2, c: 3}"},
+		{`deep_merge({"b": {"y": 1, "x": 2}, "a": 0}, {"b": {"w": 3, "x": 4}})`, "{b: {y: 1, x: 4, w: 3}, a: 0}"},
+		{`set_path({"b": 1, "a": {"d": 1, "c": 2}}, "a.e", 3)`, "{b: 1, a: {d: 1, c: 2, e: 3}}"},
+		{`set_path({"b": 1, "a": 2}, "a", 3)`, "{b: 1, a: 3}"},
+	}
+
+	for _, tt := range tests {
+		for i := 0; i < 10; i++ {
+			got := testEval(tt.input)
+			if got.(ZyloObject).Inspect() != tt.expected {
+				t.Fatalf("%s: esperado %s, obtenido %s", tt.input, tt.expected, got.(ZyloObject).Inspect())
+			}
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package evaluator
+
+// Set es un conjunto de valores sin repetir. Se crea con un literal {1, 2, 3}
+// o con set(lista); set() es el conjunto vacío, porque {} es un bloque. Dos
+// valores son el mismo elemento si son estructuralmente iguales, y 1 y 1.0
+// cuentan como el mismo, igual que con ==. Los elementos se recorren y se
+// muestran en el orden en que se añadieron.
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Set representa un objeto set
+type Set struct {
+	Items map[string]Value // Elementos por su clave (setKey)
+	Order []string         // Claves en orden de inserción
+}
+
+func (s *Set) Type() string { return "SET_OBJ" }
+func (s *Set) Inspect() string {
+	if len(s.Order) == 0 {
+		return "set()"
+	}
+	parts := make([]string, len(s.Order))
+	for i, item := range s.Values() {
+		if obj, ok := item.(ZyloObject); ok {
+			parts[i] = obj.Inspect()
+		} else {
+			parts[i] = fmt.Sprintf("%v", item)
+		}
+	}
+	return "{" + strings.Join(parts, ", ") + "}"
+}
+
+// newSet crea un set con items, descartando los repetidos
+func newSet(items []Value) *Set {
+	s := &Set{Items: make(map[string]Value, len(items))}
+	for _, item := range items {
+		s.Add(item)
+	}
+	return s
+}
+
+// setKey devuelve la clave con la que v se guarda en un set. Los floats
+// enteros usan la clave del entero, para que 1.0 in {1} sea verdadero.
+func setKey(v Value) string {
+	if f, ok := v.(*Float); ok && f.Value == math.Trunc(f.Value) && math.Abs(f.Value) < 1<<63 {
+		v = &Integer{Value: int64(f.Value)}
+	}
+	var key strings.Builder
+	writeMemoKey(&key, v)
+	return key.String()
+}
+
+// Add añade v si no estaba y devuelve si lo añadió
+func (s *Set) Add(v Value) bool {
+	key := setKey(v)
+	if _, exists := s.Items[key]; exists {
+		return false
+	}
+	s.Items[key] = v
+	s.Order = append(s.Order, key)
+	return true
+}
+
+// Remove quita v si estaba y devuelve si lo quitó
+func (s *Set) Remove(v Value) bool {
+	key := setKey(v)
+	if _, exists := s.Items[key]; !exists {
+		return false
+	}
+	delete(s.Items, key)
+	for i, k := range s.Order {
+		if k == key {
+			s.Order = append(s.Order[:i], s.Order[i+1:]...)
+			break
+		}
+	}
+	return true
+}
+
+// Has indica si v pertenece al set
+func (s *Set) Has(v Value) bool {
+	_, exists := s.Items[setKey(v)]
+	return exists
+}
+
+// Values devuelve los elementos en orden de inserción
+func (s *Set) Values() []Value {
+	values := make([]Value, len(s.Order))
+	for i, key := range s.Order {
+		values[i] = s.Items[key]
+	}
+	return values
+}
+
+// evaluateSetMember resuelve s.name: length o uno de los métodos del set
+func evaluateSetMember(s *Set, name string) (Value, bool) {
+	switch name {
+	case "length":
+		return &Integer{Value: int64(len(s.Order))}, true
+	case "add":
+		return &BuiltinFunction{Name: "Set.add", Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("add() espera 1 argumento")
+			}
+			s.Add(args[0])
+			return &Null{}, nil
+		}}, true
+	case "remove":
+		return &BuiltinFunction{Name: "Set.remove", Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("remove() espera 1 argumento")
+			}
+			return &Boolean{Value: s.Remove(args[0])}, nil
+		}}, true
+	case "has":
+		return &BuiltinFunction{Name: "Set.has", Fn: func(args []Value) (Value, error) {
+			if len(args) != 1 {
+				return nil, fmt.Errorf("has() espera 1 argumento")
+			}
+			return &Boolean{Value: s.Has(args[0])}, nil
+		}}, true
+	case "union":
+		return setOperation("union", s, func(other *Set) *Set {
+			result := newSet(s.Values())
+			for _, item := range other.Values() {
+				result.Add(item)
+			}
+			return result
+		}), true
+	case "intersection":
+		return setOperation("intersection", s, func(other *Set) *Set {
+			result := newSet(nil)
+			for _, item := range s.Values() {
+				if other.Has(item) {
+					result.Add(item)
+				}
+			}
+			return result
+		}), true
+	case "difference":
+		return setOperation("difference", s, func(other *Set) *Set {
+			result := newSet(nil)
+			for _, item := range s.Values() {
+				if !other.Has(item) {
+					result.Add(item)
+				}
+			}
+			return result
+		}), true
+	case "to_list":
+		return &BuiltinFunction{Name: "Set.to_list", Fn: func(args []Value) (Value, error) {
+			if len(args) != 0 {
+				return nil, fmt.Errorf("to_list() no espera argumentos")
+			}
+			return &List{Items: s.Values()}, nil
+		}}, true
+	}
+	return nil, false
+}
+
+// setOperation crea el método name, que combina s con otro set en uno nuevo
+// sin modificar ninguno de los dos
+func setOperation(name string, s *Set, combine func(other *Set) *Set) *BuiltinFunction {
+	return &BuiltinFunction{Name: "Set." + name, Fn: func(args []Value) (Value, error) {
+		if len(args) != 1 {
+			return nil, fmt.Errorf("%s() espera 1 argumento", name)
+		}
+		other, ok := args[0].(*Set)
+		if !ok {
+			return nil, fmt.Errorf("%s() espera un set, obtenido %T", name, args[0])
+		}
+		return combine(other), nil
+	}}
+}
+
+// registerSetBuiltins registra set() y extiende len() a los sets. Se llama
+// después de registrar los builtins del runtime, que no conoce los sets.
+func (e *Evaluator) registerSetBuiltins() {
+	e.env.Set("set", &BuiltinFunction{
+		Name: "set",
+		Fn: func(args []Value) (Value, error) {
+			switch len(args) {
+			case 0:
+				return newSet(nil), nil
+			case 1:
+				switch source := args[0].(type) {
+				case *List:
+					return newSet(source.Items), nil
+				case *Set:
+					return newSet(source.Values()), nil
+				}
+				return nil, fmt.Errorf("set() espera una lista, obtenido %T", args[0])
+			}
+			return nil, fmt.Errorf("set() espera 0 o 1 argumentos, obtenidos %d", len(args))
+		},
+	})
+
+	if builtin, ok := e.env.Get("len"); ok {
+		if runtimeLen, ok := builtin.(*BuiltinFunction); ok {
+			e.env.Set("len", &BuiltinFunction{
+				Name: "len",
+				Fn: func(args []Value) (Value, error) {
+					if len(args) == 1 {
+						if s, ok := args[0].(*Set); ok {
+							return &Integer{Value: int64(len(s.Order))}, nil
+						}
+					}
+					return runtimeLen.Fn(args)
+				},
+			})
+		}
+	}
+}
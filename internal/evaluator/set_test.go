@@ -0,0 +1,74 @@
+package evaluator
+
+import "testing"
+
+func TestSetLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{1, 2, 3}", "{1, 2, 3}"},
+		// Los repetidos se descartan y se conserva el orden de inserción
+		{"{3, 1, 3, 2, 1}", "{3, 1, 2}"},
+		{"{1, 1.0, \"1\"}", "{1, 1}"},
+		{"{[1, 2], [1, 2], [2]}", "{[1, 2], [2]}"},
+		{"set()", "set()"},
+		{"set([\"a\", \"b\", \"a\"])", "{a, b}"},
+	}
+
+	for _, tt := range tests {
+		set, ok := testEval(tt.input).(*Set)
+		if !ok {
+			t.Errorf("%s: se esperaba un set", tt.input)
+			continue
+		}
+		if set.Inspect() != tt.expected {
+			t.Errorf("%s: esperado %s, obtenido %s", tt.input, tt.expected, set.Inspect())
+		}
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"s := {1, 2}\ns.add(3)\ns.add(2)\nlen(s)", 3},
+		{"s := {1, 2}\ns.remove(1)", true},
+		{"s := {1, 2}\ns.remove(5)", false},
+		{"s := {1, 2}\ns.remove(1)\ns.length", 1},
+		{"{1, 2}.has(2)", true},
+		{"2 in {1, 2}", true},
+		{"3 not in {1, 2}", true},
+		{"2.0 in {1, 2}", true},
+		{"[1] in {[1], [2]}", true},
+		{"s := {1, 2}.union({2, 3})\ns.to_list()[2]", 3},
+		{"len({1, 2, 3}.intersection({2, 3, 4}))", 2},
+		{"{1, 2, 3}.difference({2}).to_list()[1]", 3},
+		// Las operaciones no modifican los sets originales
+		{"a := {1}\nb := a.union({2})\nlen(a)", 1},
+		{"{1, 2} == {2, 1}", true},
+		{"{1, 2} == {1, 3}", false},
+		{"total := 0\nfor n in {1, 2, 2, 3} {\n    total = total + n\n}\ntotal", 6},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestSetErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"{1}.union([1])", "union() espera un set, obtenido *evaluator.List"},
+		{"{1}.add()", "add() espera 1 argumento"},
+		{"set(1)", "set() espera una lista, obtenido *evaluator.Integer"},
+		{"set([1], [2])", "set() espera 0 o 1 argumentos, obtenidos 2"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
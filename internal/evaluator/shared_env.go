@@ -0,0 +1,93 @@
+package evaluator
+
+// Los módulos compartidos (por ejemplo, los helpers que importan los tests) se
+// parsean y evalúan una sola vez. Cada programa se ejecuta en un entorno hijo
+// de un entorno base común, de modo que sus declaraciones de nivel superior no
+// son visibles para los demás programas, y al terminar se restaura el estado
+// de los módulos que importó.
+
+import (
+	"bufio"
+	"maps"
+	"os"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+)
+
+// SharedEnvironment es un entorno base cuyos módulos cargados comparten todos
+// los programas que ejecuta
+type SharedEnvironment struct {
+	base *Evaluator
+}
+
+// NewSharedEnvironment crea un entorno base vacío, solo con los builtins
+func NewSharedEnvironment() *SharedEnvironment {
+	base := NewEvaluator()
+	base.localImports = true
+	return &SharedEnvironment{base: base}
+}
+
+// SetCoverage activa el registro de cobertura en el entorno base y en los
+// evaluadores que se creen a partir de él
+func (s *SharedEnvironment) SetCoverage(c *Coverage) {
+	s.base.SetCoverage(c)
+}
+
+// Run ejecuta program en un evaluador nuevo cuyo entorno global es hijo del
+// entorno base y devuelve ese evaluador. Los imports se resuelven desde dir y
+// pasan por importModule como en zylo run, así que cada módulo se enlaza con
+// su nombre y solo con lo que exporta; además, import nombre carga el archivo
+// nombre.zylo de dir. Un módulo ya cargado por otro programa no se vuelve a
+// evaluar. Al terminar se restauran las variables del entorno base y las de
+// los módulos, así que las reasignaciones de un programa no pasan a otro; las
+// mutaciones dentro de listas y mapas sí se comparten.
+func (s *SharedEnvironment) Run(program *ast.Program, dir string) (*Evaluator, error) {
+	restore := s.base.env.snapshot()
+	defer restore()
+	defer s.base.modules.restore()
+
+	eval := s.NewEvaluator()
+	eval.baseDir = dir
+	return eval, eval.EvaluateProgram(program)
+}
+
+// NewEvaluator crea un evaluador cuyo entorno global es hijo del entorno base.
+// Los builtins que dependen del evaluador se registran de nuevo en el entorno
+// hijo para que usen su propio estado.
+func (s *SharedEnvironment) NewEvaluator() *Evaluator {
+	eval := &Evaluator{
+		env:          s.base.env.NewChildEnvironment(),
+		reader:       bufio.NewReader(os.Stdin),
+		assertions:   &assertionLog{},
+		modules:      s.base.modules,
+		baseDir:      s.base.baseDir,
+		localImports: s.base.localImports,
+		coverage:     s.base.coverage,
+	}
+	eval.InitBuiltins()
+	return eval
+}
+
+// restore devuelve cada módulo cargado al estado en que quedó tras evaluarlo
+func (r *moduleRegistry) restore() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, restore := range r.snapshots {
+		restore()
+	}
+}
+
+// snapshot guarda las variables del entorno y devuelve una función que las
+// restaura; se puede llamar varias veces. Las funciones que capturaron el
+// entorno ven los valores restaurados.
+func (e *Environment) snapshot() func() {
+	variables := maps.Clone(e.variables)
+	constants := maps.Clone(e.constants)
+	types := maps.Clone(e.types)
+
+	return func() {
+		e.variables = maps.Clone(variables)
+		e.constants = maps.Clone(constants)
+		e.types = maps.Clone(types)
+	}
+}
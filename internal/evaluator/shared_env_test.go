@@ -0,0 +1,143 @@
+package evaluator
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func parseSharedTestProgram(t *testing.T, input string) *ast.Program {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("errores de parsing: %v", p.Errors())
+	}
+	return program
+}
+
+// writeSharedModules escribe los módulos de files en un directorio temporal
+func writeSharedModules(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatalf("error escribiendo %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestSharedEnvironmentLoadsModulesOnce(t *testing.T) {
+	dir := writeSharedModules(t, map[string]string{
+		"helpers.zylo": "cargas := [0]\ncargas[0] = cargas[0] + 1\nfunc doble(x) {\n    return x * 2\n}\n",
+	})
+	shared := NewSharedEnvironment()
+
+	for i := 0; i < 3; i++ {
+		program := parseSharedTestProgram(t, "import helpers\nassert_eq(helpers.doble(21), 42)\nassert_eq(helpers.cargas[0], 1)\n")
+		eval, err := shared.Run(program, dir)
+		if err != nil {
+			t.Fatalf("ejecución %d: error inesperado: %v", i, err)
+		}
+		for _, a := range eval.Assertions() {
+			if !a.Passed {
+				t.Errorf("ejecución %d: %s", i, a.Message)
+			}
+		}
+	}
+}
+
+func TestSharedEnvironmentNamespacesModules(t *testing.T) {
+	dir := writeSharedModules(t, map[string]string{
+		"helpers.zylo": "export func doble(x) {\n    return x * 2\n}\nfunc interna() {\n    return 1\n}\n",
+	})
+	shared := NewSharedEnvironment()
+
+	tests := []struct {
+		input    string
+		expected string // Error esperado; vacío si el programa termina bien
+	}{
+		{"import helpers\nassert_eq(helpers.doble(2), 4)", ""},
+		{"import \"./helpers\"\nassert_eq(helpers.doble(3), 6)", ""},
+		// Lo mismo que en zylo run: sin espacio de nombres no hay doble, y
+		// helpers solo tiene lo que exporta
+		{"import helpers\ndoble(2)", "variable no definida: doble"},
+		{"import helpers\nhelpers.interna()", "property 'interna' not found"},
+		// Un test que no importa helpers no lo ve aunque otro ya lo cargara
+		{"helpers.doble(2)", "variable no definida: helpers"},
+	}
+
+	for _, tt := range tests {
+		_, err := shared.Run(parseSharedTestProgram(t, tt.input), dir)
+		if tt.expected == "" {
+			if err != nil {
+				t.Errorf("%s: error inesperado: %v", tt.input, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: se esperaba un error con %q, obtenido %v", tt.input, tt.expected, err)
+		}
+	}
+}
+
+func TestSharedEnvironmentIsolatesPrograms(t *testing.T) {
+	dir := writeSharedModules(t, map[string]string{
+		"estado.zylo": "contador := 0\nfunc incrementar() {\n    contador = contador + 1\n    return contador\n}\nfunc valor() {\n    return contador\n}\n",
+	})
+	shared := NewSharedEnvironment()
+
+	// Cada programa ve el estado inicial del módulo y no las declaraciones
+	// de los programas anteriores
+	for i := 0; i < 2; i++ {
+		program := parseSharedTestProgram(t, `import estado
+if estado.incrementar() != 1 {
+    throw "estado compartido entre programas"
+}
+if estado.valor() != 1 {
+    throw "contador no actualizado"
+}
+local := 1
+`)
+		if _, err := shared.Run(program, dir); err != nil {
+			t.Fatalf("ejecución %d: %v", i, err)
+		}
+	}
+	if _, leaked := shared.base.env.Get("local"); leaked {
+		t.Errorf("las declaraciones de un programa no deben llegar al entorno base")
+	}
+}
+
+func TestSharedEnvironmentLoadError(t *testing.T) {
+	dir := writeSharedModules(t, map[string]string{
+		"roto.zylo": "x := no_existe\n",
+	})
+	shared := NewSharedEnvironment()
+
+	// Un módulo que falla al cargarse falla en cada programa que lo importa
+	for i := 0; i < 2; i++ {
+		_, err := shared.Run(parseSharedTestProgram(t, "import roto"), dir)
+		if err == nil || !strings.Contains(err.Error(), "no_existe") {
+			t.Errorf("ejecución %d: se esperaba el error del módulo, obtenido %v", i, err)
+		}
+	}
+}
+
+func TestSharedEnvironmentImportCycle(t *testing.T) {
+	dir := writeSharedModules(t, map[string]string{
+		"a.zylo": "import b\nfunc fa() {\n    return 1\n}\n",
+		"b.zylo": "import a\nfunc fb() {\n    return 2\n}\n",
+	})
+	shared := NewSharedEnvironment()
+
+	_, err := shared.Run(parseSharedTestProgram(t, "import a"), dir)
+	if err == nil || !strings.Contains(err.Error(), "import circular: a.zylo -> b.zylo -> a.zylo") {
+		t.Errorf("se esperaba un error de import circular, obtenido %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package evaluator
+
+// show.log escribe en la salida estándar; show.error, show.warn y show.debug
+// en la de errores, para no mezclarse con la salida del programa. Cada línea
+// se escribe de una vez, así que las de distintos niveles no se intercalan a
+// medias. Si la salida es una terminal, cada nivel lleva delante su nombre en
+// color; redirigida a un archivo, solo el mensaje. show.debug no escribe nada
+// salvo que ZYLO_DEBUG esté activa, como hace zylo debug.
+
+import (
+	"os"
+	"strings"
+)
+
+// DebugEnv es la variable de entorno que activa show.debug
+const DebugEnv = "ZYLO_DEBUG"
+
+// showLevel es un nivel de show.error, show.warn o show.debug con el color de
+// su prefijo en una terminal
+type showLevel struct {
+	name  string
+	color string
+}
+
+var showLevels = []showLevel{
+	{name: "error", color: "\033[31m"},
+	{name: "warn", color: "\033[33m"},
+	{name: "debug", color: "\033[90m"},
+}
+
+// registerShowBuiltins registra show.log, show.error, show.warn y show.debug
+func (e *Evaluator) registerShowBuiltins() {
+	e.env.Set("show.log", &BuiltinFunction{
+		Name: "show.log",
+		Fn: func(args []Value) (Value, error) {
+			line, err := e.showLine(args)
+			if err != nil {
+				return nil, err
+			}
+			writeLine(os.Stdout, line)
+			return &Null{}, nil
+		},
+	})
+
+	for _, level := range showLevels {
+		level := level
+		e.env.Set("show."+level.name, &BuiltinFunction{
+			Name: "show." + level.name,
+			Fn: func(args []Value) (Value, error) {
+				if level.name == "debug" && !debugEnabled() {
+					return &Null{}, nil
+				}
+				line, err := e.showLine(args)
+				if err != nil {
+					return nil, err
+				}
+				if isTerminal(os.Stderr) {
+					line = level.color + level.name + ":\033[0m " + line
+				}
+				writeLine(os.Stderr, line)
+				return &Null{}, nil
+			},
+		})
+	}
+}
+
+// showLine une args separados por espacios, como los muestra show.log
+func (e *Evaluator) showLine(args []Value) (string, error) {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		text, err := e.displayString(arg)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = text
+	}
+	return strings.Join(parts, " "), nil
+}
+
+// writeLine escribe line con su salto de línea en una sola escritura
+func writeLine(f *os.File, line string) {
+	f.WriteString(line + "\n")
+	f.Sync()
+}
+
+// debugEnabled indica si DebugEnv activa show.debug
+func debugEnabled() bool {
+	switch os.Getenv(DebugEnv) {
+	case "", "0", "false":
+		return false
+	}
+	return true
+}
+
+// isTerminal indica si f es una terminal, para decidir si usar colores
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,98 @@
+package evaluator
+
+import (
+	"io"
+	"os"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+// runShow ejecuta input y devuelve lo que escribió en stdout y en stderr
+func runShow(t *testing.T, input string) (string, string) {
+	t.Helper()
+
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	capture := func(target **os.File) (func() string, error) {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return nil, err
+		}
+		original := *target
+		*target = w
+		done := make(chan string)
+		go func() {
+			out, _ := io.ReadAll(r)
+			done <- string(out)
+		}()
+		return func() string {
+			w.Close()
+			*target = original
+			return <-done
+		}, nil
+	}
+	stdout, err := capture(&os.Stdout)
+	if err != nil {
+		t.Fatalf("error creando pipe: %v", err)
+	}
+	stderr, err := capture(&os.Stderr)
+	if err != nil {
+		stdout()
+		t.Fatalf("error creando pipe: %v", err)
+	}
+
+	evalErr := NewEvaluator().EvaluateProgram(program)
+	out, errOut := stdout(), stderr()
+	if evalErr != nil {
+		t.Fatalf("%s: error inesperado: %v", input, evalErr)
+	}
+	return out, errOut
+}
+
+func TestShowLevels(t *testing.T) {
+	t.Setenv(DebugEnv, "")
+
+	tests := []struct {
+		input  string
+		stdout string
+		stderr string
+	}{
+		{`show.log("uno", 2, [3])`, "uno 2 [3]\n", ""},
+		// error, warn y debug van a stderr, sin prefijo fuera de una terminal
+		{`show.error("mal")`, "", "mal\n"},
+		{`show.warn("cuidado", 1)`, "", "cuidado 1\n"},
+		{`show.log("a")
+show.warn("b")
+show.log("c")`, "a\nc\n", "b\n"},
+		// Sin ZYLO_DEBUG, show.debug no escribe nada
+		{`show.debug("oculto")`, "", ""},
+	}
+
+	for _, tt := range tests {
+		stdout, stderr := runShow(t, tt.input)
+		if stdout != tt.stdout || stderr != tt.stderr {
+			t.Errorf("%s: esperado stdout %q y stderr %q, obtenido %q y %q", tt.input, tt.stdout, tt.stderr, stdout, stderr)
+		}
+	}
+}
+
+func TestShowDebugEnabled(t *testing.T) {
+	for _, value := range []string{"true", "1"} {
+		t.Setenv(DebugEnv, value)
+		stdout, stderr := runShow(t, `show.debug("x =", 3)`)
+		if stdout != "" || stderr != "x = 3\n" {
+			t.Errorf("%s=%s: esperado stderr %q, obtenido stdout %q y stderr %q", DebugEnv, value, "x = 3\n", stdout, stderr)
+		}
+	}
+
+	t.Setenv(DebugEnv, "false")
+	if _, stderr := runShow(t, `show.debug("oculto")`); stderr != "" {
+		t.Errorf("%s=false: show.debug no debería escribir, obtenido %q", DebugEnv, stderr)
+	}
+}
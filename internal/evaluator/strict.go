@@ -0,0 +1,41 @@
+package evaluator
+
+// Modo estricto (zylo run --strict): una variable con tipo int, float, string
+// o bool solo acepta valores de ese tipo. Sin él, un valor de otro tipo se
+// convierte al declarado ("5" pasa a 5 en una variable int). En los dos modos
+// un int se acepta en una variable float, igual que en el análisis semántico.
+
+import "fmt"
+
+// SetStrict activa o desactiva el modo estricto
+func (e *Evaluator) SetStrict(strict bool) {
+	e.strict = strict
+}
+
+// coerceToType adapta value al tipo declarado de una variable. Los tipos que
+// no son básicos (clases, enums, structs) no se comprueban aquí.
+func (e *Evaluator) coerceToType(value Value, declaredType string) (Value, error) {
+	expectedType := unifyType(declaredType)
+	switch expectedType {
+	case "int", "float", "string", "bool":
+	default:
+		return value, nil
+	}
+
+	actualType := getNormalizedType(value)
+	if actualType == expectedType {
+		return value, nil
+	}
+	if i, ok := value.(*Integer); ok && expectedType == "float" {
+		return &Float{Value: float64(i.Value)}, nil
+	}
+	if e.strict {
+		return nil, fmt.Errorf("tipo incompatible: esperado %s, recibido %s", expectedType, actualType)
+	}
+
+	converted, err := e.convertToTypeAuto(value, expectedType)
+	if err != nil {
+		return nil, fmt.Errorf("tipo incompatible: esperado %s, recibido %s", expectedType, actualType)
+	}
+	return converted, nil
+}
@@ -0,0 +1,85 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func evalWithMode(t *testing.T, input string, strict bool) (Value, error) {
+	t.Helper()
+	p := parser.New(lexer.New(input))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("%s: parser errors: %v", input, p.Errors())
+	}
+
+	e := NewEvaluator()
+	e.SetStrict(strict)
+	var result Value
+	for _, stmt := range program.Statements {
+		value, err := e.evaluateStatement(stmt)
+		if err != nil {
+			return nil, err
+		}
+		result = value
+	}
+	return result, nil
+}
+
+func TestTypedAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"var x: int = 1\nx = 2\nx", 2},
+		{"var x: int = 1\nx += 4\nx", 5},
+		{"var x: float = 1.5\nx = 2\nx", 2.0},
+		{"var x: float = 1\nx", 1.0},
+		{"var s: string = \"a\"\ns += \"b\"\ns", "ab"},
+		{"enum Color { Red, Green }\nvar c: Color = Color.Red\nc = Color.Green\nc == Color.Green", true},
+	}
+
+	for _, strict := range []bool{false, true} {
+		for _, tt := range tests {
+			value, err := evalWithMode(t, tt.input, strict)
+			if err != nil {
+				t.Errorf("%s (strict=%v): error inesperado: %v", tt.input, strict, err)
+				continue
+			}
+			testObjectLiteral(t, value, tt.expected)
+		}
+	}
+}
+
+func TestStrictMode(t *testing.T) {
+	tests := []struct {
+		input     string
+		loose     interface{} // Resultado sin modo estricto
+		strictErr string      // Error en modo estricto
+	}{
+		{"var x: int = \"5\"\nx", 5, "tipo incompatible: esperado int, recibido string"},
+		{"var x: int = 1.9\nx", 1, "tipo incompatible: esperado int, recibido float"},
+		{"var x: string = 1\nx", "1", "tipo incompatible: esperado string, recibido int"},
+		{"var b: bool = 0\nb", false, "tipo incompatible: esperado bool, recibido int"},
+		{"var x: int = 1\nx = \"7\"\nx", 7, "tipo incompatible: esperado int, recibido string"},
+		{"var x: int = 1\nx += 0.5\nx", 1, "tipo incompatible: esperado int, recibido float"},
+		// Las funciones async se evalúan con el mismo modo
+		{"async func f(v) {\n    x int := v\n    return x\n}\nawait f(\"12\")", 12, "tipo incompatible: esperado int, recibido string"},
+	}
+
+	for _, tt := range tests {
+		value, err := evalWithMode(t, tt.input, false)
+		if err != nil {
+			t.Errorf("%s: error inesperado sin modo estricto: %v", tt.input, err)
+		} else {
+			testObjectLiteral(t, value, tt.loose)
+		}
+
+		_, err = evalWithMode(t, tt.input, true)
+		if err == nil || err.Error() != tt.strictErr {
+			t.Errorf("%s: se esperaba el error %q en modo estricto, obtenido %v", tt.input, tt.strictErr, err)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package evaluator
+
+// import "std/string" (o import string) enlaza el objeto string con las
+// funciones de texto de zyloruntime, en lugar de cargar un módulo escrito en
+// Zylo. Como en el analizador semántico, este módulo nativo tiene prioridad
+// sobre un archivo std/string.zylo.
+
+import (
+	zyloruntime "github.com/zylo-lang/zylo/runtime"
+)
+
+// nativeModules son los módulos de std/ implementados en Go, por nombre
+var nativeModules = map[string]func() *MapObject{
+	"string": newStringModule,
+	"time":   newTimeModule,
+}
+
+// stringModuleFunctions relaciona cada función del módulo string con el
+// builtin de zyloruntime que la implementa
+var stringModuleFunctions = map[string]string{
+	"split":       "split",
+	"join":        "join",
+	"trim":        "trim",
+	"replace":     "replace",
+	"substring":   "substring",
+	"contains":    "contains",
+	"starts_with": "starts_with",
+	"ends_with":   "ends_with",
+	"upper":       "to_upper",
+	"lower":       "to_lower",
+	"to_upper":    "to_upper",
+	"to_lower":    "to_lower",
+}
+
+// newStringModule crea el objeto string del módulo std/string
+func newStringModule() *MapObject {
+	builtins := zyloruntime.GetExtendedBuiltins()
+	module := &MapObject{Pairs: make(map[string]Value, len(stringModuleFunctions))}
+	for name, builtin := range stringModuleFunctions {
+		module.Pairs[name] = adaptRuntimeBuiltin("string."+name, builtins[builtin])
+	}
+	return module
+}
+
+// nativeModule devuelve un objeto nuevo del módulo nativo name, si existe
+func nativeModule(name string) (*MapObject, bool) {
+	newModule, ok := nativeModules[name]
+	if !ok {
+		return nil, false
+	}
+	return newModule(), true
+}
@@ -0,0 +1,102 @@
+package evaluator
+
+// Structs: struct Point { x: int, y: int } declara un tipo de datos sin
+// métodos y Point{x: 1, y: 2} crea un valor. Al construirlo hay que dar todos
+// los campos y solo esos; los tipos los comprueba el análisis semántico.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+)
+
+// StructDef representa un struct declarado
+type StructDef struct {
+	Name   string
+	Fields []string // Nombres de los campos en orden de declaración
+}
+
+func (d *StructDef) Type() string    { return "STRUCT_DEF_OBJ" }
+func (d *StructDef) Inspect() string { return fmt.Sprintf("struct %s", d.Name) }
+
+// hasField indica si name es un campo del struct
+func (d *StructDef) hasField(name string) bool {
+	for _, field := range d.Fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// StructValue representa un valor de un struct
+type StructValue struct {
+	Def    *StructDef
+	Fields map[string]Value
+}
+
+func (s *StructValue) Type() string { return "STRUCT_OBJ" }
+func (s *StructValue) Inspect() string {
+	parts := make([]string, len(s.Def.Fields))
+	for i, name := range s.Def.Fields {
+		value := s.Fields[name]
+		if obj, ok := value.(ZyloObject); ok {
+			parts[i] = name + ": " + obj.Inspect()
+		} else {
+			parts[i] = fmt.Sprintf("%s: %v", name, value)
+		}
+	}
+	return s.Def.Name + "{" + strings.Join(parts, ", ") + "}"
+}
+
+// evaluateStructStatement evalúa una declaración de struct. Como en los enums,
+// el nombre del struct es constante.
+func (e *Evaluator) evaluateStructStatement(stmt *ast.StructStatement) (Value, error) {
+	def := &StructDef{Name: stmt.Name.Value}
+	for _, field := range stmt.Fields {
+		def.Fields = append(def.Fields, field.Value)
+	}
+
+	e.env.Set(def.Name, def)
+	e.env.constants[def.Name] = true
+	return &Null{}, nil
+}
+
+// evaluateStructLiteral evalúa Nombre{campo: valor, ...}
+func (e *Evaluator) evaluateStructLiteral(exp *ast.StructLiteral) (Value, error) {
+	value, exists := e.env.Get(exp.Name.Value)
+	if !exists {
+		return nil, newRuntimeError(exp.Token, "variable no definida: %s", exp.Name.Value)
+	}
+	def, ok := value.(*StructDef)
+	if !ok {
+		return nil, fmt.Errorf("%s no es un struct", exp.Name.Value)
+	}
+
+	result := &StructValue{Def: def, Fields: make(map[string]Value, len(def.Fields))}
+	for _, field := range exp.Fields {
+		if !def.hasField(field.Name) {
+			return nil, fmt.Errorf("el struct %s no tiene el campo %s", def.Name, field.Name)
+		}
+		fieldValue, err := e.evaluateExpression(field.Value)
+		if err != nil {
+			return nil, err
+		}
+		result.Fields[field.Name] = fieldValue
+	}
+	for _, name := range def.Fields {
+		if _, ok := result.Fields[name]; !ok {
+			return nil, fmt.Errorf("falta el campo %s al construir %s", name, def.Name)
+		}
+	}
+	return result, nil
+}
+
+// structField devuelve el campo name de s
+func structField(s *StructValue, name string) (Value, error) {
+	if value, ok := s.Fields[name]; ok {
+		return value, nil
+	}
+	return nil, fmt.Errorf("el struct %s no tiene el campo %s", s.Def.Name, name)
+}
@@ -0,0 +1,44 @@
+package evaluator
+
+import "testing"
+
+func TestStructs(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"struct Point { x: int, y: int }\np := Point{x: 1, y: 2}\np.x + p.y", 3},
+		{"struct Point { x: int, y: int }\np := Point{y: 2, x: 1}\nformat(\"{}\", p)", "Point{x: 1, y: 2}"},
+		{"struct Par { a, b }\nformat(\"{}\", Par{a: \"uno\", b: [1]})", "Par{a: uno, b: [1]}"},
+		{"struct Point { x: int, y: int }\np := Point{x: 1, y: 2}\np.x = 5\np.y += 1\np.x * p.y", 15},
+		{"struct Point { x: int, y: int }\nPoint{x: 1, y: 2} == Point{x: 1, y: 2}", true},
+		{"struct Point { x: int, y: int }\nPoint{x: 1, y: 2} == Point{x: 2, y: 1}", false},
+		// Dos structs con los mismos campos son tipos distintos
+		{"struct A { x }\nstruct B { x }\nA{x: 1} == B{x: 1}", false},
+		{"struct A { x }\nA{x: 1} == {\"x\": 1}", false},
+		{"struct P { x: int }\nstruct L { a: P, b: P }\nl := L{a: P{x: 1}, b: P{x: 2}}\nl.b.x", 2},
+		{"struct P { x: int }\nvar p: P = P{x: 1}\np.x", 1},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestStructErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"struct P { x, y }\nP{x: 1}", "falta el campo y al construir P"},
+		{"struct P { x }\nP{x: 1, z: 2}", "el struct P no tiene el campo z"},
+		{"struct P { x }\np := P{x: 1}\np.z", "el struct P no tiene el campo z"},
+		{"struct P { x }\np := P{x: 1}\np.z = 2", "el struct P no tiene el campo z"},
+		{"P := 1\nP{x: 1}", "P no es un struct"},
+		{"struct P { x }\nP = 1", "no se puede reasignar constante: P"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
@@ -0,0 +1,81 @@
+package evaluator
+
+// El objeto time da acceso al reloj con el paquete time de Go. Los instantes
+// son timestamps unix en segundos y los formatos usan los layouts de Go
+// ("2006-01-02 15:04:05"). format y parse trabajan en UTC, así que
+// time.format(time.parse(s, layout), layout) devuelve s.
+
+import (
+	"fmt"
+	"time"
+)
+
+// newTimeModule crea el objeto time, global y también módulo std/time
+func newTimeModule() *MapObject {
+	module := &MapObject{Pairs: make(map[string]Value)}
+	module.Set("now", &BuiltinFunction{Name: "time.now", Fn: timeNow})
+	module.Set("sleep", &BuiltinFunction{Name: "time.sleep", Fn: timeSleep})
+	module.Set("format", &BuiltinFunction{Name: "time.format", Fn: timeFormat})
+	module.Set("parse", &BuiltinFunction{Name: "time.parse", Fn: timeParse})
+	return module
+}
+
+// timeNow implementa time.now(): el timestamp unix actual
+func timeNow(args []Value) (Value, error) {
+	if len(args) != 0 {
+		return nil, fmt.Errorf("time.now espera 0 argumentos, obtenidos %d", len(args))
+	}
+	return &Integer{Value: time.Now().Unix()}, nil
+}
+
+// timeSleep implementa time.sleep(ms): detiene la ejecución ms milisegundos
+func timeSleep(args []Value) (Value, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("time.sleep espera 1 argumento, obtenidos %d", len(args))
+	}
+	ms, ok := args[0].(*Integer)
+	if !ok {
+		return nil, fmt.Errorf("time.sleep espera un número entero de milisegundos")
+	}
+	if ms.Value < 0 {
+		return nil, fmt.Errorf("time.sleep espera una duración no negativa, obtenido %d", ms.Value)
+	}
+	time.Sleep(time.Duration(ms.Value) * time.Millisecond)
+	return &Null{}, nil
+}
+
+// timeFormat implementa time.format(ts, layout)
+func timeFormat(args []Value) (Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("time.format espera 2 argumentos, obtenidos %d", len(args))
+	}
+	ts, ok := args[0].(*Integer)
+	if !ok {
+		return nil, fmt.Errorf("time.format espera un timestamp entero")
+	}
+	layout, ok := args[1].(*String)
+	if !ok {
+		return nil, fmt.Errorf("time.format espera un formato string")
+	}
+	return &String{Value: time.Unix(ts.Value, 0).UTC().Format(layout.Value)}, nil
+}
+
+// timeParse implementa time.parse(str, layout): el timestamp de str
+func timeParse(args []Value) (Value, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("time.parse espera 2 argumentos, obtenidos %d", len(args))
+	}
+	str, ok := args[0].(*String)
+	if !ok {
+		return nil, fmt.Errorf("time.parse espera un string")
+	}
+	layout, ok := args[1].(*String)
+	if !ok {
+		return nil, fmt.Errorf("time.parse espera un formato string")
+	}
+	parsed, err := time.Parse(layout.Value, str.Value)
+	if err != nil {
+		return nil, fmt.Errorf("time.parse: %q no tiene el formato %q", str.Value, layout.Value)
+	}
+	return &Integer{Value: parsed.Unix()}, nil
+}
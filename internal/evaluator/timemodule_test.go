@@ -0,0 +1,60 @@
+package evaluator
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeModule(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{`time.parse("2024-03-05 10:20:30", "2006-01-02 15:04:05")`, 1709634030},
+		{`time.parse("1970-01-02", "2006-01-02")`, 86400},
+		{`time.format(1709634030, "02/01/2006 15:04")`, "05/03/2024 10:20"},
+		{`time.format(0, "2006-01-02")`, "1970-01-01"},
+		{`time.format(time.parse("31-12-1999", "02-01-2006"), "02-01-2006")`, "31-12-1999"},
+		{"import time\ntime.format(0, \"2006\")", "1970"},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestTimeNowAndSleep(t *testing.T) {
+	before := time.Now().Unix()
+	now, ok := testEval("time.now()").(*Integer)
+	if !ok {
+		t.Fatal("time.now() debería devolver un entero")
+	}
+	if now.Value < before || now.Value > time.Now().Unix() {
+		t.Errorf("time.now() = %d, fuera del intervalo esperado", now.Value)
+	}
+
+	start := time.Now()
+	if _, ok := testEval("time.sleep(30)").(*Null); !ok {
+		t.Error("time.sleep() debería devolver null")
+	}
+	if elapsed := time.Since(start); elapsed < 30*time.Millisecond {
+		t.Errorf("time.sleep(30) solo esperó %v", elapsed)
+	}
+}
+
+func TestTimeModuleErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`time.sleep(1.5)`, "time.sleep espera un número entero de milisegundos"},
+		{`time.sleep(-1)`, "time.sleep espera una duración no negativa, obtenido -1"},
+		{`time.now(1)`, "time.now espera 0 argumentos, obtenidos 1"},
+		{`time.format("0", "2006")`, "time.format espera un timestamp entero"},
+		{`time.parse("ayer", "2006-01-02")`, `time.parse: "ayer" no tiene el formato "2006-01-02"`},
+	}
+
+	for _, tt := range tests {
+		testEvalErrorExact(t, tt.input, tt.expected)
+	}
+}
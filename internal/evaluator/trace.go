@@ -0,0 +1,80 @@
+package evaluator
+
+// La traza de llamadas (zylo debug --trace) escribe cada entrada a una función
+// Zylo con sus argumentos y cada salida con su valor o su error, sangradas
+// según la profundidad de llamadas.
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// TraceEnv es la variable de entorno que activa la traza de llamadas
+const TraceEnv = "ZYLO_TRACE"
+
+// SetTrace hace que las llamadas se escriban en w; nil desactiva la traza
+func (e *Evaluator) SetTrace(w io.Writer) {
+	e.trace = w
+}
+
+// traceFromEnv devuelve dónde escribir la traza según TraceEnv: la salida de
+// errores si está activa, para no mezclarse con la salida del programa
+func traceFromEnv() io.Writer {
+	switch os.Getenv(TraceEnv) {
+	case "", "0", "false":
+		return nil
+	}
+	return os.Stderr
+}
+
+// traceCall escribe la entrada a name con sus argumentos
+func (e *Evaluator) traceCall(name string, args []Value, named map[string]Value) {
+	parts := make([]string, 0, len(args)+len(named))
+	for _, arg := range args {
+		parts = append(parts, traceValue(arg))
+	}
+	names := make([]string, 0, len(named))
+	for n := range named {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		parts = append(parts, n+": "+traceValue(named[n]))
+	}
+	fmt.Fprintf(e.trace, "%s→ %s(%s) [profundidad %d]\n", e.traceIndent(), name, strings.Join(parts, ", "), e.callDepth)
+}
+
+// traceReturn escribe la salida de name con su resultado o su error
+func (e *Evaluator) traceReturn(name string, result Value, err error) {
+	if err != nil {
+		fmt.Fprintf(e.trace, "%s← %s error: %v [profundidad %d]\n", e.traceIndent(), name, err, e.callDepth)
+		return
+	}
+	fmt.Fprintf(e.trace, "%s← %s = %s [profundidad %d]\n", e.traceIndent(), name, traceValue(result), e.callDepth)
+}
+
+// traceIndent sangra dos espacios por cada nivel por encima del primero
+func (e *Evaluator) traceIndent() string {
+	if e.callDepth <= 1 {
+		return ""
+	}
+	return strings.Repeat("  ", e.callDepth-1)
+}
+
+// traceValue muestra un valor en la traza; los strings van entre comillas
+// para distinguir "1" de 1
+func traceValue(v Value) string {
+	switch value := v.(type) {
+	case nil:
+		return "null"
+	case *String:
+		return strconv.Quote(value.Value)
+	case ZyloObject:
+		return value.Inspect()
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,77 @@
+package evaluator
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+)
+
+func TestTrace(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`func doble(n) {
+    return n * 2
+}
+func cuadruple(n) {
+    return doble(doble(n))
+}
+x := cuadruple(3)`, `→ cuadruple(3) [profundidad 1]
+  → doble(3) [profundidad 2]
+  ← doble = 6 [profundidad 2]
+  → doble(6) [profundidad 2]
+  ← doble = 12 [profundidad 2]
+← cuadruple = 12 [profundidad 1]
+`},
+		{`class Saludo {
+    func decir(nombre, signo = "!") {
+        return "hola " + nombre + signo
+    }
+}
+s := Saludo()
+x := s.decir("Ana", signo: "?")`, `→ Saludo.decir("Ana", signo: "?") [profundidad 1]
+← Saludo.decir = "hola Ana?" [profundidad 1]
+`},
+		{`func falla() {
+    throw "mal"
+}
+try {
+    falla()
+} catch (e) {
+}`, `→ falla() [profundidad 1]
+← falla error: mal [profundidad 1]
+`},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+		var out bytes.Buffer
+		eval := NewEvaluator()
+		eval.SetTrace(&out)
+		if err := eval.EvaluateProgram(program); err != nil {
+			t.Fatalf("error inesperado: %v", err)
+		}
+		if !strings.Contains(out.String(), tt.expected) {
+			t.Errorf("traza inesperada:\n%s\nse esperaba:\n%s", out.String(), tt.expected)
+		}
+	}
+}
+
+func TestTraceDisabled(t *testing.T) {
+	t.Setenv(TraceEnv, "")
+	if NewEvaluator().trace != nil {
+		t.Error("la traza no debería activarse sin " + TraceEnv)
+	}
+	t.Setenv(TraceEnv, "true")
+	if NewEvaluator().trace == nil {
+		t.Error("la traza debería activarse con " + TraceEnv + "=true")
+	}
+}
@@ -0,0 +1,87 @@
+package evaluator
+
+// Comprobaciones de tipo: x is int, x is list, x is Animal. Los nombres de
+// tipos básicos se comparan con getNormalizedType; cualquier otro nombre tiene
+// que ser una clase (también cuentan sus subclases), un struct o un enum.
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+)
+
+// basicTypeNames traduce los nombres de tipos básicos que admite is al tipo
+// que devuelve getNormalizedType
+var basicTypeNames = map[string]string{
+	"int":     "int",
+	"integer": "int",
+	"float":   "float",
+	"float64": "float",
+	"string":  "string",
+	"bool":    "bool",
+	"boolean": "bool",
+	"list":    "list",
+	"map":     "map",
+	"null":    "null",
+	"nil":     "null",
+}
+
+// evaluateIsExpression evalúa value is Tipo
+func (e *Evaluator) evaluateIsExpression(exp *ast.IsExpression) (Value, error) {
+	value, err := e.evaluateExpression(exp.Left)
+	if err != nil {
+		return nil, err
+	}
+	matches, err := e.isOfType(value, exp.TypeName)
+	if err != nil {
+		return nil, withPosition(err, exp.Token)
+	}
+	return &Boolean{Value: matches}, nil
+}
+
+// isOfType indica si value es del tipo llamado typeName
+func (e *Evaluator) isOfType(value Value, typeName string) (bool, error) {
+	name := strings.ToLower(typeName)
+	switch name {
+	case "any":
+		return true, nil
+	case "number":
+		normalized := getNormalizedType(value)
+		return normalized == "int" || normalized == "float", nil
+	case "set":
+		_, ok := value.(*Set)
+		return ok, nil
+	case "func", "function":
+		return isCallable(value), nil
+	}
+	if normalized, ok := basicTypeNames[name]; ok {
+		return getNormalizedType(value) == normalized, nil
+	}
+
+	typ, exists := e.env.Get(typeName)
+	if !exists {
+		return false, fmt.Errorf("tipo desconocido: %s", typeName)
+	}
+	switch t := typ.(type) {
+	case *ZyloClass:
+		instance, ok := value.(*ZyloInstance)
+		if !ok {
+			return false, nil
+		}
+		for class := instance.Class; class != nil; class = class.SuperClass {
+			if class == t {
+				return true, nil
+			}
+		}
+		return false, nil
+	case *StructDef:
+		s, ok := value.(*StructValue)
+		return ok && s.Def == t, nil
+	case *Enum:
+		member, ok := value.(*EnumMember)
+		return ok && member.Enum == t, nil
+	default:
+		return false, fmt.Errorf("%s no es un tipo", typeName)
+	}
+}
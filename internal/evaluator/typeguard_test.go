@@ -0,0 +1,53 @@
+package evaluator
+
+import "testing"
+
+func TestIsExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"1 is int", true},
+		{"1 is float", false},
+		{"1.5 is float", true},
+		{"1 is number", true},
+		{"\"a\" is number", false},
+		{"\"a\" is string", true},
+		{"true is bool", true},
+		{"[1] is list", true},
+		{"{\"a\": 1} is map", true},
+		{"{\"a\": 1} is list", false},
+		{"nil is nil", true},
+		{"0 is nil", false},
+		{"[1] is any", true},
+		{"len is func", true},
+		{"x := 1\nx is function", false},
+		{"class A {\n}\nA() is A", true},
+		// Una instancia también es de las clases de las que hereda
+		{"class A {\n}\nclass B extends A {\n}\nB() is A", true},
+		{"class A {\n}\nclass B extends A {\n}\nA() is B", false},
+		{"class A {\n}\n1 is A", false},
+		{"struct P { x }\nP{x: 1} is P", true},
+		{"struct P { x }\nstruct Q { x }\nP{x: 1} is Q", false},
+		{"enum Color { Red }\nColor.Red is Color", true},
+		{"enum Color { Red }\n\"Red\" is Color", false},
+	}
+
+	for _, tt := range tests {
+		testObjectLiteral(t, testEval(tt.input), tt.expected)
+	}
+}
+
+func TestIsExpressionErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"1 is Desconocido", "tipo desconocido: Desconocido"},
+		{"x := 1\n1 is x", "x no es un tipo"},
+	}
+
+	for _, tt := range tests {
+		testEvalError(t, tt.input, tt.expected)
+	}
+}
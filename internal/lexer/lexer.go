@@ -1,6 +1,7 @@
 package lexer
 
 import (
+	"fmt"
 	"strconv"
 	"strings"
 	"unicode"
@@ -15,6 +16,10 @@ type Lexer struct {
 	column      int    // Columna actual en la línea.
 	startLine   int    // Línea de inicio del token actual.
 	startColumn int    // Columna de inicio del token actual.
+
+	doc            []string // Líneas // pendientes de adjuntar al siguiente token.
+	lineHasToken   bool     // La línea actual ya produjo algún token.
+	lineHasComment bool     // La línea actual contiene un comentario.
 }
 
 // New crea un nuevo Lexer para el código fuente proporcionado.
@@ -99,13 +104,20 @@ func (l *Lexer) makeToken(tokenType TokenType, literal interface{}) Token {
 	}
 }
 
-// errorToken crea un token de error.
+// errorToken crea un token de error en la posición actual.
 func (l *Lexer) errorToken(message string) Token {
+	return l.errorTokenAt(message, l.line, l.column)
+}
+
+// errorTokenAt crea un token de error que empieza en line:col y termina en la
+// posición actual, para señalar por ejemplo la comilla de apertura de una
+// cadena sin cerrar.
+func (l *Lexer) errorTokenAt(message string, line, col int) Token {
 	return Token{
-		Type:      "ERROR",
+		Type:      ERROR,
 		Lexeme:    message,
-		StartLine: l.line,
-		StartCol:  l.column,
+		StartLine: line,
+		StartCol:  col,
 		EndLine:   l.line,
 		EndCol:    l.column,
 	}
@@ -121,9 +133,7 @@ func (l *Lexer) skipWhitespace() {
 			return
 		case '/':
 			if l.peekNext() == '/' {
-				for l.peek() != '\n' && !l.isAtEnd() {
-					l.advance()
-				}
+				l.lineComment()
 			} else if l.peekNext() == '*' {
 				l.advance()
 				l.advance()
@@ -187,51 +197,141 @@ func (l *Lexer) identifier() Token {
 	return l.makeToken(tokenType, nil)
 }
 
-// number procesa un número literal.
+// number procesa un número literal. Además de los decimales admite los
+// prefijos 0x, 0b y 0o, exponentes (1.5e3) y guiones bajos entre dígitos
+// (1_000_000), que se eliminan antes de convertir el valor. Los errores
+// señalan el comienzo del número.
 func (l *Lexer) number() Token {
+	if l.source[l.start] == '0' {
+		switch l.peek() {
+		case 'x', 'X':
+			return l.prefixedNumber(16, "hexadecimal")
+		case 'b', 'B':
+			return l.prefixedNumber(2, "binary")
+		case 'o', 'O':
+			return l.prefixedNumber(8, "octal")
+		}
+	}
+
 	isFloat := false
-	for isDigit(l.peek()) {
+	l.digits(isDigit)
+	if l.peek() == '.' && isDigit(l.peekNext()) {
+		isFloat = true
 		l.advance()
+		l.digits(isDigit)
 	}
-	if l.peek() == '.' && isDigit(l.peekNext()) {
+	// Una e detrás de los dígitos siempre empieza el exponente: 1e es un
+	// error y no el número 1 seguido del identificador e
+	if l.peek() == 'e' || l.peek() == 'E' {
 		isFloat = true
 		l.advance()
-		for isDigit(l.peek()) {
+		if l.peek() == '+' || l.peek() == '-' {
 			l.advance()
 		}
+		if !isDigit(l.peek()) {
+			return l.errorTokenAt("Invalid float number: expected digits in exponent.", l.startLine, l.startColumn)
+		}
+		l.digits(isDigit)
 	}
 
 	lexeme := string(l.source[l.start:l.current])
+	text, ok := stripUnderscores(lexeme, isDigit)
+	if !ok {
+		return l.errorTokenAt("Invalid number: '_' must separate digits.", l.startLine, l.startColumn)
+	}
 	if isFloat {
-		value, err := strconv.ParseFloat(lexeme, 64)
+		value, err := strconv.ParseFloat(text, 64)
 		if err != nil {
-			return l.errorToken("Invalid float number.")
+			return l.errorTokenAt("Invalid float number.", l.startLine, l.startColumn)
 		}
 		return l.makeToken(NUMBER, value)
 	}
 
-	value, err := strconv.ParseInt(lexeme, 10, 64)
+	value, err := strconv.ParseInt(text, 10, 64)
 	if err != nil {
-		return l.errorToken("Invalid integer number.")
+		return l.errorTokenAt("Invalid integer number.", l.startLine, l.startColumn)
 	}
 	return l.makeToken(NUMBER, value)
 }
 
+// prefixedNumber procesa un entero con prefijo de base (0x, 0b, 0o). Consume
+// todas las letras y dígitos que siguen para que 0b102 sea un único error y
+// no un número seguido de otro.
+func (l *Lexer) prefixedNumber(base int, name string) Token {
+	prefix := l.advance()
+	digitsStart := l.current
+	for isAlpha(l.peek()) || isDigit(l.peek()) {
+		l.advance()
+	}
+
+	if l.current == digitsStart {
+		return l.errorTokenAt(fmt.Sprintf("Invalid %s number: expected digits after 0%c.", name, prefix), l.startLine, l.startColumn)
+	}
+	text, ok := stripUnderscores(string(l.source[digitsStart:l.current]), isHexDigit)
+	if !ok {
+		return l.errorTokenAt("Invalid number: '_' must separate digits.", l.startLine, l.startColumn)
+	}
+	value, err := strconv.ParseInt(text, base, 64)
+	if err != nil {
+		if numErr, isNumErr := err.(*strconv.NumError); isNumErr && numErr.Err == strconv.ErrRange {
+			return l.errorTokenAt("Invalid integer number.", l.startLine, l.startColumn)
+		}
+		return l.errorTokenAt(fmt.Sprintf("Invalid %s number: %s.", name, string(l.source[l.start:l.current])), l.startLine, l.startColumn)
+	}
+	return l.makeToken(NUMBER, value)
+}
+
+// digits consume dígitos aceptados por valid y guiones bajos.
+func (l *Lexer) digits(valid func(rune) bool) {
+	for valid(l.peek()) || l.peek() == '_' {
+		l.advance()
+	}
+}
+
+// stripUnderscores quita los guiones bajos de un número. Devuelve false si
+// alguno no está entre dos dígitos aceptados por isDigitRune.
+func stripUnderscores(text string, isDigitRune func(rune) bool) (string, bool) {
+	if !strings.Contains(text, "_") {
+		return text, true
+	}
+	runes := []rune(text)
+	var builder strings.Builder
+	for i, r := range runes {
+		if r == '_' {
+			if i == 0 || i == len(runes)-1 || !isDigitRune(runes[i-1]) || !isDigitRune(runes[i+1]) {
+				return "", false
+			}
+			continue
+		}
+		builder.WriteRune(r)
+	}
+	return builder.String(), true
+}
+
 // stringLiteral procesa una cadena literal entre comillas simples o dobles.
+// Los errores señalan la comilla de apertura, o la barra de la secuencia de
+// escape no válida; tras un escape no válido se sigue hasta el final de la
+// cadena para que el resto no se lea como código.
 func (l *Lexer) stringLiteral(quote rune) Token {
 	var builder strings.Builder
+	var escapeErr *Token
 	for {
 		if l.peek() == quote || l.isAtEnd() {
 			break
 		}
 
 		if l.peek() == '\\' {
+			line, col := l.line, l.column
 			l.advance()
 			switch l.peek() {
 			case 'n':
 				builder.WriteRune('\n')
 			case 't':
 				builder.WriteRune('\t')
+			case 'r':
+				builder.WriteRune('\r')
+			case '0':
+				builder.WriteRune(0)
 			case '"':
 				builder.WriteRune('"')
 			case '\'':
@@ -240,37 +340,44 @@ func (l *Lexer) stringLiteral(quote rune) Token {
 				builder.WriteRune('\\')
 			case 'u':
 				l.advance()
-				hex := make([]rune, 4)
-				for i := 0; i < 4; i++ {
-					if !isHexDigit(l.peek()) {
-						return l.errorToken("Invalid Unicode escape sequence: expected 4 hex digits.")
-					}
-					hex[i] = l.advance()
+				hex := make([]rune, 0, 4)
+				for len(hex) < 4 && isHexDigit(l.peek()) {
+					hex = append(hex, l.advance())
 				}
-				hexVal, err := strconv.ParseInt(string(hex), 16, 32)
-				if err != nil {
-					return l.errorToken("Invalid Unicode escape sequence.")
+				if len(hex) < 4 && escapeErr == nil {
+					err := l.errorTokenAt("Invalid Unicode escape sequence: expected 4 hex digits.", line, col)
+					escapeErr = &err
 				}
-				builder.WriteRune(rune(hexVal))
+				if hexVal, err := strconv.ParseInt(string(hex), 16, 32); err == nil {
+					builder.WriteRune(rune(hexVal))
+				}
+				continue
+			case '\n', 0:
+				// La barra al final de la línea no escapa nada; la cadena queda sin cerrar
 				continue
 			default:
-				builder.WriteRune('\\')
-				builder.WriteRune(l.peek())
+				if escapeErr == nil {
+					err := l.errorTokenAt(fmt.Sprintf("Invalid escape sequence: \\%c.", l.peek()), line, col)
+					escapeErr = &err
+				}
 			}
 			l.advance()
 		} else {
 			if l.peek() == '\n' {
-				return l.errorToken("Unterminated string.")
+				return l.errorTokenAt("Unterminated string.", l.startLine, l.startColumn)
 			}
 			builder.WriteRune(l.advance())
 		}
 	}
 
 	if l.isAtEnd() {
-		return l.errorToken("Unterminated string.")
+		return l.errorTokenAt("Unterminated string.", l.startLine, l.startColumn)
 	}
 
 	l.advance()
+	if escapeErr != nil {
+		return *escapeErr
+	}
 	return l.makeToken(STRING, builder.String())
 }
 
@@ -282,7 +389,7 @@ func (l *Lexer) tripleQuotedStringLiteral() Token {
 	var builder strings.Builder
 	for {
 		if l.isAtEnd() {
-			return l.errorToken("Unterminated multi-line string.")
+			return l.errorTokenAt("Unterminated multi-line string.", l.startLine, l.startColumn)
 		}
 		if l.peek() == '"' && l.peekNext() == '"' && l.peekN(2) == '"' {
 			break
@@ -310,6 +417,7 @@ func (l *Lexer) templateStringLiteral() Token {
 			break
 		}
 		if l.peek() == '$' && l.peekNext() == '{' {
+			line, col := l.line, l.column
 			l.advance()
 			l.advance()
 			for !l.isAtEnd() && l.peek() != '}' {
@@ -318,7 +426,7 @@ func (l *Lexer) templateStringLiteral() Token {
 			if l.peek() == '}' {
 				l.advance()
 			} else {
-				return l.errorToken("Unterminated template string interpolation.")
+				return l.errorTokenAt("Unterminated template string interpolation.", line, col)
 			}
 		} else {
 			builder.WriteRune(l.advance())
@@ -326,7 +434,7 @@ func (l *Lexer) templateStringLiteral() Token {
 	}
 
 	if l.isAtEnd() {
-		return l.errorToken("Unterminated template string.")
+		return l.errorTokenAt("Unterminated template string.", l.startLine, l.startColumn)
 	}
 
 	l.advance()
@@ -343,6 +451,54 @@ func (l *Lexer) peekN(n int) rune {
 
 // NextToken escanea y devuelve el siguiente token del código fuente.
 func (l *Lexer) NextToken() Token {
+	token := l.scanToken()
+	l.attachDoc(&token)
+	return token
+}
+
+// lineComment consume un comentario //. Si ocupa la línea entera se guarda
+// como documentación del siguiente token; un comentario al final de una línea
+// con código descarta la documentación pendiente.
+func (l *Lexer) lineComment() {
+	l.advance()
+	l.advance()
+	start := l.current
+	for l.peek() != '\n' && !l.isAtEnd() {
+		l.advance()
+	}
+
+	if l.lineHasToken {
+		l.doc = nil
+		return
+	}
+	text := strings.TrimRight(string(l.source[start:l.current]), " \t\r")
+	l.doc = append(l.doc, strings.TrimPrefix(text, " "))
+	l.lineHasComment = true
+}
+
+// attachDoc adjunta al primer token de una línea los comentarios // que lo
+// preceden. Una línea en blanco separa el comentario de la declaración.
+func (l *Lexer) attachDoc(token *Token) {
+	switch token.Type {
+	case NEWLINE:
+		if !l.lineHasToken && !l.lineHasComment {
+			l.doc = nil
+		}
+		l.lineHasToken = false
+		l.lineHasComment = false
+	case EOF:
+		l.doc = nil
+	default:
+		if !l.lineHasToken && len(l.doc) > 0 {
+			token.Doc = strings.Join(l.doc, "\n")
+		}
+		l.doc = nil
+		l.lineHasToken = true
+	}
+}
+
+// scanToken reconoce el siguiente token del código fuente.
+func (l *Lexer) scanToken() Token {
 	// Skip BOM if present
 	if l.current == 0 && !l.isAtEnd() && l.source[0] == '\ufeff' {
 		l.current = 1
@@ -382,11 +538,23 @@ func (l *Lexer) NextToken() Token {
 		return l.makeToken(SEMICOLON, nil)
 	case ',':
 		return l.makeToken(COMMA, nil)
+	case '@':
+		return l.makeToken(AT, nil)
 	case '.':
 		if l.match('.') {
+			if l.match('.') {
+				return l.makeToken(ELLIPSIS, nil)
+			}
 			return l.makeToken(RANGE, nil)
 		}
 		return l.makeToken(DOT, nil)
+	case '?':
+		if l.match('.') {
+			return l.makeToken(QUESTION_DOT, nil)
+		}
+		if l.match('?') {
+			return l.makeToken(NULL_COALESCE, nil)
+		}
 	case '-':
 		if l.match('>') {
 			return l.makeToken(ARROW_RETURN, nil)
@@ -404,9 +572,6 @@ func (l *Lexer) NextToken() Token {
 		if l.match('=') {
 			return l.makeToken(SLASH_EQUAL, nil)
 		}
-		if l.match('/') {
-			return l.makeToken(FLOOR_DIVIDE, nil)
-		}
 		return l.makeToken(SLASH, nil)
 	case '*':
 		if l.match('=') {
@@ -422,7 +587,7 @@ func (l *Lexer) NextToken() Token {
 		}
 		return l.makeToken(PERCENT, nil)
 	case '^':
-		return l.makeToken(POWER, nil) // Caret for exponentiation
+		return l.makeToken(BIT_XOR, nil)
 	case ':':
 		// Skip whitespace after :
 		for l.peek() == ' ' || l.peek() == '\t' {
@@ -450,22 +615,31 @@ func (l *Lexer) NextToken() Token {
 		if l.match('=') {
 			return l.makeToken(LESS_EQUAL, nil)
 		}
+		if l.match('<') {
+			return l.makeToken(SHIFT_LEFT, nil)
+		}
 		return l.makeToken(LESS, nil)
 	case '>':
 		if l.match('=') {
 			return l.makeToken(GREATER_EQUAL, nil)
 		}
+		if l.match('>') {
+			return l.makeToken(SHIFT_RIGHT, nil)
+		}
 		return l.makeToken(GREATER, nil)
 	case '&':
 		if l.match('&') {
 			return l.makeToken(AND, nil)
 		}
-		return l.errorToken("Unexpected character '&'. Did you mean 'and' or '&&'?")
+		return l.makeToken(BIT_AND, nil)
 	case '|':
 		if l.match('|') {
 			return l.makeToken(OR, nil)
 		}
-		return l.errorToken("Unexpected character '|'. Did you mean 'or' or '||'?")
+		if l.match('>') {
+			return l.makeToken(PIPE, nil)
+		}
+		return l.makeToken(BIT_OR, nil)
 	case '\n':
 		return l.makeToken(NEWLINE, nil)
 	case '"':
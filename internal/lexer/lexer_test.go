@@ -119,6 +119,34 @@ line
 		})
 	}
 }
+func TestDocComments(t *testing.T) {
+	input := `x := 1 // comentario final
+
+// Primera línea.
+// Segunda línea.
+func sumar() {}
+
+// separado por una línea en blanco
+
+func otra() {}
+# no es documentación
+func tercera() {}
+`
+	docs := map[int]string{}
+
+	l := New(input)
+	for tok := l.NextToken(); tok.Type != EOF; tok = l.NextToken() {
+		if tok.Doc != "" {
+			docs[tok.StartLine] = tok.Doc
+		}
+	}
+
+	// Solo la función sumar (línea 5) tiene documentación
+	if len(docs) != 1 || docs[5] != "Primera línea.\nSegunda línea." {
+		t.Errorf("documentación incorrecta: %q", docs)
+	}
+}
+
 func BenchmarkLex(b *testing.B) {
 	input := `var five = 5;
 const ten = 10.5;
@@ -143,3 +171,166 @@ line"""
 		}
 	}
 }
+
+func TestStringErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+		line    int
+		col     int
+	}{
+		// Las cadenas sin cerrar señalan la comilla de apertura
+		{"x := \"hola\ny := 1", "Unterminated string.", 1, 6},
+		{"x := 1\n  y := 'hola", "Unterminated string.", 2, 8},
+		{"x := `hola", "Unterminated template string.", 1, 6},
+		{"x := `a ${b", "Unterminated template string interpolation.", 1, 9},
+		{"x := \"\"\"hola", "Unterminated multi-line string.", 1, 6},
+		// Los escapes no válidos señalan la barra
+		{`x := "a\qb"`, `Invalid escape sequence: \q.`, 1, 8},
+		{`x := "\u12"`, "Invalid Unicode escape sequence: expected 4 hex digits.", 1, 7},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		var tok Token
+		for tok = l.NextToken(); tok.Type != ERROR && tok.Type != EOF; tok = l.NextToken() {
+		}
+		if tok.Type != ERROR {
+			t.Errorf("%q: se esperaba un token ERROR", tt.input)
+			continue
+		}
+		if tok.Lexeme != tt.message || tok.StartLine != tt.line || tok.StartCol != tt.col {
+			t.Errorf("%q: esperado %q en %d:%d, obtenido %q en %d:%d", tt.input, tt.message, tt.line, tt.col, tok.Lexeme, tok.StartLine, tok.StartCol)
+		}
+		// Tras un escape no válido se sigue leyendo después de la cadena
+		if next := l.NextToken(); next.Type == ERROR {
+			t.Errorf("%q: error de más tras el primero: %q", tt.input, next.Lexeme)
+		}
+	}
+}
+
+func TestStringEscapes(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`"a\nb"`, "a\nb"},
+		{`"a\tb\rc"`, "a\tb\rc"},
+		{`'\'\"\\'`, `'"\`},
+		{`"ñ"`, "ñ"},
+		{`"\0"`, "\x00"},
+	}
+
+	for _, tt := range tests {
+		tok := New(tt.input).NextToken()
+		if tok.Type != STRING || tok.Literal != tt.expected {
+			t.Errorf("%s: esperado %q, obtenido %s %q", tt.input, tt.expected, tok.Type, tok.Literal)
+		}
+	}
+}
+
+func TestNumberLiterals(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"42", int64(42)},
+		{"0", int64(0)},
+		{"0xFF", int64(255)},
+		{"0Xff", int64(255)},
+		{"0b1010", int64(10)},
+		{"0o17", int64(15)},
+		{"1_000_000", int64(1000000)},
+		{"0xFF_FF", int64(65535)},
+		{"0b1111_0000", int64(240)},
+		{"3.14", 3.14},
+		{"1_000.5", 1000.5},
+		{"1.5e3", 1500.0},
+		{"2E-2", 0.02},
+		{"1e+2", 100.0},
+		{"1_0e1_0", 1e11},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		tok := l.NextToken()
+		if tok.Type != NUMBER || tok.Literal != tt.expected {
+			t.Errorf("%s: esperado %v (%T), obtenido %s %v (%T)", tt.input, tt.expected, tt.expected, tok.Type, tok.Literal, tok.Literal)
+		}
+		if tok.Lexeme != tt.input {
+			t.Errorf("%s: lexema %q", tt.input, tok.Lexeme)
+		}
+		if next := l.NextToken(); next.Type != EOF {
+			t.Errorf("%s: sobra el token %s %q", tt.input, next.Type, next.Lexeme)
+		}
+	}
+}
+
+func TestNumberErrors(t *testing.T) {
+	tests := []struct {
+		input   string
+		message string
+	}{
+		{"x := 0x", "Invalid hexadecimal number: expected digits after 0x."},
+		{"x := 0b", "Invalid binary number: expected digits after 0b."},
+		{"x := 0o", "Invalid octal number: expected digits after 0o."},
+		{"x := 0b102", "Invalid binary number: 0b102."},
+		{"x := 0o8", "Invalid octal number: 0o8."},
+		{"x := 0xFG", "Invalid hexadecimal number: 0xFG."},
+		{"x := 1__0", "Invalid number: '_' must separate digits."},
+		{"x := 10_", "Invalid number: '_' must separate digits."},
+		{"x := 1_.5", "Invalid number: '_' must separate digits."},
+		{"x := 0x_F", "Invalid number: '_' must separate digits."},
+		{"x := 1e+", "Invalid float number: expected digits in exponent."},
+		{"x := 1e", "Invalid float number: expected digits in exponent."},
+		{"x := 2.5E", "Invalid float number: expected digits in exponent."},
+		{"x := 0xFFFFFFFFFFFFFFFFF", "Invalid integer number."},
+	}
+
+	for _, tt := range tests {
+		l := New(tt.input)
+		var tok Token
+		for tok = l.NextToken(); tok.Type != ERROR && tok.Type != EOF; tok = l.NextToken() {
+		}
+		if tok.Type != ERROR {
+			t.Errorf("%q: se esperaba un token ERROR", tt.input)
+			continue
+		}
+		// Los errores señalan el comienzo del número
+		if tok.Lexeme != tt.message || tok.StartLine != 1 || tok.StartCol != 6 {
+			t.Errorf("%q: esperado %q en 1:6, obtenido %q en %d:%d", tt.input, tt.message, tok.Lexeme, tok.StartLine, tok.StartCol)
+		}
+		if next := l.NextToken(); next.Type != EOF {
+			t.Errorf("%q: sobra el token %s %q", tt.input, next.Type, next.Lexeme)
+		}
+	}
+}
+
+func TestOptionalAccessTokens(t *testing.T) {
+	expected := []TokenType{IDENTIFIER, QUESTION_DOT, IDENTIFIER, QUESTION_DOT, LEFT_BRACKET, STRING, RIGHT_BRACKET, EOF}
+
+	l := New(`user?.address?.["city"]`)
+	for i, want := range expected {
+		if tok := l.NextToken(); tok.Type != want {
+			t.Fatalf("token %d: expected %s, got %s (%q)", i, want, tok.Type, tok.Lexeme)
+		}
+	}
+
+	// Un '?' suelto sigue siendo un carácter inesperado
+	l = New("a ? b")
+	l.NextToken()
+	if tok := l.NextToken(); tok.Type != ERROR {
+		t.Errorf("expected ERROR for '?', got %s", tok.Type)
+	}
+}
+
+func TestNullCoalescingToken(t *testing.T) {
+	expected := []TokenType{IDENTIFIER, NULL_COALESCE, IDENTIFIER, QUESTION_DOT, IDENTIFIER, NULL_COALESCE, NUMBER, EOF}
+
+	l := New("a ?? b?.c ?? 0")
+	for i, want := range expected {
+		if tok := l.NextToken(); tok.Type != want {
+			t.Fatalf("token %d: expected %s, got %s (%q)", i, want, tok.Type, tok.Lexeme)
+		}
+	}
+}
@@ -14,6 +14,7 @@
 		StartCol  int         // La columna donde comienza el token.
 		EndLine   int         // La línea donde termina el token.
 		EndCol    int         // La columna donde termina el token.
+		Doc       string      // Comentarios // inmediatamente anteriores al token, si los hay.
 	}
 
 	// String devuelve una representación legible del token, útil para debugging.
@@ -33,7 +34,10 @@
 		RIGHT_BRACKET TokenType = "RIGHT_BRACKET"
 		COMMA         TokenType = "COMMA"
 		DOT           TokenType = "DOT"
+		QUESTION_DOT  TokenType = "QUESTION_DOT" // ?. for null-safe access
+		NULL_COALESCE TokenType = "NULL_COALESCE" // ?? for null coalescing
 		RANGE         TokenType = "RANGE" // .. for ranges
+		ELLIPSIS      TokenType = "ELLIPSIS" // ... for variadic parameters
 		MINUS         TokenType = "MINUS"
 		PLUS          TokenType = "PLUS"
 		SEMICOLON     TokenType = "SEMICOLON"
@@ -41,6 +45,7 @@
 		STAR          TokenType = "STAR"
 		PERCENT       TokenType = "PERCENT"
 		COLON         TokenType = "COLON"
+		AT            TokenType = "AT" // @ para decoradores
 
 		// Tokens de uno o dos caracteres
 		BANG          TokenType = "BANG"
@@ -84,6 +89,7 @@
 		IF       TokenType = "IF"
 		NIL      TokenType = "NIL"
 		OR       TokenType = "OR"
+		XOR      TokenType = "XOR"
 		NOT      TokenType = "NOT"
 		RETURN   TokenType = "RETURN"
 		SUPER    TokenType = "SUPER"
@@ -116,17 +122,28 @@
 		PUBLIC   TokenType = "PUBLIC"  // Nueva palabra clave para visibilidad
 		PRIVATE  TokenType = "PRIVATE" // Nueva palabra clave para visibilidad
 		VOID     TokenType = "VOID"    // Nueva palabra clave para funciones sin retorno
+		ENUM     TokenType = "ENUM"    // Nueva palabra clave para enumeraciones
+		STRUCT   TokenType = "STRUCT"  // Nueva palabra clave para structs
+		IS       TokenType = "IS"      // Nueva palabra clave para comprobar tipos
+		REPEAT   TokenType = "REPEAT"  // Nueva palabra clave para repetir un bloque N veces
 
 		// Operadores compuestos
 		PLUS_EQUAL    TokenType = "PLUS_EQUAL"    // +=
 		MINUS_EQUAL   TokenType = "MINUS_EQUAL"   // -=
+		PIPE          TokenType = "PIPE"          // |>
 		STAR_EQUAL    TokenType = "STAR_EQUAL"    // *=
 		SLASH_EQUAL   TokenType = "SLASH_EQUAL"   // /=
 		PERCENT_EQUAL TokenType = "PERCENT_EQUAL" // %=
 		POWER         TokenType = "POWER"         // **
-		FLOOR_DIVIDE  TokenType = "FLOOR_DIVIDE"  // //
 		WALRUS_ASSIGN TokenType = "WALRUS_ASSIGN" // :=
 
+		// Operadores de bits
+		BIT_AND     TokenType = "BIT_AND"     // &
+		BIT_OR      TokenType = "BIT_OR"      // |
+		BIT_XOR     TokenType = "BIT_XOR"     // ^
+		SHIFT_LEFT  TokenType = "SHIFT_LEFT"  // <<
+		SHIFT_RIGHT TokenType = "SHIFT_RIGHT" // >>
+
 		// Control
 		NEWLINE TokenType = "NEWLINE"
 		EOF     TokenType = "EOF"
@@ -145,6 +162,7 @@
 			"if":       IF,
 			"nil":      NIL,
 			"or":       OR,
+			"xor":      XOR,
 			"not":      NOT, // AÑADIDO: soporte para 'not' como palabra clave
 			"return":   RETURN,
 			"super":    SUPER,
@@ -176,6 +194,10 @@
 			"public":   PUBLIC,
 			"private":  PRIVATE,
 			"void":     VOID,
+			"enum":     ENUM,
+			"struct":   STRUCT,
+			"is":       IS,
+			"repeat":   REPEAT,
 
 			// Tipos primitivos Go agregados como palabras clave
 			"int":      INT_TYPE,
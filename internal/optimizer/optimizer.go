@@ -43,6 +43,10 @@ func (o *Optimizer) constantFolding(node ast.Node) ast.Node {
 	case *ast.IfStatement:
 		n.Condition = o.constantFolding(n.Condition).(ast.Expression)
 		n.Consequence = o.constantFolding(n.Consequence).(*ast.BlockStatement)
+		for _, clause := range n.ElseIfs {
+			clause.Condition = o.constantFolding(clause.Condition).(ast.Expression)
+			clause.Consequence = o.constantFolding(clause.Consequence).(*ast.BlockStatement)
+		}
 		if n.Alternative != nil {
 			n.Alternative = o.constantFolding(n.Alternative).(*ast.BlockStatement)
 		}
@@ -70,8 +74,8 @@ func (o *Optimizer) constantFolding(node ast.Node) ast.Node {
 		}
 		return n
 	case *ast.MapLiteral:
-		for k, v := range n.Pairs {
-			n.Pairs[k] = o.constantFolding(v).(ast.Expression)
+		for i, pair := range n.Pairs {
+			n.Pairs[i].Value = o.constantFolding(pair.Value).(ast.Expression)
 		}
 		return n
 	default:
@@ -220,11 +224,23 @@ func (o *Optimizer) deadCodeElimination(node ast.Node) ast.Node {
 		n.Condition = o.deadCodeElimination(n.Condition).(ast.Expression)
 		n.Consequence = o.deadCodeElimination(n.Consequence).(*ast.BlockStatement)
 
+		for _, clause := range n.ElseIfs {
+			clause.Condition = o.deadCodeElimination(clause.Condition).(ast.Expression)
+			clause.Consequence = o.deadCodeElimination(clause.Consequence).(*ast.BlockStatement)
+		}
+
 		// Check if condition is a constant boolean
 		if boolLit, ok := n.Condition.(*ast.BooleanLiteral); ok {
 			if boolLit.Value {
 				// If condition is always true, replace with consequence block
 				return n.Consequence
+			} else if len(n.ElseIfs) > 0 {
+				// If condition is always false, the first elif becomes the if
+				first := n.ElseIfs[0]
+				n.Condition, n.Consequence, n.ElseIfs = first.Condition, first.Consequence, n.ElseIfs[1:]
+				return o.deadCodeElimination(n)
+			} else if n.Alternative != nil {
+				return o.deadCodeElimination(n.Alternative)
 			} else {
 				// If condition is always false, eliminate the if statement
 				return nil
@@ -0,0 +1,148 @@
+package packages
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LockFile es el archivo con las versiones exactas de las dependencias
+const LockFile = "zylo.lock"
+
+// Locked es la versión instalada de una dependencia
+type Locked struct {
+	Dependency
+	Commit   string // Commit resuelto a partir de la ref
+	Checksum string // sha256 del contenido del paquete, sin el directorio .git
+}
+
+// Checksum calcula el checksum del contenido de un paquete: el sha256 de las
+// rutas relativas y el contenido de sus archivos, en orden, sin contar .git
+func Checksum(dir string) (string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if d.Type().IsRegular() {
+			rel, err := filepath.Rel(dir, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(files)
+
+	h := sha256.New()
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(file)))
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s\x00%d\x00", file, len(data))
+		h.Write(data)
+	}
+	return "sha256:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// ReadLock lee un zylo.lock. Un archivo que no existe no tiene entradas.
+//
+// El formato es una tabla [[package]] por dependencia:
+//
+//	[[package]]
+//	name = "utils"
+//	git = "https://github.com/zylo-packages/utils.git"
+//	ref = "v1.2.0"
+//	commit = "9f3c..."
+//	checksum = "sha256:..."
+func ReadLock(path string) ([]Locked, error) {
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var lock []Locked
+	scanner := bufio.NewScanner(file)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if line == "[[package]]" {
+			lock = append(lock, Locked{})
+			continue
+		}
+		key, raw, ok := strings.Cut(line, "=")
+		if !ok || len(lock) == 0 {
+			return nil, fmt.Errorf("%s:%d: línea inválida: %s", path, n, line)
+		}
+		value, err := strconv.Unquote(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: valor inválido: %s", path, n, raw)
+		}
+		entry := &lock[len(lock)-1]
+		switch strings.TrimSpace(key) {
+		case "name":
+			entry.Name = value
+		case "git":
+			entry.Git = value
+		case "ref":
+			entry.Ref = value
+		case "commit":
+			entry.Commit = value
+		case "checksum":
+			entry.Checksum = value
+		}
+	}
+	return lock, scanner.Err()
+}
+
+// WriteLock escribe las entradas en un zylo.lock, ordenadas por nombre
+func WriteLock(path string, lock []Locked) error {
+	lock = append([]Locked(nil), lock...)
+	sort.Slice(lock, func(i, j int) bool { return lock[i].Name < lock[j].Name })
+
+	var out strings.Builder
+	out.WriteString("# Generado por zylo; no editar a mano\n")
+	for _, entry := range lock {
+		out.WriteString("\n[[package]]\n")
+		fmt.Fprintf(&out, "name = %q\n", entry.Name)
+		fmt.Fprintf(&out, "git = %q\n", entry.Git)
+		if entry.Ref != "" {
+			fmt.Fprintf(&out, "ref = %q\n", entry.Ref)
+		}
+		fmt.Fprintf(&out, "commit = %q\n", entry.Commit)
+		fmt.Fprintf(&out, "checksum = %q\n", entry.Checksum)
+	}
+	return os.WriteFile(path, []byte(out.String()), 0644)
+}
+
+// upsertLocked agrega entry a lock o reemplaza la entrada con el mismo nombre
+func upsertLocked(lock []Locked, entry Locked) []Locked {
+	for i := range lock {
+		if lock[i].Name == entry.Name {
+			lock[i] = entry
+			return lock
+		}
+	}
+	return append(lock, entry)
+}
@@ -0,0 +1,161 @@
+package packages_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/packages"
+)
+
+func TestAddWritesLockfile(t *testing.T) {
+	repo := gitRepo(t, "saludos", []string{"v1.0.0"}, []map[string]string{
+		{"saludos.zylo": "func hola(): string {\n    return \"hola\"\n}\n"},
+	})
+	project := t.TempDir()
+
+	locked, err := packages.Add(project, repo+"@v1.0.0")
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if len(locked.Commit) != 40 || !strings.HasPrefix(locked.Checksum, "sha256:") {
+		t.Errorf("versión instalada inesperada: %+v", locked)
+	}
+
+	lock, err := packages.ReadLock(filepath.Join(project, packages.LockFile))
+	if err != nil {
+		t.Fatalf("error leyendo %s: %v", packages.LockFile, err)
+	}
+	if len(lock) != 1 || lock[0] != locked {
+		t.Errorf("%s inesperado: %+v, se esperaba %+v", packages.LockFile, lock, locked)
+	}
+
+	checksum, err := packages.Checksum(filepath.Join(project, packages.ModulesDir, "saludos"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if checksum != locked.Checksum {
+		t.Errorf("el checksum del paquete instalado %s no coincide con %s", checksum, locked.Checksum)
+	}
+}
+
+func TestSyncInstallsLockedCommit(t *testing.T) {
+	repo := gitRepo(t, "saludos", []string{"v1"}, []map[string]string{
+		{"saludos.zylo": "func hola(): string {\n    return \"hola\"\n}\n"},
+	})
+	project := t.TempDir()
+	// Sin ref: la dependencia sigue la rama por defecto
+	locked, err := packages.Add(project, repo)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	// Un commit nuevo en el repositorio no cambia lo que instala --sync
+	source := filepath.Join(repo, "saludos.zylo")
+	if err := os.WriteFile(source, []byte("func hola(): string {\n    return \"adiós\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command("git", "-c", "user.name=zylo", "-c", "user.email=zylo@example.com", "commit", "--quiet", "-am", "v2")
+	cmd.Dir = repo
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git commit: %v\n%s", err, out)
+	}
+	if err := os.RemoveAll(filepath.Join(project, packages.ModulesDir)); err != nil {
+		t.Fatal(err)
+	}
+
+	synced, err := packages.Sync(project)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if len(synced) != 1 || synced[0] != locked {
+		t.Errorf("versiones sincronizadas inesperadas: %+v, se esperaba %+v", synced, locked)
+	}
+	data, err := os.ReadFile(filepath.Join(project, packages.ModulesDir, "saludos", "saludos.zylo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "\"hola\"") {
+		t.Errorf("se esperaba el contenido del commit anotado, obtenido:\n%s", data)
+	}
+}
+
+func TestSyncDetectsTamperedChecksum(t *testing.T) {
+	repo := gitRepo(t, "saludos", []string{"v1"}, []map[string]string{
+		{"saludos.zylo": "func hola(): string {\n    return \"hola\"\n}\n"},
+	})
+	project := t.TempDir()
+	locked, err := packages.Add(project, repo+"@v1")
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	lockPath := filepath.Join(project, packages.LockFile)
+	tampered := locked
+	tampered.Checksum = "sha256:" + strings.Repeat("0", 64)
+	if err := packages.WriteLock(lockPath, []packages.Locked{tampered}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = packages.Sync(project)
+	if err == nil || !strings.Contains(err.Error(), "el checksum de saludos no coincide") {
+		t.Fatalf("se esperaba un error de checksum, obtenido %v", err)
+	}
+	// El lockfile no se reescribe si la verificación falla
+	lock, err := packages.ReadLock(lockPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(lock) != 1 || lock[0] != tampered {
+		t.Errorf("el lockfile no debe cambiar tras un error: %+v", lock)
+	}
+}
+
+func TestSyncRejectsInvalidCommit(t *testing.T) {
+	repo := gitRepo(t, "saludos", []string{"v1"}, []map[string]string{
+		{"saludos.zylo": "func hola(): string {\n    return \"hola\"\n}\n"},
+	})
+	project := t.TempDir()
+	locked, err := packages.Add(project, repo+"@v1")
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+
+	// Un commit que empieza por - no debe llegar a git como una opción
+	tampered := locked
+	tampered.Commit = "--orphan=x"
+	if err := packages.WriteLock(filepath.Join(project, packages.LockFile), []packages.Locked{tampered}); err != nil {
+		t.Fatal(err)
+	}
+	_, err = packages.Sync(project)
+	if err == nil || !strings.Contains(err.Error(), "commit inválido para saludos") {
+		t.Fatalf("se esperaba un error de commit inválido, obtenido %v", err)
+	}
+}
+
+func TestLockRoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), packages.LockFile)
+	lock := []packages.Locked{
+		{Dependency: packages.Dependency{Name: "zeta", Git: "https://example.com/zeta.git"}, Commit: "b", Checksum: "sha256:2"},
+		{Dependency: packages.Dependency{Name: "alfa", Git: "https://example.com/alfa.git", Ref: "v1"}, Commit: "a", Checksum: "sha256:1"},
+	}
+	if err := packages.WriteLock(path, lock); err != nil {
+		t.Fatal(err)
+	}
+	read, err := packages.ReadLock(path)
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if len(read) != 2 || read[0] != lock[1] || read[1] != lock[0] {
+		t.Errorf("entradas inesperadas (se esperaban ordenadas por nombre): %+v", read)
+	}
+
+	if err := os.WriteFile(path, []byte("name = \"suelto\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := packages.ReadLock(path); err == nil {
+		t.Errorf("se esperaba un error para una entrada fuera de [[package]]")
+	}
+}
@@ -0,0 +1,395 @@
+// Package packages instala paquetes Zylo de terceros. zylo add descarga un
+// paquete (un repositorio git) en el directorio zylo_modules/ del proyecto, lo
+// registra en la sección [dependencies] de zylo.toml y anota en zylo.lock el
+// commit exacto y el checksum de su contenido, para que zylo deps --sync
+// reproduzca las mismas dependencias. Después, import "<paquete>" carga
+// zylo_modules/<paquete>/<paquete>.zylo (o main.zylo) e import
+// "<paquete>/<módulo>" carga zylo_modules/<paquete>/<módulo>.zylo.
+package packages
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// ModulesDir es el directorio del proyecto donde se instalan los paquetes
+	ModulesDir = "zylo_modules"
+	// ManifestFile es el archivo de configuración del proyecto
+	ManifestFile = "zylo.toml"
+	// DefaultRegistry es el prefijo con el que se resuelven los paquetes por
+	// nombre; se puede cambiar con la variable de entorno ZYLO_REGISTRY
+	DefaultRegistry = "https://github.com/zylo-packages/"
+)
+
+// Dependency es un paquete del que depende el proyecto
+type Dependency struct {
+	Name string // Nombre con el que se importa
+	Git  string // URL (o ruta) del repositorio
+	Ref  string // Tag, rama o commit; vacío = rama por defecto
+}
+
+// validName restringe los nombres de paquete a identificadores que se pueden
+// usar como nombre de módulo
+var validName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// validRef y validCommit restringen las versiones y los commits que llegan a
+// git desde zylo.toml y zylo.lock; en particular, no pueden empezar por - y
+// confundirse con una opción
+var (
+	validRef    = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9_.+/-]*$`)
+	validCommit = regexp.MustCompile(`^[0-9a-f]{7,64}$`)
+)
+
+// ParseSpec interpreta el argumento de zylo add: una URL git o un nombre del
+// registro, opcionalmente seguido de @ref (por ejemplo, utils@v1.2.0)
+func ParseSpec(spec string) (Dependency, error) {
+	source, ref := spec, ""
+	// La @ de la ref va después de la última /, así no se confunde con la de
+	// git@host:usuario/repo
+	if at := strings.LastIndex(spec, "@"); at > strings.LastIndex(spec, "/") && at > 0 {
+		source, ref = spec[:at], spec[at+1:]
+		if ref == "" {
+			return Dependency{}, fmt.Errorf("versión vacía en %q", spec)
+		}
+	}
+
+	dep := Dependency{Ref: ref}
+	if isRepository(source) {
+		dep.Git = source
+		dep.Name = strings.TrimSuffix(filepath.Base(strings.TrimRight(source, "/")), ".git")
+		if i := strings.LastIndex(dep.Name, ":"); i >= 0 {
+			dep.Name = dep.Name[i+1:]
+		}
+	} else {
+		registry := os.Getenv("ZYLO_REGISTRY")
+		if registry == "" {
+			registry = DefaultRegistry
+		}
+		if !strings.HasSuffix(registry, "/") {
+			registry += "/"
+		}
+		dep.Name = source
+		dep.Git = registry + source + ".git"
+	}
+
+	if err := checkDependency(dep, nil); err != nil {
+		return Dependency{}, err
+	}
+	return dep, nil
+}
+
+// checkDependency comprueba el nombre, la versión y, si pin no es nil, el
+// commit anotado de dep antes de pasarlos a git
+func checkDependency(dep Dependency, pin *Locked) error {
+	if !validName.MatchString(dep.Name) {
+		return fmt.Errorf("nombre de paquete inválido: %q", dep.Name)
+	}
+	if dep.Ref != "" && (!validRef.MatchString(dep.Ref) || strings.Contains(dep.Ref, "..")) {
+		return fmt.Errorf("versión inválida para %s: %q", dep.Name, dep.Ref)
+	}
+	if pin != nil && !validCommit.MatchString(pin.Commit) {
+		return fmt.Errorf("commit inválido para %s en %s: %q", dep.Name, LockFile, pin.Commit)
+	}
+	return nil
+}
+
+// isRepository indica si source es una URL o ruta de un repositorio en lugar
+// de un nombre del registro
+func isRepository(source string) bool {
+	return strings.Contains(source, "://") ||
+		strings.HasPrefix(source, "git@") ||
+		strings.HasSuffix(source, ".git") ||
+		filepath.IsAbs(source) ||
+		strings.HasPrefix(source, ".")
+}
+
+// Add instala el paquete spec en el proyecto de projectDir, lo registra en
+// su zylo.toml y anota la versión instalada en zylo.lock
+func Add(projectDir, spec string) (Locked, error) {
+	dep, err := ParseSpec(spec)
+	if err != nil {
+		return Locked{}, err
+	}
+	locked, err := Install(projectDir, dep)
+	if err != nil {
+		return Locked{}, err
+	}
+	if err := SaveDependency(filepath.Join(projectDir, ManifestFile), dep); err != nil {
+		return Locked{}, fmt.Errorf("error actualizando %s: %v", ManifestFile, err)
+	}
+
+	lockPath := filepath.Join(projectDir, LockFile)
+	lock, err := ReadLock(lockPath)
+	if err != nil {
+		return Locked{}, err
+	}
+	if err := WriteLock(lockPath, upsertLocked(lock, locked)); err != nil {
+		return Locked{}, fmt.Errorf("error actualizando %s: %v", LockFile, err)
+	}
+	return locked, nil
+}
+
+// Sync instala las dependencias de zylo.toml. Las que están en zylo.lock con
+// la misma fuente y versión se instalan en el commit anotado y se verifica su
+// checksum; las demás se resuelven de nuevo y se anotan. Las entradas de
+// paquetes que ya no están en zylo.toml se quitan del lockfile.
+func Sync(projectDir string) ([]Locked, error) {
+	deps, err := ReadDependencies(filepath.Join(projectDir, ManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	lockPath := filepath.Join(projectDir, LockFile)
+	lock, err := ReadLock(lockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	var synced []Locked
+	for _, dep := range deps {
+		var pin *Locked
+		for i := range lock {
+			if lock[i].Name == dep.Name && lock[i].Git == dep.Git && lock[i].Ref == dep.Ref {
+				pin = &lock[i]
+			}
+		}
+		locked, err := install(projectDir, dep, pin)
+		if err != nil {
+			return nil, err
+		}
+		synced = append(synced, locked)
+	}
+
+	if err := WriteLock(lockPath, synced); err != nil {
+		return nil, fmt.Errorf("error actualizando %s: %v", LockFile, err)
+	}
+	return synced, nil
+}
+
+// Install descarga dep en zylo_modules/, reemplazando una versión anterior, y
+// devuelve el commit y el checksum instalados
+func Install(projectDir string, dep Dependency) (Locked, error) {
+	return install(projectDir, dep, nil)
+}
+
+// install descarga dep; si pin no es nil, en su commit y comprobando que el
+// contenido tenga su checksum
+func install(projectDir string, dep Dependency, pin *Locked) (Locked, error) {
+	if err := checkDependency(dep, pin); err != nil {
+		return Locked{}, err
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return Locked{}, fmt.Errorf("se necesita git para instalar paquetes")
+	}
+
+	target := filepath.Join(projectDir, ModulesDir, dep.Name)
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return Locked{}, err
+	}
+	// Se clona en un directorio temporal para no perder la versión instalada
+	// si la descarga falla
+	tmp, err := os.MkdirTemp(filepath.Dir(target), "."+dep.Name+"-")
+	if err != nil {
+		return Locked{}, err
+	}
+	defer os.RemoveAll(tmp)
+
+	if _, err := git("", "clone", "--quiet", "--", dep.Git, tmp); err != nil {
+		return Locked{}, fmt.Errorf("no se pudo descargar %s: %v", dep.Git, err)
+	}
+	switch {
+	case pin != nil:
+		if _, err := git(tmp, "checkout", "--quiet", pin.Commit, "--"); err != nil {
+			return Locked{}, fmt.Errorf("commit %s de %s no encontrado: %v", pin.Commit, dep.Name, err)
+		}
+	case dep.Ref != "":
+		if _, err := git(tmp, "checkout", "--quiet", dep.Ref, "--"); err != nil {
+			return Locked{}, fmt.Errorf("versión %s no encontrada en %s: %v", dep.Ref, dep.Git, err)
+		}
+	}
+	if entry(tmp, dep.Name) == "" {
+		return Locked{}, fmt.Errorf("%s no es un paquete Zylo: falta %s.zylo o main.zylo", dep.Git, dep.Name)
+	}
+
+	commit, err := git(tmp, "rev-parse", "HEAD")
+	if err != nil {
+		return Locked{}, err
+	}
+	checksum, err := Checksum(tmp)
+	if err != nil {
+		return Locked{}, err
+	}
+	if pin != nil && pin.Checksum != checksum {
+		return Locked{}, fmt.Errorf("el checksum de %s no coincide con %s: esperado %s, obtenido %s", dep.Name, LockFile, pin.Checksum, checksum)
+	}
+
+	if err := os.RemoveAll(target); err != nil {
+		return Locked{}, err
+	}
+	if err := os.Rename(tmp, target); err != nil {
+		return Locked{}, err
+	}
+	return Locked{Dependency: dep, Commit: commit, Checksum: checksum}, nil
+}
+
+// git ejecuta un comando git en dir y devuelve su salida; si falla, la salida
+// de error es el mensaje del error
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return "", fmt.Errorf("%s", msg)
+		}
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// entry devuelve el archivo principal del paquete instalado en dir
+func entry(dir, name string) string {
+	for _, file := range []string{name + ".zylo", "main.zylo"} {
+		path := filepath.Join(dir, file)
+		if _, err := os.Stat(path); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// Resolve busca el archivo de un import de paquete ("<paquete>" o
+// "<paquete>/<módulo>") en el directorio zylo_modules/ más cercano a baseDir,
+// subiendo por los directorios padre. El módulo no puede salir del directorio
+// del paquete con ..
+func Resolve(baseDir, modulePath string) (string, bool) {
+	name, sub, _ := strings.Cut(strings.TrimSuffix(modulePath, ".zylo"), "/")
+	if !validName.MatchString(name) || slices.Contains(strings.Split(sub, "/"), "..") {
+		return "", false
+	}
+
+	dir, err := filepath.Abs(baseDir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		pkgDir := filepath.Join(dir, ModulesDir, name)
+		if info, err := os.Stat(pkgDir); err == nil && info.IsDir() {
+			if sub == "" {
+				path := entry(pkgDir, name)
+				return path, path != ""
+			}
+			path := filepath.Join(pkgDir, sub+".zylo")
+			_, err := os.Stat(path)
+			return path, err == nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// dependencyLine reconoce una entrada de [dependencies]:
+// nombre = { git = "...", ref = "..." }
+var dependencyLine = regexp.MustCompile(`^([A-Za-z0-9_-]+)\s*=\s*\{(.*)\}\s*$`)
+
+// fieldPattern reconoce los campos clave = "valor" de una tabla en línea
+var fieldPattern = regexp.MustCompile(`(\w+)\s*=\s*"([^"]*)"`)
+
+// ReadDependencies lee las dependencias de un zylo.toml. Un archivo que no
+// existe no tiene dependencias.
+func ReadDependencies(manifest string) ([]Dependency, error) {
+	file, err := os.Open(manifest)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var deps []Dependency
+	inDeps := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "[") {
+			inDeps = line == "[dependencies]"
+			continue
+		}
+		m := dependencyLine.FindStringSubmatch(line)
+		if !inDeps || m == nil {
+			continue
+		}
+		dep := Dependency{Name: m[1]}
+		for _, field := range fieldPattern.FindAllStringSubmatch(m[2], -1) {
+			switch field[1] {
+			case "git":
+				dep.Git = field[2]
+			case "ref":
+				dep.Ref = field[2]
+			}
+		}
+		deps = append(deps, dep)
+	}
+	return deps, scanner.Err()
+}
+
+// SaveDependency agrega dep a la sección [dependencies] de un zylo.toml (o
+// reemplaza la entrada con el mismo nombre) y conserva el resto del archivo
+func SaveDependency(manifest string, dep Dependency) error {
+	deps, err := ReadDependencies(manifest)
+	if err != nil {
+		return err
+	}
+	replaced := false
+	for i := range deps {
+		if deps[i].Name == dep.Name {
+			deps[i], replaced = dep, true
+		}
+	}
+	if !replaced {
+		deps = append(deps, dep)
+	}
+	sort.Slice(deps, func(i, j int) bool { return deps[i].Name < deps[j].Name })
+
+	// Todo lo que no es la sección [dependencies] se copia tal cual
+	var lines []string
+	if data, err := os.ReadFile(manifest); err == nil {
+		inDeps := false
+		for _, line := range strings.Split(strings.TrimRight(string(data), "\n"), "\n") {
+			trimmed := strings.TrimSpace(line)
+			if strings.HasPrefix(trimmed, "[") {
+				inDeps = trimmed == "[dependencies]"
+			}
+			if !inDeps {
+				lines = append(lines, line)
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > 0 {
+		lines = append(lines, "")
+	}
+
+	lines = append(lines, "[dependencies]")
+	for _, d := range deps {
+		entry := fmt.Sprintf("%s = { git = %q", d.Name, d.Git)
+		if d.Ref != "" {
+			entry += fmt.Sprintf(", ref = %q", d.Ref)
+		}
+		lines = append(lines, entry+" }")
+	}
+	return os.WriteFile(manifest, []byte(strings.Join(lines, "\n")+"\n"), 0644)
+}
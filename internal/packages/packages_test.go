@@ -0,0 +1,222 @@
+// Paquete externo para poder importar el paquete instalado con el evaluador,
+// que a su vez usa packages
+package packages_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/evaluator"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/packages"
+	"github.com/zylo-lang/zylo/internal/parser"
+	"github.com/zylo-lang/zylo/internal/sema"
+)
+
+// gitRepo crea un repositorio git con un commit por versión de files; cada
+// commit queda con el tag de su versión
+func gitRepo(t *testing.T, name string, versions []string, files []map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git no está disponible")
+	}
+	dir := filepath.Join(t.TempDir(), name)
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=zylo", "-c", "user.email=zylo@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	run("init", "--quiet")
+	for i, version := range versions {
+		for file, source := range files[i] {
+			if err := os.WriteFile(filepath.Join(dir, file), []byte(source), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		run("add", "-A")
+		run("commit", "--quiet", "-m", version)
+		run("tag", version)
+	}
+	return dir
+}
+
+func TestParseSpec(t *testing.T) {
+	t.Setenv("ZYLO_REGISTRY", "https://registro.example.com/zylo")
+	tests := []struct {
+		spec string
+		name string
+		git  string
+		ref  string
+	}{
+		{"utils", "utils", "https://registro.example.com/zylo/utils.git", ""},
+		{"utils@v1.2.0", "utils", "https://registro.example.com/zylo/utils.git", "v1.2.0"},
+		{"https://github.com/ana/colores.git", "colores", "https://github.com/ana/colores.git", ""},
+		{"https://github.com/ana/colores@main", "colores", "https://github.com/ana/colores", "main"},
+		{"git@github.com:ana/colores.git@v2", "colores", "git@github.com:ana/colores.git", "v2"},
+		{"/srv/repos/fechas", "fechas", "/srv/repos/fechas", ""},
+	}
+
+	for _, tt := range tests {
+		dep, err := packages.ParseSpec(tt.spec)
+		if err != nil {
+			t.Errorf("%s: error inesperado: %v", tt.spec, err)
+			continue
+		}
+		if dep.Name != tt.name || dep.Git != tt.git || dep.Ref != tt.ref {
+			t.Errorf("%s: obtenido %+v, se esperaba {%s %s %s}", tt.spec, dep, tt.name, tt.git, tt.ref)
+		}
+	}
+
+	for _, spec := range []string{"utils@", "mal nombre", "https://example.com/9mal.git", "utils@--upload-pack=x", "utils@v1..v2"} {
+		if _, err := packages.ParseSpec(spec); err == nil {
+			t.Errorf("%s: se esperaba un error", spec)
+		}
+	}
+}
+
+func TestAddFetchesPackage(t *testing.T) {
+	repo := gitRepo(t, "saludos", []string{"v1.0.0", "v2.0.0"}, []map[string]string{
+		{"saludos.zylo": "func hola(nombre string): string {\n    return \"hola \" + nombre\n}\n"},
+		{"saludos.zylo": "func hola(nombre string): string {\n    return \"buenas \" + nombre\n}\n"},
+	})
+	project := t.TempDir()
+	manifest := filepath.Join(project, packages.ManifestFile)
+	if err := os.WriteFile(manifest, []byte("[package]\nname = \"demo\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	dep, err := packages.Add(project, repo+"@v1.0.0")
+	if err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	if dep.Name != "saludos" || dep.Ref != "v1.0.0" {
+		t.Errorf("dependencia inesperada: %+v", dep)
+	}
+
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "[package]\nname = \"demo\"\n\n[dependencies]\nsaludos = { git = \"" + repo + "\", ref = \"v1.0.0\" }\n"
+	if string(data) != expected {
+		t.Errorf("zylo.toml inesperado:\n%s\nse esperaba:\n%s", data, expected)
+	}
+	importAndCall(t, project, "hola zylo")
+
+	// Volver a agregar el paquete con otra versión la reemplaza
+	if _, err := packages.Add(project, repo+"@v2.0.0"); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+	deps, err := packages.ReadDependencies(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 1 || deps[0].Ref != "v2.0.0" {
+		t.Errorf("dependencias inesperadas: %+v", deps)
+	}
+	importAndCall(t, project, "buenas zylo")
+}
+
+// importAndCall importa el paquete saludos desde un archivo de src/ del
+// proyecto y comprueba el resultado de saludos.hola("zylo")
+func importAndCall(t *testing.T, project, expected string) {
+	t.Helper()
+	src := filepath.Join(project, "src")
+	if err := os.MkdirAll(src, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	source := fmt.Sprintf("import \"saludos\"\nassert_eq(saludos.hola(\"zylo\"), %q)\n", expected)
+	p := parser.New(lexer.New(source))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("errores de parsing: %v", p.Errors())
+	}
+
+	sa := sema.NewSemanticAnalyzer()
+	sa.SetBaseDir(src)
+	sa.Analyze(program)
+	if len(sa.Errors()) > 0 {
+		t.Fatalf("errores semánticos: %v", sa.Errors())
+	}
+
+	// Cada llamada usa un evaluador nuevo: los módulos se cargan una vez por
+	// evaluador
+	eval := evaluator.NewEvaluator()
+	eval.SetBaseDir(src)
+	if err := eval.EvaluateProgram(program); err != nil {
+		t.Fatalf("error inesperado: %v", err)
+	}
+}
+
+func TestAddErrors(t *testing.T) {
+	notPackage := gitRepo(t, "vacio", []string{"v1"}, []map[string]string{{"README.md": "sin código"}})
+	tests := []struct {
+		spec     string
+		expected string
+	}{
+		{notPackage, "no es un paquete Zylo"},
+		{notPackage + "@v9", "versión v9 no encontrada"},
+		{filepath.Join(t.TempDir(), "noexiste"), "no se pudo descargar"},
+	}
+
+	for _, tt := range tests {
+		project := t.TempDir()
+		_, err := packages.Add(project, tt.spec)
+		if err == nil || !strings.Contains(err.Error(), tt.expected) {
+			t.Errorf("%s: se esperaba un error con %q, obtenido %v", tt.spec, tt.expected, err)
+		}
+		if _, err := os.Stat(filepath.Join(project, packages.ManifestFile)); err == nil {
+			t.Errorf("%s: no se debe registrar un paquete que no se instaló", tt.spec)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	project := t.TempDir()
+	pkg := filepath.Join(project, packages.ModulesDir, "utils")
+	if err := os.MkdirAll(pkg, 0755); err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range []string{"main.zylo", "texto.zylo"} {
+		if err := os.WriteFile(filepath.Join(pkg, file), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	nested := filepath.Join(project, "src", "app")
+	if err := os.MkdirAll(nested, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(nested, "fuga.zylo"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		modulePath string
+		expected   string
+	}{
+		{"utils", filepath.Join(pkg, "main.zylo")},
+		{"utils/texto", filepath.Join(pkg, "texto.zylo")},
+		{"utils/texto.zylo", filepath.Join(pkg, "texto.zylo")},
+		{"utils/otro", ""},
+		{"otro", ""},
+		// Un import no puede salir del directorio del paquete
+		{"utils/../../src/app/fuga", ""},
+		{"utils/../utils/texto", ""},
+	}
+	for _, tt := range tests {
+		path, ok := packages.Resolve(nested, tt.modulePath)
+		if ok != (tt.expected != "") || (ok && path != tt.expected) {
+			t.Errorf("%s: obtenido %q (%v), se esperaba %q", tt.modulePath, path, ok, tt.expected)
+		}
+	}
+}
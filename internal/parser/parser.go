@@ -11,9 +11,12 @@ type Parser struct {
 	l              *lexer.Lexer
 	curToken       lexer.Token
 	peekToken      lexer.Token
+	ahead          []lexer.Token // Tokens after peekToken already read by peekAhead
 	errors         []string
+	errorTokens    map[int]lexer.Token // Lexer ERROR token behind errors[i]
 	prefixParseFns map[lexer.TokenType]prefixParseFn
 	infixParseFns  map[lexer.TokenType]infixParseFn
+	structs        map[string]bool // Structs declared so far, so Name{} is a struct literal
 }
 
 type (
@@ -25,9 +28,15 @@ const (
 	_ int = iota
 	LOWEST
 	ASSIGN
+	PIPE_PREC
 	ANDOR
+	NULLISH // ??
 	EQUALS
 	LESSGREATER
+	BIT_OR_PREC  // |
+	BIT_XOR_PREC // ^
+	BIT_AND_PREC // &
+	SHIFT        // << >>
 	SUM
 	PRODUCT
 	POWER_PREC
@@ -40,8 +49,10 @@ func New(l *lexer.Lexer) *Parser {
 	p := &Parser{
 		l:              l,
 		errors:         []string{},
+		errorTokens:    make(map[int]lexer.Token),
 		prefixParseFns: make(map[lexer.TokenType]prefixParseFn),
 		infixParseFns:  make(map[lexer.TokenType]infixParseFn),
+		structs:        make(map[string]bool),
 	}
 
 	p.nextToken()
@@ -49,6 +60,7 @@ func New(l *lexer.Lexer) *Parser {
 
 	// Prefix parsers
 	p.registerPrefix(lexer.IDENTIFIER, p.parseIdentifier)
+	p.registerPrefix(lexer.REPEAT, p.parseIdentifier) // repeat("ab", 3) de std/string
 	p.registerPrefix(lexer.NUMBER, p.parseNumberLiteral)
 	p.registerPrefix(lexer.STRING, p.parseStringLiteral)
 	p.registerPrefix(lexer.TEMPLATE_STRING, p.parseTemplateStringLiteral)
@@ -90,11 +102,12 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerPrefix(lexer.ELIF, p.parseUnexpectedPrefix)
 	p.registerPrefix(lexer.ELSE, p.parseUnexpectedPrefix)
 
-	// Handle type tokens in expression context (should not happen)
-	p.registerPrefix(lexer.INT_TYPE, p.parseUnexpectedPrefix)
-	p.registerPrefix(lexer.STRING_TYPE, p.parseUnexpectedPrefix)
-	p.registerPrefix(lexer.FLOAT_TYPE, p.parseUnexpectedPrefix)
-	p.registerPrefix(lexer.BOOL_TYPE, p.parseUnexpectedPrefix)
+	// En una expresión, los nombres de tipo son identificadores: las
+	// conversiones string(x), int(x) y el módulo string de import "std/string"
+	p.registerPrefix(lexer.INT_TYPE, p.parseTypeNameIdentifier)
+	p.registerPrefix(lexer.STRING_TYPE, p.parseTypeNameIdentifier)
+	p.registerPrefix(lexer.FLOAT_TYPE, p.parseTypeNameIdentifier)
+	p.registerPrefix(lexer.BOOL_TYPE, p.parseTypeNameIdentifier)
 	p.registerPrefix(lexer.WALRUS_ASSIGN, p.parseWalrusAssignInExpression)
 
 	// Infix parsers - operadores de comparación y matemáticos
@@ -104,7 +117,12 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(lexer.STAR, p.parseInfixExpression)
 	p.registerInfix(lexer.PERCENT, p.parseInfixExpression)
 	p.registerInfix(lexer.POWER, p.parseInfixExpression)
-	p.registerInfix(lexer.FLOOR_DIVIDE, p.parseInfixExpression)
+	p.registerInfix(lexer.BIT_AND, p.parseInfixExpression)
+	p.registerInfix(lexer.BIT_OR, p.parseInfixExpression)
+	p.registerInfix(lexer.BIT_XOR, p.parseInfixExpression)
+	p.registerInfix(lexer.SHIFT_LEFT, p.parseInfixExpression)
+	p.registerInfix(lexer.SHIFT_RIGHT, p.parseInfixExpression)
+	p.registerInfix(lexer.XOR, p.parseInfixExpression)
 	p.registerInfix(lexer.EQUAL_EQUAL, p.parseInfixExpression)
 	p.registerInfix(lexer.BANG_EQUAL, p.parseInfixExpression)
 	p.registerInfix(lexer.LESS, p.parseInfixExpression)
@@ -113,6 +131,7 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(lexer.GREATER_EQUAL, p.parseInfixExpression)
 	p.registerInfix(lexer.AND, p.parseInfixExpression)
 	p.registerInfix(lexer.OR, p.parseInfixExpression)
+	p.registerInfix(lexer.NULL_COALESCE, p.parseInfixExpression)
 	p.registerInfix(lexer.EQUAL, p.parseAssignmentExpression)
 	p.registerInfix(lexer.PLUS_EQUAL, p.parseAssignmentExpression)
 	p.registerInfix(lexer.MINUS_EQUAL, p.parseAssignmentExpression)
@@ -120,12 +139,16 @@ func New(l *lexer.Lexer) *Parser {
 	p.registerInfix(lexer.SLASH_EQUAL, p.parseAssignmentExpression)
 	p.registerInfix(lexer.PERCENT_EQUAL, p.parseAssignmentExpression)
 	p.registerInfix(lexer.DOT, p.parseDotExpression)
+	p.registerInfix(lexer.QUESTION_DOT, p.parseOptionalDotExpression)
 	p.registerInfix(lexer.LEFT_PAREN, p.parseCallExpression)
 	p.registerInfix(lexer.LEFT_BRACKET, p.parseIndexExpression)
 	p.registerInfix(lexer.RANGE, p.parseRangeExpression)
 	p.registerInfix(lexer.IN, p.parseInExpression)
+	p.registerInfix(lexer.NOT, p.parseNotInExpression)
 	p.registerInfix(lexer.ARROW_RETURN, p.parseArrowFunctionExpressionInfix)
 	p.registerInfix(lexer.AS, p.parseAsExpression)
+	p.registerInfix(lexer.IS, p.parseIsExpression)
+	p.registerInfix(lexer.PIPE, p.parsePipeExpression)
 
 	// Comentarios explicativos
 	// The prefix parsers for comparison operators are not needed since they work as infix operators
@@ -139,12 +162,34 @@ func New(l *lexer.Lexer) *Parser {
 
 func (p *Parser) nextToken() {
 	p.curToken = p.peekToken
+	if len(p.ahead) > 0 {
+		p.peekToken = p.ahead[0]
+		p.ahead = p.ahead[1:]
+		return
+	}
 	p.peekToken = p.l.NextToken()
 }
 
+// peekAhead returns the n-th token after peekToken (n >= 1) without
+// consuming anything.
+func (p *Parser) peekAhead(n int) lexer.Token {
+	for len(p.ahead) < n {
+		p.ahead = append(p.ahead, p.l.NextToken())
+	}
+	return p.ahead[n-1]
+}
+
 func (p *Parser) Errors() []string    { return p.errors }
 func (p *Parser) addError(msg string) { p.errors = append(p.errors, msg) }
 
+// ErrorToken returns the lexer error token behind Errors()[i], if that error
+// came from the lexer. Its Lexeme is the message and its start position is
+// where the problem begins (e.g., the opening quote of an unterminated string).
+func (p *Parser) ErrorToken(i int) (lexer.Token, bool) {
+	tok, ok := p.errorTokens[i]
+	return tok, ok
+}
+
 func (p *Parser) registerPrefix(tt lexer.TokenType, fn prefixParseFn) { p.prefixParseFns[tt] = fn }
 func (p *Parser) registerInfix(tt lexer.TokenType, fn infixParseFn)   { p.infixParseFns[tt] = fn }
 
@@ -170,7 +215,37 @@ func (p *Parser) ParseProgram() *ast.Program {
 
 func (p *Parser) parseStatement() ast.Statement {
 	p.skipNewlines()
+	doc := p.curToken.Doc
+
+	stmt := p.parseStatementKind()
+	// El comentario de un export documenta la declaración exportada
+	target := stmt
+	if export, ok := stmt.(*ast.ExportStatement); ok {
+		target = export.Declaration
+	}
+	switch node := target.(type) {
+	case *ast.FuncStatement:
+		if node.Doc == "" {
+			node.Doc = doc
+		}
+	case *ast.ClassStatement:
+		if node.Doc == "" {
+			node.Doc = doc
+		}
+	case *ast.EnumStatement:
+		if node.Doc == "" {
+			node.Doc = doc
+		}
+	case *ast.StructStatement:
+		if node.Doc == "" {
+			node.Doc = doc
+		}
+	}
+	return stmt
+}
 
+// parseStatementKind elige el parser según el token actual.
+func (p *Parser) parseStatementKind() ast.Statement {
 	switch p.curToken.Type {
 	case lexer.SEMICOLON, lexer.NEWLINE:
 		p.nextToken()
@@ -206,12 +281,22 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseIfStatement()
 	case lexer.WHILE:
 		return p.parseWhileStatement()
+	case lexer.REPEAT:
+		// repeat(...) sigue siendo una llamada a una función llamada repeat
+		if p.peekTokenIs(lexer.LEFT_PAREN) {
+			return p.parseExpressionStatement()
+		}
+		return p.parseRepeatStatement()
 	case lexer.FOR:
 		return p.parseForStatement()
 	case lexer.RETURN:
 		return p.parseReturnStatement()
 	case lexer.CLASS:
 		return p.parseClassStatement()
+	case lexer.ENUM:
+		return p.parseEnumStatement()
+	case lexer.STRUCT:
+		return p.parseStructStatement()
 	case lexer.TRY:
 		return p.parseTryStatement()
 	case lexer.THROW:
@@ -230,6 +315,8 @@ func (p *Parser) parseStatement() ast.Statement {
 		return p.parseMatchStatement()
 	case lexer.SPAWN:
 		return p.parseSpawnStatement()
+	case lexer.AT:
+		return p.parseDecoratedStatement()
 	default:
 		return p.parseExpressionStatement()
 	}
@@ -238,12 +325,13 @@ func (p *Parser) parseStatement() ast.Statement {
 // Statements
 
 // parseVarStatement parses a variable declaration (e.g., var x = 10; or public x = 10;).
+// const X = 10 declares a constant, which must have a value.
 func (p *Parser) parseVarStatement() ast.Statement {
 	token := p.curToken
 	var visibility string
 
-	// Consume 'var' keyword if present
-	if p.curTokenIs(lexer.VAR) {
+	// Consume 'var' or 'const' keyword if present
+	if p.curTokenIs(lexer.VAR) || p.curTokenIs(lexer.CONST) {
 		p.nextToken()
 	}
 
@@ -256,7 +344,7 @@ func (p *Parser) parseVarStatement() ast.Statement {
 		p.nextToken()
 	}
 
-	stmt := &ast.VarStatement{Token: token, Visibility: visibility}
+	stmt := &ast.VarStatement{Token: token, Visibility: visibility, IsConstant: token.Type == lexer.CONST}
 
 	// At this point, curToken should be the variable name (IDENTIFIER)
 	if !p.curTokenIs(lexer.IDENTIFIER) {
@@ -284,6 +372,9 @@ func (p *Parser) parseVarStatement() ast.Statement {
 		p.nextToken() // Consume WALRUS_ASSIGN
 		p.nextToken() // Advance to expression
 		stmt.Value = p.parseExpression(LOWEST)
+	} else if stmt.IsConstant {
+		p.addError(fmt.Sprintf("expected '=' after const %s", stmt.Name.Value))
+		return nil
 	}
 
 	return stmt
@@ -393,6 +484,17 @@ func (p *Parser) parseDeclaration() ast.Statement {
 	} else if p.curTokenIs(lexer.CLASS) {
 		// It's a class
 		return p.parseClassWithModifier(modifier)
+	} else if p.curTokenIs(lexer.ENUM) || p.curTokenIs(lexer.STRUCT) {
+		var visibility string
+		if modifier.Type == lexer.PUBLIC {
+			visibility = "public"
+		} else if modifier.Type == lexer.PRIVATE {
+			visibility = "private"
+		}
+		if p.curTokenIs(lexer.STRUCT) {
+			return p.parseStructWithModifier(modifier, visibility)
+		}
+		return p.parseEnumWithModifier(modifier, visibility)
 	}
 
 	// Invalid
@@ -430,8 +532,9 @@ func (p *Parser) parseFunctionStatementWithAsync(isAsync bool) ast.Statement {
 		p.nextToken() // consume 'func'
 	}
 
-	// At this point, curToken MUST be the function name (IDENTIFIER)
-	if !p.curTokenIs(lexer.IDENTIFIER) {
+	// At this point, curToken MUST be the function name (IDENTIFIER).
+	// repeat is a keyword but std/string declares a function with that name.
+	if !p.curTokenIs(lexer.IDENTIFIER) && !p.curTokenIs(lexer.REPEAT) {
 		p.addError(fmt.Sprintf("expected function name, got %s", p.curToken.Type))
 		return nil
 	}
@@ -559,7 +662,7 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	}
 
 	p.nextToken() // Advance to first parameter identifier
-	ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+	ident := p.parseParameterName()
 
 	// Check for type after identifier (new syntax: name type)
 	if p.peekTokenIs(lexer.IDENTIFIER) || p.peekTokenIs(lexer.ANY_TYPE) || p.peekTokenIs(lexer.INT_TYPE) || p.peekTokenIs(lexer.STRING_TYPE) || p.peekTokenIs(lexer.FLOAT_TYPE) || p.peekTokenIs(lexer.BOOL_TYPE) {
@@ -577,13 +680,17 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	} else {
 		ident.TypeAnnotation = "ANY"
 	}
+	p.parseParameterDefault(ident)
 
 	identifiers = append(identifiers, ident)
 
 	for p.peekTokenIs(lexer.COMMA) {
 		p.nextToken() // Consume COMMA
+		if previous := identifiers[len(identifiers)-1]; previous.Variadic {
+			p.addError(fmt.Sprintf("el parámetro variádico ...%s debe ser el último", previous.Value))
+		}
 		p.nextToken() // Advance to next parameter identifier
-		ident := &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+		ident := p.parseParameterName()
 
 		// Check for type after identifier (new syntax: name type)
 		if p.peekTokenIs(lexer.IDENTIFIER) || p.peekTokenIs(lexer.ANY_TYPE) || p.peekTokenIs(lexer.INT_TYPE) || p.peekTokenIs(lexer.STRING_TYPE) || p.peekTokenIs(lexer.FLOAT_TYPE) || p.peekTokenIs(lexer.BOOL_TYPE) {
@@ -601,7 +708,11 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 		} else {
 			ident.TypeAnnotation = "ANY"
 		}
+		p.parseParameterDefault(ident)
 
+		if ident.Default == nil && !ident.Variadic && identifiers[len(identifiers)-1].Default != nil {
+			p.addError(fmt.Sprintf("el parámetro %s necesita un valor por defecto porque lo tiene un parámetro anterior", ident.Value))
+		}
 		identifiers = append(identifiers, ident)
 	}
 
@@ -612,6 +723,27 @@ func (p *Parser) parseFunctionParameters() []*ast.Identifier {
 	return identifiers
 }
 
+// parseParameterName parsea el nombre de un parámetro, precedido de ... si es
+// variádico
+func (p *Parser) parseParameterName() *ast.Identifier {
+	variadic := p.curTokenIs(lexer.ELLIPSIS)
+	if variadic {
+		p.nextToken() // Consume ELLIPSIS
+	}
+	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme, Variadic: variadic}
+}
+
+// parseParameterDefault parsea el valor por defecto opcional de un parámetro
+// (name = expr o name type = expr)
+func (p *Parser) parseParameterDefault(ident *ast.Identifier) {
+	if !p.peekTokenIs(lexer.EQUAL) {
+		return
+	}
+	p.nextToken() // Consume EQUAL
+	p.nextToken() // Advance to the default expression
+	ident.Default = p.parseExpression(LOWEST)
+}
+
 // isTypeToken checks if a token is a valid type token.
 func (p *Parser) isTypeToken(token lexer.Token) bool {
 	switch token.Type {
@@ -675,11 +807,65 @@ func (p *Parser) parseReturnStatement() ast.Statement {
 func (p *Parser) parseExpressionStatement() ast.Statement {
 	stmt := &ast.ExpressionStatement{Token: p.curToken}
 	stmt.Expression = p.parseExpression(LOWEST)
+	if p.peekTokenIs(lexer.COMMA) && isAssignable(stmt.Expression) {
+		stmt.Expression = p.parseDestructuringAssignment(stmt.Expression)
+	}
 	p.skipNewlines()
 	return stmt
 }
 
-// parseIfStatement parses an if-else if-else statement.
+// parseDestructuringAssignment parses a, b = value, with the first target
+// already parsed. Several values on the right (a, b = b, a) are collected
+// into a list literal, so every value is evaluated before any assignment.
+func (p *Parser) parseDestructuringAssignment(first ast.Expression) ast.Expression {
+	targets := []ast.Expression{first}
+	for p.peekTokenIs(lexer.COMMA) {
+		p.nextToken() // Consume ','
+		p.nextToken()
+		target := p.parseExpression(ASSIGN)
+		if !isAssignable(target) {
+			p.addError(fmt.Sprintf("left side of assignment must be assignable, got %T", target))
+			return nil
+		}
+		targets = append(targets, target)
+	}
+	if !p.expectPeek(lexer.EQUAL) {
+		return nil
+	}
+
+	expr := &ast.DestructuringAssignmentExpression{
+		Token:    p.curToken,
+		Targets:  targets,
+		Operator: p.curToken.Lexeme,
+	}
+	p.nextToken() // Consume '='
+	value := p.parseExpression(LOWEST)
+	if !p.peekTokenIs(lexer.COMMA) {
+		expr.Value = value
+		return expr
+	}
+
+	list := &ast.ListLiteral{Token: expr.Token, Elements: []ast.Expression{value}}
+	for p.peekTokenIs(lexer.COMMA) {
+		p.nextToken() // Consume ','
+		p.nextToken()
+		list.Elements = append(list.Elements, p.parseExpression(LOWEST))
+	}
+	expr.Value = list
+	return expr
+}
+
+// isAssignable reports whether exp can appear on the left of an assignment.
+func isAssignable(exp ast.Expression) bool {
+	switch exp.(type) {
+	case *ast.Identifier, *ast.IndexExpression, *ast.DotExpression:
+		return true
+	}
+	return false
+}
+
+// parseIfStatement parses an if-else if-else statement. Each elif or else if
+// becomes an entry in ElseIfs, so long chains stay flat.
 func (p *Parser) parseIfStatement() ast.Statement {
 	stmt := &ast.IfStatement{Token: p.curToken}
 	p.nextToken() // Consume IF
@@ -692,31 +878,48 @@ func (p *Parser) parseIfStatement() ast.Statement {
 
 	stmt.Consequence = p.parseBlockStatement()
 
-	if p.peekTokenIs(lexer.ELIF) {
-		p.nextToken() // Consume ELIF
-		elseIfStmt := p.parseIfStatement()
-		stmt.Alternative = &ast.BlockStatement{
-			Statements: []ast.Statement{elseIfStmt},
-		}
-	} else if p.peekTokenIs(lexer.ELSE) {
-		p.nextToken() // Consume ELSE
-		p.skipNewlines()
+	for {
+		if p.peekTokenIs(lexer.ELIF) {
+			p.nextToken() // Consume ELIF
+		} else if p.peekTokenIs(lexer.ELSE) {
+			p.nextToken() // Consume ELSE
+			p.skipNewlines()
 
-		if p.peekTokenIs(lexer.IF) || p.peekTokenIs(lexer.ELIF) {
-			p.nextToken() // Consume IF or ELIF for else if
-			elseIfStmt := p.parseIfStatement()
-			stmt.Alternative = &ast.BlockStatement{
-				Statements: []ast.Statement{elseIfStmt},
+			if !p.peekTokenIs(lexer.IF) && !p.peekTokenIs(lexer.ELIF) {
+				if !p.expectPeek(lexer.LEFT_BRACE) {
+					p.addError("expected 'if', 'elif' or '{' after 'else'")
+					return nil
+				}
+				stmt.Alternative = p.parseBlockStatement()
+				return stmt
 			}
-		} else if p.expectPeek(lexer.LEFT_BRACE) {
-			stmt.Alternative = p.parseBlockStatement()
+			p.nextToken() // Consume IF or ELIF for else if
 		} else {
-			p.addError("expected 'if', 'elif' or '{' after 'else'")
+			return stmt
+		}
+
+		clause := p.parseElseIfClause()
+		if clause == nil {
 			return nil
 		}
+		stmt.ElseIfs = append(stmt.ElseIfs, clause)
 	}
+}
 
-	return stmt
+// parseElseIfClause parses the condition and block of an elif or else if
+// branch; the current token is ELIF or IF.
+func (p *Parser) parseElseIfClause() *ast.ElseIfClause {
+	clause := &ast.ElseIfClause{Token: p.curToken}
+	p.nextToken() // Consume ELIF or IF
+	clause.Condition = p.parseExpression(LOWEST)
+
+	p.skipNewlines()
+	if !p.expectPeek(lexer.LEFT_BRACE) {
+		return nil
+	}
+
+	clause.Consequence = p.parseBlockStatement()
+	return clause
 }
 
 // parseWhileStatement parses a while loop.
@@ -734,6 +937,21 @@ func (p *Parser) parseWhileStatement() ast.Statement {
 	return stmt
 }
 
+// parseRepeatStatement parses a repeat loop (e.g., repeat 3 { ... }).
+func (p *Parser) parseRepeatStatement() ast.Statement {
+	stmt := &ast.RepeatStatement{Token: p.curToken}
+	p.nextToken() // Consume REPEAT
+	stmt.Count = p.parseExpression(LOWEST)
+
+	p.skipNewlines()
+	if !p.expectPeek(lexer.LEFT_BRACE) {
+		return nil
+	}
+
+	stmt.Body = p.parseBlockStatement()
+	return stmt
+}
+
 // parseForStatement parses a for loop, including for-in and traditional for loops.
 func (p *Parser) parseForStatement() ast.Statement {
 	token := p.curToken
@@ -757,17 +975,17 @@ func (p *Parser) parseForStatement() ast.Statement {
 	}
 
 	// Traditional for loop: for [init]; [condition]; [post] { body }
+	// Each part may be empty, so a part is only parsed when the current token
+	// is not already the delimiter that ends it.
 	stmt := &ast.ForStatement{Token: token}
 
 	// Parse init statement (optional)
 	if !p.curTokenIs(lexer.SEMICOLON) {
 		stmt.Init = p.parseStatement()
 		p.skipNewlines()
-	}
-
-	// Expect first semicolon
-	if !p.expectPeek(lexer.SEMICOLON) {
-		return nil
+		if !p.expectPeek(lexer.SEMICOLON) {
+			return nil
+		}
 	}
 	p.nextToken() // Consume SEMICOLON
 
@@ -775,11 +993,9 @@ func (p *Parser) parseForStatement() ast.Statement {
 	if !p.curTokenIs(lexer.SEMICOLON) {
 		stmt.Condition = p.parseExpression(LOWEST)
 		p.skipNewlines()
-	}
-
-	// Expect second semicolon
-	if !p.expectPeek(lexer.SEMICOLON) {
-		return nil
+		if !p.expectPeek(lexer.SEMICOLON) {
+			return nil
+		}
 	}
 	p.nextToken() // Consume SEMICOLON
 
@@ -787,12 +1003,9 @@ func (p *Parser) parseForStatement() ast.Statement {
 	if !p.curTokenIs(lexer.LEFT_BRACE) {
 		stmt.Post = p.parseStatement()
 		p.skipNewlines()
-	}
-
-	// Parse body
-	p.skipNewlines()
-	if !p.expectPeek(lexer.LEFT_BRACE) {
-		return nil
+		if !p.expectPeek(lexer.LEFT_BRACE) {
+			return nil
+		}
 	}
 
 	stmt.Body = p.parseBlockStatement()
@@ -924,6 +1137,7 @@ func (p *Parser) parseClassStatement() ast.Statement {
 				ReturnType: node.ReturnType,
 				Body:       node.Body,
 				IsAsync:    node.IsAsync,
+				Doc:        node.Doc,
 			}
 			if node.Name.Value == "init" {
 				stmt.InitMethod = &ast.ConstructorStatement{
@@ -931,6 +1145,7 @@ func (p *Parser) parseClassStatement() ast.Statement {
 					Name:       node.Name,
 					Parameters: node.Parameters,
 					Body:       node.Body,
+					Doc:        node.Doc,
 				}
 			} else {
 				stmt.Methods = append(stmt.Methods, method)
@@ -1004,6 +1219,7 @@ func (p *Parser) parseClassWithModifier(modifier lexer.Token) ast.Statement {
 				ReturnType: node.ReturnType,
 				Body:       node.Body,
 				IsAsync:    node.IsAsync,
+				Doc:        node.Doc,
 			}
 			if node.Name.Value == "init" {
 				stmt.InitMethod = &ast.ConstructorStatement{
@@ -1011,6 +1227,7 @@ func (p *Parser) parseClassWithModifier(modifier lexer.Token) ast.Statement {
 					Name:       node.Name,
 					Parameters: node.Parameters,
 					Body:       node.Body,
+					Doc:        node.Doc,
 				}
 			} else {
 				stmt.Methods = append(stmt.Methods, method)
@@ -1086,9 +1303,9 @@ func (p *Parser) parseImportStatement() ast.Statement {
 		// For string imports like import "std/math"
 		stmt.ModulePath = strings.Trim(p.curToken.Lexeme, `"`)
 		return stmt
-	} else if p.peekTokenIs(lexer.IDENTIFIER) {
+	} else if p.peekTokenIs(lexer.IDENTIFIER) || p.peekTokenIs(lexer.STRING_TYPE) {
 		p.nextToken() // consume IDENTIFIER
-		// For identifier imports like import math
+		// For identifier imports like import math (or import string)
 		stmt.ModuleName = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
 		return stmt
 	} else {
@@ -1140,6 +1357,125 @@ func (p *Parser) parseForInLoop() ast.Statement {
 	}
 }
 
+// parseEnumStatement parses an enum declaration (e.g., enum Color { Red, Green, Blue }).
+func (p *Parser) parseEnumStatement() ast.Statement {
+	return p.parseEnumWithModifier(p.curToken, "")
+}
+
+// parseEnumWithModifier parses an enum declaration once its modifier, if any,
+// has been consumed; curToken is ENUM. Members are separated by commas or
+// newlines, and a trailing comma is allowed.
+func (p *Parser) parseEnumWithModifier(token lexer.Token, visibility string) ast.Statement {
+	stmt := &ast.EnumStatement{Token: token, Visibility: visibility}
+
+	if !p.expectPeek(lexer.IDENTIFIER) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+
+	if !p.expectPeek(lexer.LEFT_BRACE) {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for {
+		p.nextToken() // Advance to the next member, or to '}' after a separator
+		p.skipNewlines()
+		if p.curTokenIs(lexer.RIGHT_BRACE) {
+			return stmt
+		}
+
+		if !p.curTokenIs(lexer.IDENTIFIER) {
+			p.addError(fmt.Sprintf("expected enum member name, got %s", p.curToken.Type))
+			return nil
+		}
+		if seen[p.curToken.Lexeme] {
+			p.addError(fmt.Sprintf("duplicate enum member %s in %s", p.curToken.Lexeme, stmt.Name.Value))
+			return nil
+		}
+		seen[p.curToken.Lexeme] = true
+		stmt.Members = append(stmt.Members, &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme})
+
+		if p.peekTokenIs(lexer.COMMA) || p.peekTokenIs(lexer.NEWLINE) {
+			p.nextToken() // Consume the separator
+			continue
+		}
+		if !p.peekTokenIs(lexer.RIGHT_BRACE) {
+			p.addError(fmt.Sprintf("expected ',' or '}', got %s", p.peekToken.Type))
+			return nil
+		}
+		p.nextToken() // Consume RIGHT_BRACE
+		return stmt
+	}
+}
+
+// parseStructStatement parses a struct declaration (e.g., struct Point { x: int, y: int }).
+func (p *Parser) parseStructStatement() ast.Statement {
+	return p.parseStructWithModifier(p.curToken, "")
+}
+
+// parseStructWithModifier parses a struct declaration once its modifier, if
+// any, has been consumed; curToken is STRUCT. Fields are written as name: type
+// or name type, the type is optional, and they are separated by commas or
+// newlines.
+func (p *Parser) parseStructWithModifier(token lexer.Token, visibility string) ast.Statement {
+	stmt := &ast.StructStatement{Token: token, Visibility: visibility}
+
+	if !p.expectPeek(lexer.IDENTIFIER) {
+		return nil
+	}
+	stmt.Name = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+	p.structs[stmt.Name.Value] = true
+
+	if !p.expectPeek(lexer.LEFT_BRACE) {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	for {
+		p.nextToken() // Advance to the next field, or to '}' after a separator
+		p.skipNewlines()
+		if p.curTokenIs(lexer.RIGHT_BRACE) {
+			return stmt
+		}
+
+		if !p.curTokenIs(lexer.IDENTIFIER) {
+			p.addError(fmt.Sprintf("expected struct field name, got %s", p.curToken.Type))
+			return nil
+		}
+		if seen[p.curToken.Lexeme] {
+			p.addError(fmt.Sprintf("duplicate field %s in struct %s", p.curToken.Lexeme, stmt.Name.Value))
+			return nil
+		}
+		seen[p.curToken.Lexeme] = true
+		field := &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme, TypeAnnotation: "ANY"}
+		stmt.Fields = append(stmt.Fields, field)
+
+		if p.peekTokenIs(lexer.COLON) {
+			p.nextToken() // Consume COLON
+			if !p.isTypeToken(p.peekToken) {
+				p.addError(fmt.Sprintf("expected type for field %s, got %s", field.Value, p.peekToken.Type))
+				return nil
+			}
+		}
+		if p.isTypeToken(p.peekToken) {
+			p.nextToken()
+			field.TypeAnnotation = p.curToken.Lexeme
+		}
+
+		if p.peekTokenIs(lexer.COMMA) || p.peekTokenIs(lexer.NEWLINE) {
+			p.nextToken() // Consume the separator
+			continue
+		}
+		if !p.peekTokenIs(lexer.RIGHT_BRACE) {
+			p.addError(fmt.Sprintf("expected ',' or '}', got %s", p.peekToken.Type))
+			return nil
+		}
+		p.nextToken() // Consume RIGHT_BRACE
+		return stmt
+	}
+}
+
 // parseSwitchStatement parses a switch statement.
 func (p *Parser) parseSwitchStatement() ast.Statement {
 	stmt := &ast.SwitchStatement{Token: p.curToken}
@@ -1160,8 +1496,6 @@ func (p *Parser) parseSwitchStatement() ast.Statement {
 		if p.curTokenIs(lexer.CASE) {
 			p.nextToken() // Consume CASE
 			caseClause.Expression = p.parseExpression(LOWEST)
-		} else {
-			p.nextToken() // Consume DEFAULT
 		}
 
 		p.skipNewlines()
@@ -1204,6 +1538,7 @@ func (p *Parser) parseMatchStatement() ast.Statement {
 	}
 
 	p.nextToken() // Consume LEFT_BRACE
+	p.skipNewlines()
 
 	for p.curTokenIs(lexer.CASE) || p.curTokenIs(lexer.DEFAULT) {
 		patternCase := &ast.PatternCase{Token: p.curToken}
@@ -1242,7 +1577,8 @@ func (p *Parser) parseMatchStatement() ast.Statement {
 
 // parsePattern parses a pattern for match statements.
 func (p *Parser) parsePattern() ast.Pattern {
-	if p.curTokenIs(lexer.IDENTIFIER) {
+	// Color.Red compares against the member instead of binding a variable
+	if p.curTokenIs(lexer.IDENTIFIER) && !p.peekTokenIs(lexer.DOT) {
 		return &ast.VariablePattern{
 			Token: p.curToken,
 			Name:  &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme},
@@ -1267,6 +1603,40 @@ func (p *Parser) parseSpawnStatement() ast.Statement {
 	return stmt
 }
 
+// parseDecoratedStatement parses one or more decorators (@expr) followed by the
+// function or class declaration they apply to.
+func (p *Parser) parseDecoratedStatement() ast.Statement {
+	var decorators []ast.Expression
+	for p.curTokenIs(lexer.AT) {
+		p.nextToken() // Consume '@'
+		decorator := p.parseExpression(LOWEST)
+		if decorator == nil {
+			return nil
+		}
+		decorators = append(decorators, decorator)
+		p.nextToken()
+		p.skipNewlines()
+	}
+
+	switch stmt := p.parseStatement().(type) {
+	case *ast.FuncStatement:
+		if stmt != nil {
+			stmt.Decorators = decorators
+		}
+		return stmt
+	case *ast.ClassStatement:
+		if stmt != nil {
+			stmt.Decorators = decorators
+		}
+		return stmt
+	case nil:
+		return nil
+	default:
+		p.addError("los decoradores solo pueden aplicarse a funciones y clases")
+		return nil
+	}
+}
+
 // Expressions
 
 // parseExpression is the main entry point for parsing expressions with precedence.
@@ -1295,9 +1665,81 @@ func (p *Parser) parseExpression(precedence int) ast.Expression {
 
 // parseIdentifier parses an identifier expression.
 func (p *Parser) parseIdentifier() ast.Expression {
+	if p.peekTokenIs(lexer.LEFT_BRACE) && p.isStructLiteral() {
+		return p.parseStructLiteral()
+	}
 	return &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
 }
 
+// isStructLiteral reports whether the identifier in curToken, followed by '{',
+// starts a struct literal rather than a block (as in if ok { ... }). It is
+// one when the brace is followed by "field:", which never starts a block, or
+// when the identifier names a struct declared earlier in the source.
+func (p *Parser) isStructLiteral() bool {
+	if p.structs[p.curToken.Lexeme] {
+		return true
+	}
+	n := 1
+	for p.peekAhead(n).Type == lexer.NEWLINE {
+		n++
+	}
+	return p.peekAhead(n).Type == lexer.IDENTIFIER && p.peekAhead(n+1).Type == lexer.COLON
+}
+
+// parseStructLiteral parses a struct literal (e.g., Point{x: 1, y: 2}); fields
+// are separated by commas or newlines.
+func (p *Parser) parseStructLiteral() ast.Expression {
+	lit := &ast.StructLiteral{Token: p.curToken, Name: &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}}
+	p.nextToken() // Consume the struct name; curToken is '{'
+
+	seen := make(map[string]bool)
+	for {
+		p.nextToken() // Advance to the next field, or to '}' after a separator
+		p.skipNewlines()
+		if p.curTokenIs(lexer.RIGHT_BRACE) {
+			return lit
+		}
+
+		if !p.curTokenIs(lexer.IDENTIFIER) {
+			p.addError(fmt.Sprintf("expected field name in %s literal, got %s", lit.Name.Value, p.curToken.Type))
+			return nil
+		}
+		field := &ast.NamedArgument{Token: p.curToken, Name: p.curToken.Lexeme}
+		if seen[field.Name] {
+			p.addError(fmt.Sprintf("duplicate field %s in %s literal", field.Name, lit.Name.Value))
+			return nil
+		}
+		seen[field.Name] = true
+		if !p.expectPeek(lexer.COLON) {
+			return nil
+		}
+		p.nextToken() // Advance to the value
+		field.Value = p.parseExpression(LOWEST)
+		if field.Value == nil {
+			return nil
+		}
+		lit.Fields = append(lit.Fields, field)
+
+		for p.peekTokenIs(lexer.NEWLINE) {
+			p.nextToken()
+		}
+		if p.peekTokenIs(lexer.COMMA) {
+			p.nextToken() // Consume COMMA
+			continue
+		}
+		if !p.peekTokenIs(lexer.RIGHT_BRACE) {
+			// A newline also separates fields
+			if p.curTokenIs(lexer.NEWLINE) {
+				continue
+			}
+			p.addError(fmt.Sprintf("expected ',' or '}', got %s", p.peekToken.Type))
+			return nil
+		}
+		p.nextToken() // Consume RIGHT_BRACE
+		return lit
+	}
+}
+
 // parseNumberLiteral parses a number literal.
 func (p *Parser) parseNumberLiteral() ast.Expression {
 	lit := &ast.NumberLiteral{Token: p.curToken, Value: p.curToken.Literal}
@@ -1391,6 +1833,37 @@ func (p *Parser) parseInfixExpression(left ast.Expression) ast.Expression {
 	return expr
 }
 
+// parsePipeExpression parses `x |> f(a)` and rewrites it into the call
+// `f(x, a)`: the left side is inserted as the first argument. A right side
+// that is not a call, such as `x |> f`, becomes `f(x)`.
+func (p *Parser) parsePipeExpression(left ast.Expression) ast.Expression {
+	token := p.curToken
+	precedence := p.curPrecedence()
+	p.nextToken() // Consume '|>'
+	right := p.parseExpression(precedence)
+	if right == nil {
+		return nil
+	}
+
+	// Se copia la llamada entera para conservar los argumentos con nombre
+	switch call := right.(type) {
+	case *ast.CallExpression:
+		piped := *call
+		piped.Arguments = append([]ast.Expression{left}, call.Arguments...)
+		return &piped
+	case *ast.CollectionMethodCall:
+		piped := *call
+		piped.Arguments = append([]ast.Expression{left}, call.Arguments...)
+		return &piped
+	default:
+		return &ast.CallExpression{
+			Token:     token,
+			Function:  right,
+			Arguments: []ast.Expression{left},
+		}
+	}
+}
+
 // parseAssignmentExpression parses an assignment expression (e.g., x = 10, y += 5).
 func (p *Parser) parseAssignmentExpression(left ast.Expression) ast.Expression {
 	// The left side of an assignment must be an identifier or an index/dot expression.
@@ -1421,7 +1894,36 @@ func (p *Parser) parseAssignmentExpression(left ast.Expression) ast.Expression {
 func (p *Parser) parseDotExpression(left ast.Expression) ast.Expression {
 	expr := &ast.DotExpression{Token: p.curToken, Left: left}
 
-	if !p.expectPeek(lexer.IDENTIFIER) {
+	// Los nombres de tipo también valen como propiedad (read.int, read.float),
+	// igual que repeat (text.repeat)
+	if p.isTypeToken(p.peekToken) || p.peekTokenIs(lexer.REPEAT) {
+		p.nextToken()
+	} else if !p.expectPeek(lexer.IDENTIFIER) {
+		return nil
+	}
+
+	expr.Property = &ast.Identifier{Token: p.curToken, Value: p.curToken.Lexeme}
+	return expr
+}
+
+// parseOptionalDotExpression parses a null-safe access (e.g., user?.name or
+// config?.["key"]).
+func (p *Parser) parseOptionalDotExpression(left ast.Expression) ast.Expression {
+	expr := &ast.OptionalDotExpression{Token: p.curToken, Left: left}
+
+	if p.peekTokenIs(lexer.LEFT_BRACKET) {
+		p.nextToken() // Consume '?.'
+		p.nextToken() // Consume '['
+		expr.Index = p.parseExpression(LOWEST)
+		if !p.expectPeek(lexer.RIGHT_BRACKET) {
+			return nil
+		}
+		return expr
+	}
+
+	if p.isTypeToken(p.peekToken) || p.peekTokenIs(lexer.REPEAT) {
+		p.nextToken()
+	} else if !p.expectPeek(lexer.IDENTIFIER) {
 		return nil
 	}
 
@@ -1438,26 +1940,70 @@ func (p *Parser) parseCallExpression(fn ast.Expression) ast.Expression {
 		if leftIdent, ok := dotExpr.Left.(*ast.Identifier); ok && leftIdent.Value == "show" {
 			// show.log is special - treat as regular CallExpression
 			exp := &ast.CallExpression{Token: p.curToken, Function: fn}
-			exp.Arguments = p.parseExpressionList(lexer.RIGHT_PAREN)
+			exp.Arguments, exp.NamedArguments = p.parseCallArguments()
 			return exp
 		}
 		// For other dot expressions, treat as collection method calls
 		// The semantic analyzer will handle the distinction
 		exp := &ast.CollectionMethodCall{
-			Token:     p.curToken,
-			Object:    dotExpr.Left,
-			Method:    dotExpr.Property,
-			Arguments: p.parseExpressionList(lexer.RIGHT_PAREN),
+			Token:  p.curToken,
+			Object: dotExpr.Left,
+			Method: dotExpr.Property,
 		}
+		exp.Arguments, exp.NamedArguments = p.parseCallArguments()
 		return exp
 	}
 
 	// Regular function call
 	exp := &ast.CallExpression{Token: p.curToken, Function: fn}
-	exp.Arguments = p.parseExpressionList(lexer.RIGHT_PAREN)
+	exp.Arguments, exp.NamedArguments = p.parseCallArguments()
 	return exp
 }
 
+// parseCallArguments parses the arguments of a call up to ')'. Arguments
+// written as name: value are returned separately; they must come after every
+// positional argument and each name may appear only once.
+func (p *Parser) parseCallArguments() ([]ast.Expression, []*ast.NamedArgument) {
+	args := []ast.Expression{}
+	var named []*ast.NamedArgument
+
+	if p.peekTokenIs(lexer.RIGHT_PAREN) {
+		p.nextToken() // Consume ')'
+		return args, named
+	}
+
+	for {
+		p.nextToken() // Advance to the argument
+		if p.curTokenIs(lexer.IDENTIFIER) && p.peekTokenIs(lexer.COLON) {
+			arg := &ast.NamedArgument{Token: p.curToken, Name: p.curToken.Lexeme}
+			for _, other := range named {
+				if other.Name == arg.Name {
+					p.addError(fmt.Sprintf("duplicate named argument: %s", arg.Name))
+				}
+			}
+			p.nextToken() // Consume the name
+			p.nextToken() // Consume ':'
+			arg.Value = p.parseExpression(LOWEST)
+			named = append(named, arg)
+		} else {
+			if len(named) > 0 {
+				p.addError(fmt.Sprintf("positional argument cannot follow named arguments (after %s)", named[len(named)-1].Name))
+			}
+			args = append(args, p.parseExpression(LOWEST))
+		}
+
+		if !p.peekTokenIs(lexer.COMMA) {
+			break
+		}
+		p.nextToken() // Consume ','
+	}
+
+	if !p.expectPeek(lexer.RIGHT_PAREN) {
+		return nil, nil
+	}
+	return args, named
+}
+
 // parseIndexExpression parses an index or slice access expression (e.g., arr[0], arr[1:3], arr[-1]).
 func (p *Parser) parseIndexExpression(left ast.Expression) ast.Expression {
 	exp := &ast.IndexExpression{Token: p.curToken, Left: left}
@@ -1501,10 +2047,11 @@ func (p *Parser) parseListLiteral() ast.Expression {
 }
 
 // parseBlockOrCollectionLiteral handles the logic to distinguish between BlockStatement, MapLiteral, and SetLiteral.
-// It assumes the LEFT_BRACE has already been consumed.
+// curToken is the LEFT_BRACE.
 func (p *Parser) parseBlockOrCollectionLiteral() ast.Expression {
 	token := p.curToken // The '{' token (LEFT_BRACE)
-	p.nextToken()       // Consume LEFT_BRACE
+	start := p.saveState()
+	p.nextToken() // Consume LEFT_BRACE
 	p.skipNewlines()
 
 	// If the next token is '}', it's an empty block, map, or set.
@@ -1515,53 +2062,22 @@ func (p *Parser) parseBlockOrCollectionLiteral() ast.Expression {
 		return &ast.BlockExpression{Token: token, Block: &ast.BlockStatement{Token: token, Statements: []ast.Statement{}}}
 	}
 
-	// Try to parse the first element/key.
-	// We need to peek ahead to distinguish between map and set.
-	// This requires a more advanced peek mechanism or backtracking.
-	// For simplicity, let's try to parse the first element/key.
+	// Parse the first element/key to tell the three apart.
 	// If it's followed by a COLON, it's a map.
 	// If it's followed by a COMMA or RIGHT_BRACE, it's a set.
 	// Otherwise, it's a block statement.
-
-	// Save current token to potentially backtrack
-	curTokenBackup := p.curToken
-
-	// Try to parse the first element/key.
-	// We need to peek ahead to distinguish between map and set.
-	// For simplicity, let's try to parse the first expression.
+	// The lookahead is then discarded and parsing starts again at the '{'.
 	firstExp := p.parseExpression(LOWEST)
-	if firstExp == nil {
-		// If we couldn't parse an expression, it's likely a block statement starting with a statement.
-		// Rewind tokens and parse as a block.
-		p.curToken = token // Rewind to LEFT_BRACE
-		p.peekToken = curTokenBackup
-		block := p.parseBlockStatement()
-		if block == nil {
-			return nil
-		}
-		return &ast.BlockExpression{Token: token, Block: block}
-	}
+	isMap := firstExp != nil && p.peekTokenIs(lexer.COLON)
+	isSet := firstExp != nil && (p.peekTokenIs(lexer.COMMA) || p.peekTokenIs(lexer.RIGHT_BRACE))
+	p.restoreState(start)
 
-	if p.curTokenIs(lexer.COLON) {
-		// It's a MapLiteral
-		// Rewind tokens to before firstExp and parse as map
-		p.curToken = token // Rewind to LEFT_BRACE
-		p.peekToken = curTokenBackup
-		p.nextToken() // Consume LEFT_BRACE again
+	switch {
+	case isMap:
 		return p.parseMapLiteral()
-	} else if p.curTokenIs(lexer.COMMA) || p.curTokenIs(lexer.RIGHT_BRACE) {
-		// It's a SetLiteral
-		// Rewind tokens to before firstExp and parse as set
-		p.curToken = token // Rewind to LEFT_BRACE
-		p.peekToken = curTokenBackup
-		p.nextToken() // Consume LEFT_BRACE again
+	case isSet:
 		return p.parseSetLiteral()
-	} else {
-		// If it's not a map or set, it must be a block statement.
-		// The firstExp was actually the first expression statement in the block.
-		// Rewind tokens and parse as a block.
-		p.curToken = token // Rewind to LEFT_BRACE
-		p.peekToken = curTokenBackup
+	default:
 		block := p.parseBlockStatement()
 		if block == nil {
 			return nil
@@ -1570,23 +2086,39 @@ func (p *Parser) parseBlockOrCollectionLiteral() ast.Expression {
 	}
 }
 
-// parseMapLiteral parses a map literal (e.g., {key: value, another: 1}).
-// It assumes the LEFT_BRACE has already been consumed.
-func (p *Parser) parseMapLiteral() ast.Expression {
-	m := &ast.MapLiteral{Token: p.curToken, Pairs: make(map[string]ast.Expression)}
+// parserState is a parser position that can be restored for backtracking.
+type parserState struct {
+	lexer     lexer.Lexer
+	cur, peek lexer.Token
+	errors    int
+}
 
-	p.skipNewlines()
-	if p.peekTokenIs(lexer.RIGHT_BRACE) {
-		p.nextToken() // Consume RIGHT_BRACE
-		return m
-	}
+// saveState records the current position of the parser and its lexer.
+func (p *Parser) saveState() parserState {
+	return parserState{lexer: *p.l, cur: p.curToken, peek: p.peekToken, errors: len(p.errors)}
+}
 
-	for !p.peekTokenIs(lexer.RIGHT_BRACE) && !p.peekTokenIs(lexer.EOF) {
-		p.skipNewlines()
+// restoreState goes back to a saved position, dropping the errors found since.
+func (p *Parser) restoreState(s parserState) {
+	*p.l = s.lexer
+	p.curToken = s.cur
+	p.peekToken = s.peek
+	p.errors = p.errors[:s.errors]
+}
+
+// parseMapLiteral parses a map literal (e.g., {key: value, another: 1}),
+// keeping its keys in the order they were written. A repeated key keeps its
+// first position and takes the last value.
+// curToken is the LEFT_BRACE.
+func (p *Parser) parseMapLiteral() ast.Expression {
+	m := &ast.MapLiteral{Token: p.curToken, Pairs: []ast.MapPair{}}
+	positions := make(map[string]int)
 
-		// ✅ Verificar si llegamos al final después de una coma trailing
+	for {
+		p.nextToken() // Advance to the next key, or to '}' after a trailing comma
+		p.skipNewlines()
 		if p.curTokenIs(lexer.RIGHT_BRACE) {
-			break
+			return m
 		}
 
 		key := p.parseExpression(LOWEST)
@@ -1599,6 +2131,7 @@ func (p *Parser) parseMapLiteral() ast.Expression {
 		}
 
 		p.nextToken() // Advance to value
+		p.skipNewlines()
 		value := p.parseExpression(LOWEST)
 		if value == nil {
 			return nil
@@ -1614,61 +2147,62 @@ func (p *Parser) parseMapLiteral() ast.Expression {
 			p.addError("map key must be a string literal or identifier")
 			return nil
 		}
-		m.Pairs[keyStr] = value
+		if i, seen := positions[keyStr]; seen {
+			m.Pairs[i].Value = value
+		} else {
+			positions[keyStr] = len(m.Pairs)
+			m.Pairs = append(m.Pairs, ast.MapPair{Key: keyStr, Value: value})
+		}
 
-		p.skipNewlines()
+		for p.peekTokenIs(lexer.NEWLINE) {
+			p.nextToken()
+		}
 		if p.peekTokenIs(lexer.COMMA) {
 			p.nextToken() // Consume COMMA
-			p.skipNewlines()
-			p.nextToken() // Advance to next key
-			// ✅ Continuar el loop - si viene }, el loop lo detectará
-		} else if !p.peekTokenIs(lexer.RIGHT_BRACE) {
+			continue
+		}
+		if !p.peekTokenIs(lexer.RIGHT_BRACE) {
 			p.addError(fmt.Sprintf("expected ',' or '}', got %s", p.peekToken.Type))
 			return nil
 		}
+		p.nextToken() // Consume RIGHT_BRACE
+		return m
 	}
-
-	if !p.expectPeek(lexer.RIGHT_BRACE) {
-		return nil
-	}
-
-	return m
 }
 
-// parseSetLiteral parses a set literal (e.g., {1, 2, 3}).
-// It assumes the LEFT_BRACE has already been consumed.
+// parseSetLiteral parses a set literal (e.g., {1, 2, 3}). Like map literals,
+// elements may span several lines and end with a trailing comma.
+// curToken is the LEFT_BRACE.
 func (p *Parser) parseSetLiteral() ast.Expression {
 	s := &ast.SetLiteral{Token: p.curToken, Elements: []ast.Expression{}} // Token is LEFT_BRACE
 
-	p.skipNewlines()
-	if p.peekTokenIs(lexer.RIGHT_BRACE) {
-		p.nextToken() // Consume RIGHT_BRACE
-		return s
-	}
+	for {
+		p.nextToken() // Advance to the next element, or to '}' after a trailing comma
+		p.skipNewlines()
+		if p.curTokenIs(lexer.RIGHT_BRACE) {
+			return s
+		}
 
-	for !p.peekTokenIs(lexer.RIGHT_BRACE) && !p.peekTokenIs(lexer.EOF) {
-		p.nextToken() // Advance to element
 		element := p.parseExpression(LOWEST)
 		if element == nil {
 			return nil
 		}
 		s.Elements = append(s.Elements, element)
 
-		p.skipNewlines()
+		for p.peekTokenIs(lexer.NEWLINE) {
+			p.nextToken()
+		}
 		if p.peekTokenIs(lexer.COMMA) {
 			p.nextToken() // Consume COMMA
-			p.skipNewlines()
-		} else if !p.peekTokenIs(lexer.RIGHT_BRACE) {
+			continue
+		}
+		if !p.peekTokenIs(lexer.RIGHT_BRACE) {
 			p.addError(fmt.Sprintf("expected ',' or '}', got %s", p.peekToken.Type))
 			return nil
 		}
+		p.nextToken() // Consume RIGHT_BRACE
+		return s
 	}
-
-	if !p.expectPeek(lexer.RIGHT_BRACE) {
-		return nil
-	}
-
-	return s
 }
 
 // parseFunctionLiteralPrefix parses an anonymous function literal used as a prefix expression (e.g., func() {}).
@@ -1785,6 +2319,12 @@ func (p *Parser) parseReturnExpression() ast.Expression {
 	return &ast.Identifier{Token: p.curToken, Value: "INVALID_RETURN_EXPRESSION"}
 }
 
+// parseTypeNameIdentifier parsea un nombre de tipo usado como identificador
+// en una expresión
+func (p *Parser) parseTypeNameIdentifier() ast.Expression {
+	return &ast.Identifier{Token: p.curToken, Value: strings.ToLower(p.curToken.Lexeme)}
+}
+
 // parseUnexpectedPrefix is a temporary stub for tokens that should not be prefixes.
 func (p *Parser) parseUnexpectedPrefix() ast.Expression {
 	if p.curToken.Type == lexer.COMMA || p.curToken.Type == lexer.COLON ||
@@ -1801,7 +2341,8 @@ func (p *Parser) parseUnexpectedPrefix() ast.Expression {
 
 // parseErrorToken handles lexer error tokens.
 func (p *Parser) parseErrorToken() ast.Expression {
-	p.addError(fmt.Sprintf("lexer error: %s", p.curToken.Lexeme))
+	p.errorTokens[len(p.errors)] = p.curToken
+	p.addError(fmt.Sprintf("lexer error at %d:%d: %s", p.curToken.StartLine, p.curToken.StartCol, p.curToken.Lexeme))
 	p.nextToken() // Advance past the error token
 	return &ast.Identifier{Token: p.curToken, Value: "LEXER_ERROR"}
 }
@@ -1830,6 +2371,22 @@ func (p *Parser) parseInExpression(left ast.Expression) ast.Expression {
 	return expr
 }
 
+// parseNotInExpression parses a 'not in' infix expression (e.g., x not in list).
+func (p *Parser) parseNotInExpression(left ast.Expression) ast.Expression {
+	expr := &ast.InfixExpression{
+		Token:    p.curToken,
+		Operator: "not in",
+		Left:     left,
+	}
+	if !p.expectPeek(lexer.IN) {
+		return nil
+	}
+	precedence := p.curPrecedence()
+	p.nextToken() // Consume IN
+	expr.Right = p.parseExpression(precedence)
+	return expr
+}
+
 // parseArrowFunctionExpression parses an arrow function expression (e.g., (a, b) => a + b or (a) => { return a; }).
 // It assumes the LEFT_PAREN (or identifier for single param) has already been consumed.
 func (p *Parser) parseArrowFunctionExpression(isAsync bool) ast.Expression {
@@ -2002,6 +2559,26 @@ func (p *Parser) parseAsExpression(left ast.Expression) ast.Expression {
 	}
 }
 
+// parseIsExpression parses a type check (e.g., x is int, x is Animal).
+func (p *Parser) parseIsExpression(left ast.Expression) ast.Expression {
+	token := p.curToken // The 'is' token
+
+	switch p.peekToken.Type {
+	case lexer.IDENTIFIER, lexer.ANY_TYPE, lexer.INT_TYPE, lexer.FLOAT_TYPE, lexer.STRING_TYPE,
+		lexer.BOOL_TYPE, lexer.LIST_TYPE, lexer.MAP_TYPE, lexer.NIL, lexer.FUNC:
+		p.nextToken()
+	default:
+		p.addError(fmt.Sprintf("expected type name after 'is', got %s", p.peekToken.Type))
+		return nil
+	}
+
+	return &ast.IsExpression{
+		Token:    token,
+		Left:     left,
+		TypeName: p.curToken.Lexeme,
+	}
+}
+
 // parseModifierInExpression handles modifiers that appear in expression context (should not happen).
 func (p *Parser) parseModifierInExpression() ast.Expression {
 	p.addError(fmt.Sprintf("modifier '%s' should not appear in expression context", p.curToken.Lexeme))
@@ -2060,21 +2637,33 @@ func tokenPrecedence(tt lexer.TokenType) int {
 	switch tt {
 	case lexer.EQUAL, lexer.PLUS_EQUAL, lexer.MINUS_EQUAL, lexer.STAR_EQUAL, lexer.SLASH_EQUAL, lexer.PERCENT_EQUAL:
 		return ASSIGN
+	case lexer.PIPE:
+		return PIPE_PREC
 	case lexer.OR:
 		return ANDOR
-	case lexer.AND:
+	case lexer.AND, lexer.XOR:
 		return ANDOR
+	case lexer.NULL_COALESCE:
+		return NULLISH
 	case lexer.EQUAL_EQUAL, lexer.BANG_EQUAL:
 		return EQUALS
 	case lexer.LESS, lexer.LESS_EQUAL, lexer.GREATER, lexer.GREATER_EQUAL:
 		return LESSGREATER
+	case lexer.BIT_OR:
+		return BIT_OR_PREC
+	case lexer.BIT_XOR:
+		return BIT_XOR_PREC
+	case lexer.BIT_AND:
+		return BIT_AND_PREC
+	case lexer.SHIFT_LEFT, lexer.SHIFT_RIGHT:
+		return SHIFT
 	case lexer.PLUS, lexer.MINUS:
 		return SUM
-	case lexer.SLASH, lexer.STAR, lexer.PERCENT, lexer.FLOOR_DIVIDE:
+	case lexer.SLASH, lexer.STAR, lexer.PERCENT:
 		return PRODUCT
 	case lexer.POWER:
 		return POWER_PREC
-	case lexer.DOT:
+	case lexer.DOT, lexer.QUESTION_DOT:
 		return CALL
 	case lexer.LEFT_PAREN:
 		return CALL
@@ -2082,9 +2671,7 @@ func tokenPrecedence(tt lexer.TokenType) int {
 		return INDEX
 	case lexer.RANGE:
 		return SUM
-	case lexer.NOT:
-		return PREFIX
-	case lexer.IN:
+	case lexer.IN, lexer.NOT, lexer.IS: // NOT como infijo solo aparece en 'not in'
 		return EQUALS
 	case lexer.ARROW_RETURN: // Added for arrow functions
 		return ASSIGN // Low precedence, similar to assignment
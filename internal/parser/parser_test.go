@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"strings"
 	"testing"
 	"github.com/zylo-lang/zylo/internal/ast"
 	"github.com/zylo-lang/zylo/internal/lexer"
@@ -371,24 +372,145 @@ func TestIfElifElseStatement(t *testing.T) {
 			program.Statements[0])
 	}
 
+	if len(stmt.ElseIfs) != 1 {
+		t.Fatalf("stmt.ElseIfs should contain 1 clause. got=%d", len(stmt.ElseIfs))
+	}
+	if stmt.ElseIfs[0].Condition.String() != "y" {
+		t.Errorf("elif condition is not y. got=%s", stmt.ElseIfs[0].Condition.String())
+	}
 	if stmt.Alternative == nil {
 		t.Errorf("stmt.Alternative should not be nil")
 	}
+}
+
+func TestElseIfChainIsFlat(t *testing.T) {
+	input := `if a { } elif b { } else if c { } elif d { } else { }`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. got=%d",
+			len(program.Statements))
+	}
+
+	stmt, ok := program.Statements[0].(*ast.IfStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.IfStatement. got=%T",
+			program.Statements[0])
+	}
+
+	conditions := []string{"b", "c", "d"}
+	if len(stmt.ElseIfs) != len(conditions) {
+		t.Fatalf("stmt.ElseIfs should contain %d clauses. got=%d", len(conditions), len(stmt.ElseIfs))
+	}
+	for i, name := range conditions {
+		if stmt.ElseIfs[i].Condition.String() != name {
+			t.Errorf("clause %d condition is not %s. got=%s", i, name, stmt.ElseIfs[i].Condition.String())
+		}
+		if len(stmt.ElseIfs[i].Consequence.Statements) != 0 {
+			t.Errorf("clause %d should have an empty block", i)
+		}
+	}
+	if stmt.Alternative == nil || len(stmt.Alternative.Statements) != 0 {
+		t.Errorf("stmt.Alternative should be an empty block. got=%+v", stmt.Alternative)
+	}
+}
+
+func TestConstStatement(t *testing.T) {
+	p := New(lexer.New("const HORA = 60 * 60\nconst NOMBRE: string = \"zylo\""))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
 
-	// The alternative should be a block with the elif statement
-	if len(stmt.Alternative.Statements) != 1 {
-		t.Fatalf("stmt.Alternative.Statements should contain 1 statement. got=%d",
-			len(stmt.Alternative.Statements))
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d", len(program.Statements))
 	}
+	for i, name := range []string{"HORA", "NOMBRE"} {
+		stmt, ok := program.Statements[i].(*ast.VarStatement)
+		if !ok {
+			t.Fatalf("program.Statements[%d] is not ast.VarStatement. got=%T", i, program.Statements[i])
+		}
+		if stmt.Token.Type != lexer.CONST || !stmt.IsConstant || stmt.Name.Value != name || stmt.Value == nil {
+			t.Errorf("statement %d is not const %s with a value. got=%s", i, name, stmt.String())
+		}
+	}
+
+	p = New(lexer.New("const HORA"))
+	p.ParseProgram()
+	if errs := p.Errors(); len(errs) != 1 || errs[0] != "expected '=' after const HORA" {
+		t.Errorf("expected a missing value error, got %v", errs)
+	}
+}
+
+func TestRepeatStatement(t *testing.T) {
+	p := New(lexer.New("repeat n * 2 {\n    x := 1\n}"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
 
-	elifStmt, ok := stmt.Alternative.Statements[0].(*ast.IfStatement)
+	if len(program.Statements) != 1 {
+		t.Fatalf("program.Statements does not contain 1 statements. got=%d", len(program.Statements))
+	}
+	stmt, ok := program.Statements[0].(*ast.RepeatStatement)
 	if !ok {
-		t.Fatalf("stmt.Alternative.Statements[0] is not ast.IfStatement. got=%T",
-			stmt.Alternative.Statements[0])
+		t.Fatalf("program.Statements[0] is not ast.RepeatStatement. got=%T", program.Statements[0])
+	}
+	if stmt.Count.String() != "(n * 2)" {
+		t.Errorf("stmt.Count is not (n * 2). got=%s", stmt.Count.String())
+	}
+	if len(stmt.Body.Statements) != 1 {
+		t.Errorf("stmt.Body should contain 1 statement. got=%d", len(stmt.Body.Statements))
 	}
 
-	if elifStmt.Alternative == nil {
-		t.Errorf("elifStmt.Alternative should not be nil")
+	// repeat(...) sigue siendo una llamada a la función repeat de std
+	p = New(lexer.New(`repeat("ab", 3)`))
+	program = p.ParseProgram()
+	checkParserErrors(t, p)
+	exprStmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+	}
+	if _, ok := exprStmt.Expression.(*ast.CallExpression); !ok {
+		t.Errorf("repeat(...) is not ast.CallExpression. got=%T", exprStmt.Expression)
+	}
+}
+
+func TestOptionalDotExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"user?.address?.city", "user?.address?.city"},
+		{"config?.[\"key\"]", "config?.[\"key\"]"},
+		{"a?.b.c", "a?.b.c"},
+		{"x + a?.b", "(x + a?.b)"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("program.Statements[0] is not ast.ExpressionStatement. got=%T", program.Statements[0])
+		}
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.input, tt.expected, stmt.Expression.String())
+		}
+	}
+
+	// obj?.metodo() es una llamada cuya función es el acceso opcional
+	p := New(lexer.New("user?.keys()"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	call, ok := program.Statements[0].(*ast.ExpressionStatement).Expression.(*ast.CallExpression)
+	if !ok {
+		t.Fatalf("expression is not ast.CallExpression. got=%T", program.Statements[0].(*ast.ExpressionStatement).Expression)
+	}
+	if _, ok := call.Function.(*ast.OptionalDotExpression); !ok {
+		t.Errorf("call.Function is not ast.OptionalDotExpression. got=%T", call.Function)
 	}
 }
 
@@ -547,3 +669,854 @@ func suma(a int, b int) {
 		}
 	}
 }
+
+func TestDefaultParameters(t *testing.T) {
+	input := `func greet(name, greeting string = "Hello", times = 1 + 1) {
+    return greeting
+}`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	fn, ok := program.Statements[0].(*ast.FuncStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.FuncStatement. got=%T", program.Statements[0])
+	}
+	expected := []struct {
+		name string
+		typ  string
+		def  string
+	}{
+		{"name", "ANY", ""},
+		{"greeting", "string", `"Hello"`},
+		{"times", "ANY", "(1 + 1)"},
+	}
+	if len(fn.Parameters) != len(expected) {
+		t.Fatalf("expected %d parameters, got=%d", len(expected), len(fn.Parameters))
+	}
+	for i, param := range fn.Parameters {
+		if param.Value != expected[i].name || param.TypeAnnotation != expected[i].typ {
+			t.Errorf("parameter %d: expected %s %s, got=%s %s", i, expected[i].name, expected[i].typ, param.Value, param.TypeAnnotation)
+		}
+		def := ""
+		if param.Default != nil {
+			def = param.Default.String()
+		}
+		if def != expected[i].def {
+			t.Errorf("parameter %d default: expected %q, got=%q", i, expected[i].def, def)
+		}
+	}
+}
+
+func TestRequiredParameterAfterDefaultError(t *testing.T) {
+	p := New(lexer.New("func f(a = 1, b) {\n    return a\n}\n"))
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected an error for a required parameter after a default one")
+	}
+	if errors[0] != "el parámetro b necesita un valor por defecto porque lo tiene un parámetro anterior" {
+		t.Errorf("unexpected error: %s", errors[0])
+	}
+}
+
+func TestVariadicParameters(t *testing.T) {
+	p := New(lexer.New("func f(a, ...rest int) {\n    return rest\n}\n"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	fn, ok := program.Statements[0].(*ast.FuncStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.FuncStatement. got=%T", program.Statements[0])
+	}
+	if len(fn.Parameters) != 2 {
+		t.Fatalf("expected 2 parameters, got=%d", len(fn.Parameters))
+	}
+	if fn.Parameters[0].Variadic {
+		t.Errorf("parameter a should not be variadic")
+	}
+	rest := fn.Parameters[1]
+	if rest.Value != "rest" || rest.TypeAnnotation != "int" || !rest.Variadic {
+		t.Errorf("expected variadic parameter rest int, got=%s %s (variadic=%v)", rest.Value, rest.TypeAnnotation, rest.Variadic)
+	}
+}
+
+func TestVariadicParameterNotLastError(t *testing.T) {
+	p := New(lexer.New("func f(...rest, a) {\n    return a\n}\n"))
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected an error for a variadic parameter that is not the last one")
+	}
+	if errors[0] != "el parámetro variádico ...rest debe ser el último" {
+		t.Errorf("unexpected error: %s", errors[0])
+	}
+}
+
+func TestDecorators(t *testing.T) {
+	input := `
+@memoize
+func fib(n) {
+    return n
+}
+
+@tag("modelo")
+@registrar
+class Usuario {
+}
+`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 2 {
+		t.Fatalf("program.Statements does not contain 2 statements. got=%d",
+			len(program.Statements))
+	}
+
+	funcStmt, ok := program.Statements[0].(*ast.FuncStatement)
+	if !ok {
+		t.Fatalf("program.Statements[0] is not ast.FuncStatement. got=%T", program.Statements[0])
+	}
+	if len(funcStmt.Decorators) != 1 || funcStmt.Decorators[0].String() != "memoize" {
+		t.Errorf("function decorators wrong. got=%v", funcStmt.Decorators)
+	}
+
+	classStmt, ok := program.Statements[1].(*ast.ClassStatement)
+	if !ok {
+		t.Fatalf("program.Statements[1] is not ast.ClassStatement. got=%T", program.Statements[1])
+	}
+	if len(classStmt.Decorators) != 2 {
+		t.Fatalf("class should have 2 decorators. got=%d", len(classStmt.Decorators))
+	}
+	if _, ok := classStmt.Decorators[0].(*ast.CallExpression); !ok {
+		t.Errorf("first decorator is not ast.CallExpression. got=%T", classStmt.Decorators[0])
+	}
+	if classStmt.Decorators[1].String() != "registrar" {
+		t.Errorf("second decorator not registrar. got=%s", classStmt.Decorators[1].String())
+	}
+}
+
+func TestDecoratorOnNonDeclarationError(t *testing.T) {
+	l := lexer.New("@memoize\nx := 1\n")
+	p := New(l)
+	p.ParseProgram()
+
+	errors := p.Errors()
+	if len(errors) == 0 {
+		t.Fatalf("expected an error for a decorator on a variable")
+	}
+	if errors[0] != "los decoradores solo pueden aplicarse a funciones y clases" {
+		t.Errorf("unexpected error: %s", errors[0])
+	}
+}
+
+func TestPipeExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x |> f", "f(x)"},
+		{"x |> f(a)", "f(x, a)"},
+		{"x |> f(a, b) |> g |> h(c)", "h(g(f(x, a, b)), c)"},
+		{"1 + 2 |> f", "f((1 + 2))"},
+		{"x |> f(a, z: 1)", "f(x, a, z: 1)"},
+	}
+
+	for _, tt := range tests {
+		l := lexer.New(tt.input)
+		p := New(l)
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%s: expected 1 statement. got=%d", tt.input, len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("%s: statement is not ast.ExpressionStatement. got=%T", tt.input, program.Statements[0])
+		}
+		if _, ok := stmt.Expression.(*ast.CallExpression); !ok {
+			t.Errorf("%s: expression is not ast.CallExpression. got=%T", tt.input, stmt.Expression)
+		}
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, stmt.Expression.String())
+		}
+	}
+}
+
+func TestMembershipExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x in lista", "(x in lista)"},
+		{"x not in lista", "(x not in lista)"},
+		{"a + 1 not in b and c", "(((a + 1) not in b) and c)"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%s: expected 1 statement. got=%d", tt.input, len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("%s: statement is not ast.ExpressionStatement. got=%T", tt.input, program.Statements[0])
+		}
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, stmt.Expression.String())
+		}
+	}
+}
+
+func TestBitwiseExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a & b | c ^ d", "((a & b) | (c ^ d))"},
+		{"a | b & c", "(a | (b & c))"},
+		{"a ^ b & c", "(a ^ (b & c))"},
+		{"1 << 2 + 3", "(1 << (2 + 3))"},
+		{"a & 1 == 0", "((a & 1) == 0)"},
+		{"a < b | c", "(a < (b | c))"},
+		{"a - b - c & d", "(((a - b) - c) & d)"},
+		{"a << b << c", "((a << b) << c)"},
+		{"a >> b >> c", "((a >> b) >> c)"},
+		{"a ^ b ^ c", "((a ^ b) ^ c)"},
+		{"a xor b xor c", "((a xor b) xor c)"},
+		{"a == b xor c", "((a == b) xor c)"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%s: expected 1 statement. got=%d", tt.input, len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("%s: statement is not ast.ExpressionStatement. got=%T", tt.input, program.Statements[0])
+		}
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, stmt.Expression.String())
+		}
+	}
+}
+
+func TestNullCoalescingPrecedence(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"a ?? b", "(a ?? b)"},
+		{"a ?? b ?? c", "((a ?? b) ?? c)"},
+		// ?? va justo por encima de or y and, y por debajo de las comparaciones
+		{"a ?? b or c", "((a ?? b) or c)"},
+		{"a or b ?? c", "(a or (b ?? c))"},
+		{"a and b ?? c", "(a and (b ?? c))"},
+		{"a ?? b == c", "(a ?? (b == c))"},
+		{"a ?? b + 1", "(a ?? (b + 1))"},
+		{"user?.name ?? \"anónimo\"", "(user?.name ?? \"anónimo\")"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("%s: statement is not ast.ExpressionStatement. got=%T", tt.input, program.Statements[0])
+		}
+		if stmt.Expression.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, stmt.Expression.String())
+		}
+	}
+}
+
+func TestDestructuringAssignment(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		targets  int
+	}{
+		{"a, b = [1, 2]", "a, b = [1, 2]", 2},
+		{"a, b = b, a", "a, b = [b, a]", 2},
+		{"a, b, c = pair()", "a, b, c = pair()", 3},
+		{"l[0], obj.x = 1 + 2, 3", "(l[0]), obj.x = [(1 + 2), 3]", 2},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%s: expected 1 statement. got=%d", tt.input, len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("%s: statement is not ast.ExpressionStatement. got=%T", tt.input, program.Statements[0])
+		}
+		exp, ok := stmt.Expression.(*ast.DestructuringAssignmentExpression)
+		if !ok {
+			t.Fatalf("%s: expression is not ast.DestructuringAssignmentExpression. got=%T", tt.input, stmt.Expression)
+		}
+		if len(exp.Targets) != tt.targets {
+			t.Errorf("%s: expected %d targets, got %d", tt.input, tt.targets, len(exp.Targets))
+		}
+		if exp.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, exp.String())
+		}
+	}
+}
+
+func TestDestructuringAssignmentErrors(t *testing.T) {
+	for _, input := range []string{"a, 1 = [1, 2]", "a, b"} {
+		p := New(lexer.New(input))
+		p.ParseProgram()
+		if len(p.Errors()) == 0 {
+			t.Errorf("%s: expected a parser error", input)
+		}
+	}
+}
+
+func TestForStatementClauses(t *testing.T) {
+	tests := []struct {
+		input     string
+		init      bool
+		condition bool
+		post      bool
+	}{
+		{"for i := 0; i < 3; i = i + 1 {\n}", true, true, true},
+		{"for ; i < 3; i = i + 1 {\n}", false, true, true},
+		{"for i := 0; i < 3; {\n}", true, true, false},
+		{"for ;; {\n    break\n}", false, false, false},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%s: expected 1 statement. got=%d", tt.input, len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.ForStatement)
+		if !ok {
+			t.Fatalf("%s: statement is not ast.ForStatement. got=%T", tt.input, program.Statements[0])
+		}
+		if (stmt.Init != nil) != tt.init || (stmt.Condition != nil) != tt.condition || (stmt.Post != nil) != tt.post {
+			t.Errorf("%s: unexpected clauses: init=%v condition=%v post=%v", tt.input, stmt.Init, stmt.Condition, stmt.Post)
+		}
+		if stmt.Body == nil {
+			t.Errorf("%s: missing body", tt.input)
+		}
+	}
+}
+
+func TestSetLiteralParsing(t *testing.T) {
+	tests := []struct {
+		input    string
+		elements int
+	}{
+		{"s := {1, 2, 3}", 3},
+		{"s := {\"a\"}", 1},
+		{"s := {\n    1,\n    2,\n}", 2},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.VarStatement)
+		if !ok {
+			t.Fatalf("%s: statement is not ast.VarStatement. got=%T", tt.input, program.Statements[0])
+		}
+		set, ok := stmt.Value.(*ast.SetLiteral)
+		if !ok {
+			t.Fatalf("%s: value is not ast.SetLiteral. got=%T", tt.input, stmt.Value)
+		}
+		if len(set.Elements) != tt.elements {
+			t.Errorf("%s: expected %d elements, got %d", tt.input, tt.elements, len(set.Elements))
+		}
+	}
+}
+
+func TestNamedArguments(t *testing.T) {
+	tests := []struct {
+		input      string
+		expected   string
+		positional int
+		named      int
+	}{
+		{`greet(name: "Ana")`, `greet(name: "Ana")`, 0, 1},
+		{`greet("Ana", greeting: "Hola")`, `greet("Ana", greeting: "Hola")`, 1, 1},
+		{`f(1, b: 2 + 3, c: g(x: 1))`, `f(1, b: (2 + 3), c: g(x: 1))`, 1, 2},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("%s: statement is not ast.ExpressionStatement. got=%T", tt.input, program.Statements[0])
+		}
+		call, ok := stmt.Expression.(*ast.CallExpression)
+		if !ok {
+			t.Fatalf("%s: expression is not ast.CallExpression. got=%T", tt.input, stmt.Expression)
+		}
+		if len(call.Arguments) != tt.positional || len(call.NamedArguments) != tt.named {
+			t.Errorf("%s: expected %d positional and %d named arguments, got %d and %d",
+				tt.input, tt.positional, tt.named, len(call.Arguments), len(call.NamedArguments))
+		}
+		if call.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, call.String())
+		}
+	}
+}
+
+func TestNamedArgumentErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{`f(a: 1, a: 2)`, "duplicate named argument: a"},
+		{`f(a: 1, 2)`, "positional argument cannot follow named arguments (after a)"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		found := false
+		for _, err := range p.Errors() {
+			if strings.Contains(err, tt.expected) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: expected error %q, got %v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestTypeNamesInExpressions(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"string(5)", "string(5)"},
+		{`int("3") + 1`, `(int("3") + 1)`},
+		{`string.upper("a")`, `string.upper("a")`},
+		{"read.int()", "read.int()"},
+		{"read.float()", "read.float()"},
+		{"import string", "import string;"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%s: expected 1 statement. got=%d", tt.input, len(program.Statements))
+		}
+		if program.Statements[0].String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, program.Statements[0].String())
+		}
+	}
+}
+
+func TestMapLiteralKeepsOrder(t *testing.T) {
+	tests := []struct {
+		input string
+		keys  []string
+	}{
+		{`m := {"b": 1, "a": 2, "c": 3}`, []string{"b", "a", "c"}},
+		{`m := {z: 1, y: 2}`, []string{"z", "y"}},
+		{"m := {\n  \"b\": 1,\n  \"a\": {\"x\": 1},\n}", []string{"b", "a"}},
+		{`m := {"a": 1, "b": 2, "a": 3}`, []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		if len(program.Statements) != 1 {
+			t.Fatalf("%s: expected 1 statement. got=%d", tt.input, len(program.Statements))
+		}
+		stmt, ok := program.Statements[0].(*ast.VarStatement)
+		if !ok {
+			t.Fatalf("%s: expected *ast.VarStatement, got %T", tt.input, program.Statements[0])
+		}
+		m, ok := stmt.Value.(*ast.MapLiteral)
+		if !ok {
+			t.Fatalf("%s: expected *ast.MapLiteral, got %T", tt.input, stmt.Value)
+		}
+		var keys []string
+		for _, pair := range m.Pairs {
+			keys = append(keys, pair.Key)
+		}
+		if strings.Join(keys, ",") != strings.Join(tt.keys, ",") {
+			t.Errorf("%s: expected keys %v, got %v", tt.input, tt.keys, keys)
+		}
+	}
+
+	// Una clave repetida conserva su posición y toma el último valor
+	p := New(lexer.New(`m := {"a": 1, "b": 2, "a": 3}`))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	m := program.Statements[0].(*ast.VarStatement).Value.(*ast.MapLiteral)
+	if m.Pairs[0].Value.String() != "3" {
+		t.Errorf("expected the last value for a repeated key, got %s", m.Pairs[0].Value.String())
+	}
+}
+
+func TestDocComments(t *testing.T) {
+	input := `
+// Suma dos números.
+func sumar(a int, b int): int {
+    return a + b
+}
+
+// Un decorador no separa el comentario de la función.
+@memoize
+func fib(n) {
+    return n
+}
+
+// Representa una persona.
+class Persona {
+    // Saluda.
+    func saludar() {
+        return "hola"
+    }
+}
+
+// Resta dos números.
+export func restar(a, b) {
+    return a - b
+}
+`
+
+	l := lexer.New(input)
+	p := New(l)
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	if len(program.Statements) != 4 {
+		t.Fatalf("program.Statements does not contain 4 statements. got=%d", len(program.Statements))
+	}
+	if doc := program.Statements[0].(*ast.FuncStatement).Doc; doc != "Suma dos números." {
+		t.Errorf("function doc wrong. got=%q", doc)
+	}
+	if doc := program.Statements[1].(*ast.FuncStatement).Doc; doc != "Un decorador no separa el comentario de la función." {
+		t.Errorf("decorated function doc wrong. got=%q", doc)
+	}
+	class := program.Statements[2].(*ast.ClassStatement)
+	if class.Doc != "Representa una persona." {
+		t.Errorf("class doc wrong. got=%q", class.Doc)
+	}
+	if len(class.Methods) != 1 || class.Methods[0].Doc != "Saluda." {
+		t.Errorf("method doc wrong. got=%v", class.Methods)
+	}
+	export := program.Statements[3].(*ast.ExportStatement)
+	if doc := export.Declaration.(*ast.FuncStatement).Doc; doc != "Resta dos números." {
+		t.Errorf("exported function doc wrong. got=%q", doc)
+	}
+}
+
+func TestEnumStatement(t *testing.T) {
+	tests := []struct {
+		input      string
+		expected   string
+		visibility string
+	}{
+		{"enum Color { Red, Green, Blue }", "enum Color { Red, Green, Blue }", ""},
+		{"enum Color {\n    Red,\n    Green\n    Blue,\n}", "enum Color { Red, Green, Blue }", ""},
+		{"enum Vacio {}", "enum Vacio {}", ""},
+		{"public enum Dir { Norte, Sur }", "public enum Dir { Norte, Sur }", "public"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.EnumStatement)
+		if !ok {
+			t.Fatalf("%s: statement is not ast.EnumStatement. got=%T", tt.input, program.Statements[0])
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, stmt.String())
+		}
+		if stmt.Visibility != tt.visibility {
+			t.Errorf("%s: expected visibility %q, got %q", tt.input, tt.visibility, stmt.Visibility)
+		}
+	}
+}
+
+func TestEnumStatementErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"enum Color { Red, Red }", "duplicate enum member Red in Color"},
+		{"enum Color { Red, 1 }", "expected enum member name, got NUMBER"},
+		{"enum Color { Red Green }", "expected ',' or '}', got IDENTIFIER"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		found := false
+		for _, err := range p.Errors() {
+			if strings.Contains(err, tt.expected) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: expected error %q, got %v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestSwitchAndMatchStatements(t *testing.T) {
+	input := `
+switch c {
+case Color.Red:
+    x = 1
+default:
+    x = 2
+}
+match c {
+case Color.Red:
+    x = 1
+case otro:
+    x = 2
+}
+`
+	p := New(lexer.New(input))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+
+	sw, ok := program.Statements[0].(*ast.SwitchStatement)
+	if !ok {
+		t.Fatalf("statement is not ast.SwitchStatement. got=%T", program.Statements[0])
+	}
+	if len(sw.Cases) != 2 || sw.Cases[1].Expression != nil {
+		t.Errorf("expected a case and a default, got %v", sw.Cases)
+	}
+
+	match, ok := program.Statements[1].(*ast.MatchStatement)
+	if !ok {
+		t.Fatalf("statement is not ast.MatchStatement. got=%T", program.Statements[1])
+	}
+	if len(match.Cases) != 2 {
+		t.Fatalf("expected 2 cases, got %d", len(match.Cases))
+	}
+	if _, ok := match.Cases[0].Pattern.(*ast.LiteralPattern); !ok {
+		t.Errorf("Color.Red should be a literal pattern, got %T", match.Cases[0].Pattern)
+	}
+	if _, ok := match.Cases[1].Pattern.(*ast.VariablePattern); !ok {
+		t.Errorf("otro should be a variable pattern, got %T", match.Cases[1].Pattern)
+	}
+}
+
+func TestStructStatement(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"struct Point { x: int, y: int }", "struct Point { x: int, y: int }"},
+		{"struct Point {\n    x int\n    y int\n}", "struct Point { x: int, y: int }"},
+		{"struct Par { a, b: string, }", "struct Par { a, b: string }"},
+		{"private struct Vacio {}", "private struct Vacio {}"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.StructStatement)
+		if !ok {
+			t.Fatalf("%s: statement is not ast.StructStatement. got=%T", tt.input, program.Statements[0])
+		}
+		if stmt.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, stmt.String())
+		}
+	}
+}
+
+func TestStructLiteral(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"p := Point{x: 1, y: 2 + 3}", "Point{x: 1, y: (2 + 3)}"},
+		{"p := Point{\n    x: 1\n    y: 2,\n}", "Point{x: 1, y: 2}"},
+		{"p := Linea{a: Point{x: 1, y: 2}, b: q}", "Linea{a: Point{x: 1, y: 2}, b: q}"},
+		// Un struct declarado antes también admite el literal vacío
+		{"struct Vacio {}\np := Vacio{}", "Vacio{}"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[len(program.Statements)-1].(*ast.VarStatement)
+		if !ok {
+			t.Fatalf("%s: statement is not ast.VarStatement. got=%T", tt.input, program.Statements[0])
+		}
+		lit, ok := stmt.Value.(*ast.StructLiteral)
+		if !ok {
+			t.Fatalf("%s: value is not ast.StructLiteral. got=%T", tt.input, stmt.Value)
+		}
+		if lit.String() != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, lit.String())
+		}
+	}
+
+	// Un identificador seguido de un bloque no es un literal de struct
+	p := New(lexer.New("if ok {\n    x = 1\n}\nwhile ok {}"))
+	program := p.ParseProgram()
+	checkParserErrors(t, p)
+	if _, ok := program.Statements[0].(*ast.IfStatement); !ok {
+		t.Errorf("statement is not ast.IfStatement. got=%T", program.Statements[0])
+	}
+	if _, ok := program.Statements[1].(*ast.WhileStatement); !ok {
+		t.Errorf("statement is not ast.WhileStatement. got=%T", program.Statements[1])
+	}
+}
+
+func TestStructErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"struct P { x, x }", "duplicate field x in struct P"},
+		{"struct P { 1 }", "expected struct field name, got NUMBER"},
+		{"struct P { x: }", "expected type for field x, got RIGHT_BRACE"},
+		{"p := P{x: 1, x: 2}", "duplicate field x in P literal"},
+		{"p := P{x: 1 y: 2}", "expected ',' or '}', got IDENTIFIER"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		p.ParseProgram()
+		found := false
+		for _, err := range p.Errors() {
+			if strings.Contains(err, tt.expected) {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: expected error %q, got %v", tt.input, tt.expected, p.Errors())
+		}
+	}
+}
+
+func TestIsExpression(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x is int", "(x is int)"},
+		{"x is Animal", "(x is Animal)"},
+		{"x is list and y is map", "((x is list) and (y is map))"},
+		{"!(x is string)", "(!(x is string))"},
+		{"f is func", "(f is func)"},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+		if got := program.String(); got != tt.expected {
+			t.Errorf("%s: expected %q, got %q", tt.input, tt.expected, got)
+		}
+	}
+
+	p := New(lexer.New("x is 1"))
+	p.ParseProgram()
+	if len(p.Errors()) == 0 || p.Errors()[0] != "expected type name after 'is', got NUMBER" {
+		t.Errorf("expected a type name error, got %v", p.Errors())
+	}
+}
+
+func TestLexerErrorTokens(t *testing.T) {
+	p := New(lexer.New("x := 1\ny := (1\nnombre := \"hola\n"))
+	p.ParseProgram()
+
+	found := false
+	for i, msg := range p.Errors() {
+		tok, ok := p.ErrorToken(i)
+		if !ok {
+			continue
+		}
+		found = true
+		if tok.StartLine != 3 || tok.StartCol != 11 || tok.Lexeme != "Unterminated string." {
+			t.Errorf("wrong lexer error token: %+v", tok)
+		}
+		if msg != "lexer error at 3:11: Unterminated string." {
+			t.Errorf("wrong lexer error message: %q", msg)
+		}
+	}
+	if !found {
+		t.Fatalf("expected a lexer error, got %v", p.Errors())
+	}
+	if _, ok := p.ErrorToken(0); ok {
+		t.Errorf("the parser error for line 2 should not come from the lexer")
+	}
+}
+
+func TestNumberLiteralForms(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected interface{}
+	}{
+		{"0xFF", int64(255)},
+		{"0b1010", int64(10)},
+		{"0o17", int64(15)},
+		{"1_000_000", int64(1000000)},
+		{"1.5e3", 1500.0},
+	}
+
+	for _, tt := range tests {
+		p := New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		checkParserErrors(t, p)
+
+		stmt, ok := program.Statements[0].(*ast.ExpressionStatement)
+		if !ok {
+			t.Fatalf("%s: statement is not *ast.ExpressionStatement. got=%T", tt.input, program.Statements[0])
+		}
+		lit, ok := stmt.Expression.(*ast.NumberLiteral)
+		if !ok {
+			t.Fatalf("%s: expression is not *ast.NumberLiteral. got=%T", tt.input, stmt.Expression)
+		}
+		if lit.Value != tt.expected {
+			t.Errorf("%s: value not %v. got=%v", tt.input, tt.expected, lit.Value)
+		}
+		if lit.String() != tt.input {
+			t.Errorf("%s: String() = %q", tt.input, lit.String())
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package sema
+
+// Plegado de constantes: el valor de una declaración const se calcula durante
+// el análisis y se sustituye en el AST por un literal, así el evaluador y
+// codegen usan directamente el resultado (const HORA = 60 * 60 queda como
+// const HORA = 3600). Una expresión es constante si solo tiene literales,
+// otras constantes declaradas con const y operaciones aritméticas entre ellos.
+
+import (
+	"errors"
+	"math"
+	"strconv"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+)
+
+// errNotConstant indica que una expresión no se puede calcular en el análisis
+var errNotConstant = errors.New("no es una expresión constante")
+
+// foldConstant calcula el valor de exp y lo devuelve como literal
+func (sa *SemanticAnalyzer) foldConstant(exp ast.Expression) (ast.Expression, error) {
+	switch e := exp.(type) {
+	case *ast.NumberLiteral, *ast.StringLiteral, *ast.BooleanLiteral:
+		return e, nil
+	case *ast.Identifier:
+		if sym, ok := sa.symbolTable.Resolve(e.Value); ok && sym.ConstValue != nil {
+			return sym.ConstValue, nil
+		}
+	case *ast.PrefixExpression:
+		right, err := sa.foldConstant(e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return foldPrefix(e.Token, e.Operator, right)
+	case *ast.InfixExpression:
+		left, err := sa.foldConstant(e.Left)
+		if err != nil {
+			return nil, err
+		}
+		right, err := sa.foldConstant(e.Right)
+		if err != nil {
+			return nil, err
+		}
+		return foldInfix(e.Token, e.Operator, left, right)
+	}
+	return nil, errNotConstant
+}
+
+// foldPrefix aplica -x o !x a un literal
+func foldPrefix(token lexer.Token, operator string, right ast.Expression) (ast.Expression, error) {
+	switch operator {
+	case "-":
+		if n, ok := right.(*ast.NumberLiteral); ok {
+			switch v := n.Value.(type) {
+			case int64:
+				return intLiteral(token, -v), nil
+			case float64:
+				return floatLiteral(token, -v), nil
+			}
+		}
+	case "!":
+		if b, ok := right.(*ast.BooleanLiteral); ok {
+			return boolLiteral(token, !b.Value), nil
+		}
+	}
+	return nil, errNotConstant
+}
+
+// foldInfix aplica un operador aritmético a dos literales con la misma
+// semántica que el evaluador: int con int da int y si hay un float, float
+func foldInfix(token lexer.Token, operator string, left, right ast.Expression) (ast.Expression, error) {
+	if l, ok := left.(*ast.StringLiteral); ok {
+		if r, ok := right.(*ast.StringLiteral); ok && operator == "+" {
+			return stringLiteral(token, l.Value+r.Value), nil
+		}
+		return nil, errNotConstant
+	}
+
+	l, lok := left.(*ast.NumberLiteral)
+	r, rok := right.(*ast.NumberLiteral)
+	if !lok || !rok {
+		return nil, errNotConstant
+	}
+
+	li, lInt := l.Value.(int64)
+	ri, rInt := r.Value.(int64)
+	if lInt && rInt {
+		switch operator {
+		case "+":
+			return intLiteral(token, li+ri), nil
+		case "-":
+			return intLiteral(token, li-ri), nil
+		case "*":
+			return intLiteral(token, li*ri), nil
+		case "/":
+			if ri == 0 {
+				return nil, errors.New("división por cero")
+			}
+			return intLiteral(token, li/ri), nil
+		case "%":
+			if ri == 0 {
+				return nil, errors.New("módulo por cero")
+			}
+			return intLiteral(token, li%ri), nil
+		}
+		return nil, errNotConstant
+	}
+
+	lf, rf := numberValue(l), numberValue(r)
+	switch operator {
+	case "+":
+		return floatLiteral(token, lf+rf), nil
+	case "-":
+		return floatLiteral(token, lf-rf), nil
+	case "*":
+		return floatLiteral(token, lf*rf), nil
+	case "/":
+		if rf == 0 {
+			return nil, errors.New("división por cero")
+		}
+		return floatLiteral(token, lf/rf), nil
+	case "%":
+		if rf == 0 {
+			return nil, errors.New("módulo por cero")
+		}
+		return floatLiteral(token, math.Mod(lf, rf)), nil
+	}
+	return nil, errNotConstant
+}
+
+// numberValue devuelve el valor de un literal numérico como float64
+func numberValue(n *ast.NumberLiteral) float64 {
+	switch v := n.Value.(type) {
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
+
+// Los literales plegados conservan la posición de la expresión original
+
+func intLiteral(token lexer.Token, v int64) *ast.NumberLiteral {
+	token.Type, token.Lexeme, token.Literal = lexer.NUMBER, strconv.FormatInt(v, 10), v
+	return &ast.NumberLiteral{Token: token, Value: v}
+}
+
+func floatLiteral(token lexer.Token, v float64) *ast.NumberLiteral {
+	lexeme := strconv.FormatFloat(v, 'g', -1, 64)
+	if _, err := strconv.ParseInt(lexeme, 10, 64); err == nil {
+		lexeme += ".0" // Que siga leyéndose como float
+	}
+	token.Type, token.Lexeme, token.Literal = lexer.NUMBER, lexeme, v
+	return &ast.NumberLiteral{Token: token, Value: v}
+}
+
+func stringLiteral(token lexer.Token, v string) *ast.StringLiteral {
+	token.Type, token.Lexeme, token.Literal = lexer.STRING, strconv.Quote(v), v
+	return &ast.StringLiteral{Token: token, Value: v}
+}
+
+func boolLiteral(token lexer.Token, v bool) *ast.BooleanLiteral {
+	lexeme := lexer.FALSE
+	if v {
+		lexeme = lexer.TRUE
+	}
+	token.Type, token.Lexeme, token.Literal = lexeme, strconv.FormatBool(v), v
+	return &ast.BooleanLiteral{Token: token, Value: v}
+}
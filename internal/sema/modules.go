@@ -0,0 +1,254 @@
+package sema
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/zylo-lang/zylo/internal/ast"
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/packages"
+	"github.com/zylo-lang/zylo/internal/parser"
+	"github.com/zylo-lang/zylo/internal/stdlib"
+)
+
+// ModuleCache guarda el resultado de analizar los módulos locales, por ruta
+// absoluta, para que cada módulo se parsee y analice una sola vez aunque lo
+// importen varios archivos de la misma compilación. Una entrada se invalida
+// cuando cambia el contenido del archivo, así que la caché se puede
+// reutilizar entre recompilaciones (por ejemplo, en modo watch).
+type ModuleCache struct {
+	modules map[string]*cachedModule
+	parses  int      // Módulos parseados hasta ahora
+	stack   []string // Módulos que se están analizando, en orden de import
+}
+
+// cachedModule es un módulo local ya analizado
+type cachedModule struct {
+	hash   [sha256.Size]byte
+	typ    *ClassType
+	errors []*ZyloError
+	cycle  []string // Import circular encontrado al analizar el módulo
+}
+
+// NewModuleCache crea una caché de módulos vacía
+func NewModuleCache() *ModuleCache {
+	return &ModuleCache{modules: make(map[string]*cachedModule)}
+}
+
+// SetModuleCache hace que el analizador comparta la caché de módulos con
+// otros analizadores de la misma compilación
+func (sa *SemanticAnalyzer) SetModuleCache(cache *ModuleCache) {
+	sa.modules = cache
+}
+
+// SetBaseDir fija el directorio desde el que se resuelven los imports
+// locales, normalmente el del archivo analizado
+func (sa *SemanticAnalyzer) SetBaseDir(dir string) {
+	sa.baseDir = dir
+}
+
+// resolveLocalModule analiza el módulo local modulePath (relativo a baseDir,
+// con o sin extensión .zylo) y devuelve su tipo con las funciones, clases y
+// variables de nivel superior. Devuelve nil si el archivo no existe.
+func (sa *SemanticAnalyzer) resolveLocalModule(token lexer.Token, modulePath string) *ClassType {
+	path := filepath.Join(sa.baseDir, modulePath)
+	if filepath.Ext(path) != ".zylo" {
+		path += ".zylo"
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return sa.loadModule(token, modulePath, path, content)
+}
+
+// resolveEmbeddedModule analiza el módulo name de la biblioteca estándar
+// incluida en el ejecutable. Devuelve nil si no existe.
+func (sa *SemanticAnalyzer) resolveEmbeddedModule(token lexer.Token, name string) *ClassType {
+	source, ok := stdlib.Source(name)
+	if !ok {
+		return nil
+	}
+	return sa.loadModule(token, "std/"+name, "std/"+name+".zylo", []byte(source))
+}
+
+// resolvePackageModule analiza el módulo de un paquete instalado en
+// zylo_modules/. Devuelve nil si el paquete no está instalado.
+func (sa *SemanticAnalyzer) resolvePackageModule(token lexer.Token, modulePath string) *ClassType {
+	path, ok := packages.Resolve(sa.baseDir, modulePath)
+	if !ok {
+		return nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+	return sa.loadModule(token, modulePath, path, content)
+}
+
+// loadModule analiza (o toma de la caché) el módulo con ruta path e informa
+// de sus errores como errores del import
+func (sa *SemanticAnalyzer) loadModule(token lexer.Token, modulePath, path string, content []byte) *ClassType {
+	if cycle := sa.modules.cycle(path); cycle != nil {
+		sa.reportImportCycle(token, cycle)
+		return nil
+	}
+
+	module := sa.modules.load(path, content)
+	if module.cycle != nil {
+		// El ciclo se informa tal cual en cada archivo que lo atraviesa
+		sa.reportImportCycle(token, module.cycle)
+	} else if len(module.errors) > 0 {
+		sa.addError(token, fmt.Sprintf("errores en el módulo %s: %s", modulePath, module.errors[0].Message))
+	}
+	return module.typ
+}
+
+// reportImportCycle informa del import circular cycle, una sola vez por archivo
+func (sa *SemanticAnalyzer) reportImportCycle(token lexer.Token, cycle []string) {
+	if sa.importCycle != nil {
+		return
+	}
+	sa.importCycle = cycle
+	sa.addError(token, "import circular: "+strings.Join(cycle, " -> "))
+}
+
+// cycle devuelve el ciclo de imports que se formaría al importar path, o nil
+// si path no se está analizando
+func (c *ModuleCache) cycle(path string) []string {
+	for i, p := range c.stack {
+		if p != path {
+			continue
+		}
+		var cycle []string
+		for _, m := range append(c.stack[i:], path) {
+			cycle = append(cycle, filepath.Base(m))
+		}
+		return cycle
+	}
+	return nil
+}
+
+// load devuelve el módulo de path, analizándolo si no está en la caché o si
+// su contenido cambió
+func (c *ModuleCache) load(path string, content []byte) *cachedModule {
+	hash := sha256.Sum256(content)
+	if module, ok := c.modules[path]; ok && module.hash == hash {
+		return module
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), ".zylo")
+	module := &cachedModule{
+		hash: hash,
+		typ:  &ClassType{Name: name, Methods: make(map[string]*FunctionType), Fields: make(map[string]Type)},
+	}
+	c.modules[path] = module
+	c.stack = append(c.stack, path)
+	defer func() { c.stack = c.stack[:len(c.stack)-1] }()
+
+	c.parses++
+	p := parser.New(lexer.New(string(content)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		module.errors = []*ZyloError{{Code: ZYLO_ERR_001_PARSER_ERROR, Message: p.Errors()[0], Filename: path, Severity: "error"}}
+		return module
+	}
+
+	sa := NewSemanticAnalyzer()
+	sa.SetModuleCache(c)
+	sa.SetBaseDir(filepath.Dir(path))
+	sa.Analyze(program)
+	module.errors = sa.ZyloErrors()
+	module.cycle = sa.importCycle
+	sa.addExports(module.typ, program)
+	return module
+}
+
+// addExports añade a typ lo que exporta program, ya analizado por sa
+func (sa *SemanticAnalyzer) addExports(typ *ClassType, program *ast.Program) {
+	for _, exported := range program.Exports() {
+		symbol, ok := sa.symbolTable.symbols[exported]
+		if !ok {
+			continue
+		}
+		if fn, isFunc := symbol.Type.(*FunctionType); isFunc {
+			typ.Methods[exported] = fn
+		} else {
+			typ.Fields[exported] = symbol.Type
+		}
+	}
+}
+
+// ExportSignature describe la interfaz que ve quien importa program, ya
+// analizado: cada nombre exportado con su tipo resuelto. Si dos versiones de
+// un módulo tienen la misma firma, los módulos que lo importan no necesitan
+// volver a generarse.
+func (sa *SemanticAnalyzer) ExportSignature(program *ast.Program) string {
+	exports := &ClassType{Methods: make(map[string]*FunctionType), Fields: make(map[string]Type)}
+	sa.addExports(exports, program)
+	var decls []string
+	for name, fn := range exports.Methods {
+		decls = append(decls, name+" "+describeType(fn))
+	}
+	for name, typ := range exports.Fields {
+		decl := name + " " + describeType(typ)
+		if class, ok := typ.(*ClassType); ok {
+			decl += describeClass(class)
+		}
+		decls = append(decls, decl)
+	}
+	sort.Strings(decls)
+	return strings.Join(decls, "\n")
+}
+
+// describeType describe typ con más detalle que String: las funciones
+// incluyen sus parámetros y los enums sus miembros
+func describeType(typ Type) string {
+	switch t := typ.(type) {
+	case *FunctionType:
+		params := make([]string, len(t.ParamTypes))
+		for i, param := range t.ParamTypes {
+			params[i] = describeType(param)
+			if i < len(t.ParamNames) {
+				params[i] = t.ParamNames[i] + " " + params[i]
+			}
+		}
+		return fmt.Sprintf("func(%s) %s optional=%d variadic=%v", strings.Join(params, ", "), describeType(t.ReturnType), t.Optional, t.Variadic)
+	case *ListType:
+		return "List<" + describeType(t.ElementType) + ">"
+	case *SetType:
+		return "Set<" + describeType(t.ElementType) + ">"
+	case *MapType:
+		return "Map<" + describeType(t.KeyType) + ", " + describeType(t.ValueType) + ">"
+	case *EnumType:
+		return "enum " + t.Name + " {" + strings.Join(t.Members, ", ") + "}"
+	case nil:
+		return "nil"
+	}
+	return typ.String()
+}
+
+// describeClass describe los campos, métodos y superclase de una clase o struct
+func describeClass(class *ClassType) string {
+	var members []string
+	for name, typ := range class.Fields {
+		members = append(members, name+" "+describeType(typ))
+	}
+	for name, fn := range class.Methods {
+		members = append(members, name+" "+describeType(fn))
+	}
+	sort.Strings(members)
+	decl := fmt.Sprintf(" struct=%v [%s] {%s}", class.IsStruct, strings.Join(class.TypeParams, ", "), strings.Join(members, "; "))
+	if class.SuperClass != nil {
+		decl += " extends " + class.SuperClass.Name
+	}
+	return decl
+}
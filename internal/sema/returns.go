@@ -0,0 +1,128 @@
+package sema
+
+// Análisis de caminos de retorno: una función que declara un tipo de retorno
+// concreto tiene que terminar con return (o throw) en todos los caminos. El
+// análisis es conservador: un if necesita else, un switch o match necesita
+// default, y un bucle solo cuenta si es infinito (while true o for sin
+// condición) y no tiene ningún break que lo termine.
+
+import "github.com/zylo-lang/zylo/internal/ast"
+
+// blockAlwaysReturns indica si ejecutar block termina siempre en return o
+// throw. Basta con que lo haga una de sus sentencias, porque lo que venga
+// después no se ejecuta.
+func blockAlwaysReturns(block *ast.BlockStatement) bool {
+	if block == nil {
+		return false
+	}
+	for _, stmt := range block.Statements {
+		if alwaysReturns(stmt) {
+			return true
+		}
+	}
+	return false
+}
+
+// alwaysReturns indica si ejecutar stmt termina siempre en return o throw
+func alwaysReturns(stmt ast.Statement) bool {
+	switch s := stmt.(type) {
+	case *ast.ReturnStatement, *ast.ThrowStatement:
+		return true
+	case *ast.BlockStatement:
+		return blockAlwaysReturns(s)
+	case *ast.IfStatement:
+		if s.Alternative == nil || !blockAlwaysReturns(s.Consequence) || !blockAlwaysReturns(s.Alternative) {
+			return false
+		}
+		for _, clause := range s.ElseIfs {
+			if !blockAlwaysReturns(clause.Consequence) {
+				return false
+			}
+		}
+		return true
+	case *ast.SwitchStatement:
+		hasDefault := false
+		for _, c := range s.Cases {
+			if c.Expression == nil {
+				hasDefault = true
+			}
+			if !blockAlwaysReturns(c.Body) {
+				return false
+			}
+		}
+		return hasDefault
+	case *ast.MatchStatement:
+		hasDefault := false
+		for _, c := range s.Cases {
+			if _, catchAll := c.Pattern.(*ast.VariablePattern); c.Pattern == nil || (catchAll && c.Guard == nil) {
+				hasDefault = true
+			}
+			if !blockAlwaysReturns(c.Body) {
+				return false
+			}
+		}
+		return hasDefault
+	case *ast.TryStatement:
+		if blockAlwaysReturns(s.FinallyBlock) {
+			return true
+		}
+		if s.CatchClause != nil && !blockAlwaysReturns(s.CatchClause.CatchBlock) {
+			return false
+		}
+		return blockAlwaysReturns(s.TryBlock)
+	case *ast.WhileStatement:
+		condition, ok := s.Condition.(*ast.BooleanLiteral)
+		return ok && condition.Value && !hasBreak(s.Body)
+	case *ast.ForStatement:
+		return s.Condition == nil && !hasBreak(s.Body)
+	}
+	return false
+}
+
+// hasBreak indica si block contiene un break que sale del bucle al que
+// pertenece block. Los break de bucles anidados no cuentan; los de un switch
+// o match sí, porque terminan el bucle que los contiene.
+func hasBreak(block *ast.BlockStatement) bool {
+	if block == nil {
+		return false
+	}
+	for _, stmt := range block.Statements {
+		switch s := stmt.(type) {
+		case *ast.BreakStatement:
+			return true
+		case *ast.BlockStatement:
+			if hasBreak(s) {
+				return true
+			}
+		case *ast.IfStatement:
+			if hasBreak(s.Consequence) || hasBreak(s.Alternative) {
+				return true
+			}
+			for _, clause := range s.ElseIfs {
+				if hasBreak(clause.Consequence) {
+					return true
+				}
+			}
+		case *ast.SwitchStatement:
+			for _, c := range s.Cases {
+				if hasBreak(c.Body) {
+					return true
+				}
+			}
+		case *ast.MatchStatement:
+			for _, c := range s.Cases {
+				if hasBreak(c.Body) {
+					return true
+				}
+			}
+		case *ast.TryStatement:
+			if hasBreak(s.TryBlock) || hasBreak(s.FinallyBlock) {
+				return true
+			}
+			if s.CatchClause != nil && hasBreak(s.CatchClause.CatchBlock) {
+				return true
+			}
+		}
+	}
+	return false
+}
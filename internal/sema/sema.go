@@ -2,10 +2,12 @@ package sema
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/zylo-lang/zylo/internal/ast"
 	"github.com/zylo-lang/zylo/internal/lexer"
+	zyloruntime "github.com/zylo-lang/zylo/runtime"
 )
 
 // ZYLO ERRORS - Sistema profesional de errores de tipo
@@ -24,6 +26,8 @@ const (
 	ZYLO_ERR_012_DUPLICATE_VAR     = "ZYLO_ERR_012: Variable ya declarada"
 	ZYLO_ERR_013_FUNCTION_NOT_FOUND = "ZYLO_ERR_013: Función no encontrada"
 	ZYLO_ERR_014_ACCESS_DENIED     = "ZYLO_ERR_014: Acceso denegado"
+
+	ZYLO_WARN_001_UNUSED_VAR = "ZYLO_WARN_001: Variable no utilizada"
 )
 
 // ZyloError representa un error profesional con metadata completa
@@ -126,6 +130,50 @@ func (eb *ErrorBuilder) TypeCaseError(token lexer.Token, wrongType string) *Zylo
 	}
 }
 
+// DuplicateVarError crea error ZYLO_ERR_012. original es el nombre en la
+// primera declaración de la variable.
+func (eb *ErrorBuilder) DuplicateVarError(token lexer.Token, varName string, original lexer.Token) *ZyloError {
+	return &ZyloError{
+		Code:       ZYLO_ERR_012_DUPLICATE_VAR,
+		Message:    fmt.Sprintf("Variable '%s' ya declarada en este ámbito", varName),
+		Line:       token.StartLine,
+		Column:     token.StartCol,
+		Filename:   eb.filename,
+		Suggestion: fmt.Sprintf("Use '%s = ...' para reasignarla o elija otro nombre", varName),
+		Severity:   "error",
+		Context:    fmt.Sprintf("declarada antes en la línea %d, columna %d", original.StartLine, original.StartCol),
+	}
+}
+
+// MissingReturnError crea error ZYLO_ERR_008 para una función que puede
+// terminar sin devolver el tipo que declara
+func (eb *ErrorBuilder) MissingReturnError(token lexer.Token, funcName string, returnType Type) *ZyloError {
+	return &ZyloError{
+		Code:       ZYLO_ERR_008_RETURN_TYPE,
+		Message:    fmt.Sprintf("La función '%s' puede terminar sin devolver un valor de tipo %s", funcName, returnType),
+		Line:       token.StartLine,
+		Column:     token.StartCol,
+		Filename:   eb.filename,
+		Expected:   returnType.String(),
+		Received:   "nil",
+		Suggestion: "Añada un return al final de la función o un else a los if sin él",
+		Severity:   "error",
+	}
+}
+
+// UnusedVarWarning crea la advertencia ZYLO_WARN_001
+func (eb *ErrorBuilder) UnusedVarWarning(token lexer.Token, varName string) *ZyloError {
+	return &ZyloError{
+		Code:       ZYLO_WARN_001_UNUSED_VAR,
+		Message:    fmt.Sprintf("Variable '%s' declarada pero no utilizada", varName),
+		Line:       token.StartLine,
+		Column:     token.StartCol,
+		Filename:   eb.filename,
+		Suggestion: "Elimine la variable o use un nombre que empiece por '_'",
+		Severity:   "warning",
+	}
+}
+
 // Type representa un tipo en el sistema de tipos de Zylo
 type Type interface {
 	String() string
@@ -154,6 +202,17 @@ func (t *ListType) Equals(other Type) bool {
 	return false
 }
 
+// SetType representa tipos de set
+type SetType struct{ ElementType Type }
+
+func (t *SetType) String() string { return fmt.Sprintf("Set<%s>", t.ElementType.String()) }
+func (t *SetType) Equals(other Type) bool {
+	if o, ok := other.(*SetType); ok {
+		return t.ElementType.Equals(o.ElementType)
+	}
+	return false
+}
+
 // MapType representa tipos de mapa
 type MapType struct {
 	KeyType   Type
@@ -173,7 +232,10 @@ func (t *MapType) Equals(other Type) bool {
 // FunctionType representa tipos de función
 type FunctionType struct {
 	ParamTypes []Type
+	ParamNames []string // Nombres de los parámetros; nil si no se conocen (builtins)
 	ReturnType Type
+	Optional   int  // Parámetros finales con valor por defecto
+	Variadic   bool // El último parámetro recibe el resto de argumentos; su tipo es el de cada uno
 }
 
 func (t *FunctionType) String() string { return "func" }
@@ -199,6 +261,8 @@ type ClassType struct {
 	Methods    map[string]*FunctionType
 	Fields     map[string]Type
 	TypeParams []string
+	IsStruct   bool          // Declarado con struct: solo tiene los campos de Fields
+	Call       *FunctionType // Módulo que además se llama como función; nil si no
 }
 
 func (t *ClassType) String() string { return t.Name }
@@ -209,6 +273,31 @@ func (t *ClassType) Equals(other Type) bool {
 	return false
 }
 
+// EnumType representa un enum declarado; el nombre del enum y sus miembros
+// tienen este tipo, como ClassType para una clase y sus instancias
+type EnumType struct {
+	Name    string
+	Members []string
+}
+
+func (t *EnumType) String() string { return t.Name }
+func (t *EnumType) Equals(other Type) bool {
+	if o, ok := other.(*EnumType); ok {
+		return t.Name == o.Name
+	}
+	return false
+}
+
+// HasMember indica si name es un miembro del enum
+func (t *EnumType) HasMember(name string) bool {
+	for _, member := range t.Members {
+		if member == name {
+			return true
+		}
+	}
+	return false
+}
+
 // AnyType representa el tipo any (top type)
 type AnyType struct{}
 
@@ -230,6 +319,18 @@ type Symbol struct {
 	Name  string
 	Type  Type
 	Scope string
+
+	// Solo para variables declaradas con VarStatement: dónde se declararon y
+	// si se leyeron alguna vez, para detectar variables sin usar
+	Variable bool
+	Token    lexer.Token
+	Used     bool
+
+	// Constant indica que la variable no se puede reasignar (nombre en mayúsculas)
+	Constant bool
+
+	// ConstValue es el valor ya calculado de una declaración const
+	ConstValue ast.Expression
 }
 
 // SymbolTable representa una tabla de símbolos
@@ -272,6 +373,12 @@ func (st *SymbolTable) Define(name string, t Type) *Symbol {
 	return symbol
 }
 
+// ResolveLocal busca un símbolo solo en este scope, sin subir a los padres
+func (st *SymbolTable) ResolveLocal(name string) (*Symbol, bool) {
+	sym, ok := st.symbols[name]
+	return sym, ok
+}
+
 // Resolve busca un símbolo
 func (st *SymbolTable) Resolve(name string) (*Symbol, bool) {
 	if sym, ok := st.symbols[name]; ok {
@@ -295,10 +402,14 @@ func (st *SymbolTable) Resolve(name string) (*Symbol, bool) {
 type SemanticAnalyzer struct {
 	symbolTable     *SymbolTable
 	zyloErrors      []*ZyloError
+	warnings        []*ZyloError
 	currentFunction *FunctionType
 	inAsyncContext  bool
 	inLoop          bool
 	errorBuilder    *ErrorBuilder
+	modules         *ModuleCache // Módulos locales ya analizados
+	baseDir         string       // Directorio desde el que se resuelven los imports locales
+	importCycle     []string     // Primer import circular encontrado
 }
 
 // NewSemanticAnalyzer crea un analizador semántico
@@ -314,11 +425,112 @@ func NewSemanticAnalyzer() *SemanticAnalyzer {
 	showModule := &ClassType{
 		Name: "show",
 		Methods: map[string]*FunctionType{
-			"log": {ParamTypes: []Type{Any}, ReturnType: NullType}, // Variadic
+			"log":   {ParamTypes: []Type{Any}, ReturnType: NullType}, // Variadic
+			"error": {ParamTypes: []Type{Any}, ReturnType: NullType},
+			"warn":  {ParamTypes: []Type{Any}, ReturnType: NullType},
+			"debug": {ParamTypes: []Type{Any}, ReturnType: NullType},
 		},
 		Fields:  make(map[string]Type),
 	}
 	globalScope.Define("show", showModule)
+	// Módulo "read" para leer de la entrada estándar
+	readModule := &ClassType{
+		Name: "read",
+		Methods: map[string]*FunctionType{
+			"line":  {ParamTypes: []Type{}, ReturnType: StringType},
+			"int":   {ParamTypes: []Type{}, ReturnType: IntType},
+			"float": {ParamTypes: []Type{}, ReturnType: FloatType},
+		},
+		Fields: make(map[string]Type),
+	}
+	globalScope.Define("read", readModule)
+	// Módulo "http" del intérprete
+	httpModule := &ClassType{
+		Name: "http",
+		Methods: map[string]*FunctionType{
+			"get":             {ParamTypes: []Type{StringType}, ReturnType: Any},
+			"post_json":       {ParamTypes: []Type{StringType, Any}, ReturnType: Any},
+			"get_async":       {ParamTypes: []Type{StringType}, ReturnType: Any},
+			"post_json_async": {ParamTypes: []Type{StringType, Any}, ReturnType: Any},
+			"listen":          {ParamTypes: []Type{IntType, Any}, ReturnType: StringType, Optional: 1}, // El handler es opcional si hay rutas
+			"route":           {ParamTypes: []Type{StringType, StringType, Any}, ReturnType: NullType},
+			"stop":            {ParamTypes: []Type{}, ReturnType: StringType},
+		},
+		Fields: make(map[string]Type),
+	}
+	globalScope.Define("http", httpModule)
+	// Módulo "json"
+	jsonModule := &ClassType{
+		Name: "json",
+		Methods: map[string]*FunctionType{
+			"parse":     {ParamTypes: []Type{StringType}, ReturnType: Any},
+			"stringify": {ParamTypes: []Type{Any}, ReturnType: StringType},
+		},
+		Fields: make(map[string]Type),
+	}
+	globalScope.Define("json", jsonModule)
+	// Módulo "fs" del intérprete
+	globalScope.Define("fs", &ClassType{
+		Name: "fs",
+		Methods: map[string]*FunctionType{
+			"read":    {ParamTypes: []Type{StringType}, ReturnType: StringType},
+			"write":   {ParamTypes: []Type{StringType, StringType}, ReturnType: NullType},
+			"append":  {ParamTypes: []Type{StringType, StringType}, ReturnType: NullType},
+			"exists":  {ParamTypes: []Type{StringType}, ReturnType: BoolType},
+			"listdir": {ParamTypes: []Type{StringType}, ReturnType: &ListType{ElementType: StringType}},
+		},
+		Fields: make(map[string]Type),
+	})
+	// Módulo "os" del intérprete
+	globalScope.Define("os", &ClassType{
+		Name: "os",
+		Methods: map[string]*FunctionType{
+			"getenv": {ParamTypes: []Type{StringType}, ReturnType: Any}, // string o null
+			"setenv": {ParamTypes: []Type{StringType, StringType}, ReturnType: NullType},
+			"args":   {ParamTypes: []Type{}, ReturnType: &ListType{ElementType: StringType}},
+			"exit":   {ParamTypes: []Type{IntType}, ReturnType: NullType},
+		},
+		Fields: make(map[string]Type),
+	})
+	// Módulo "time" del intérprete
+	globalScope.Define("time", &ClassType{
+		Name:    "time",
+		Methods: timeModuleMethods(),
+		Fields:  make(map[string]Type),
+	})
+	// Módulo "random" del intérprete
+	globalScope.Define("random", &ClassType{
+		Name: "random",
+		Methods: map[string]*FunctionType{
+			"seed":    {ParamTypes: []Type{IntType}, ReturnType: NullType},
+			"int":     {ParamTypes: []Type{IntType, IntType}, ReturnType: IntType},
+			"float":   {ParamTypes: []Type{}, ReturnType: FloatType},
+			"choice":  {ParamTypes: []Type{&ListType{ElementType: Any}}, ReturnType: Any},
+			"shuffle": {ParamTypes: []Type{&ListType{ElementType: Any}}, ReturnType: &ListType{ElementType: Any}},
+		},
+		Fields: make(map[string]Type),
+	})
+	// Módulo "math" (sin import)
+	mathModule := &ClassType{
+		Name:    "math",
+		Methods: make(map[string]*FunctionType),
+		Fields:  map[string]Type{"pi": FloatType, "e": FloatType, "PI": FloatType, "E": FloatType},
+	}
+	for _, name := range []string{"sqrt", "abs", "floor", "ceil", "round", "sin", "cos", "tan"} {
+		mathModule.Methods[name] = &FunctionType{ParamTypes: []Type{FloatType}, ReturnType: FloatType}
+	}
+	for _, name := range []string{"pow", "power", "min", "max"} {
+		mathModule.Methods[name] = &FunctionType{ParamTypes: []Type{FloatType, FloatType}, ReturnType: FloatType}
+	}
+	globalScope.Define("math", mathModule)
+	// Las versiones globales de abs, round, floor y ceil devuelven el tipo que
+	// reciben: int con un int y float con un float
+	for _, name := range []string{"abs", "round", "floor", "ceil"} {
+		globalScope.Define(name, &FunctionType{ParamTypes: []Type{FloatType}, ReturnType: Any})
+	}
+	globalScope.Define("sqrt", &FunctionType{ParamTypes: []Type{FloatType}, ReturnType: FloatType})
+	globalScope.Define("pow", &FunctionType{ParamTypes: []Type{FloatType, FloatType}, ReturnType: FloatType})
+	globalScope.Define("floor_div", &FunctionType{ParamTypes: []Type{FloatType, FloatType}, ReturnType: Any})
 	globalScope.Define("print", &FunctionType{
 		ParamTypes: []Type{Any},
 		ReturnType: NullType,
@@ -331,14 +543,46 @@ func NewSemanticAnalyzer() *SemanticAnalyzer {
 		ParamTypes: []Type{},
 		ReturnType: IntType,
 	})
+	globalScope.Define("read.float", &FunctionType{
+		ParamTypes: []Type{},
+		ReturnType: FloatType,
+	})
 	globalScope.Define("string", &FunctionType{
 		ParamTypes: []Type{Any},
 		ReturnType: StringType,
 	})
+	globalScope.Define("int", &FunctionType{
+		ParamTypes: []Type{Any},
+		ReturnType: IntType,
+	})
+	globalScope.Define("float", &FunctionType{
+		ParamTypes: []Type{Any},
+		ReturnType: FloatType,
+	})
+	globalScope.Define("bool", &FunctionType{
+		ParamTypes: []Type{Any},
+		ReturnType: BoolType,
+	})
 	globalScope.Define("println", &FunctionType{
 		ParamTypes: []Type{Any}, // Variadic
 		ReturnType: NullType,
 	})
+	globalScope.Define("set", &FunctionType{
+		ParamTypes: []Type{Any},
+		ReturnType: &SetType{ElementType: Any},
+		Optional:   1,
+	})
+	// sum, min y max devuelven int o float según los elementos
+	for _, name := range []string{"sum", "min", "max"} {
+		globalScope.Define(name, &FunctionType{
+			ParamTypes: []Type{Any},
+			ReturnType: Any,
+		})
+	}
+	globalScope.Define("avg", &FunctionType{
+		ParamTypes: []Type{Any},
+		ReturnType: FloatType,
+	})
 	globalScope.Define("len", &FunctionType{
 		ParamTypes: []Type{Any},
 		ReturnType: IntType,
@@ -347,17 +591,104 @@ func NewSemanticAnalyzer() *SemanticAnalyzer {
 		ParamTypes: []Type{StringType, StringType},
 		ReturnType: &ListType{ElementType: StringType},
 	})
+	// Funciones de string de zyloruntime
+	globalScope.Define("join", &FunctionType{
+		ParamTypes: []Type{Any, StringType},
+		ReturnType: StringType,
+	})
+	globalScope.Define("replace", &FunctionType{
+		ParamTypes: []Type{StringType, StringType, StringType},
+		ReturnType: StringType,
+	})
+	for _, name := range []string{"trim", "to_upper", "to_lower"} {
+		globalScope.Define(name, &FunctionType{
+			ParamTypes: []Type{StringType},
+			ReturnType: StringType,
+		})
+	}
+	for _, name := range []string{"contains", "starts_with", "ends_with"} {
+		globalScope.Define(name, &FunctionType{
+			ParamTypes: []Type{StringType, StringType},
+			ReturnType: BoolType,
+		})
+	}
+	stringBuilderType := &ClassType{
+		Name: "StringBuilder",
+		Methods: map[string]*FunctionType{
+			"append": {ParamTypes: []Type{Any}, ReturnType: Any}, // Variadic
+			"build":  {ParamTypes: []Type{}, ReturnType: StringType},
+			"reset":  {ParamTypes: []Type{}, ReturnType: Any},
+		},
+		Fields: map[string]Type{"length": IntType},
+	}
+	globalScope.Define("string_builder", &FunctionType{
+		ParamTypes: []Type{},
+		ReturnType: stringBuilderType,
+	})
+	globalScope.Define("assert", &FunctionType{
+		ParamTypes: []Type{Any}, // Variadic
+		ReturnType: NullType,
+	})
+	globalScope.Define("assert_eq", &FunctionType{
+		ParamTypes: []Type{Any}, // Variadic
+		ReturnType: NullType,
+	})
+	globalScope.Define("fields", &FunctionType{
+		ParamTypes: []Type{Any},
+		ReturnType: &MapType{KeyType: StringType, ValueType: Any},
+	})
+	globalScope.Define("methods", &FunctionType{
+		ParamTypes: []Type{Any},
+		ReturnType: &ListType{ElementType: StringType},
+	})
+	globalScope.Define("memoize", &FunctionType{
+		ParamTypes: []Type{Any},
+		ReturnType: Any,
+	})
+	globalScope.Define("compose", &FunctionType{
+		ParamTypes: []Type{Any, Any},
+		ReturnType: Any,
+	})
+	globalScope.Define("partial", &FunctionType{
+		ParamTypes: []Type{Any}, // Variadic
+		ReturnType: Any,
+	})
+	globalScope.Define("await_timeout", &FunctionType{
+		ParamTypes: []Type{Any, IntType},
+		ReturnType: Any,
+	})
+	globalScope.Define("format", &FunctionType{
+		ParamTypes: []Type{StringType, Any},
+		ReturnType: StringType,
+		Variadic:   true,
+	})
+	globalScope.Define("await_all", &FunctionType{
+		ParamTypes: []Type{Any},
+		ReturnType: &ListType{ElementType: Any},
+	})
 	globalScope.Define("to_number", &FunctionType{
 		ParamTypes: []Type{StringType},
 		ReturnType: FloatType,
 	})
 
+	// Biblioteca estándar de zyloruntime, disponible también en el intérprete
+	for name := range zyloruntime.GetExtendedBuiltins() {
+		if _, exists := globalScope.Resolve(name); !exists {
+			globalScope.Define(name, &FunctionType{
+				ParamTypes: []Type{Any}, // Variadic
+				ReturnType: Any,
+			})
+		}
+	}
+
 	return &SemanticAnalyzer{
 		symbolTable:     globalScope,
 		zyloErrors:      []*ZyloError{},
 		inAsyncContext:  false,
 		inLoop:          false,
 		errorBuilder:    NewErrorBuilder("analysis"),
+		modules:         NewModuleCache(),
+		baseDir:         ".",
 	}
 }
 
@@ -368,6 +699,7 @@ func (sa *SemanticAnalyzer) Analyze(node ast.Node) Type {
 		for _, stmt := range n.Statements {
 			sa.Analyze(stmt)
 		}
+		sa.collectUnused(sa.symbolTable)
 		return nil
 
 	case *ast.VarStatement:
@@ -375,6 +707,11 @@ func (sa *SemanticAnalyzer) Analyze(node ast.Node) Type {
 
 	case *ast.ImportStatement:
 		return sa.analyzeImportStatement(n)
+	case *ast.ExportStatement:
+		if n.Declaration == nil {
+			return nil
+		}
+		return sa.Analyze(n.Declaration)
 	case *ast.FuncStatement:
 		return sa.analyzeFuncStatement(n)
 
@@ -387,6 +724,9 @@ func (sa *SemanticAnalyzer) Analyze(node ast.Node) Type {
 	case *ast.WhileStatement:
 		return sa.analyzeWhileStatement(n)
 
+	case *ast.RepeatStatement:
+		return sa.analyzeRepeatStatement(n)
+
 	case *ast.ForStatement:
 		return sa.analyzeForStatement(n)
 	case *ast.CollectionMethodCall:
@@ -408,6 +748,14 @@ func (sa *SemanticAnalyzer) Analyze(node ast.Node) Type {
 
 	case *ast.ClassStatement:
 		return sa.analyzeClassStatement(n)
+	case *ast.EnumStatement:
+		return sa.analyzeEnumStatement(n)
+	case *ast.StructStatement:
+		return sa.analyzeStructStatement(n)
+	case *ast.SwitchStatement:
+		return sa.analyzeSwitchStatement(n)
+	case *ast.MatchStatement:
+		return sa.analyzeMatchStatement(n)
 
 	case *ast.ExpressionStatement:
 		if n.Expression != nil {
@@ -445,6 +793,12 @@ func (sa *SemanticAnalyzer) Analyze(node ast.Node) Type {
 	case *ast.ListLiteral:
 		return sa.analyzeListLiteral(n)
 
+	case *ast.SetLiteral:
+		return sa.analyzeSetLiteral(n)
+
+	case *ast.StructLiteral:
+		return sa.analyzeStructLiteral(n)
+
 	case *ast.MapLiteral:
 		return sa.analyzeMapLiteral(n)
 
@@ -454,6 +808,14 @@ func (sa *SemanticAnalyzer) Analyze(node ast.Node) Type {
 	case *ast.DotExpression:
 		return sa.analyzeDotExpression(n)
 
+	case *ast.OptionalDotExpression:
+		// El resultado puede ser null, así que no se conoce su tipo
+		sa.Analyze(n.Left)
+		if n.Index != nil {
+			sa.Analyze(n.Index)
+		}
+		return Any
+
 	case *ast.IndexExpression:
 		return sa.analyzeIndexExpression(n)
 
@@ -463,8 +825,16 @@ func (sa *SemanticAnalyzer) Analyze(node ast.Node) Type {
 	case *ast.PrefixExpression:
 		return sa.analyzePrefixExpression(n)
 
+	case *ast.IsExpression:
+		return sa.analyzeIsExpression(n)
+
 	case *ast.AssignmentExpression:
 		return sa.analyzeAssignmentExpression(n)
+	case *ast.DestructuringAssignmentExpression:
+		return sa.analyzeDestructuringAssignment(n)
+
+	case *ast.IfExpression:
+		return sa.analyzeIfExpression(n)
 
 	default:
 		return Any
@@ -484,6 +854,18 @@ func (sa *SemanticAnalyzer) analyzeVarStatement(stmt *ast.VarStatement) Type {
 		valueType = sa.Analyze(stmt.Value)
 	}
 
+	// El valor de un const se calcula aquí y sustituye a la expresión
+	var constValue ast.Expression
+	if stmt.Token.Type == lexer.CONST && stmt.Value != nil {
+		folded, err := sa.foldConstant(stmt.Value)
+		if err != nil {
+			sa.addError(stmt.Name.Token, fmt.Sprintf("const %s: %v", stmt.Name.Value, err))
+		} else {
+			stmt.Value = folded
+			constValue = folded
+		}
+	}
+
 	if expectedType == Any {
 		expectedType = valueType
 	}
@@ -492,44 +874,91 @@ func (sa *SemanticAnalyzer) analyzeVarStatement(stmt *ast.VarStatement) Type {
 		sa.addError(stmt.Token, fmt.Sprintf("no se puede asignar %s a variable de tipo %s", valueType, expectedType))
 	}
 
-	sa.symbolTable.Define(stmt.Name.Value, expectedType)
+	// Una variable solo se declara una vez por scope; en un scope anidado sí
+	// puede ocultar a la de fuera
+	if previous, ok := sa.symbolTable.ResolveLocal(stmt.Name.Value); ok && previous.Variable {
+		sa.addZyloError(sa.errorBuilder.DuplicateVarError(stmt.Name.Token, stmt.Name.Value, previous.Token))
+		return nil
+	}
+
+	symbol := sa.symbolTable.Define(stmt.Name.Value, expectedType)
+	symbol.Variable = true
+	symbol.Constant = stmt.IsConstant
+	symbol.ConstValue = constValue
+	symbol.Token = stmt.Name.Token
 	return nil
 }
 
 // analyzeFuncStatement analiza declaración de función
 func (sa *SemanticAnalyzer) analyzeFuncStatement(stmt *ast.FuncStatement) Type {
-	paramTypes := make([]Type, len(stmt.Parameters))
-	for i, p := range stmt.Parameters {
-		if p.TypeAnnotation != "" {
-			paramTypes[i] = sa.stringToType(p.Token, p.TypeAnnotation)
-		} else {
-			paramTypes[i] = Any
-		}
-	}
-
-	var returnType Type = Any
-	if stmt.ReturnType != "" {
-		returnType = sa.stringToType(stmt.Token, stmt.ReturnType)
-	}
-
-	funcType := &FunctionType{ParamTypes: paramTypes, ReturnType: returnType}
+	funcType := sa.functionType(stmt.Token, stmt.Parameters, stmt.ReturnType)
 	sa.symbolTable.Define(stmt.Name.Value, funcType)
 
 	sa.enterFunctionScope(stmt.Name.Value)
 	previousFunction := sa.currentFunction
 	sa.currentFunction = funcType
 
+	// Los valores por defecto se analizan en el ámbito de la función porque
+	// pueden usar los parámetros anteriores
 	for i, p := range stmt.Parameters {
-		sa.symbolTable.Define(p.Value, paramTypes[i])
+		paramType := funcType.ParamTypes[i]
+		if p.Default != nil {
+			if defaultType := sa.Analyze(p.Default); !sa.isAssignable(paramType, defaultType) {
+				sa.addError(p.Token, fmt.Sprintf("valor por defecto de %s: esperado %s, obtenido %s", p.Value, paramType, defaultType))
+			}
+		}
+		if p.Variadic {
+			paramType = &ListType{ElementType: paramType}
+		}
+		sa.symbolTable.Define(p.Value, paramType)
 	}
 
 	sa.Analyze(stmt.Body)
+	if funcType.ReturnType != Any && funcType.ReturnType != NullType && !blockAlwaysReturns(stmt.Body) {
+		sa.addZyloError(sa.errorBuilder.MissingReturnError(stmt.Name.Token, stmt.Name.Value, funcType.ReturnType))
+	}
 
 	sa.currentFunction = previousFunction
 	sa.exitFunctionScope()
+
+	// Un decorador puede devolver cualquier callable, así que la firma
+	// declarada deja de ser fiable
+	if len(stmt.Decorators) > 0 {
+		sa.analyzeDecorators(stmt.Decorators)
+		sa.symbolTable.Define(stmt.Name.Value, Any)
+	}
 	return nil
 }
 
+// functionType construye el tipo de una función a partir de los tipos
+// declarados de sus parámetros y de su tipo de retorno
+func (sa *SemanticAnalyzer) functionType(token lexer.Token, params []*ast.Identifier, returnType string) *FunctionType {
+	ft := &FunctionType{ParamTypes: make([]Type, len(params)), ParamNames: make([]string, len(params)), ReturnType: Any}
+	for i, p := range params {
+		ft.ParamNames[i] = p.Value
+		if p.TypeAnnotation != "" {
+			ft.ParamTypes[i] = sa.stringToType(p.Token, p.TypeAnnotation)
+		} else {
+			ft.ParamTypes[i] = Any
+		}
+		if p.Default != nil {
+			ft.Optional++
+		}
+		ft.Variadic = p.Variadic
+	}
+	if returnType != "" {
+		ft.ReturnType = sa.stringToType(token, returnType)
+	}
+	return ft
+}
+
+// analyzeDecorators analiza las expresiones de los decoradores de una declaración
+func (sa *SemanticAnalyzer) analyzeDecorators(decorators []ast.Expression) {
+	for _, decorator := range decorators {
+		sa.Analyze(decorator)
+	}
+}
+
 // analyzeReturnStatement analiza return
 func (sa *SemanticAnalyzer) analyzeReturnStatement(stmt *ast.ReturnStatement) Type {
 	if sa.currentFunction == nil {
@@ -551,6 +980,41 @@ func (sa *SemanticAnalyzer) analyzeReturnStatement(stmt *ast.ReturnStatement) Ty
 }
 
 // analyzeIfStatement analiza if
+// analyzeIfExpression analiza un if usado como expresión. Su tipo es el del
+// último valor de las ramas si coincide en las dos; sin else puede ser null.
+func (sa *SemanticAnalyzer) analyzeIfExpression(exp *ast.IfExpression) Type {
+	condType := sa.Analyze(exp.Condition)
+	if condType != BoolType && condType != Any {
+		sa.addError(exp.Token, "condición debe ser booleana")
+	}
+
+	consequence := sa.analyzeBranch(exp.Consequence)
+	if exp.Alternative == nil {
+		return Any
+	}
+	alternative := sa.analyzeBranch(exp.Alternative)
+	if consequence.Equals(alternative) {
+		return consequence
+	}
+	return Any
+}
+
+// analyzeBranch analiza una rama de un if expresión y devuelve el tipo de su
+// último valor
+func (sa *SemanticAnalyzer) analyzeBranch(block *ast.BlockStatement) Type {
+	sa.enterScope("block")
+	defer sa.exitScope()
+
+	var last Type = NullType
+	for _, stmt := range block.Statements {
+		last = sa.Analyze(stmt)
+		if last == nil {
+			last = Any
+		}
+	}
+	return last
+}
+
 func (sa *SemanticAnalyzer) analyzeIfStatement(stmt *ast.IfStatement) Type {
 	condType := sa.Analyze(stmt.Condition)
 	if condType != BoolType && condType != Any {
@@ -558,6 +1022,13 @@ func (sa *SemanticAnalyzer) analyzeIfStatement(stmt *ast.IfStatement) Type {
 	}
 
 	sa.Analyze(stmt.Consequence)
+	for _, clause := range stmt.ElseIfs {
+		condType := sa.Analyze(clause.Condition)
+		if condType != BoolType && condType != Any {
+			sa.addError(clause.Token, "condición debe ser booleana")
+		}
+		sa.Analyze(clause.Consequence)
+	}
 	if stmt.Alternative != nil {
 		sa.Analyze(stmt.Alternative)
 	}
@@ -578,8 +1049,26 @@ func (sa *SemanticAnalyzer) analyzeWhileStatement(stmt *ast.WhileStatement) Type
 	return nil
 }
 
+// analyzeRepeatStatement analiza repeat; el número de vueltas es un int
+func (sa *SemanticAnalyzer) analyzeRepeatStatement(stmt *ast.RepeatStatement) Type {
+	countType := sa.Analyze(stmt.Count)
+	if countType != IntType && countType != Any {
+		sa.addError(stmt.Token, fmt.Sprintf("repeat espera un int, obtenido %s", countType))
+	}
+
+	wasInLoop := sa.inLoop
+	sa.inLoop = true
+	sa.Analyze(stmt.Body)
+	sa.inLoop = wasInLoop
+	return nil
+}
+
 // analyzeForStatement analiza bucle for tradicional
 func (sa *SemanticAnalyzer) analyzeForStatement(stmt *ast.ForStatement) Type {
+	// La variable de la inicialización solo existe dentro del bucle
+	sa.enterScope("for")
+	defer sa.exitScope()
+
 	// Analizar la inicialización
 	if stmt.Init != nil {
 		sa.Analyze(stmt.Init)
@@ -614,10 +1103,12 @@ func (sa *SemanticAnalyzer) analyzeForInStatement(stmt *ast.ForInStatement) Type
 	var elementType Type = Any
 	if listType, ok := iterableType.(*ListType); ok {
 		elementType = listType.ElementType
+	} else if setType, ok := iterableType.(*SetType); ok {
+		elementType = setType.ElementType
 	} else if iterableType == StringType {
 		elementType = StringType
 	} else if iterableType != Any {
-		sa.addError(stmt.Token, "for-in requiere lista o string")
+		sa.addError(stmt.Token, "for-in requiere lista, set o string")
 	}
 
 	sa.enterScope("for-in")
@@ -668,32 +1159,130 @@ func (sa *SemanticAnalyzer) analyzeClassStatement(stmt *ast.ClassStatement) Type
 	}
 
 	for _, method := range stmt.Methods {
-		paramTypes := make([]Type, len(method.Parameters))
-		for i, p := range method.Parameters {
-			if p.TypeAnnotation != "" {
-				paramTypes[i] = sa.stringToType(p.Token, p.TypeAnnotation)
-			} else {
-				paramTypes[i] = Any
-			}
+		classType.Methods[method.Name.Value] = sa.functionType(method.Token, method.Parameters, method.ReturnType)
+	}
+
+	sa.exitScope()
+	sa.analyzeDecorators(stmt.Decorators)
+	sa.symbolTable.Define(stmt.Name.Value, classType)
+	return nil
+}
+
+// analyzeEnumStatement analiza enum
+func (sa *SemanticAnalyzer) analyzeEnumStatement(stmt *ast.EnumStatement) Type {
+	enumType := &EnumType{Name: stmt.Name.Value}
+	for _, member := range stmt.Members {
+		enumType.Members = append(enumType.Members, member.Value)
+	}
+	sa.symbolTable.Define(stmt.Name.Value, enumType)
+	return nil
+}
+
+// analyzeStructStatement analiza struct; se registra como una clase sin
+// métodos
+func (sa *SemanticAnalyzer) analyzeStructStatement(stmt *ast.StructStatement) Type {
+	structType := &ClassType{
+		Name:     stmt.Name.Value,
+		Methods:  make(map[string]*FunctionType),
+		Fields:   make(map[string]Type),
+		IsStruct: true,
+	}
+	// Se define antes de los campos para que un campo pueda ser del mismo struct
+	sa.symbolTable.Define(stmt.Name.Value, structType)
+	for _, field := range stmt.Fields {
+		structType.Fields[field.Value] = sa.stringToType(field.Token, field.TypeAnnotation)
+	}
+	return nil
+}
+
+// analyzeStructLiteral analiza Nombre{campo: valor}: hay que dar todos los
+// campos del struct, solo esos, y con su tipo
+func (sa *SemanticAnalyzer) analyzeStructLiteral(exp *ast.StructLiteral) Type {
+	valueTypes := make([]Type, len(exp.Fields))
+	for i, field := range exp.Fields {
+		valueTypes[i] = sa.Analyze(field.Value)
+	}
+	sym, ok := sa.symbolTable.Resolve(exp.Name.Value)
+	if !ok {
+		sa.addError(exp.Token, fmt.Sprintf("variable no definida: %s", exp.Name.Value))
+		return Any
+	}
+	sym.Used = true
+	structType, ok := sym.Type.(*ClassType)
+	if !ok || !structType.IsStruct {
+		sa.addError(exp.Token, fmt.Sprintf("%s no es un struct", exp.Name.Value))
+		return Any
+	}
+
+	given := make(map[string]bool)
+	for i, field := range exp.Fields {
+		given[field.Name] = true
+		fieldType, exists := structType.Fields[field.Name]
+		if !exists {
+			sa.addError(field.Token, fmt.Sprintf("el struct %s no tiene el campo %s", structType.Name, field.Name))
+			continue
 		}
+		if !sa.isAssignable(fieldType, valueTypes[i]) {
+			sa.addError(field.Token, fmt.Sprintf("campo %s: esperado %s, obtenido %s", field.Name, fieldType, valueTypes[i]))
+		}
+	}
 
-		var returnType Type = Any
-		if method.ReturnType != "" {
-			returnType = sa.stringToType(method.Token, method.ReturnType)
+	var missing []string
+	for name := range structType.Fields {
+		if !given[name] {
+			missing = append(missing, name)
 		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		sa.addError(exp.Token, fmt.Sprintf("falta el campo %s al construir %s", name, structType.Name))
+	}
+	return structType
+}
 
-		funcType := &FunctionType{ParamTypes: paramTypes, ReturnType: returnType}
-		classType.Methods[method.Name.Value] = funcType
+// analyzeSwitchStatement analiza switch; cada case tiene su propio scope
+func (sa *SemanticAnalyzer) analyzeSwitchStatement(stmt *ast.SwitchStatement) Type {
+	sa.Analyze(stmt.Expression)
+	for _, c := range stmt.Cases {
+		if c.Expression != nil {
+			sa.Analyze(c.Expression)
+		}
+		sa.Analyze(c.Body)
 	}
+	return nil
+}
 
-	sa.exitScope()
-	sa.symbolTable.Define(stmt.Name.Value, classType)
+// analyzeMatchStatement analiza match. La variable de un patrón se define
+// en el scope de su case con el tipo del valor comparado.
+func (sa *SemanticAnalyzer) analyzeMatchStatement(stmt *ast.MatchStatement) Type {
+	subjectType := sa.Analyze(stmt.Expression)
+	for _, c := range stmt.Cases {
+		sa.enterScope("case")
+		switch pattern := c.Pattern.(type) {
+		case *ast.VariablePattern:
+			if pattern.Name != nil && pattern.Name.Value != "_" {
+				sa.symbolTable.Define(pattern.Name.Value, subjectType)
+			}
+		case *ast.LiteralPattern:
+			sa.Analyze(pattern.Value)
+		case *ast.TypePattern:
+			if pattern.Variable != nil {
+				sa.symbolTable.Define(pattern.Variable.Value, Any)
+			}
+		}
+		if c.Guard != nil {
+			sa.Analyze(c.Guard)
+		}
+		sa.Analyze(c.Body)
+		sa.exitScope()
+	}
 	return nil
 }
 
 // analyzeIdentifier analiza identificador
 func (sa *SemanticAnalyzer) analyzeIdentifier(exp *ast.Identifier) Type {
 	if sym, ok := sa.symbolTable.Resolve(exp.Value); ok {
+		sym.Used = true
 		return sym.Type
 	}
 	sa.addError(exp.Token, fmt.Sprintf("variable no definida: %s", exp.Value))
@@ -716,25 +1305,58 @@ func (sa *SemanticAnalyzer) analyzeListLiteral(exp *ast.ListLiteral) Type {
 	return &ListType{ElementType: firstType}
 }
 
+// setMethods son los métodos disponibles para sets
+var setMethods = map[string]bool{
+	"add": true, "remove": true, "has": true, "union": true,
+	"intersection": true, "difference": true, "to_list": true, "length": true,
+}
+
+// analyzeSetLiteral analiza literal de set; como en las listas, elementos de
+// tipos distintos dan un set de Any
+func (sa *SemanticAnalyzer) analyzeSetLiteral(exp *ast.SetLiteral) Type {
+	var elementType Type = Any
+	for i, elem := range exp.Elements {
+		elemType := sa.Analyze(elem)
+		if i == 0 {
+			elementType = elemType
+		} else if !elementType.Equals(elemType) && elemType != Any && elementType != Any {
+			elementType = Any
+		}
+	}
+	return &SetType{ElementType: elementType}
+}
+
 // analyzeMapLiteral analiza literal de mapa
 func (sa *SemanticAnalyzer) analyzeMapLiteral(exp *ast.MapLiteral) Type {
 	if len(exp.Pairs) == 0 {
 		return &MapType{KeyType: Any, ValueType: Any}
 	}
 
-	var keyType, valueType Type = StringType, Any
-	for _, v := range exp.Pairs {
-		vType := sa.Analyze(v)
-		if valueType == Any {
-			valueType = vType
+	// Como en las listas, valores de tipos distintos dan un mapa de Any
+	valueType := sa.Analyze(exp.Pairs[0].Value)
+	for _, pair := range exp.Pairs[1:] {
+		vType := sa.Analyze(pair.Value)
+		if !valueType.Equals(vType) && vType != Any && valueType != Any {
+			valueType = Any
 		}
 	}
-	return &MapType{KeyType: keyType, ValueType: valueType}
+	return &MapType{KeyType: StringType, ValueType: valueType}
 }
 
 // analyzeCallExpression analiza llamada a función
 func (sa *SemanticAnalyzer) analyzeCallExpression(exp *ast.CallExpression) Type {
 	funcType := sa.Analyze(exp.Function)
+	if module, ok := funcType.(*ClassType); ok && module.Call != nil {
+		funcType = module.Call
+	}
+
+	if ft, ok := funcType.(*FunctionType); ok && len(exp.NamedArguments) > 0 {
+		sa.analyzeNamedCall(exp, ft)
+		return ft.ReturnType
+	}
+	for _, arg := range exp.NamedArguments {
+		sa.Analyze(arg.Value)
+	}
 
 	if ft, ok := funcType.(*FunctionType); ok {
 		// Handle variadic functions (show.log accepts any number of Any arguments)
@@ -745,13 +1367,30 @@ func (sa *SemanticAnalyzer) analyzeCallExpression(exp *ast.CallExpression) Type
 			}
 		} else {
 			// Regular function - check argument count and types
-			if len(exp.Arguments) != len(ft.ParamTypes) {
-				sa.addError(exp.Token, fmt.Sprintf("esperados %d argumentos, recibidos %d", len(ft.ParamTypes), len(exp.Arguments)))
+			fixed := len(ft.ParamTypes)
+			if ft.Variadic {
+				fixed--
+			}
+			required := fixed - ft.Optional
+			if len(exp.Arguments) < required || (!ft.Variadic && len(exp.Arguments) > fixed) {
+				switch {
+				case ft.Variadic:
+					sa.addError(exp.Token, fmt.Sprintf("esperados al menos %d argumentos, recibidos %d", required, len(exp.Arguments)))
+				case ft.Optional > 0:
+					sa.addError(exp.Token, fmt.Sprintf("esperados entre %d y %d argumentos, recibidos %d", required, fixed, len(exp.Arguments)))
+				default:
+					sa.addError(exp.Token, fmt.Sprintf("esperados %d argumentos, recibidos %d", fixed, len(exp.Arguments)))
+				}
 			} else {
 				for i, arg := range exp.Arguments {
+					// Los argumentos sobrantes son del tipo del parámetro variádico
+					paramType := ft.ParamTypes[len(ft.ParamTypes)-1]
+					if i < len(ft.ParamTypes) {
+						paramType = ft.ParamTypes[i]
+					}
 					argType := sa.Analyze(arg)
-					if !sa.isAssignable(ft.ParamTypes[i], argType) {
-						sa.addError(exp.Token, fmt.Sprintf("argumento %d: esperado %s, obtenido %s", i+1, ft.ParamTypes[i], argType))
+					if !sa.isAssignable(paramType, argType) {
+						sa.addError(exp.Token, fmt.Sprintf("argumento %d: esperado %s, obtenido %s", i+1, paramType, argType))
 					}
 				}
 			}
@@ -766,11 +1405,94 @@ func (sa *SemanticAnalyzer) analyzeCallExpression(exp *ast.CallExpression) Type
 	return Any
 }
 
+// analyzeNamedCall analiza una llamada con argumentos con nombre: cada nombre
+// debe ser un parámetro que no reciba ya un argumento posicional, y todos los
+// parámetros sin valor por defecto deben recibir un argumento
+func (sa *SemanticAnalyzer) analyzeNamedCall(exp *ast.CallExpression, ft *FunctionType) {
+	if ft.ParamNames == nil {
+		sa.addError(exp.Token, "la función no admite argumentos con nombre")
+		for _, arg := range exp.Arguments {
+			sa.Analyze(arg)
+		}
+		for _, arg := range exp.NamedArguments {
+			sa.Analyze(arg.Value)
+		}
+		return
+	}
+
+	fixed := len(ft.ParamTypes)
+	if ft.Variadic {
+		fixed--
+	}
+	provided := make([]bool, len(ft.ParamTypes))
+	for i, arg := range exp.Arguments {
+		argType := sa.Analyze(arg)
+		if i >= fixed && !ft.Variadic {
+			sa.addError(exp.Token, fmt.Sprintf("esperados como mucho %d argumentos posicionales, recibidos %d", fixed, len(exp.Arguments)))
+			continue
+		}
+		index := i
+		if index >= fixed {
+			index = len(ft.ParamTypes) - 1
+		} else {
+			provided[index] = true
+		}
+		if !sa.isAssignable(ft.ParamTypes[index], argType) {
+			sa.addError(exp.Token, fmt.Sprintf("argumento %d: esperado %s, obtenido %s", i+1, ft.ParamTypes[index], argType))
+		}
+	}
+
+	for _, arg := range exp.NamedArguments {
+		argType := sa.Analyze(arg.Value)
+		index := -1
+		for i, name := range ft.ParamNames {
+			if name == arg.Name {
+				index = i
+				break
+			}
+		}
+		switch {
+		case index < 0:
+			sa.addError(arg.Token, fmt.Sprintf("parámetro desconocido: %s", arg.Name))
+		case index >= fixed:
+			sa.addError(arg.Token, fmt.Sprintf("el parámetro variádico %s no se puede pasar por nombre", arg.Name))
+		case provided[index]:
+			sa.addError(arg.Token, fmt.Sprintf("el argumento %s se pasó por posición y por nombre", arg.Name))
+		default:
+			provided[index] = true
+			if !sa.isAssignable(ft.ParamTypes[index], argType) {
+				sa.addError(arg.Token, fmt.Sprintf("argumento %s: esperado %s, obtenido %s", arg.Name, ft.ParamTypes[index], argType))
+			}
+		}
+	}
+
+	for i := 0; i < fixed-ft.Optional; i++ {
+		if !provided[i] {
+			sa.addError(exp.Token, fmt.Sprintf("falta el argumento %s", ft.ParamNames[i]))
+		}
+	}
+}
+
 // analyzeDotExpression analiza expresión de punto
 func (sa *SemanticAnalyzer) analyzeDotExpression(exp *ast.DotExpression) Type {
 	objType := sa.Analyze(exp.Left)
 
+	if enumType, ok := objType.(*EnumType); ok {
+		if !enumType.HasMember(exp.Property.Value) {
+			sa.addError(exp.Property.Token, fmt.Sprintf("el enum %s no tiene el miembro %s", enumType.Name, exp.Property.Value))
+		}
+		return enumType
+	}
+
 	if classType, ok := objType.(*ClassType); ok {
+		if classType.IsStruct {
+			fieldType, exists := classType.Fields[exp.Property.Value]
+			if !exists {
+				sa.addError(exp.Property.Token, fmt.Sprintf("el struct %s no tiene el campo %s", classType.Name, exp.Property.Value))
+				return Any
+			}
+			return fieldType
+		}
 		// Check if this is an imported module (e.g., math.sqrt)
 		if _, exists := classType.Methods[exp.Property.Value]; exists {
 			return classType.Methods[exp.Property.Value]
@@ -802,6 +1524,13 @@ func (sa *SemanticAnalyzer) analyzeIndexExpression(exp *ast.IndexExpression) Typ
 	leftType := sa.Analyze(exp.Left)
 	indexType := sa.Analyze(exp.Index)
 
+	if mapType, ok := leftType.(*MapType); ok {
+		if indexType != Any && mapType.KeyType != Any && !indexType.Equals(mapType.KeyType) {
+			sa.addError(exp.Token, "clave de mapa debe ser string")
+		}
+		return mapType.ValueType
+	}
+
 	if indexType != IntType && indexType != Any {
 		sa.addError(exp.Token, "índice debe ser entero")
 	}
@@ -809,9 +1538,6 @@ func (sa *SemanticAnalyzer) analyzeIndexExpression(exp *ast.IndexExpression) Typ
 	if listType, ok := leftType.(*ListType); ok {
 		return listType.ElementType
 	}
-	if mapType, ok := leftType.(*MapType); ok {
-		return mapType.ValueType
-	}
 	if leftType == StringType {
 		return StringType
 	}
@@ -849,9 +1575,41 @@ func (sa *SemanticAnalyzer) analyzePrefixExpression(exp *ast.PrefixExpression) T
 	return Any
 }
 
+// isTypeNames son los tipos básicos que admite el operador is
+var isTypeNames = map[string]bool{
+	"int": true, "integer": true, "float": true, "float64": true, "number": true,
+	"string": true, "bool": true, "boolean": true, "list": true, "map": true,
+	"set": true, "null": true, "nil": true, "func": true, "function": true, "any": true,
+}
+
+// analyzeIsExpression analiza value is Tipo. El tipo tiene que ser básico o
+// el nombre de una clase, un struct o un enum.
+func (sa *SemanticAnalyzer) analyzeIsExpression(exp *ast.IsExpression) Type {
+	sa.Analyze(exp.Left)
+
+	if isTypeNames[strings.ToLower(exp.TypeName)] {
+		return BoolType
+	}
+	sym, ok := sa.symbolTable.Resolve(exp.TypeName)
+	if !ok {
+		sa.addError(exp.Token, fmt.Sprintf("tipo desconocido: %s", exp.TypeName))
+		return BoolType
+	}
+	switch sym.Type.(type) {
+	case *ClassType, *EnumType:
+	default:
+		sa.addError(exp.Token, fmt.Sprintf("%s no es un tipo", exp.TypeName))
+	}
+	return BoolType
+}
+
 // analyzeAssignmentExpression analiza asignación
 func (sa *SemanticAnalyzer) analyzeAssignmentExpression(exp *ast.AssignmentExpression) Type {
-	targetType := sa.Analyze(exp.Name)
+	if root := sa.constantRoot(exp.Name); root != nil {
+		sa.addError(root.Token, fmt.Sprintf("no se puede reasignar constante: %s", root.Value))
+	}
+
+	targetType := sa.analyzeAssignmentTarget(exp.Name, exp.Operator)
 	valueType := sa.Analyze(exp.Value)
 
 	if !sa.isAssignable(targetType, valueType) {
@@ -861,6 +1619,79 @@ func (sa *SemanticAnalyzer) analyzeAssignmentExpression(exp *ast.AssignmentExpre
 	return targetType
 }
 
+// analyzeAssignmentTarget devuelve el tipo del destino de una asignación
+func (sa *SemanticAnalyzer) analyzeAssignmentTarget(target ast.Expression, operator string) Type {
+	ident, ok := target.(*ast.Identifier)
+	if !ok || operator != "=" {
+		return sa.Analyze(target)
+	}
+	// Asignar no cuenta como uso de la variable
+	if sym, ok := sa.symbolTable.Resolve(ident.Value); ok {
+		return sym.Type
+	}
+	sa.addError(ident.Token, fmt.Sprintf("variable no definida: %s", ident.Value))
+	return Any
+}
+
+// analyzeDestructuringAssignment analiza a, b = lista. Con una lista literal
+// a la derecha se comprueba el número de valores y el tipo de cada uno.
+func (sa *SemanticAnalyzer) analyzeDestructuringAssignment(exp *ast.DestructuringAssignmentExpression) Type {
+	targetTypes := make([]Type, len(exp.Targets))
+	for i, target := range exp.Targets {
+		if root := sa.constantRoot(target); root != nil {
+			sa.addError(root.Token, fmt.Sprintf("no se puede reasignar constante: %s", root.Value))
+		}
+		targetTypes[i] = sa.analyzeAssignmentTarget(target, exp.Operator)
+	}
+
+	if list, ok := exp.Value.(*ast.ListLiteral); ok {
+		if len(list.Elements) != len(exp.Targets) {
+			sa.addError(exp.Token, fmt.Sprintf("no se puede desestructurar: %d variables para %d valores", len(exp.Targets), len(list.Elements)))
+		}
+		for i, element := range list.Elements {
+			valueType := sa.Analyze(element)
+			if i < len(targetTypes) && !sa.isAssignable(targetTypes[i], valueType) {
+				sa.addError(exp.Token, fmt.Sprintf("no se puede asignar %s a %s", valueType, targetTypes[i]))
+			}
+		}
+		return Any
+	}
+
+	switch valueType := sa.Analyze(exp.Value).(type) {
+	case *ListType:
+		for _, targetType := range targetTypes {
+			if !sa.isAssignable(targetType, valueType.ElementType) {
+				sa.addError(exp.Token, fmt.Sprintf("no se puede asignar %s a %s", valueType.ElementType, targetType))
+			}
+		}
+	default:
+		if valueType != Any {
+			sa.addError(exp.Token, fmt.Sprintf("no se puede desestructurar %s: se esperaba una lista", valueType))
+		}
+	}
+	return Any
+}
+
+// constantRoot devuelve la variable en la raíz de un destino de asignación
+// como a[0].b si es una constante, o nil si no lo es
+func (sa *SemanticAnalyzer) constantRoot(target ast.Expression) *ast.Identifier {
+	for {
+		switch t := target.(type) {
+		case *ast.IndexExpression:
+			target = t.Left
+		case *ast.DotExpression:
+			target = t.Left
+		case *ast.Identifier:
+			if sym, ok := sa.symbolTable.Resolve(t.Value); ok && sym.Constant {
+				return t
+			}
+			return nil
+		default:
+			return nil
+		}
+	}
+}
+
 // Helper functions
 
 func (sa *SemanticAnalyzer) stringToType(token lexer.Token, typeStr string) Type {
@@ -932,14 +1763,27 @@ func (sa *SemanticAnalyzer) areTypesCompatible(left, right Type, op string) bool
 			return true
 		}
 		return sa.isNumericType(left) && sa.isNumericType(right)
-	case "-", "*", "/", "%", "**", "//":
+	case "-", "*", "/", "%", "**":
 		return sa.isNumericType(left) && sa.isNumericType(right)
 	case "==", "!=":
 		return true
 	case "<", "<=", ">", ">=":
+		if left == StringType && right == StringType {
+			return true
+		}
 		return sa.isNumericType(left) && sa.isNumericType(right)
-	case "and", "or", "&&", "||":
+	case "and", "or", "&&", "||", "??":
 		return true
+	case "xor":
+		return left == BoolType && right == BoolType
+	case "&", "|", "^", "<<", ">>":
+		return left == IntType && right == IntType
+	case "in", "not in":
+		switch right.(type) {
+		case *ListType, *MapType, *SetType:
+			return true
+		}
+		return left == StringType && right == StringType
 	}
 
 	return left.Equals(right)
@@ -951,8 +1795,19 @@ func (sa *SemanticAnalyzer) isNumericType(t Type) bool {
 
 func (sa *SemanticAnalyzer) inferInfixReturnType(left, right Type, op string) Type {
 	switch op {
-	case "==", "!=", "<", "<=", ">", ">=", "and", "or", "&&", "||":
+	case "==", "!=", "<", "<=", ">", ">=", "in", "not in", "xor":
 		return BoolType
+	case "&", "|", "^", "<<", ">>":
+		return IntType
+	case "and", "or", "&&", "||", "??":
+		// Devuelven uno de los operandos
+		if left == NullType {
+			return right
+		}
+		if left.Equals(right) {
+			return left
+		}
+		return Any
 	case "+":
 		if left == StringType || right == StringType {
 			return StringType
@@ -961,7 +1816,7 @@ func (sa *SemanticAnalyzer) inferInfixReturnType(left, right Type, op string) Ty
 			return FloatType
 		}
 		return IntType
-	case "-", "*", "/", "%", "**", "//":
+	case "-", "*", "/", "%", "**":
 		if left == FloatType || right == FloatType {
 			return FloatType
 		}
@@ -977,10 +1832,21 @@ func (sa *SemanticAnalyzer) enterScope(name string) {
 
 func (sa *SemanticAnalyzer) exitScope() {
 	if sa.symbolTable.parent != nil {
+		sa.collectUnused(sa.symbolTable)
 		sa.symbolTable = sa.symbolTable.parent
 	}
 }
 
+// collectUnused agrega una advertencia por cada variable del scope que se
+// declaró pero nunca se leyó. Las que empiezan por '_' se ignoran.
+func (sa *SemanticAnalyzer) collectUnused(scope *SymbolTable) {
+	for _, sym := range scope.symbols {
+		if sym.Variable && !sym.Used && !strings.HasPrefix(sym.Name, "_") {
+			sa.warnings = append(sa.warnings, sa.errorBuilder.UnusedVarWarning(sym.Token, sym.Name))
+		}
+	}
+}
+
 func (sa *SemanticAnalyzer) enterFunctionScope(name string) {
 	newScope := NewFunctionSymbolTable(name, sa.symbolTable.scopeLevel+1, sa.symbolTable)
 	sa.symbolTable = newScope
@@ -1000,6 +1866,18 @@ func (sa *SemanticAnalyzer) ZyloErrors() []*ZyloError {
 	return sa.zyloErrors
 }
 
+// Warnings retorna las advertencias, que no impiden ejecutar el programa,
+// ordenadas por posición
+func (sa *SemanticAnalyzer) Warnings() []*ZyloError {
+	sort.SliceStable(sa.warnings, func(i, j int) bool {
+		if sa.warnings[i].Line != sa.warnings[j].Line {
+			return sa.warnings[i].Line < sa.warnings[j].Line
+		}
+		return sa.warnings[i].Column < sa.warnings[j].Column
+	})
+	return sa.warnings
+}
+
 // Errors retorna los errores como strings (para compatibilidad)
 func (sa *SemanticAnalyzer) Errors() []string {
 	strings := make([]string, len(sa.zyloErrors))
@@ -1044,11 +1922,11 @@ func (sa *SemanticAnalyzer) analyzeImportStatement(stmt *ast.ImportStatement) Ty
 			}
 		}
 
-		sa.symbolTable.Define(stmt.ModuleName.Value, moduleType)
+		sa.defineModule(stmt.ModuleName.Value, moduleType)
 	} else if stmt.ModulePath != "" {
 		// Import de path (e.g., import "std/math" or "./local/module")
 		// Intentar resolver tanto stdlib como local paths
-		if resolved := sa.resolveModulePath(stmt.ModulePath); resolved != nil {
+		if resolved := sa.resolveModulePath(stmt.Token, stmt.ModulePath); resolved != nil {
 			moduleType = resolved
 			// Para paths, usar el nombre del archivo como nombre del módulo
 			parts := strings.Split(stmt.ModulePath, "/")
@@ -1057,7 +1935,7 @@ func (sa *SemanticAnalyzer) analyzeImportStatement(stmt *ast.ImportStatement) Ty
 				if moduleName == "" {
 					moduleName = parts[len(parts)-1]
 				}
-				sa.symbolTable.Define(moduleName, moduleType)
+				sa.defineModule(moduleName, moduleType)
 			}
 		} else {
 			sa.addError(stmt.Token, fmt.Sprintf("Módulo no encontrado: %s", stmt.ModulePath))
@@ -1069,6 +1947,22 @@ func (sa *SemanticAnalyzer) analyzeImportStatement(stmt *ast.ImportStatement) Ty
 	return moduleType
 }
 
+// defineModule enlaza el módulo importado con name. Si name ya es una
+// función, como la conversión string(), el módulo se sigue pudiendo llamar
+// como ella: import "std/string" no oculta string(5).
+func (sa *SemanticAnalyzer) defineModule(name string, module *ClassType) {
+	if symbol, ok := sa.symbolTable.Resolve(name); ok {
+		fn, _ := symbol.Type.(*FunctionType)
+		if previous, isModule := symbol.Type.(*ClassType); isModule {
+			fn = previous.Call
+		}
+		if fn != nil {
+			module = &ClassType{Name: module.Name, Methods: module.Methods, Fields: module.Fields, Call: fn}
+		}
+	}
+	sa.symbolTable.Define(name, module)
+}
+
 // resolveStdLibModule resuelve un módulo de la biblioteca estándar
 func (sa *SemanticAnalyzer) resolveStdLibModule(moduleName string) *ClassType {
 	switch moduleName {
@@ -1078,6 +1972,9 @@ func (sa *SemanticAnalyzer) resolveStdLibModule(moduleName string) *ClassType {
 			Methods: map[string]*FunctionType{
 				"sqrt":    {ParamTypes: []Type{FloatType}, ReturnType: FloatType},
 				"power":   {ParamTypes: []Type{FloatType, FloatType}, ReturnType: FloatType},
+				"pow":     {ParamTypes: []Type{FloatType, FloatType}, ReturnType: FloatType},
+				"min":     {ParamTypes: []Type{FloatType, FloatType}, ReturnType: FloatType},
+				"max":     {ParamTypes: []Type{FloatType, FloatType}, ReturnType: FloatType},
 				"abs":     {ParamTypes: []Type{FloatType}, ReturnType: FloatType},
 				"floor":   {ParamTypes: []Type{FloatType}, ReturnType: FloatType},
 				"ceil":    {ParamTypes: []Type{FloatType}, ReturnType: FloatType},
@@ -1118,6 +2015,8 @@ func (sa *SemanticAnalyzer) resolveStdLibModule(moduleName string) *ClassType {
 				"trim":      {ParamTypes: []Type{StringType}, ReturnType: StringType},
 				"to_upper":  {ParamTypes: []Type{StringType}, ReturnType: StringType},
 				"to_lower":  {ParamTypes: []Type{StringType}, ReturnType: StringType},
+				"upper":     {ParamTypes: []Type{StringType}, ReturnType: StringType},
+				"lower":     {ParamTypes: []Type{StringType}, ReturnType: StringType},
 				"contains":  {ParamTypes: []Type{StringType, StringType}, ReturnType: BoolType},
 				"starts_with": {ParamTypes: []Type{StringType, StringType}, ReturnType: BoolType},
 				"ends_with": {ParamTypes: []Type{StringType, StringType}, ReturnType: BoolType},
@@ -1146,15 +2045,8 @@ func (sa *SemanticAnalyzer) resolveStdLibModule(moduleName string) *ClassType {
 	case "time":
 		return &ClassType{
 			Name: "time",
-			Methods: map[string]*FunctionType{
-				"now":       {ParamTypes: []Type{}, ReturnType: StringType},
-				"parse":     {ParamTypes: []Type{StringType, StringType}, ReturnType: StringType},
-				"format":    {ParamTypes: []Type{StringType, StringType}, ReturnType: StringType},
-				"add_days":  {ParamTypes: []Type{StringType, IntType}, ReturnType: StringType},
-				"add_hours": {ParamTypes: []Type{StringType, IntType}, ReturnType: StringType},
-				"diff_days": {ParamTypes: []Type{StringType, StringType}, ReturnType: IntType},
-			},
-			Fields: make(map[string]Type),
+			Methods: timeModuleMethods(),
+			Fields:  make(map[string]Type),
 		}
 	case "list":
 		return &ClassType{
@@ -1194,25 +2086,47 @@ func (sa *SemanticAnalyzer) resolveStdLibModule(moduleName string) *ClassType {
 	}
 }
 
-// resolveModulePath resuelve un módulo desde una ruta de archivo
-func (sa *SemanticAnalyzer) resolveModulePath(modulePath string) *ClassType {
-	// TODO: Implement file system resolution for local modules
-	// For now, support basic std/ path resolution
+// timeModuleMethods son las funciones del módulo time, global y std/time
+func timeModuleMethods() map[string]*FunctionType {
+	return map[string]*FunctionType{
+		"now":    {ParamTypes: []Type{}, ReturnType: IntType},
+		"sleep":  {ParamTypes: []Type{IntType}, ReturnType: NullType},
+		"format": {ParamTypes: []Type{IntType, StringType}, ReturnType: StringType},
+		"parse":  {ParamTypes: []Type{StringType, StringType}, ReturnType: IntType},
+	}
+}
+
+// resolveModulePath resuelve un módulo desde una ruta: std/ para la
+// biblioteca estándar y cualquier otra ruta como un archivo local
+func (sa *SemanticAnalyzer) resolveModulePath(token lexer.Token, modulePath string) *ClassType {
 	if strings.HasPrefix(modulePath, "std/") {
 		stdModuleName := strings.TrimPrefix(modulePath, "std/")
 		stdModuleName = strings.TrimSuffix(stdModuleName, ".zylo")
-		return sa.resolveStdLibModule(stdModuleName)
+		if module := sa.resolveStdLibModule(stdModuleName); module != nil {
+			return module
+		}
+		// Un archivo std/<nombre>.zylo del proyecto tiene prioridad sobre el
+		// módulo incluido en el ejecutable
+		if module := sa.resolveLocalModule(token, modulePath); module != nil {
+			return module
+		}
+		return sa.resolveEmbeddedModule(token, stdModuleName)
 	}
-	// For other paths, return nil to indicate not found
-	return nil
+	if module := sa.resolveLocalModule(token, modulePath); module != nil {
+		return module
+	}
+	return sa.resolvePackageModule(token, modulePath)
 }
 
 // analyzeCollectionMethodCall analiza llamada a método de colección o función de módulo
 func (sa *SemanticAnalyzer) analyzeCollectionMethodCall(exp *ast.CollectionMethodCall) Type {
 	// First check if this is a module function call (e.g., math.sqrt(4))
 	objType := sa.Analyze(exp.Object)
+	for _, arg := range exp.NamedArguments {
+		sa.Analyze(arg.Value)
+	}
 
-	if _, ok := objType.(*ClassType); ok {
+	if classType, ok := objType.(*ClassType); ok {
 		// This is a module function call (e.g., math.sqrt(x))
 		// For now, accept any function call on modules
 		// TODO: Add proper validation for specific module functions
@@ -1222,6 +2136,11 @@ func (sa *SemanticAnalyzer) analyzeCollectionMethodCall(exp *ast.CollectionMetho
 			sa.Analyze(arg)
 		}
 
+		// Los módulos locales conocen el tipo de retorno de sus funciones
+		if method, ok := classType.Methods[exp.Method.Value]; ok && method.ReturnType != nil {
+			return method.ReturnType
+		}
+
 		// Return appropriate type based on method name
 		switch exp.Method.Value {
 		case "sqrt", "abs", "floor", "ceil", "round", "sin", "cos", "tan":
@@ -1253,7 +2172,8 @@ func (sa *SemanticAnalyzer) analyzeCollectionMethodCall(exp *ast.CollectionMetho
 			"splice": true, "forEach": true, "map": true, "filter": true,
 			"find": true, "some": true, "every": true, "indexOf": true,
 			"includes": true, "join": true, "slice": true, "reverse": true,
-			"sort": true, "concat": true, "length": true,
+			"sort": true, "concat": true, "length": true, "fill": true,
+			"append": true, "insert": true, "remove": true, "contains": true,
 		}
 	} else if _, isMap := objType.(*MapType); isMap || objType == Any {
 		// Métodos disponibles para mapas
@@ -1262,13 +2182,16 @@ func (sa *SemanticAnalyzer) analyzeCollectionMethodCall(exp *ast.CollectionMetho
 			"clear": true, "keys": true, "values": true, "entries": true,
 			"forEach": true, "size": true,
 		}
+	} else if _, isSet := objType.(*SetType); isSet {
+		methods = setMethods
 	} else {
 		sa.addError(exp.Token, fmt.Sprintf("El objeto no es una colección válida para método '%s'", exp.Method.Value))
 		return Any
 	}
 
-	// Verificar que el método existe
-	if !methods[exp.Method.Value] {
+	// Verificar que el método existe. Un objeto de tipo desconocido puede ser
+	// también un set.
+	if !methods[exp.Method.Value] && !(objType == Any && setMethods[exp.Method.Value]) {
 		sa.addError(exp.Token, fmt.Sprintf("Método '%s' no existe en este tipo de colección", exp.Method.Value))
 		return Any
 	}
@@ -1288,14 +2211,31 @@ func (sa *SemanticAnalyzer) analyzeCollectionMethodCall(exp *ast.CollectionMetho
 			return mapType.ValueType
 		}
 		return Any
-	case "push", "unshift", "splice", "reverse", "sort", "set", "delete", "clear":
+	case "push", "unshift", "splice", "fill", "reverse", "sort", "clear":
 		// Estos métodos modifican la colección y pueden retornar la colección o void
 		return objType
 	case "indexOf", "size", "length":
 		return IntType
-	case "includes", "has", "some", "every":
+	case "includes", "has", "some", "every", "remove", "contains", "delete":
 		return BoolType
-	case "slice", "filter", "map", "concat", "keys", "values", "entries", "join":
+	case "keys", "values":
+		if mapType, ok := objType.(*MapType); ok {
+			if exp.Method.Value == "keys" {
+				return &ListType{ElementType: mapType.KeyType}
+			}
+			return &ListType{ElementType: mapType.ValueType}
+		}
+		return Any
+	case "union", "intersection", "difference":
+		return objType
+	case "add", "append", "insert", "set":
+		return Any
+	case "to_list":
+		if setType, ok := objType.(*SetType); ok {
+			return &ListType{ElementType: setType.ElementType}
+		}
+		return Any
+	case "slice", "filter", "map", "concat", "entries", "join":
 		// Estos retornan una nueva colección
 		return objType
 	case "find", "forEach":
@@ -1,6 +1,10 @@
 package sema
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"github.com/zylo-lang/zylo/internal/ast"
 	"github.com/zylo-lang/zylo/internal/lexer"
@@ -49,14 +53,32 @@ var x = y; // y is not declared
 			},
 		},
 		{
-			name: "Shadowing (optional, current implementation allows it)",
+			name: "Redeclaration in the same scope",
 			input: `
 var z = 10;
-var z = 20; // This might be allowed or an error depending on language rules.
+var z = "veinte";
 `,
-			expectedErrors: 0, // Asumiendo que el shadowing simple es permitido por ahora.
+			expectedErrors: 1, // ZYLO_ERR_012: la segunda declaración no se acepta
 			expectedSymbols: map[string]string{
-				"z": "int", // El último 'z' define el símbolo.
+				"z": "int", // El símbolo sigue siendo el de la primera declaración.
+			},
+		},
+		{
+			name: "Shadowing in a nested scope",
+			input: `
+z := 10
+if z > 5 {
+    z := "veinte"
+    show.log(z)
+}
+func f(n) {
+    z := n
+    return z
+}
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"z": "int",
 			},
 		},
 		{
@@ -94,6 +116,392 @@ var result = add(5, 10);
 				"result": "any",
 			},
 		},
+		{
+			name: "Global math module",
+			input: `
+var r = math.sqrt(16) + math.pi;
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"r": "float",
+			},
+		},
+		{
+			name: "String builtins",
+			input: `
+var s = to_upper(trim(" hola "));
+var b = starts_with(s, "HO");
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"s": "string",
+				"b": "bool",
+			},
+		},
+		{
+			name: "Map literal indexed by string",
+			input: `
+var persona = {"nombre": "Ana", "edad": 25};
+var edad = persona["edad"];
+var precios = {"pan": 1.5, "leche": 0.9};
+var pan = precios["pan"];
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"edad": "any",
+				"pan":  "float",
+			},
+		},
+		{
+			name: "Map literal indexed by int",
+			input: `
+var precios = {"pan": 1.5};
+var pan = precios[0];
+`,
+			expectedErrors: 1,
+		},
+		{
+			name: "If expression",
+			input: `
+var x = 5;
+var tipo = if x > 3 { "grande" } else { "pequeño" };
+var mixto = if x > 3 { "grande" } else { 0 };
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"tipo":  "string",
+				"mixto": "any",
+			},
+		},
+		{
+			name: "If expression with undefined variable in branch",
+			input: `
+var tipo = if true { desconocida } else { "b" };
+`,
+			expectedErrors: 1,
+		},
+		{
+			name: "Bitwise operators",
+			input: `
+var flags = 12 & 10 | 1 << 2;
+var distinto = true xor false;
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"flags":    "int",
+				"distinto": "bool",
+			},
+		},
+		{
+			name: "Bitwise operator on floats",
+			input: `
+var x = 1.5 & 2;
+`,
+			expectedErrors: 1,
+		},
+		{
+			name: "For init variable is scoped to the loop",
+			input: `
+for i := 0; i < 3; i = i + 1 {
+    show.log(i);
+}
+show.log(i);
+`,
+			expectedErrors: 1,
+		},
+		{
+			name: "Set literal and methods",
+			input: `
+var s = {1, 2, 3};
+var tiene = s.has(2);
+var dentro = 2 in s;
+var comun = s.intersection({2, 3});
+var lista = s.to_list();
+var vacio = set();
+for n in s {
+    show.log(n + 1);
+}
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"s":      "Set<int>",
+				"tiene":  "bool",
+				"dentro": "bool",
+				"comun":  "Set<int>",
+				"lista":  "List<int>",
+				"vacio":  "Set<any>",
+			},
+		},
+		{
+			name: "Unknown set method",
+			input: `
+var s = {1, 2};
+s.push(3);
+`,
+			expectedErrors: 1,
+		},
+		{
+			name: "Format builtin",
+			input: `
+var texto = format("{} de {}", 1, 2);
+var solo = format("sin huecos");
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"texto": "string",
+				"solo":  "string",
+			},
+		},
+		{
+			name: "List methods",
+			input: `
+var xs = [1, 2, 3];
+xs.append(4);
+xs.insert(0, 0);
+var ultimo = xs.pop();
+var quitado = xs.remove(2);
+var pos = xs.indexOf(3);
+var esta = xs.contains(1);
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"ultimo":  "int",
+				"quitado": "bool",
+				"pos":     "int",
+				"esta":    "bool",
+			},
+		},
+		{
+			name: "Map methods",
+			input: `
+var m = {"a": 1, "b": 2};
+m.set("c", 3);
+var claves = m.keys();
+var valores = m.values();
+var borrado = m.delete("a");
+var tiene = m.has("b");
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"claves":  "List<string>",
+				"valores": "List<int>",
+				"borrado": "bool",
+				"tiene":   "bool",
+			},
+		},
+		{
+			name: "Enum declaration and members",
+			input: `
+enum Color { Red, Green, Blue }
+var c = Color.Red;
+var igual = c == Color.Green;
+switch c {
+case Color.Blue:
+    show.log("azul");
+}
+match c {
+case Color.Red:
+    show.log("rojo");
+case otro:
+    show.log(otro);
+}
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"Color": "Color",
+				"c":     "Color",
+				"igual": "bool",
+			},
+		},
+		{
+			name: "Unknown enum member",
+			input: `
+enum Color { Red }
+var c = Color.Purple;
+switch c {
+case Color.Green:
+    show.log("verde");
+}
+`,
+			expectedErrors: 2,
+		},
+		{
+			name: "Struct declaration and literal",
+			input: `
+struct Point { x: int, y: float }
+var p = Point{x: 1, y: 2};
+var x = p.x;
+var y = p.y;
+p.x = 3;
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"p": "Point",
+				"x": "int",
+				"y": "float",
+			},
+		},
+		{
+			name: "Struct literal with wrong fields",
+			input: `
+struct Point { x: int, y: int }
+var p = Point{x: "uno", z: 2};
+var w = p.w;
+`,
+			expectedErrors: 4, // tipo de x, campo z, falta y, campo w
+		},
+		{
+			name: "Is type checks",
+			input: `
+class Animal {
+}
+enum Color { Red }
+var x = 1;
+var a = x is int and x is Animal and x is Color;
+var b = x is Desconocido;
+var c = x is a;
+`,
+			expectedErrors: 2, // Desconocido no existe, a no es un tipo
+		},
+		{
+			name: "Destructuring assignment",
+			input: `
+var a = 1;
+var b = 2;
+a, b = b, a;
+a, b = [3, 4];
+`,
+			expectedErrors: 0,
+		},
+		{
+			name: "Destructuring assignment with wrong count",
+			input: `
+var a = 1;
+var b = 2;
+a, b = [1, 2, 3];
+`,
+			expectedErrors: 1,
+		},
+		{
+			name: "Destructuring assignment with wrong type",
+			input: `
+var a = 1;
+var b = 2;
+a, b = ["x", "y"];
+var n = 5;
+a, b = n;
+`,
+			expectedErrors: 3,
+		},
+		{
+			name: "Read module",
+			input: `
+var linea = read.line();
+var n = read.int();
+var f = read.float();
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"linea": "string",
+				"n":     "int",
+				"f":     "float",
+			},
+		},
+		{
+			name: "Time module",
+			input: `
+var ahora = time.now();
+var texto = time.format(ahora, "2006-01-02");
+var ts = time.parse(texto, "2006-01-02");
+time.sleep(10);
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"ahora": "int",
+				"texto": "string",
+				"ts":    "int",
+			},
+		},
+		{
+			name: "Random module",
+			input: `
+random.seed(42);
+var dado = random.int(1, 6);
+var p = random.float();
+var mezcla = random.shuffle([1, 2, 3]);
+var elegido = random.choice(["a", "b"]);
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"dado":    "int",
+				"p":       "float",
+				"mezcla":  "List<any>",
+				"elegido": "any",
+			},
+		},
+		{
+			name: "Global math builtins",
+			input: `
+var r = round(3.7);
+var raiz = sqrt(2);
+var potencia = pow(2, 8);
+entero int := floor(3)
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"r":        "any",
+				"raiz":     "float",
+				"potencia": "float",
+				"entero":   "int",
+			},
+		},
+		{
+			name: "FS and OS modules",
+			input: `
+var existe = fs.exists("a.txt");
+var nombres = fs.listdir(".");
+var args = os.args();
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"existe":  "bool",
+				"nombres": "List<string>",
+				"args":    "List<string>",
+			},
+		},
+		{
+			name: "Import std/string",
+			input: `
+import "std/string"
+var s = string.upper("hola");
+var n = int("3");
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"s": "string",
+				"n": "int",
+			},
+		},
+		{
+			name: "Import std/string keeps the string conversion",
+			input: `
+import "std/string"
+t string := string(5)
+var u = t + string(6);
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"t": "string",
+				"u": "string",
+			},
+		},
+		{
+			name: "String builtin with wrong argument",
+			input: `
+var s = to_upper(5);
+`,
+			expectedErrors: 1,
+		},
 		{
 			name: "Nested scopes",
 			input: `
@@ -128,6 +536,83 @@ outer();
 			expectedErrors: 1, // Esperamos un error de "identifier not found" para undeclaredVar.
 			expectedSymbols: map[string]string{},
 		},
+		{
+			name: "String ordering comparison",
+			input: `
+var menor = "apple" < "banana";
+var mayor = "b" >= "a";
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"menor": "bool",
+				"mayor": "bool",
+			},
+		},
+		{
+			name: "Mixed string and number ordering",
+			input: `
+var x = "a" < 1;
+`,
+			expectedErrors: 1,
+			expectedSymbols: map[string]string{},
+		},
+		{
+			name: "Typed constant",
+			input: `
+MAX int := 5
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"MAX": "int",
+			},
+		},
+		{
+			name: "Typed constant with wrong value",
+			input: `
+MAX int := "x"
+`,
+			expectedErrors: 1,
+			expectedSymbols: map[string]string{
+				"MAX": "int",
+			},
+		},
+		{
+			name: "Typed constant records the annotation",
+			input: `
+LIMITE float := 5
+`,
+			expectedErrors: 0,
+			expectedSymbols: map[string]string{
+				"LIMITE": "float",
+			},
+		},
+		{
+			name: "Typed constant misuse",
+			input: `
+MAX int := 5
+s string := MAX
+`,
+			expectedErrors: 1,
+			expectedSymbols: map[string]string{},
+		},
+		{
+			name: "Constant reassignment",
+			input: `
+MAX int := 5
+MAX = 6
+`,
+			expectedErrors: 1,
+			expectedSymbols: map[string]string{},
+		},
+		{
+			name: "Constant element assignment",
+			input: `
+LISTA := [1, 2]
+LISTA[0] = 3
+`,
+			expectedErrors: 1,
+			expectedSymbols: map[string]string{},
+		},
 	}
 
 	for _, tt := range tests {
@@ -161,6 +646,401 @@ outer();
 	}
 }
 
+func TestDuplicateVariableError(t *testing.T) {
+	p := parser.New(lexer.New("total := 0\nfor i := 0; i < 3; i = i + 1 {\n    total += i\n}\nvar total = 1\n"))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	sa := NewSemanticAnalyzer()
+	sa.Analyze(program)
+
+	errs := sa.ZyloErrors()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errs), sa.Errors())
+	}
+	err := errs[0]
+	if err.Code != ZYLO_ERR_012_DUPLICATE_VAR || err.Line != 5 || err.Column != 5 {
+		t.Errorf("unexpected error: %s", err.Error())
+	}
+	if err.Context != "declarada antes en la línea 1, columna 1" {
+		t.Errorf("the error must point to the first declaration, got %q", err.Context)
+	}
+}
+
+func TestReturnPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		body    string
+		missing bool
+	}{
+		{"Return at the end", "return n", false},
+		{"Empty body", "", true},
+		{"If without else", "if n > 0 {\n    return 1\n}", true},
+		{"If and else", "if n > 0 {\n    return 1\n} else {\n    return 2\n}", false},
+		{"Elif chain with else", "if n > 0 {\n    return 1\n} elif n < 0 {\n    return -1\n} else {\n    return 0\n}", false},
+		{"Elif branch without return", "if n > 0 {\n    return 1\n} elif n < 0 {\n    show.log(n)\n} else {\n    return 0\n}", true},
+		{"If followed by return", "if n > 0 {\n    return 1\n}\nreturn 0", false},
+		{"Throw", "if n > 0 {\n    return 1\n}\nthrow \"negativo\"", false},
+		{"Switch with default", "switch n {\ncase 1:\n    return 10\ndefault:\n    return 0\n}", false},
+		{"Switch without default", "switch n {\ncase 1:\n    return 10\ncase 2:\n    return 20\n}", true},
+		{"Switch case without return", "switch n {\ncase 1:\n    show.log(n)\ndefault:\n    return 0\n}", true},
+		{"While loop", "while n > 0 {\n    return n\n}", true},
+		{"Infinite loop", "while true {\n    if n > 10 {\n        return n\n    }\n    n = n + 1\n}", false},
+		{"Infinite loop with break", "while true {\n    if n > 10 {\n        break\n    }\n    return n\n}", true},
+		{"Break in a nested loop", "while true {\n    for i in [1] {\n        break\n    }\n    return n\n}", false},
+		{"For in loop", "for i in [1, 2] {\n    return i\n}", true},
+		{"Repeat loop", "repeat 3 {\n    return n\n}", true},
+		{"Break in a nested repeat", "while true {\n    repeat 2 {\n        break\n    }\n    return n\n}", false},
+		{"Try and catch", "try {\n    return n\n} catch (e) {\n    return 0\n}", false},
+		{"Catch without return", "try {\n    return n\n} catch (e) {\n    show.log(e)\n}", true},
+		{"Finally with return", "try {\n    show.log(n)\n} finally {\n    return 0\n}", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := "func f(n int): int {\n" + tt.body + "\n}\n"
+			p := parser.New(lexer.New(input))
+			program := p.ParseProgram()
+			if len(p.Errors()) > 0 {
+				t.Fatalf("Parser errors: %v", p.Errors())
+			}
+
+			sa := NewSemanticAnalyzer()
+			sa.Analyze(program)
+
+			var missing []*ZyloError
+			for _, err := range sa.ZyloErrors() {
+				if err.Code == ZYLO_ERR_008_RETURN_TYPE {
+					missing = append(missing, err)
+				}
+			}
+			if tt.missing && (len(missing) != 1 || missing[0].Line != 1 || missing[0].Column != 6) {
+				t.Errorf("expected a missing return error at 1:6, got %v", sa.Errors())
+			}
+			if !tt.missing && len(missing) != 0 {
+				t.Errorf("expected no missing return error, got %v", sa.Errors())
+			}
+		})
+	}
+
+	// Sin tipo de retorno, o con nil, la función puede terminar sin return
+	for _, input := range []string{"func f(n) {\n    show.log(n)\n}\n", "func f(n): nil {\n    show.log(n)\n}\n"} {
+		p := parser.New(lexer.New(input))
+		program := p.ParseProgram()
+		sa := NewSemanticAnalyzer()
+		sa.Analyze(program)
+		if len(sa.Errors()) != 0 {
+			t.Errorf("%q: expected no errors, got %v", input, sa.Errors())
+		}
+	}
+}
+
+func TestConstFolding(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string // Valor plegado de la última declaración
+	}{
+		{"const X = 2 * 60 * 60", "7200"},
+		{"const X = (1 + 2) * -3", "-9"},
+		{"const X = 7 / 2", "3"},
+		{"const X = 7 % 4", "3"},
+		{"const X = 1 + 0.5", "1.5"},
+		{"const X = 3 / 1.5", "2.0"},
+		{`const X = "ho" + "la"`, `"hola"`},
+		{"const X = !true", "false"},
+		{"const HORA = 60 * 60\nconst X = 24 * HORA", "86400"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("%q: parser errors: %v", tt.input, p.Errors())
+		}
+
+		sa := NewSemanticAnalyzer()
+		sa.Analyze(program)
+		if len(sa.Errors()) > 0 {
+			t.Fatalf("%q: unexpected errors: %v", tt.input, sa.Errors())
+		}
+
+		stmt := program.Statements[len(program.Statements)-1].(*ast.VarStatement)
+		if stmt.Value.String() != tt.expected {
+			t.Errorf("%q: folded to %s, expected %s", tt.input, stmt.Value.String(), tt.expected)
+		}
+	}
+}
+
+func TestConstFoldingErrors(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"x := 3\nconst X = x * 2", "const X: no es una expresión constante"},
+		{"X := 3\nconst Y = X * 2", "const Y: no es una expresión constante"},
+		{"const X = len([1])", "const X: no es una expresión constante"},
+		{"const X = 1 / 0", "const X: división por cero"},
+		{"const X = 1 < 2", "const X: no es una expresión constante"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("%q: parser errors: %v", tt.input, p.Errors())
+		}
+
+		sa := NewSemanticAnalyzer()
+		sa.Analyze(program)
+		errs := sa.ZyloErrors()
+		if len(errs) != 1 || errs[0].Message != tt.expected {
+			t.Errorf("%q: expected error %q, got %v", tt.input, tt.expected, sa.Errors())
+		}
+	}
+}
+
+func TestConstantReassignmentError(t *testing.T) {
+	p := parser.New(lexer.New("MAX int := 5\nfunc f() {\n    MAX += 1\n}\n"))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	sa := NewSemanticAnalyzer()
+	sa.Analyze(program)
+
+	errs := sa.ZyloErrors()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errs), sa.Errors())
+	}
+	if errs[0].Message != "no se puede reasignar constante: MAX" || errs[0].Line != 3 || errs[0].Column != 5 {
+		t.Errorf("unexpected error: %s", errs[0].Error())
+	}
+}
+
+func TestDefaultParameterArguments(t *testing.T) {
+	functions := "func greet(name string, greeting string = \"Hello\") {\n    return greeting + name\n}\n"
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`greet("a")`, nil},
+		{`greet("a", "b")`, nil},
+		{`greet()`, []string{"esperados entre 1 y 2 argumentos, recibidos 0"}},
+		{`greet("a", "b", "c")`, []string{"esperados entre 1 y 2 argumentos, recibidos 3"}},
+		{`greet("a", 1)`, []string{"argumento 2: esperado string, obtenido int"}},
+		{"func f(n int = \"x\") {\n    return n\n}\n", []string{"valor por defecto de n: esperado int, obtenido string"}},
+		// El handler de http.listen es opcional, como en el evaluador
+		{`http.listen(8080)`, nil},
+		{"serve := http.listen\nserve(8080)", nil},
+		{"serve := http.listen\nserve(8080, greet)", nil},
+		{"serve := http.listen\nserve()", []string{"esperados entre 1 y 2 argumentos, recibidos 0"}},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(functions + tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+		sa := NewSemanticAnalyzer()
+		sa.Analyze(program)
+
+		errs := sa.ZyloErrors()
+		if len(errs) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.input, tt.expected, sa.Errors())
+			continue
+		}
+		for i, msg := range tt.expected {
+			if errs[i].Message != msg {
+				t.Errorf("%s: expected %q, got %q", tt.input, msg, errs[i].Message)
+			}
+		}
+	}
+}
+
+func TestNamedArgumentTypes(t *testing.T) {
+	functions := "func greet(name string, greeting string = \"Hello\") {\n    return greeting + name\n}\n"
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`greet(name: "a")`, nil},
+		{`greet("a", greeting: "b")`, nil},
+		{`greet(greeting: "b", name: "a")`, nil},
+		{`greet(greeting: "b")`, []string{"falta el argumento name"}},
+		{`greet("a", name: "b")`, []string{"el argumento name se pasó por posición y por nombre"}},
+		{`greet(name: "a", color: "b")`, []string{"parámetro desconocido: color"}},
+		{`greet(name: 1)`, []string{"argumento name: esperado string, obtenido int"}},
+		{`len(x: [1])`, []string{"la función no admite argumentos con nombre"}},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(functions + tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+		sa := NewSemanticAnalyzer()
+		sa.Analyze(program)
+
+		errs := sa.ZyloErrors()
+		if len(errs) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.input, tt.expected, sa.Errors())
+			continue
+		}
+		for i, msg := range tt.expected {
+			if errs[i].Message != msg {
+				t.Errorf("%s: expected %q, got %q", tt.input, msg, errs[i].Message)
+			}
+		}
+	}
+}
+
+func TestLogicalOperatorTypes(t *testing.T) {
+	functions := "func saludar(nombre string) {\n    return nombre\n}\n"
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		// and/or devuelven un operando: con operandos string el resultado es string
+		{`saludar("" or "anónimo")`, nil},
+		{`saludar("a" and "b")`, nil},
+		{`saludar(1 or 2)`, []string{"argumento 1: esperado string, obtenido int"}},
+		// Con operandos de tipos distintos el tipo no se conoce
+		{`saludar(1 or "x")`, nil},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(functions + tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+		sa := NewSemanticAnalyzer()
+		sa.Analyze(program)
+
+		errs := sa.ZyloErrors()
+		if len(errs) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.input, tt.expected, sa.Errors())
+			continue
+		}
+		for i, msg := range tt.expected {
+			if errs[i].Message != msg {
+				t.Errorf("%s: expected %q, got %q", tt.input, msg, errs[i].Message)
+			}
+		}
+	}
+}
+
+func TestVariadicParameterArguments(t *testing.T) {
+	functions := "func sumar(base int, ...nums int): int {\n    return base + len(nums)\n}\n"
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{`sumar(1)`, nil},
+		{`sumar(1, 2, 3, 4)`, nil},
+		{`sumar()`, []string{"esperados al menos 1 argumentos, recibidos 0"}},
+		{`sumar(1, 2, "x")`, []string{"argumento 3: esperado int, obtenido string"}},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(functions + tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+		sa := NewSemanticAnalyzer()
+		sa.Analyze(program)
+
+		errs := sa.ZyloErrors()
+		if len(errs) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.input, tt.expected, sa.Errors())
+			continue
+		}
+		for i, msg := range tt.expected {
+			if errs[i].Message != msg {
+				t.Errorf("%s: expected %q, got %q", tt.input, msg, errs[i].Message)
+			}
+		}
+	}
+}
+
+func TestUnusedVariableWarnings(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected []string // "línea:columna nombre"
+	}{
+		{
+			name:     "Used variable",
+			input:    "x := 1\nshow.log(x)\n",
+			expected: nil,
+		},
+		{
+			name:     "Unused global",
+			input:    "x := 1\ny := 2\nshow.log(y)\n",
+			expected: []string{"1:1 x"},
+		},
+		{
+			name:     "Unused local in function",
+			input:    "func f(a) {\n    z := a\n    return a\n}\nshow.log(f(1))\n",
+			expected: []string{"2:5 z"},
+		},
+		{
+			name:     "Assignment is not a use",
+			input:    "x := 1\nx = 2\n",
+			expected: []string{"1:1 x"},
+		},
+		{
+			name:     "Compound assignment reads the variable",
+			input:    "x := 1\nx += 2\n",
+			expected: nil,
+		},
+		{
+			name:     "Underscore prefix is ignored",
+			input:    "_x := 1\n",
+			expected: nil,
+		},
+		{
+			name:     "Closure use counts",
+			input:    "x := 1\nfunc f() {\n    return x\n}\nshow.log(f())\n",
+			expected: nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := parser.New(lexer.New(tt.input))
+			program := p.ParseProgram()
+			if len(p.Errors()) > 0 {
+				t.Fatalf("Parser errors: %v", p.Errors())
+			}
+
+			sa := NewSemanticAnalyzer()
+			sa.Analyze(program)
+
+			var got []string
+			for _, w := range sa.Warnings() {
+				if w.Code != ZYLO_WARN_001_UNUSED_VAR || w.Severity != "warning" {
+					t.Errorf("unexpected warning: %s", w.Error())
+				}
+				name := strings.TrimSuffix(strings.TrimPrefix(w.Message, "Variable '"), "' declarada pero no utilizada")
+				got = append(got, fmt.Sprintf("%d:%d %s", w.Line, w.Column, name))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+				t.Errorf("expected warnings %v, got %v", tt.expected, got)
+			}
+			if len(sa.Errors()) > 0 {
+				t.Errorf("unused variables must not be errors: %v", sa.Errors())
+			}
+		})
+	}
+}
+
 // Helper function to create a simple AST program for testing.
 // This is a simplified approach; a real test would use the parser.
 func createTestProgram(statements ...ast.Statement) *ast.Program {
@@ -188,3 +1068,302 @@ func createIdentifier(name string) *ast.Identifier {
 		Value: name,
 	}
 }
+
+func analyzeFileWithCache(t *testing.T, filename string, cache *ModuleCache) *SemanticAnalyzer {
+	t.Helper()
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("error leyendo %s: %v", filename, err)
+	}
+	p := parser.New(lexer.New(string(content)))
+	program := p.ParseProgram()
+	if len(p.Errors()) > 0 {
+		t.Fatalf("Parser errors: %v", p.Errors())
+	}
+
+	sa := NewSemanticAnalyzer()
+	sa.SetBaseDir(filepath.Dir(filename))
+	sa.SetModuleCache(cache)
+	sa.Analyze(program)
+	return sa
+}
+
+func TestModuleCacheAnalyzesModuleOnce(t *testing.T) {
+	dir := t.TempDir()
+	module := filepath.Join(dir, "util.zylo")
+	files := map[string]string{
+		"util.zylo": "func doble(x int): int {\n    return x * 2\n}\nLIMITE := 10\n",
+		"a.zylo":    "import \"./util\"\nshow.log(util.doble(1))\n",
+		"b.zylo":    "import \"util\"\nshow.log(util.LIMITE)\n",
+		"c.zylo":    "import \"./util.zylo\"\nx := util.doble(3)\nshow.log(x)\n",
+	}
+	for name, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatalf("error escribiendo %s: %v", name, err)
+		}
+	}
+
+	cache := NewModuleCache()
+	for _, name := range []string{"a.zylo", "b.zylo", "c.zylo"} {
+		sa := analyzeFileWithCache(t, filepath.Join(dir, name), cache)
+		if len(sa.Errors()) > 0 {
+			t.Fatalf("%s: errores inesperados: %v", name, sa.Errors())
+		}
+	}
+	if cache.parses != 1 {
+		t.Fatalf("el módulo se parseó %d veces, se esperaba 1", cache.parses)
+	}
+
+	sa := analyzeFileWithCache(t, filepath.Join(dir, "c.zylo"), cache)
+	if sym, ok := sa.symbolTable.Resolve("x"); !ok || sym.Type != IntType {
+		t.Errorf("el tipo de retorno del módulo debería ser int, obtenido %v", sym)
+	}
+
+	// Si cambia el contenido, el módulo se vuelve a analizar
+	if err := os.WriteFile(module, []byte("func doble(x int): int {\n    return x\n}\nLIMITE := 20\n"), 0644); err != nil {
+		t.Fatalf("error escribiendo el módulo: %v", err)
+	}
+	analyzeFileWithCache(t, filepath.Join(dir, "a.zylo"), cache)
+	analyzeFileWithCache(t, filepath.Join(dir, "b.zylo"), cache)
+	if cache.parses != 2 {
+		t.Fatalf("tras cambiar el módulo se esperaban 2 parseos, obtenidos %d", cache.parses)
+	}
+}
+
+func TestModuleExports(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"exporta.zylo": "func ayuda(): string {\n    return \"Hola, \"\n}\nexport func saludar(n string): string {\n    return ayuda() + n\n}\n",
+		"todo.zylo":    "func uno(): int {\n    return 1\n}\nprivate func dos(): int {\n    return 2\n}\n",
+	}
+	for name, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatalf("error escribiendo %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		module   string
+		expected []string
+	}{
+		// Con export solo se ve lo exportado
+		{"exporta.zylo", []string{"saludar"}},
+		// Sin export se ve todo salvo lo privado
+		{"todo.zylo", []string{"uno"}},
+	}
+	cache := NewModuleCache()
+	for _, tt := range tests {
+		path := filepath.Join(dir, tt.module)
+		content, _ := os.ReadFile(path)
+		module := cache.load(path, content)
+		if len(module.errors) > 0 {
+			t.Fatalf("%s: errores inesperados: %v", tt.module, module.errors[0].Message)
+		}
+		var names []string
+		for name := range module.typ.Methods {
+			names = append(names, name)
+		}
+		if strings.Join(names, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("%s: se esperaban %v, obtenidos %v", tt.module, tt.expected, names)
+		}
+	}
+}
+
+func TestLocalModuleErrors(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"roto.zylo":  "x := no_definida\n",
+		"main.zylo":  "import \"./roto\"\nimport \"./no_existe\"\n",
+		"ciclo.zylo": "import \"./ciclo\"\nfunc f() {\n    return 1\n}\n",
+	}
+	for name, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatalf("error escribiendo %s: %v", name, err)
+		}
+	}
+
+	sa := analyzeFileWithCache(t, filepath.Join(dir, "main.zylo"), NewModuleCache())
+	expected := []string{
+		"errores en el módulo ./roto: variable no definida: no_definida",
+		"Módulo no encontrado: ./no_existe",
+	}
+	errs := sa.ZyloErrors()
+	if len(errs) != len(expected) {
+		t.Fatalf("se esperaban %d errores, obtenidos %v", len(expected), sa.Errors())
+	}
+	for i, msg := range expected {
+		if errs[i].Message != msg {
+			t.Errorf("error %d: esperado %q, obtenido %q", i, msg, errs[i].Message)
+		}
+	}
+
+	// Un módulo que se importa a sí mismo no entra en un bucle infinito
+	analyzeFileWithCache(t, filepath.Join(dir, "ciclo.zylo"), NewModuleCache())
+}
+
+func TestEmbeddedStdlibModules(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"import \"std/text\"\nshow.log(text.capitalize(\"zylo\"))\n", nil},
+		{"import \"std/collections\"\nshow.log(collections.chunk([1, 2], 1))\n", nil},
+		{"import \"std/no_existe\"\n", []string{"Módulo no encontrado: std/no_existe"}},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+		sa := NewSemanticAnalyzer()
+		sa.SetBaseDir(t.TempDir())
+		sa.Analyze(program)
+
+		errs := sa.ZyloErrors()
+		if len(errs) != len(tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.input, tt.expected, sa.Errors())
+			continue
+		}
+		for i, msg := range tt.expected {
+			if errs[i].Message != msg {
+				t.Errorf("%s: expected %q, got %q", tt.input, msg, errs[i].Message)
+			}
+		}
+	}
+}
+
+func TestImportCycleError(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"a.zylo":    "import \"./b\"\nfunc fa() {\n    return 1\n}\n",
+		"b.zylo":    "import \"./a\"\nfunc fb() {\n    return 2\n}\n",
+		"main.zylo": "import \"./a\"\n",
+		"yo.zylo":   "import \"./yo\"\n",
+	}
+	for name, source := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0644); err != nil {
+			t.Fatalf("error escribiendo %s: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		file     string
+		expected string
+	}{
+		{"a.zylo", "import circular: b.zylo -> a.zylo -> b.zylo"},
+		{"main.zylo", "import circular: a.zylo -> b.zylo -> a.zylo"},
+		{"yo.zylo", "import circular: yo.zylo -> yo.zylo"},
+	}
+	for _, tt := range tests {
+		sa := analyzeFileWithCache(t, filepath.Join(dir, tt.file), NewModuleCache())
+		errs := sa.ZyloErrors()
+		if len(errs) != 1 || errs[0].Message != tt.expected {
+			t.Errorf("%s: se esperaba %q, obtenido %v", tt.file, tt.expected, sa.Errors())
+		}
+	}
+}
+
+func TestRepeatStatement(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr string
+	}{
+		{"repeat 3 {\n    show.log(1)\n}", ""},
+		{"n := 2\nrepeat n * 2 {\n    if n > 5 {\n        break\n    }\n    n = n + 1\n}", ""},
+		{"repeat \"3\" {\n}", "repeat espera un int, obtenido string"},
+		{"repeat 1.5 {\n}", "repeat espera un int, obtenido float"},
+		// Las variables del cuerpo no existen fuera del bucle
+		{"repeat 2 {\n    x := 1\n}\nshow.log(x)", "x"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+
+		sa := NewSemanticAnalyzer()
+		sa.Analyze(program)
+		errs := sa.Errors()
+		if tt.expectedErr == "" {
+			if len(errs) != 0 {
+				t.Errorf("%q: expected no errors, got %v", tt.input, errs)
+			}
+			continue
+		}
+		if len(errs) == 0 || !strings.Contains(errs[0], tt.expectedErr) {
+			t.Errorf("%q: expected an error containing %q, got %v", tt.input, tt.expectedErr, errs)
+		}
+	}
+}
+
+func TestOptionalDotExpression(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr string
+	}{
+		{"user := {\"address\": {\"city\": \"Lima\"}}\nciudad := user?.address?.city", ""},
+		{"user := {\"name\": \"Ana\"}\nk := \"name\"\nnombre := user?.[k]", ""},
+		{"ciudad := nadie?.address", "variable no definida: nadie"},
+		{"user := {}\nx := user?.[clave]", "variable no definida: clave"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+
+		sa := NewSemanticAnalyzer()
+		sa.Analyze(program)
+		errs := sa.Errors()
+		if tt.expectedErr == "" {
+			if len(errs) != 0 {
+				t.Errorf("%q: expected no errors, got %v", tt.input, errs)
+			}
+			continue
+		}
+		if len(errs) == 0 || !strings.Contains(errs[0], tt.expectedErr) {
+			t.Errorf("%q: expected an error containing %q, got %v", tt.input, tt.expectedErr, errs)
+		}
+	}
+}
+
+func TestNullCoalescingTypes(t *testing.T) {
+	tests := []struct {
+		input       string
+		expectedErr string
+	}{
+		// nil ?? x tiene el tipo de x
+		{"a int := nil ?? 5", ""},
+		{"b string := \"x\" ?? \"y\"", ""},
+		{"c := {\"a\": 1}\nd := c?.a ?? 0", ""},
+		{"e int := \"x\" ?? \"y\"", "no se puede asignar string a variable de tipo int"},
+		{"f int := nil ?? \"y\"", "no se puede asignar string a variable de tipo int"},
+	}
+
+	for _, tt := range tests {
+		p := parser.New(lexer.New(tt.input))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Fatalf("Parser errors: %v", p.Errors())
+		}
+
+		sa := NewSemanticAnalyzer()
+		sa.Analyze(program)
+		errs := sa.Errors()
+		if tt.expectedErr == "" {
+			if len(errs) != 0 {
+				t.Errorf("%q: expected no errors, got %v", tt.input, errs)
+			}
+			continue
+		}
+		if len(errs) == 0 || !strings.Contains(errs[0], tt.expectedErr) {
+			t.Errorf("%q: expected an error containing %q, got %v", tt.input, tt.expectedErr, errs)
+		}
+	}
+}
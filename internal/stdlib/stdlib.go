@@ -0,0 +1,37 @@
+// Package stdlib contiene los módulos de la biblioteca estándar escritos en
+// Zylo. Se incluyen en el ejecutable, así que import "std/<nombre>" funciona
+// sin instalar nada: el módulo se busca primero como archivo std/<nombre>.zylo
+// junto al programa y, si no existe, aquí.
+package stdlib
+
+import (
+	"embed"
+	"path"
+	"sort"
+	"strings"
+)
+
+//go:embed modules/*.zylo
+var modules embed.FS
+
+// Source devuelve el código del módulo estándar name (sin el prefijo std/ y
+// con o sin la extensión .zylo)
+func Source(name string) (string, bool) {
+	name = strings.TrimSuffix(name, ".zylo")
+	data, err := modules.ReadFile(path.Join("modules", name+".zylo"))
+	if err != nil {
+		return "", false
+	}
+	return string(data), true
+}
+
+// Names devuelve los nombres de los módulos estándar incluidos, ordenados
+func Names() []string {
+	entries, _ := modules.ReadDir("modules")
+	var names []string
+	for _, entry := range entries {
+		names = append(names, strings.TrimSuffix(entry.Name(), ".zylo"))
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,46 @@
+// Paquete externo porque sema importa stdlib
+package stdlib_test
+
+import (
+	"testing"
+
+	"github.com/zylo-lang/zylo/internal/lexer"
+	"github.com/zylo-lang/zylo/internal/parser"
+	"github.com/zylo-lang/zylo/internal/sema"
+	"github.com/zylo-lang/zylo/internal/stdlib"
+)
+
+func TestModulesAreValid(t *testing.T) {
+	names := stdlib.Names()
+	if len(names) == 0 {
+		t.Fatalf("no hay módulos estándar incluidos")
+	}
+	for _, name := range names {
+		source, ok := stdlib.Source(name)
+		if !ok {
+			t.Fatalf("%s: no se encontró el código del módulo", name)
+		}
+		p := parser.New(lexer.New(source))
+		program := p.ParseProgram()
+		if len(p.Errors()) > 0 {
+			t.Errorf("%s: errores de parsing: %v", name, p.Errors())
+			continue
+		}
+		sa := sema.NewSemanticAnalyzer()
+		sa.Analyze(program)
+		if len(sa.Errors()) > 0 {
+			t.Errorf("%s: errores semánticos: %v", name, sa.Errors())
+		}
+	}
+}
+
+func TestSource(t *testing.T) {
+	if _, ok := stdlib.Source("collections.zylo"); !ok {
+		t.Errorf("Source debe aceptar el nombre con extensión")
+	}
+	for _, name := range []string{"no_existe", "../stdlib"} {
+		if _, ok := stdlib.Source(name); ok {
+			t.Errorf("%s: no se esperaba un módulo", name)
+		}
+	}
+}
@@ -78,14 +78,40 @@ func (l *List) Inspect() string {
 	return out.String()
 }
 
-type Map struct{ Pairs map[string]ZyloObject }
+type Map struct {
+	Pairs map[string]ZyloObject
+	Order []string // Orden de inserción de las claves
+}
 
 func (m *Map) Type() ObjectType { return MAP_OBJ }
+
+// Keys devuelve las claves del mapa en orden de inserción, para que mostrar o
+// recorrer un mapa dé siempre el mismo resultado. Las claves que no están en
+// Order van al final, ordenadas.
+func (m *Map) Keys() []string {
+	keys := make([]string, 0, len(m.Pairs))
+	listed := make(map[string]bool, len(m.Order))
+	for _, k := range m.Order {
+		if _, ok := m.Pairs[k]; ok && !listed[k] {
+			listed[k] = true
+			keys = append(keys, k)
+		}
+	}
+	inserted := len(keys)
+	for k := range m.Pairs {
+		if !listed[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys[inserted:])
+	return keys
+}
+
 func (m *Map) Inspect() string {
 	var out strings.Builder
 	pairs := []string{}
-	for k, v := range m.Pairs {
-		pairs = append(pairs, fmt.Sprintf("%q: %s", k, v.Inspect()))
+	for _, k := range m.Keys() {
+		pairs = append(pairs, fmt.Sprintf("%q: %s", k, m.Pairs[k].Inspect()))
 	}
 	out.WriteString("{")
 	out.WriteString(strings.Join(pairs, ", "))
@@ -526,7 +552,7 @@ func ValidateArrayBounds(array *List, index int64) error {
 // SafeArrayAccess accede a un array con validación robusta
 func SafeArrayAccess(array *List, index int64) (ZyloObject, error) {
 	if err := ValidateArrayBounds(array, index); err != nil {
-		return NewError(err.Error()), err
+		return NewError("%s", err.Error()), err
 	}
 	return array.Elements[index], nil
 }
@@ -678,7 +704,7 @@ func builtinAutoCast(args ...ZyloObject) ZyloObject {
 
 	if changed {
 		// Retornar tuple-like con objetos convertidos
-		result := &Map{Pairs: map[string]ZyloObject{}}
+		result := &Map{Pairs: map[string]ZyloObject{}, Order: []string{"left_casted", "right_casted", "changed"}}
 		result.Pairs["left_casted"] = newLeft
 		result.Pairs["right_casted"] = newRight
 		result.Pairs["changed"] = &Bool{Value: changed}
@@ -1305,7 +1331,7 @@ func builtinMapSet(args ...ZyloObject) ZyloObject {
 	}
 	newPairs[key.Value] = args[2]
 	
-	return &Map{Pairs: newPairs}
+	return &Map{Pairs: newPairs, Order: append(m.Keys(), key.Value)}
 }
 
 func builtinMapHas(args ...ZyloObject) ZyloObject {
@@ -1335,7 +1361,7 @@ func builtinMapKeys(args ...ZyloObject) ZyloObject {
 	}
 	
 	keys := make([]ZyloObject, 0, len(m.Pairs))
-	for k := range m.Pairs {
+	for _, k := range m.Keys() {
 		keys = append(keys, &String{Value: k})
 	}
 	return &List{Elements: keys}
@@ -1351,12 +1377,259 @@ func builtinMapValues(args ...ZyloObject) ZyloObject {
 	}
 	
 	values := make([]ZyloObject, 0, len(m.Pairs))
-	for _, v := range m.Pairs {
-		values = append(values, v)
+	for _, k := range m.Keys() {
+		values = append(values, m.Pairs[k])
 	}
 	return &List{Elements: values}
 }
 
+// builtinDeepMerge combina dos mapas recursivamente en uno nuevo, sin modificar
+// los originales. Si una clave tiene un mapa en ambos lados se combinan; en
+// cualquier otro caso gana el valor de la derecha. Las listas se reemplazan,
+// no se concatenan.
+func builtinDeepMerge(args ...ZyloObject) ZyloObject {
+	if len(args) != 2 {
+		return NewError("deep_merge expects 2 arguments, got %d", len(args))
+	}
+	left, ok := args[0].(*Map)
+	if !ok {
+		return NewError("deep_merge expects a map as first argument, got %s", args[0].Type())
+	}
+	right, ok := args[1].(*Map)
+	if !ok {
+		return NewError("deep_merge expects a map as second argument, got %s", args[1].Type())
+	}
+	return deepMerge(left, right)
+}
+
+func deepMerge(left, right *Map) *Map {
+	pairs := make(map[string]ZyloObject, len(left.Pairs)+len(right.Pairs))
+	for k, v := range left.Pairs {
+		pairs[k] = v
+	}
+	for k, v := range right.Pairs {
+		leftMap, leftIsMap := pairs[k].(*Map)
+		rightMap, rightIsMap := v.(*Map)
+		if leftIsMap && rightIsMap {
+			pairs[k] = deepMerge(leftMap, rightMap)
+		} else {
+			pairs[k] = v
+		}
+	}
+	// Primero las claves de left y después las nuevas de right, cada una en
+	// su orden de inserción
+	return &Map{Pairs: pairs, Order: append(left.Keys(), right.Keys()...)}
+}
+
+// builtinGetPath recorre mapas y listas anidados siguiendo una ruta con
+// puntos ("a.b.0.c"); los segmentos numéricos indexan listas. Devuelve null si
+// la ruta no existe.
+func builtinGetPath(args ...ZyloObject) ZyloObject {
+	if len(args) != 2 {
+		return NewError("get_path expects 2 arguments, got %d", len(args))
+	}
+	path, ok := args[1].(*String)
+	if !ok {
+		return NewError("get_path expects a string path, got %s", args[1].Type())
+	}
+
+	current := args[0]
+	for _, segment := range splitPath(path.Value) {
+		switch c := current.(type) {
+		case *Map:
+			value, exists := c.Pairs[segment]
+			if !exists {
+				return NewNull()
+			}
+			current = value
+		case *List:
+			index, err := strconv.Atoi(segment)
+			if err != nil || index < 0 || index >= len(c.Elements) {
+				return NewNull()
+			}
+			current = c.Elements[index]
+		default:
+			return NewNull()
+		}
+	}
+	return current
+}
+
+// builtinSetPath devuelve una copia de value con el elemento de la ruta
+// reemplazado. Solo se copian los mapas y listas del camino; los argumentos no
+// se modifican. El último segmento puede crear una clave nueva en un mapa,
+// pero los intermedios deben existir.
+func builtinSetPath(args ...ZyloObject) ZyloObject {
+	if len(args) != 3 {
+		return NewError("set_path expects 3 arguments, got %d", len(args))
+	}
+	path, ok := args[1].(*String)
+	if !ok {
+		return NewError("set_path expects a string path, got %s", args[1].Type())
+	}
+	return setPath(args[0], splitPath(path.Value), args[2], path.Value)
+}
+
+func setPath(current ZyloObject, segments []string, value ZyloObject, path string) ZyloObject {
+	if len(segments) == 0 {
+		return value
+	}
+	segment, rest := segments[0], segments[1:]
+
+	switch c := current.(type) {
+	case *Map:
+		existing, exists := c.Pairs[segment]
+		if !exists && len(rest) > 0 {
+			return NewError("set_path: key '%s' not found in path '%s'", segment, path)
+		}
+		updated := setPath(existing, rest, value, path)
+		if err, isErr := updated.(*Error); isErr {
+			return err
+		}
+		pairs := make(map[string]ZyloObject, len(c.Pairs)+1)
+		for k, v := range c.Pairs {
+			pairs[k] = v
+		}
+		pairs[segment] = updated
+		return &Map{Pairs: pairs, Order: append(c.Keys(), segment)}
+	case *List:
+		index, err := strconv.Atoi(segment)
+		if err != nil {
+			return NewError("set_path: '%s' is not a list index in path '%s'", segment, path)
+		}
+		if index < 0 || index >= len(c.Elements) {
+			return NewError("set_path: index %d out of range in path '%s'", index, path)
+		}
+		updated := setPath(c.Elements[index], rest, value, path)
+		if err, isErr := updated.(*Error); isErr {
+			return err
+		}
+		elements := make([]ZyloObject, len(c.Elements))
+		copy(elements, c.Elements)
+		elements[index] = updated
+		return &List{Elements: elements}
+	default:
+		return NewError("set_path: cannot traverse '%s' in path '%s'", segment, path)
+	}
+}
+
+// splitPath divide una ruta con puntos; la ruta vacía se refiere a la raíz
+func splitPath(path string) []string {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, ".")
+}
+
+// schemaTypes asocia los nombres de tipo de un esquema de validate con los
+// tipos de objeto del runtime. "number" acepta enteros y flotantes.
+var schemaTypes = map[string][]ObjectType{
+	"string": {STRING_OBJ},
+	"int":    {INTEGER_OBJ},
+	"float":  {FLOAT_OBJ},
+	"number": {INTEGER_OBJ, FLOAT_OBJ},
+	"bool":   {BOOL_OBJ},
+	"null":   {NULL_OBJ},
+	"list":   {LIST_OBJ},
+	"map":    {MAP_OBJ},
+}
+
+// builtinValidate comprueba value contra un esquema y devuelve la lista de
+// errores encontrados (vacía si es válido). Un esquema es el nombre de un tipo
+// ("string", "int", "any", ...; con "?" al final el campo es opcional), un
+// mapa con el esquema de cada campo o una lista con el esquema de sus
+// elementos.
+func builtinValidate(args ...ZyloObject) ZyloObject {
+	if len(args) != 2 {
+		return NewError("validate expects 2 arguments, got %d", len(args))
+	}
+	var errors []ZyloObject
+	if err := validateValue(args[0], args[1], "value", &errors); err != nil {
+		return err
+	}
+	if errors == nil {
+		errors = []ZyloObject{}
+	}
+	return &List{Elements: errors}
+}
+
+// validateValue añade a errors los problemas de value en path. Solo devuelve
+// un error si el esquema en sí es inválido.
+func validateValue(value, schema ZyloObject, path string, errors *[]ZyloObject) *Error {
+	switch s := schema.(type) {
+	case *String:
+		name := strings.TrimSuffix(s.Value, "?")
+		if name == "any" {
+			return nil
+		}
+		expected, ok := schemaTypes[name]
+		if !ok {
+			return NewError("validate: unknown type '%s' in schema at '%s'", s.Value, path)
+		}
+		for _, t := range expected {
+			if value.Type() == t {
+				return nil
+			}
+		}
+		if value.Type() == NULL_OBJ && strings.HasSuffix(s.Value, "?") {
+			return nil
+		}
+		*errors = append(*errors, NewString(fmt.Sprintf("%s: expected %s, got %s", path, name, schemaTypeName(value))))
+	case *Map:
+		m, ok := value.(*Map)
+		if !ok {
+			*errors = append(*errors, NewString(fmt.Sprintf("%s: expected map, got %s", path, schemaTypeName(value))))
+			return nil
+		}
+		keys := make([]string, 0, len(s.Pairs))
+		for k := range s.Pairs {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fieldSchema := s.Pairs[k]
+			field, exists := m.Pairs[k]
+			if !exists {
+				if optional, ok := fieldSchema.(*String); ok && strings.HasSuffix(optional.Value, "?") {
+					continue
+				}
+				*errors = append(*errors, NewString(fmt.Sprintf("%s.%s: missing field", path, k)))
+				continue
+			}
+			if err := validateValue(field, fieldSchema, path+"."+k, errors); err != nil {
+				return err
+			}
+		}
+	case *List:
+		if len(s.Elements) != 1 {
+			return NewError("validate: list schema at '%s' must have exactly 1 element, got %d", path, len(s.Elements))
+		}
+		l, ok := value.(*List)
+		if !ok {
+			*errors = append(*errors, NewString(fmt.Sprintf("%s: expected list, got %s", path, schemaTypeName(value))))
+			return nil
+		}
+		for i, element := range l.Elements {
+			if err := validateValue(element, s.Elements[0], fmt.Sprintf("%s.%d", path, i), errors); err != nil {
+				return err
+			}
+		}
+	default:
+		return NewError("validate: invalid schema at '%s': %s", path, schema.Type())
+	}
+	return nil
+}
+
+// schemaTypeName devuelve el nombre que usa el esquema para el tipo de value
+func schemaTypeName(value ZyloObject) string {
+	for name, types := range schemaTypes {
+		if len(types) == 1 && types[0] == value.Type() {
+			return name
+		}
+	}
+	return strings.ToLower(string(value.Type()))
+}
+
 // --- Funciones matemáticas adicionales ---
 
 func builtinFloor(args ...ZyloObject) ZyloObject {
@@ -1474,6 +1747,163 @@ func builtinEndsWith(args ...ZyloObject) ZyloObject {
 	return &Bool{Value: strings.HasSuffix(s.Value, suffix.Value)}
 }
 
+// --- Funciones de localización ---
+
+// numberFormat describe cómo escribe los números un locale
+type numberFormat struct {
+	group   string // Separador de miles
+	decimal string // Separador decimal
+}
+
+// numberFormats contiene los locales soportados, por idioma o por idioma y
+// región. "de-DE" o "de_DE" usan el formato de "de"; "de-CH" tiene el suyo.
+var numberFormats = map[string]numberFormat{
+	"en":    {group: ",", decimal: "."},
+	"es":    {group: ".", decimal: ","},
+	"de":    {group: ".", decimal: ","},
+	"it":    {group: ".", decimal: ","},
+	"pt":    {group: ".", decimal: ","},
+	"nl":    {group: ".", decimal: ","},
+	"fr":    {group: "\u202f", decimal: ","},
+	"de-ch": {group: "'", decimal: "."},
+}
+
+// lookupNumberFormat busca el formato de un locale por su idioma y región o,
+// si no hay uno para la región, por su idioma
+func lookupNumberFormat(name string, locale ZyloObject) (numberFormat, *Error) {
+	l, ok := locale.(*String)
+	if !ok {
+		return numberFormat{}, NewError("%s expects a string locale, got %s", name, locale.Type())
+	}
+	tag := strings.ToLower(strings.ReplaceAll(l.Value, "_", "-"))
+	format, ok := numberFormats[tag]
+	if !ok {
+		lang, _, _ := strings.Cut(tag, "-")
+		format, ok = numberFormats[lang]
+	}
+	if !ok {
+		return numberFormat{}, NewError("%s: unsupported locale '%s'", name, l.Value)
+	}
+	return format, nil
+}
+
+// builtinParseNumber convierte un número escrito según un locale
+// ("1.234,56" en "de") a entero o flotante.
+func builtinParseNumber(args ...ZyloObject) ZyloObject {
+	if len(args) != 2 {
+		return NewError("parse_number expects 2 arguments, got %d", len(args))
+	}
+	str, ok := args[0].(*String)
+	if !ok {
+		return NewError("parse_number expects a string, got %s", args[0].Type())
+	}
+	format, err := lookupNumberFormat("parse_number", args[1])
+	if err != nil {
+		return err
+	}
+
+	text := strings.TrimSpace(str.Value)
+	if format.group == "\u202f" {
+		// En francés también se agrupa con espacios normales o no separables
+		text = strings.NewReplacer(" ", "\u202f", "\u00a0", "\u202f").Replace(text)
+	}
+	intPart, fracPart, hasFrac := strings.Cut(text, format.decimal)
+	if !validGrouping(intPart, format.group) || strings.Contains(fracPart, format.group) {
+		return NewError("parse_number: invalid number '%s'", str.Value)
+	}
+	text = strings.ReplaceAll(intPart, format.group, "")
+	if hasFrac {
+		text += "." + fracPart
+	}
+
+	if i, convErr := strconv.ParseInt(text, 10, 64); convErr == nil {
+		return &Integer{Value: i}
+	}
+	if f, convErr := strconv.ParseFloat(text, 64); convErr == nil && !strings.ContainsAny(text, "eEnN") {
+		return &Float{Value: f}
+	}
+	return NewError("parse_number: invalid number '%s'", str.Value)
+}
+
+// validGrouping indica si los separadores de miles de intPart están en su
+// sitio: el primer grupo tiene de 1 a 3 dígitos y los demás, 3
+func validGrouping(intPart, group string) bool {
+	groups := strings.Split(strings.TrimLeft(intPart, "+-"), group)
+	if len(groups) == 1 {
+		return true
+	}
+	if len(groups[0]) < 1 || len(groups[0]) > 3 {
+		return false
+	}
+	for _, g := range groups[1:] {
+		if len(g) != 3 {
+			return false
+		}
+	}
+	return true
+}
+
+// builtinFormatNumber escribe un número con los separadores de un locale
+func builtinFormatNumber(args ...ZyloObject) ZyloObject {
+	if len(args) != 2 {
+		return NewError("format_number expects 2 arguments, got %d", len(args))
+	}
+	var text string
+	switch n := args[0].(type) {
+	case *Integer:
+		text = strconv.FormatInt(n.Value, 10)
+	case *Float:
+		text = strconv.FormatFloat(n.Value, 'f', -1, 64)
+	default:
+		return NewError("format_number expects a number, got %s", args[0].Type())
+	}
+	format, err := lookupNumberFormat("format_number", args[1])
+	if err != nil {
+		return err
+	}
+
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	intPart, fracPart, hasFrac := strings.Cut(text, ".")
+
+	var grouped strings.Builder
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			grouped.WriteString(format.group)
+		}
+		grouped.WriteRune(digit)
+	}
+
+	result := sign + grouped.String()
+	if hasFrac {
+		result += format.decimal + fracPart
+	}
+	return &String{Value: result}
+}
+
+// builtinEqualsIgnoreCase compara dos strings sin distinguir mayúsculas,
+// usando el plegado de mayúsculas de Unicode ("ß" equivale a "SS").
+func builtinEqualsIgnoreCase(args ...ZyloObject) ZyloObject {
+	if len(args) != 2 {
+		return NewError("equals_ignore_case expects 2 arguments, got %d", len(args))
+	}
+	a, ok := args[0].(*String)
+	if !ok {
+		return NewError("equals_ignore_case expects a string, got %s", args[0].Type())
+	}
+	b, ok := args[1].(*String)
+	if !ok {
+		return NewError("equals_ignore_case expects a string, got %s", args[1].Type())
+	}
+	return &Bool{Value: strings.EqualFold(foldSpecialCases(a.Value), foldSpecialCases(b.Value))}
+}
+
+// foldSpecialCases expande las letras cuyo plegado ocupa varias runas, que
+// strings.EqualFold no contempla
+var foldSpecialCases = strings.NewReplacer("ß", "ss", "ẞ", "ss", "ﬁ", "fi", "ﬂ", "fl").Replace
+
 // --- Funciones para el Code Generator ---
 
 // ToInt convierte un valor interface{} a un objeto Integer
@@ -1728,7 +2158,7 @@ func MapSet(m interface{}, key interface{}, value interface{}) interface{} {
 			newPairs[k] = v
 		}
 		newPairs[keyStr] = ToZyloObject(value)
-		return &Map{Pairs: newPairs}
+		return &Map{Pairs: newPairs, Order: append(mapObj.Keys(), keyStr)}
 	}
 	return NewError("MapSet expects a Map as first argument")
 }
@@ -1783,7 +2213,7 @@ func MapDelete(m interface{}, key interface{}) interface{} {
 				newPairs[k] = v
 			}
 		}
-		return &Map{Pairs: newPairs}
+		return &Map{Pairs: newPairs, Order: mapObj.Keys()}
 	}
 	return NewError("MapDelete expects a Map as first argument")
 }
@@ -1808,7 +2238,7 @@ func MapSize(m interface{}) interface{} {
 func MapKeys(m interface{}) interface{} {
 	if mapObj, ok := m.(*Map); ok {
 		keys := make([]ZyloObject, 0, len(mapObj.Pairs))
-		for k := range mapObj.Pairs {
+		for _, k := range mapObj.Keys() {
 			keys = append(keys, &String{Value: k})
 		}
 		return &List{Elements: keys}
@@ -1820,8 +2250,8 @@ func MapKeys(m interface{}) interface{} {
 func MapValues(m interface{}) interface{} {
 	if mapObj, ok := m.(*Map); ok {
 		values := make([]ZyloObject, 0, len(mapObj.Pairs))
-		for _, v := range mapObj.Pairs {
-			values = append(values, v)
+		for _, k := range mapObj.Keys() {
+			values = append(values, mapObj.Pairs[k])
 		}
 		return &List{Elements: values}
 	}
@@ -1832,8 +2262,8 @@ func MapValues(m interface{}) interface{} {
 func MapEntries(m interface{}) interface{} {
 	if mapObj, ok := m.(*Map); ok {
 		entries := make([]ZyloObject, 0, len(mapObj.Pairs))
-		for k, v := range mapObj.Pairs {
-			pair := []ZyloObject{&String{Value: k}, v}
+		for _, k := range mapObj.Keys() {
+			pair := []ZyloObject{&String{Value: k}, mapObj.Pairs[k]}
 			entries = append(entries, &List{Elements: pair})
 		}
 		return &List{Elements: entries}
@@ -1918,6 +2348,10 @@ func GetExtendedBuiltins() map[string]*Builtin {
 	builtins["map_has"] = NewBuiltin(builtinMapHas)
 	builtins["map_keys"] = NewBuiltin(builtinMapKeys)
 	builtins["map_values"] = NewBuiltin(builtinMapValues)
+	builtins["deep_merge"] = NewBuiltin(builtinDeepMerge)
+	builtins["get_path"] = NewBuiltin(builtinGetPath)
+	builtins["set_path"] = NewBuiltin(builtinSetPath)
+	builtins["validate"] = NewBuiltin(builtinValidate)
 
 	builtins["floor"] = NewBuiltin(builtinFloor)
 	builtins["ceil"] = NewBuiltin(builtinCeil)
@@ -1928,6 +2362,9 @@ func GetExtendedBuiltins() map[string]*Builtin {
 	builtins["contains"] = NewBuiltin(builtinContains)
 	builtins["starts_with"] = NewBuiltin(builtinStartsWith)
 	builtins["ends_with"] = NewBuiltin(builtinEndsWith)
+	builtins["equals_ignore_case"] = NewBuiltin(builtinEqualsIgnoreCase)
+	builtins["parse_number"] = NewBuiltin(builtinParseNumber)
+	builtins["format_number"] = NewBuiltin(builtinFormatNumber)
 
 	builtins["string_list"] = NewBuiltin(builtinStringList)
 	builtins["string_map"] = NewBuiltin(builtinStringMap)